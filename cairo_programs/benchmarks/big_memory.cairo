@@ -0,0 +1,19 @@
+// Recurses n levels deep, each frame writing a few cells, so that the execution segment holds
+// several hundred thousand cells once the run ends
+func fill(n) -> (sum: felt) {
+    alloc_locals;
+    local a = n * 2;
+    local b = a + 1;
+    if (n == 0) {
+        return (sum=0);
+    }
+    let (rest) = fill(n - 1);
+    return (sum=rest + a + b);
+}
+
+func main() {
+    // Make sure the sum of 4n + 1 for n in [1, 100000] is 20000300000
+    let (sum) = fill(100000);
+    assert sum = 20000300000;
+    return ();
+}
@@ -0,0 +1,33 @@
+%builtins range_check bitwise
+
+from starkware.cairo.common.alloc import alloc
+from starkware.cairo.common.cairo_builtins import BitwiseBuiltin
+from starkware.cairo.common.cairo_keccak.keccak import cairo_keccak_felts, finalize_keccak
+from starkware.cairo.common.uint256 import Uint256
+
+// Hashes [seed, seed + 1, seed + 2] n times, each hash seeding the next one
+func hash_chain{range_check_ptr, bitwise_ptr: BitwiseBuiltin*, keccak_ptr: felt*}(seed, n) -> (
+    res: felt
+) {
+    alloc_locals;
+    if (n == 0) {
+        return (res=seed);
+    }
+    let (elements) = alloc();
+    assert elements[0] = seed;
+    assert elements[1] = seed + 1;
+    assert elements[2] = seed + 2;
+    let (hash: Uint256) = cairo_keccak_felts(n_elements=3, elements=elements);
+    return hash_chain(seed=hash.low, n=n - 1);
+}
+
+func main{range_check_ptr, bitwise_ptr: BitwiseBuiltin*}() {
+    alloc_locals;
+    let (local keccak_ptr_start: felt*) = alloc();
+    let keccak_ptr = keccak_ptr_start;
+    with keccak_ptr {
+        hash_chain(seed=0, n=100);
+    }
+    finalize_keccak(keccak_ptr_start=keccak_ptr_start, keccak_ptr_end=keccak_ptr);
+    return ();
+}
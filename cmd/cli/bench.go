@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/metrics"
+	"runtime/pprof"
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+// Results of running a program several times
+type benchResult struct {
+	Runs  int
+	Steps uint
+	Total time.Duration
+	Min   time.Duration
+	Max   time.Duration
+	// Time spent in the PreStep hook running hints, and running instructions, over all runs
+	Hints        time.Duration
+	Instructions time.Duration
+	// Highest heap in use, sampled every heapSampleInterval steps and at the end of each run
+	PeakHeap uint64
+}
+
+// Number of steps between two samples of the heap size
+const heapSampleInterval = 1024
+
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// Times the hooks of a run, separating the hints run by PreStep from the instructions
+type stepTimer struct {
+	hooks      vm.Hooks
+	hints      time.Duration
+	start, end time.Time
+	steps      uint
+	heap       []metrics.Sample
+	peakHeap   uint64
+}
+
+func newStepTimer(hooks vm.Hooks) *stepTimer {
+	return &stepTimer{hooks: hooks, heap: []metrics.Sample{{Name: heapMetric}}}
+}
+
+func (t *stepTimer) preStep(v *vm.VirtualMachine) error {
+	now := time.Now()
+	if t.start.IsZero() {
+		t.start = now
+	}
+	if t.hooks.PreStep == nil {
+		return nil
+	}
+	err := t.hooks.PreStep(v)
+	t.hints += time.Since(now)
+	return err
+}
+
+func (t *stepTimer) postStep(v *vm.VirtualMachine) error {
+	var err error
+	if t.hooks.PostStep != nil {
+		err = t.hooks.PostStep(v)
+	}
+	t.end = time.Now()
+	t.steps++
+	if t.steps%heapSampleInterval == 0 {
+		t.sampleHeap()
+	}
+	return err
+}
+
+func (t *stepTimer) sampleHeap() {
+	metrics.Read(t.heap)
+	if t.heap[0].Value.Kind() == metrics.KindUint64 && t.heap[0].Value.Uint64() > t.peakHeap {
+		t.peakHeap = t.heap[0].Value.Uint64()
+	}
+}
+
+// Time spent running instructions, between the first and the last step, without the hints
+func (t *stepTimer) instructions() time.Duration {
+	if t.start.IsZero() {
+		return 0
+	}
+	return t.end.Sub(t.start) - t.hints
+}
+
+// Runs the bench subcommand, which runs a program several times and reports its performance
+func runBench(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run bench", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run bench [flags] COMPILED_JSON")
+		flags.PrintDefaults()
+	}
+	runs := flags.Int("runs", 10, "number of times the program is run")
+	proofMode := flags.Bool("proof_mode", false, "run the program in proof mode")
+	cpuProfile := flags.String("cpu_profile", "", "write a CPU profile of the runs to this file")
+
+	programPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+	if *runs <= 0 {
+		fmt.Fprintln(stderr, "--runs must be positive")
+		flags.Usage()
+		return exitUsageError
+	}
+
+	if *cpuProfile != "" {
+		profile, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to create CPU profile: %s\n", err)
+			return exitRunFailure
+		}
+		defer profile.Close()
+		if err := pprof.StartCPUProfile(profile); err != nil {
+			fmt.Fprintf(stderr, "Failed to start CPU profile: %s\n", err)
+			return exitRunFailure
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	result, err := bench(programPath, cairo_run.CairoRunConfig{ProofMode: *proofMode}, *runs)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	result.Print(stdout)
+	return exitOk
+}
+
+// Runs the program the given number of times, measuring each run from parsing to relocation. The
+// hooks of config are timed separately, as they run the hints
+func bench(programPath string, config cairo_run.CairoRunConfig, runs int) (benchResult, error) {
+	result := benchResult{Runs: runs}
+	hooks := config.Hooks
+	for i := 0; i < runs; i++ {
+		timer := newStepTimer(hooks)
+		config.Hooks = vm.Hooks{PreStep: timer.preStep, PostStep: timer.postStep}
+		start := time.Now()
+		cairoRunner, err := cairo_run.CairoRun(programPath, config)
+		elapsed := time.Since(start)
+		if err != nil {
+			return benchResult{}, err
+		}
+		// The runner is still alive, so the heap includes everything the run allocated
+		timer.sampleHeap()
+		if timer.peakHeap > result.PeakHeap {
+			result.PeakHeap = timer.peakHeap
+		}
+		result.Hints += timer.hints
+		result.Instructions += timer.instructions()
+		result.Steps = cairoRunner.Vm.CurrentStep
+		result.Total += elapsed
+		if i == 0 || elapsed < result.Min {
+			result.Min = elapsed
+		}
+		if elapsed > result.Max {
+			result.Max = elapsed
+		}
+	}
+	return result, nil
+}
+
+// Steps executed per second, over all runs
+func (r benchResult) StepsPerSecond() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Steps) * float64(r.Runs) / r.Total.Seconds()
+}
+
+func (r benchResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "Runs:          %d\n", r.Runs)
+	fmt.Fprintf(w, "Steps per run: %d\n", r.Steps)
+	fmt.Fprintf(w, "Mean time:     %s (min %s, max %s)\n", r.Total/time.Duration(r.Runs), r.Min, r.Max)
+	fmt.Fprintf(w, "Instructions:  %s per run\n", r.Instructions/time.Duration(r.Runs))
+	fmt.Fprintf(w, "Hints:         %s per run\n", r.Hints/time.Duration(r.Runs))
+	fmt.Fprintf(w, "Steps/second:  %.0f\n", r.StepsPerSecond())
+	fmt.Fprintf(w, "Peak heap:     %.2f MiB\n", float64(r.PeakHeap)/(1<<20))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+func TestBenchInvalidRuns(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bench", "--runs", "0", "a.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestBenchMissingProgram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bench", "missing.json"}, &stdout, &stderr); code != exitRunFailure {
+		t.Errorf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+}
+
+func TestBenchResultStepsPerSecond(t *testing.T) {
+	result := benchResult{Runs: 4, Steps: 500, Total: 2 * time.Second}
+	if result.StepsPerSecond() != 1000 {
+		t.Errorf("Wrong steps per second, got %f", result.StepsPerSecond())
+	}
+}
+
+func TestBenchFibonacci(t *testing.T) {
+	if _, err := os.Stat("../../cairo_programs/fibonacci.json"); err != nil {
+		t.Skip("fibonacci.json is not compiled")
+	}
+	profilePath := filepath.Join(t.TempDir(), "cpu.prof")
+	var stdout, stderr bytes.Buffer
+	args := []string{"bench", "--runs", "2", "--cpu_profile", profilePath, "../../cairo_programs/fibonacci.json"}
+	if code := run(args, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Steps/second:") {
+		t.Errorf("The report should include the steps per second, got %q", stdout.String())
+	}
+	if info, err := os.Stat(profilePath); err != nil || info.Size() == 0 {
+		t.Errorf("The CPU profile should have been written to %s", profilePath)
+	}
+}
+
+func TestBenchHintTime(t *testing.T) {
+	if _, err := os.Stat("../../cairo_programs/fibonacci.json"); err != nil {
+		t.Skip("fibonacci.json is not compiled")
+	}
+	hints := 0
+	config := cairo_run.CairoRunConfig{Hooks: vm.Hooks{PreStep: func(v *vm.VirtualMachine) error {
+		hints++
+		time.Sleep(time.Microsecond)
+		return nil
+	}}}
+	result, err := bench("../../cairo_programs/fibonacci.json", config, 1)
+	if err != nil {
+		t.Fatalf("bench failed with error: %s", err)
+	}
+	if hints != int(result.Steps) || result.Hints < time.Duration(hints)*time.Microsecond {
+		t.Errorf("The PreStep hook should run on every step and be timed as hints, got %d calls for %d steps and %s", hints, result.Steps, result.Hints)
+	}
+	if result.Instructions <= 0 || result.PeakHeap == 0 {
+		t.Errorf("Expected instruction time and heap to be measured, got %s and %d", result.Instructions, result.PeakHeap)
+	}
+}
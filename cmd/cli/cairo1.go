@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/cairovm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Runs the cairo1 subcommand, which calls an external entry point of a Cairo 1 contract class
+// with the given calldata and prints its return values. Returns exitRunFailure if it panics
+func runCairo1(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run cairo1", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run cairo1 [flags] CASM_JSON")
+		flags.PrintDefaults()
+	}
+	selector := flags.String("selector", "", "selector of the external entry point to call, required if the contract has several")
+	calldataFlag := flags.String("calldata", "", "comma separated calldata felts, in decimal or 0x prefixed hex")
+	gas := flags.Uint64("gas", 1_000_000_000, "gas available to the entry point")
+
+	casmPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+	calldata, err := parseCalldata(*calldataFlag)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		flags.Usage()
+		return exitUsageError
+	}
+
+	casm, err := parser.ParseCasm(casmPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	program, err := vm.DeserializeCasmContractClass(casm)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+
+	result := cairovm.RunProgram(program, cairovm.RunOptions{Selector: *selector, Calldata: calldata, InitialGas: *gas})
+	if result.Err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", result.Err)
+		return exitRunFailure
+	}
+	retdata := make([]string, 0, len(result.Retdata))
+	for _, value := range result.Retdata {
+		retdata = append(retdata, value.String())
+	}
+	if result.Failed {
+		fmt.Fprintf(stdout, "Panicked with [%s]\n", strings.Join(retdata, ", "))
+		return exitRunFailure
+	}
+	fmt.Fprintf(stdout, "Return values: [%s]\n", strings.Join(retdata, ", "))
+	fmt.Fprintf(stdout, "Gas left: %d\n", result.Gas)
+	return exitOk
+}
+
+func parseCalldata(calldata string) ([]lambdaworks.Felt, error) {
+	var felts []lambdaworks.Felt
+	if calldata == "" {
+		return felts, nil
+	}
+	for _, value := range strings.Split(calldata, ",") {
+		n, ok := new(big.Int).SetString(strings.TrimSpace(value), 0)
+		if !ok {
+			return nil, fmt.Errorf("Invalid calldata value %q", value)
+		}
+		felts = append(felts, lambdaworks.FeltFromBigInt(n))
+	}
+	return felts, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes a contract class whose external entry points return their calldata: 0x1 from offset 0,
+// 0x2 from offset 7, which panics with it instead
+func writeEchoCasm(t *testing.T) string {
+	casm := `{
+		"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+		"compiler_version": "2.1.0",
+		"bytecode": [
+			"0x480a7ffa7fff8000", "0x480a7ffb7fff8000", "0x480680017fff8000", "0x0", "0x480a7ffc7fff8000", "0x480a7ffd7fff8000", "0x208b7fff7fff7ffe",
+			"0x480a7ffa7fff8000", "0x480a7ffb7fff8000", "0x480680017fff8000", "0x1", "0x480a7ffc7fff8000", "0x480a7ffd7fff8000", "0x208b7fff7fff7ffe"
+		],
+		"hints": [],
+		"entry_points_by_type": {
+			"EXTERNAL": [{"selector": "0x1", "offset": 0, "builtins": []}, {"selector": "0x2", "offset": 7, "builtins": []}],
+			"L1_HANDLER": [],
+			"CONSTRUCTOR": []
+		}
+	}`
+	path := filepath.Join(t.TempDir(), "echo.casm.json")
+	os.WriteFile(path, []byte(casm), 0644)
+	return path
+}
+
+func TestCairo1ReturnValues(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"cairo1", "--selector", "0x1", "--calldata", "3,0x10", "--gas", "500", writeEchoCasm(t)}
+	if code := run(args, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s%s", exitOk, code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Return values: [3, 16]") || !strings.Contains(stdout.String(), "Gas left: 500") {
+		t.Errorf("Wrong output: %s", stdout.String())
+	}
+}
+
+func TestCairo1Panic(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"cairo1", "--selector", "2", "--calldata", "7", writeEchoCasm(t)}, &stdout, &stderr); code != exitRunFailure {
+		t.Fatalf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	if !strings.Contains(stdout.String(), "Panicked with [7]") {
+		t.Errorf("Wrong output: %s", stdout.String())
+	}
+}
+
+func TestCairo1AmbiguousEntryPoint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"cairo1", writeEchoCasm(t)}, &stdout, &stderr); code != exitRunFailure {
+		t.Fatalf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	if !strings.Contains(stderr.String(), "a selector is required") {
+		t.Errorf("Wrong error: %s", stderr.String())
+	}
+}
+
+func TestCairo1InvalidCalldata(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"cairo1", "--calldata", "1,x", writeEchoCasm(t)}, &stdout, &stderr); code != exitUsageError {
+		t.Fatalf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/differential"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// A flag that can be given multiple times, collecting every value
+type pathsFlag []string
+
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// Runs the compare subcommand, which reports the first divergence between two traces and/or
+// two memories in the cairo-lang binary format. Returns exitRunFailure if they differ
+func runCompare(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run compare", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run compare [--trace_file LEFT --trace_file RIGHT] [--memory_file LEFT --memory_file RIGHT]")
+		flags.PrintDefaults()
+	}
+	var traceFiles, memoryFiles pathsFlag
+	flags.Var(&traceFiles, "trace_file", "trace file to compare, must be given twice")
+	flags.Var(&memoryFiles, "memory_file", "memory file to compare, must be given twice")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitUsageError
+		}
+		fmt.Fprintln(stderr, err)
+		flags.Usage()
+		return exitUsageError
+	}
+	validPair := func(paths pathsFlag) bool { return len(paths) == 0 || len(paths) == 2 }
+	if flags.NArg() != 0 || !validPair(traceFiles) || !validPair(memoryFiles) || len(traceFiles)+len(memoryFiles) == 0 {
+		fmt.Fprintln(stderr, "Expected a pair of trace files and/or a pair of memory files")
+		flags.Usage()
+		return exitUsageError
+	}
+
+	equal := true
+	if len(traceFiles) == 2 {
+		left, right, err := loadPair(traceFiles, vm.DecodeTrace)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to load traces: %s\n", err)
+			return exitRunFailure
+		}
+		if diff := differential.DiffTraces(left, right); diff != "" {
+			fmt.Fprintln(stdout, diff)
+			equal = false
+		} else {
+			fmt.Fprintf(stdout, "Traces match (%d steps)\n", len(left))
+		}
+	}
+	if len(memoryFiles) == 2 {
+		left, right, err := loadPair(memoryFiles, vm.DecodeMemory)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to load memories: %s\n", err)
+			return exitRunFailure
+		}
+		if diff := differential.DiffMemories(left, right); diff != "" {
+			fmt.Fprintln(stdout, diff)
+			equal = false
+		} else {
+			fmt.Fprintf(stdout, "Memories match (%d cells)\n", countCells(left))
+		}
+	}
+
+	if !equal {
+		return exitRunFailure
+	}
+	return exitOk
+}
+
+// Decodes both files of a pair using decode
+func loadPair[T any](paths []string, decode func(io.Reader) (T, error)) (T, T, error) {
+	var decoded [2]T
+	for i, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return decoded[0], decoded[1], err
+		}
+		decoded[i], err = decode(bufio.NewReader(file))
+		file.Close()
+		if err != nil {
+			return decoded[0], decoded[1], fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return decoded[0], decoded[1], nil
+}
+
+func countCells(memory []*lambdaworks.Felt) int {
+	count := 0
+	for _, value := range memory {
+		if value != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+func writeTrace(t *testing.T, trace []vm.RelocatedTraceEntry) string {
+	var buffer bytes.Buffer
+	if err := vm.EncodeTrace(trace, &buffer); err != nil {
+		t.Fatalf("EncodeTrace failed with error: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "trace")
+	os.WriteFile(path, buffer.Bytes(), 0644)
+	return path
+}
+
+func writeMemory(t *testing.T, relocatedMemory []*lambdaworks.Felt) string {
+	var buffer bytes.Buffer
+	if err := vm.EncodeMemory(relocatedMemory, &buffer); err != nil {
+		t.Fatalf("EncodeMemory failed with error: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "memory")
+	os.WriteFile(path, buffer.Bytes(), 0644)
+	return path
+}
+
+func traceEntry(pc uint64, ap uint64, fp uint64) vm.RelocatedTraceEntry {
+	return vm.RelocatedTraceEntry{Pc: lambdaworks.FeltFromUint64(pc), Ap: lambdaworks.FeltFromUint64(ap), Fp: lambdaworks.FeltFromUint64(fp)}
+}
+
+func TestCompareEqualTraces(t *testing.T) {
+	trace := []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 5, 4)}
+	left, right := writeTrace(t, trace), writeTrace(t, trace)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"compare", "--trace_file", left, "--trace_file", right}, &stdout, &stderr); code != exitOk {
+		t.Errorf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+}
+
+func TestCompareDivergingTraces(t *testing.T) {
+	left := writeTrace(t, []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 5, 4)})
+	right := writeTrace(t, []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 6, 4)})
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"compare", "--trace_file", left, "--trace_file", right}, &stdout, &stderr); code != exitRunFailure {
+		t.Errorf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	expected := "Traces diverge at step 1:\n  left:  pc=3 ap=5 fp=4\n  right: pc=3 ap=6 fp=4\n"
+	if stdout.String() != expected {
+		t.Errorf("Wrong output. Expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestCompareDivergingMemories(t *testing.T) {
+	one, two := lambdaworks.FeltOne(), lambdaworks.FeltFromUint64(2)
+	left := writeMemory(t, []*lambdaworks.Felt{nil, &one, &two})
+	right := writeMemory(t, []*lambdaworks.Felt{nil, &one})
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"compare", "--memory_file", left, "--memory_file", right}, &stdout, &stderr); code != exitRunFailure {
+		t.Errorf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	expected := "Memories diverge at address 2:\n  left:  0x2 (2)\n  right: <empty>\n"
+	if stdout.String() != expected {
+		t.Errorf("Wrong output. Expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestCompareWithoutPairs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"compare", "--trace_file", "a.trace"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+	if code := run([]string{"compare"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/debugger"
+)
+
+// Runs the dap subcommand, a Debug Adapter Protocol server communicating over stdin and stdout.
+// The program to debug is given by the client in its launch request
+func runDap(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run dap", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run dap")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		if err == nil {
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+
+	if err := debugger.NewDAPServer(stdin, stdout).Serve(); err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	return exitOk
+}
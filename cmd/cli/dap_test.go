@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDapDisconnect(t *testing.T) {
+	content := `{"seq": 1, "type": "request", "command": "disconnect"}`
+	stdin := strings.NewReader("Content-Length: 54\r\n\r\n" + content)
+	var stdout, stderr bytes.Buffer
+	if code := runDap(nil, stdin, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"command":"disconnect"`) {
+		t.Errorf("The disconnect request should have been answered, got %q", stdout.String())
+	}
+}
+
+func TestDapUnexpectedArgument(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runDap([]string{"program.json"}, strings.NewReader(""), &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
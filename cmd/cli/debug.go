@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/debugger"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+const debugHelp = `Commands:
+  break, b PC|FILE:LINE   add a breakpoint
+  delete, d PC            remove a breakpoint
+  breakpoints             list the breakpoints
+  step, s                 run one instruction
+  next, n                 run one instruction, stepping over calls
+  continue, c             run until a breakpoint or the end of the program
+  regs, r                 print the registers
+  mem, x ADDR [COUNT]     print COUNT memory cells starting at ADDR (e.g. 1:4, ap, fp-3)
+  ids, i                  print the references visible at the current pc
+  where, w                print the current source location
+  help, h                 print this help
+  quit, q                 exit the debugger`
+
+// Runs the debug subcommand, an interactive stepper reading commands from stdin
+func runDebug(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run debug", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run debug COMPILED_JSON")
+		flags.PrintDefaults()
+	}
+
+	programPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+
+	programFile, err := os.Open(programPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	program, err := vm.ParseProgramStream(bufio.NewReader(programFile))
+	programFile.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	d, err := debugger.NewDebugger(program)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+
+	fmt.Fprintln(stdout, "Type help for the list of commands")
+	printStop(d, stdout)
+	scanner := bufio.NewScanner(stdin)
+	for {
+		fmt.Fprint(stdout, "(cairo) ")
+		if !scanner.Scan() {
+			fmt.Fprintln(stdout)
+			return exitOk
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			return exitOk
+		}
+		if err := debugCommand(d, fields[0], fields[1:], stdout); err != nil {
+			fmt.Fprintf(stdout, "Error: %s\n", err)
+		}
+	}
+}
+
+// Runs a single debugger command
+func debugCommand(d *debugger.Debugger, command string, args []string, out io.Writer) error {
+	switch command {
+	case "break", "b":
+		if len(args) != 1 {
+			return errors.New("expected a pc or a FILE:LINE location")
+		}
+		pc, err := parseBreakpoint(d, args[0])
+		if err != nil {
+			return err
+		}
+		d.AddBreakpoint(pc)
+		fmt.Fprintf(out, "Breakpoint set at pc %d\n", pc)
+	case "delete", "d":
+		if len(args) != 1 {
+			return errors.New("expected a pc")
+		}
+		pc, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid pc %s", args[0])
+		}
+		if !d.RemoveBreakpoint(uint(pc)) {
+			return fmt.Errorf("no breakpoint at pc %d", pc)
+		}
+	case "breakpoints":
+		for _, pc := range d.Breakpoints() {
+			fmt.Fprintf(out, "pc %d\n", pc)
+		}
+	case "step", "s":
+		return runAndReport(d, d.Step, out)
+	case "next", "n":
+		return runAndReport(d, d.Next, out)
+	case "continue", "c":
+		return runAndReport(d, d.Continue, out)
+	case "regs", "r":
+		context := d.Runner.Vm.RunContext
+		fmt.Fprintf(out, "pc = %s\nap = %s\nfp = %s\nstep = %d\n",
+			debugger.FormatAddress(context.Pc), debugger.FormatAddress(context.Ap), debugger.FormatAddress(context.Fp), d.Runner.Vm.CurrentStep)
+	case "mem", "x":
+		return printMemory(d, args, out)
+	case "ids", "i":
+		ids := d.Ids()
+		if len(ids) == 0 {
+			fmt.Fprintln(out, "No references visible at this pc")
+		}
+		for _, id := range ids {
+			if id.Err != nil {
+				fmt.Fprintf(out, "%s = <%s>\n", id.Name, id.Err)
+			} else {
+				fmt.Fprintf(out, "%s = %s\n", id.Name, debugger.FormatValue(&id.Value))
+			}
+		}
+	case "where", "w":
+		location, ok := d.Location()
+		if !ok {
+			return errors.New("no debug info for the current pc")
+		}
+		fmt.Fprintf(out, "%s:%d:%d\n", location.InputFile.Filename, location.StartLine, location.StartCol)
+	case "help", "h":
+		fmt.Fprintln(out, debugHelp)
+	default:
+		return fmt.Errorf("unknown command %s, type help for the list of commands", command)
+	}
+	return nil
+}
+
+func runAndReport(d *debugger.Debugger, run func() (debugger.StopReason, error), out io.Writer) error {
+	reason, err := run()
+	if err != nil {
+		return err
+	}
+	if reason == debugger.StopBreakpoint {
+		fmt.Fprintf(out, "Breakpoint hit at pc %d\n", d.PC())
+	}
+	printStop(d, out)
+	return nil
+}
+
+// Prints where the execution is stopped
+func printStop(d *debugger.Debugger, out io.Writer) {
+	if d.Finished() {
+		fmt.Fprintf(out, "Program finished after %d steps\n", d.Runner.Vm.CurrentStep)
+		return
+	}
+	if location, ok := d.Location(); ok {
+		fmt.Fprintf(out, "pc %d at %s:%d:%d\n", d.PC(), location.InputFile.Filename, location.StartLine, location.StartCol)
+		return
+	}
+	fmt.Fprintf(out, "pc %d\n", d.PC())
+}
+
+// Parses a breakpoint given either as a pc or as a FILE:LINE source location
+func parseBreakpoint(d *debugger.Debugger, location string) (uint, error) {
+	if colon := strings.LastIndex(location, ":"); colon >= 0 {
+		line, err := strconv.Atoi(location[colon+1:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid line in %s", location)
+		}
+		return d.PCForLine(location[:colon], line)
+	}
+	pc, err := strconv.ParseUint(location, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pc %s", location)
+	}
+	return uint(pc), nil
+}
+
+func printMemory(d *debugger.Debugger, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("expected an address and an optional count")
+	}
+	addr, err := parseAddress(d, args[0])
+	if err != nil {
+		return err
+	}
+	count := uint64(1)
+	if len(args) == 2 {
+		if count, err = strconv.ParseUint(args[1], 10, 0); err != nil {
+			return fmt.Errorf("invalid count %s", args[1])
+		}
+	}
+	cells, err := d.ReadMemory(addr, uint(count))
+	if err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		fmt.Fprintf(out, "%s: %s\n", debugger.FormatAddress(addr), debugger.FormatValue(cell))
+		addr.Offset++
+	}
+	return nil
+}
+
+// Parses an address given as SEGMENT:OFFSET, or as a register optionally followed by an offset (e.g. fp-3)
+func parseAddress(d *debugger.Debugger, addr string) (memory.Relocatable, error) {
+	if segment, offset, ok := strings.Cut(addr, ":"); ok {
+		segmentIndex, err := strconv.Atoi(segment)
+		if err != nil {
+			return memory.Relocatable{}, fmt.Errorf("invalid segment in %s", addr)
+		}
+		offsetValue, err := strconv.ParseUint(offset, 10, 0)
+		if err != nil {
+			return memory.Relocatable{}, fmt.Errorf("invalid offset in %s", addr)
+		}
+		return memory.NewRelocatable(segmentIndex, uint(offsetValue)), nil
+	}
+
+	context := d.Runner.Vm.RunContext
+	registers := map[string]memory.Relocatable{"ap": context.Ap, "fp": context.Fp, "pc": context.Pc}
+	if len(addr) < 2 {
+		return memory.Relocatable{}, fmt.Errorf("invalid address %s", addr)
+	}
+	register, ok := registers[addr[:2]]
+	if !ok {
+		return memory.Relocatable{}, fmt.Errorf("invalid address %s", addr)
+	}
+	if len(addr) == 2 {
+		return register, nil
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(addr[2:], "+"))
+	if err != nil {
+		return memory.Relocatable{}, fmt.Errorf("invalid offset in %s", addr)
+	}
+	if offset < 0 {
+		return register.SubUint(uint(-offset))
+	}
+	return register.AddUint(uint(offset))
+}
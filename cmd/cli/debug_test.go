@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// main calls a function at pc 3 that writes 5 at ap, and returns
+const debugProgram = `{
+	"data": ["0x1104800180018000", "0x3", "0x208b7fff7fff7ffe", "0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe"],
+	"builtins": [],
+	"identifiers": {"__main__.main": {"type": "function", "pc": 0, "decorators": []}},
+	"hints": {},
+	"reference_manager": {"references": []}
+}`
+
+func runDebugSession(t *testing.T, commands ...string) string {
+	programPath := filepath.Join(t.TempDir(), "program.json")
+	os.WriteFile(programPath, []byte(debugProgram), 0644)
+	stdin := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	if code := runDebug([]string{programPath}, stdin, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestDebugBreakpointAndMemory(t *testing.T) {
+	output := runDebugSession(t, "break 5", "continue", "regs", "mem ap-1", "continue", "quit")
+	for _, expected := range []string{
+		"Breakpoint set at pc 5",
+		"Breakpoint hit at pc 5",
+		"ap = 1:5",
+		"1:4: 5",
+		"Program finished after 4 steps",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("The output should contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestDebugStepAndNext(t *testing.T) {
+	output := runDebugSession(t, "next", "step")
+	if !strings.Contains(output, "pc 2\n") || !strings.Contains(output, "Program finished after 4 steps") {
+		t.Errorf("Wrong output:\n%s", output)
+	}
+}
+
+func TestDebugInvalidCommands(t *testing.T) {
+	output := runDebugSession(t, "jump", "break", "mem xx", "break main.cairo:3")
+	if strings.Count(output, "Error: ") != 4 {
+		t.Errorf("Every command should have failed, got:\n%s", output)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Runs the disasm subcommand, which prints the instruction listing of a compiled program
+func runDisasm(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run disasm", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run disasm COMPILED_JSON")
+		flags.PrintDefaults()
+	}
+
+	programPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+
+	programFile, err := os.Open(programPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	defer programFile.Close()
+	program, err := vm.ParseProgramStream(bufio.NewReader(programFile))
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+
+	writer := bufio.NewWriter(stdout)
+	for _, word := range vm.Disassemble(program) {
+		fmt.Fprintln(writer, word)
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	return exitOk
+}
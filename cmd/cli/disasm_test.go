@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisasmProgram(t *testing.T) {
+	program := `{"data": ["0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe"], "builtins": [], "identifiers": {}, "hints": {}, "reference_manager": {"references": []}}`
+	programPath := filepath.Join(t.TempDir(), "program.json")
+	os.WriteFile(programPath, []byte(program), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"disasm", programPath}, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "assert_eq") || !strings.Contains(lines[1], "immediate 5") || !strings.Contains(lines[2], "ret") {
+		t.Errorf("Wrong listing:\n%s", stdout.String())
+	}
+}
+
+func TestDisasmWithoutProgram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"disasm"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
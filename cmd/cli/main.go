@@ -1,35 +1,240 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"path/filepath"
 
+	"github.com/lambdaclass/cairo-vm.go/pkg/profiler"
+	"github.com/lambdaclass/cairo-vm.go/pkg/report"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
 )
 
+// Exit codes
+const (
+	exitOk         = 0
+	exitRunFailure = 1
+	exitUsageError = 2
+)
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Wrong argument count: Use go run cmd/cli/main.go COMPILED_JSON")
-		return
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Runs the cli with the given arguments (without the program name), returning the exit code
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "compare":
+			return runCompare(args[1:], stdout, stderr)
+		case "bench":
+			return runBench(args[1:], stdout, stderr)
+		case "disasm":
+			return runDisasm(args[1:], stdout, stderr)
+		case "debug":
+			return runDebug(args[1:], os.Stdin, stdout, stderr)
+		case "dap":
+			return runDap(args[1:], os.Stdin, stdout, stderr)
+		case "verify":
+			return runVerify(args[1:], stdout, stderr)
+		case "cairo1":
+			return runCairo1(args[1:], stdout, stderr)
+		}
+	}
+
+	flags := flag.NewFlagSet("cairo-run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run [flags] COMPILED_JSON")
+		fmt.Fprintln(stderr, "       cairo-run compare [flags]")
+		fmt.Fprintln(stderr, "       cairo-run bench [flags] COMPILED_JSON")
+		fmt.Fprintln(stderr, "       cairo-run disasm COMPILED_JSON")
+		fmt.Fprintln(stderr, "       cairo-run debug COMPILED_JSON")
+		fmt.Fprintln(stderr, "       cairo-run dap")
+		fmt.Fprintln(stderr, "       cairo-run verify --trace_file TRACE --memory_file MEMORY COMPILED_JSON")
+		fmt.Fprintln(stderr, "       cairo-run cairo1 [flags] CASM_JSON")
+		flags.PrintDefaults()
+	}
+
+	traceFile := flags.String("trace_file", "", "write the relocated trace to this file, in the cairo-lang binary format")
+	memoryFile := flags.String("memory_file", "", "write the relocated memory to this file, in the cairo-lang binary format")
+	airPublicInput := flags.String("air_public_input", "", "write the AIR public input to this file, requires --proof_mode")
+	airPrivateInput := flags.String("air_private_input", "", "write the AIR private input to this file, requires --proof_mode and --air_public_input")
+	profileOutput := flags.String("profile_output", "", "write a profile of the steps run by each Cairo function to this file, in the pprof format")
+	reportOutput := flags.String("report_output", "", "write an HTML report of the memory layout, memory accesses and call graph of the run to this file")
+	callGraphOutput := flags.String("call_graph_output", "", "write the call graph of the Cairo functions run to this file, in the Graphviz DOT format")
+	layout := flags.String("layout", "plain", "layout to run the program on, its builtins must all be present in it")
+	proofMode := flags.Bool("proof_mode", false, "run the program from __start__ to __end__ and pad the trace so it can be proven, requires --trace_file and --memory_file")
+	spillTrace := flags.Bool("spill_trace", false, "keep the trace in a temporary file instead of in memory, for runs whose trace doesn't fit in RAM")
+	secureRun := flags.Bool("secure_run", false, "check that hints didn't modify the program segment nor write out of bounds once the run ends")
+
+	programPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+	if *proofMode && (*traceFile == "" || *memoryFile == "") {
+		fmt.Fprintln(stderr, "--proof_mode requires --trace_file and --memory_file")
+		flags.Usage()
+		return exitUsageError
 	}
-	cli_args := os.Args[1:]
-	programPath := cli_args[0]
-	cairoRunner, err := cairo_run.CairoRun(programPath)
+	if (*airPublicInput != "" || *airPrivateInput != "") && !*proofMode {
+		fmt.Fprintln(stderr, "--air_public_input and --air_private_input require --proof_mode")
+		flags.Usage()
+		return exitUsageError
+	}
+	if *airPrivateInput != "" && *airPublicInput == "" {
+		fmt.Fprintln(stderr, "--air_private_input requires --air_public_input")
+		flags.Usage()
+		return exitUsageError
+	}
+
+	if *spillTrace && *reportOutput != "" {
+		fmt.Fprintln(stderr, "--report_output can't be used with --spill_trace")
+		flags.Usage()
+		return exitUsageError
+	}
+
+	config := cairo_run.CairoRunConfig{ProofMode: *proofMode, Layout: *layout, SpillTrace: *spillTrace, SecureRun: *secureRun}
+	var cairoProfiler *profiler.Profiler
+	if *profileOutput != "" || *reportOutput != "" || *callGraphOutput != "" {
+		cairoProfiler = profiler.NewProfiler()
+		config.Hooks.PreStep = cairoProfiler.WithHints(config.Hooks.PreStep)
+	}
+
+	cairoRunner, err := cairo_run.CairoRun(programPath, config)
 	if err != nil {
-		fmt.Printf("Failed with error: %s", err)
-		return
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	if cairoRunner.Vm.TraceSpill != nil {
+		defer cairoRunner.Vm.TraceSpill.Close()
+	}
+
+	if *traceFile != "" {
+		if err := writeFile(*traceFile, cairoRunner.Vm.WriteEncodedTrace); err != nil {
+			fmt.Fprintf(stderr, "Failed to write trace: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *memoryFile != "" {
+		if err := writeFile(*memoryFile, cairoRunner.Vm.WriteEncodedMemory); err != nil {
+			fmt.Fprintf(stderr, "Failed to write memory: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *profileOutput != "" {
+		err := writeFile(*profileOutput, func(w io.Writer) error { return cairoProfiler.WriteProfile(w, &cairoRunner.Program) })
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to write profile: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *callGraphOutput != "" {
+		if err := writeFile(*callGraphOutput, cairoProfiler.CallGraph(&cairoRunner.Program).WriteDOT); err != nil {
+			fmt.Fprintf(stderr, "Failed to write call graph: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *reportOutput != "" {
+		runReport, err := report.NewReport(cairoRunner, cairoProfiler)
+		if err == nil {
+			err = writeFile(*reportOutput, runReport.WriteHTML)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to write report: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *airPublicInput != "" {
+		publicInput, err := cairoRunner.GetAirPublicInput()
+		if err == nil {
+			err = writeFile(*airPublicInput, writeJSON(publicInput))
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to write AIR public input: %s\n", err)
+			return exitRunFailure
+		}
+	}
+
+	if *airPrivateInput != "" {
+		// The prover reads the trace and memory files from the paths in the private input
+		tracePath, err := filepath.Abs(*traceFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to write AIR private input: %s\n", err)
+			return exitRunFailure
+		}
+		memoryPath, err := filepath.Abs(*memoryFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to write AIR private input: %s\n", err)
+			return exitRunFailure
+		}
+		privateInput := cairoRunner.GetAirPrivateInput(tracePath, memoryPath)
+		if err := writeFile(*airPrivateInput, writeJSON(privateInput)); err != nil {
+			fmt.Fprintf(stderr, "Failed to write AIR private input: %s\n", err)
+			return exitRunFailure
+		}
 	}
-	traceFilePath := strings.Replace(programPath, ".json", ".go.trace", 1)
-	traceFile, err := os.OpenFile(traceFilePath, os.O_RDWR|os.O_CREATE, 0644)
-	defer traceFile.Close()
 
-	memoryFilePath := strings.Replace(programPath, ".json", ".go.memory", 1)
-	memoryFile, err := os.OpenFile(memoryFilePath, os.O_RDWR|os.O_CREATE, 0644)
-	defer memoryFile.Close()
+	fmt.Fprintln(stdout, "Done!")
+	return exitOk
+}
 
-	cairo_run.WriteEncodedTrace(cairoRunner.Vm.RelocatedTrace, traceFile)
-	cairo_run.WriteEncodedMemory(cairoRunner.Vm.RelocatedMemory, memoryFile)
+// Parses the flags and the program path, which may come before, after or between the flags
+func parseArgs(flags *flag.FlagSet, args []string) (string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return "", err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != 1 {
+		return "", fmt.Errorf("Wrong argument count: expected the path of a compiled program, got %d arguments", len(positional))
+	}
+	return positional[0], nil
+}
 
-	println("Done!")
+// Creates (or truncates) the file at path and writes to it using write
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if err := write(writer); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Returns a function writing value as indented JSON, to be used with writeFile
+func writeJSON(value any) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
 }
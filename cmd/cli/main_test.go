@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunWithoutProgram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunWithTooManyPrograms(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"a.json", "b.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunUnknownFlag(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--unknown", "a.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunProofModeWithoutOutputFiles(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--proof_mode", "--trace_file", "a.trace", "a.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunAirInputsWithoutProofMode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--air_public_input", "a.public.json", "a.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunAirPrivateInputWithoutPublicInput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"--proof_mode", "--trace_file", "a.trace", "--memory_file", "a.memory", "--air_private_input", "a.private.json", "a.json"}
+	if code := run(args, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
+
+func TestRunMissingProgram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"missing.json"}, &stdout, &stderr); code != exitRunFailure {
+		t.Errorf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	if stderr.Len() == 0 {
+		t.Errorf("The error should be reported on stderr")
+	}
+}
+
+func TestRunFibonacci(t *testing.T) {
+	program, err := os.ReadFile("../../cairo_programs/fibonacci.json")
+	if err != nil {
+		t.Skip("fibonacci.json is not compiled")
+	}
+	programPath := filepath.Join(t.TempDir(), "fibonacci.json")
+	os.WriteFile(programPath, program, 0644)
+
+	tracePath := filepath.Join(t.TempDir(), "fibonacci.trace")
+	memoryPath := filepath.Join(t.TempDir(), "fibonacci.memory")
+	profilePath := filepath.Join(t.TempDir(), "fibonacci.pprof")
+	reportPath := filepath.Join(t.TempDir(), "fibonacci.html")
+	callGraphPath := filepath.Join(t.TempDir(), "fibonacci.dot")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{programPath, "--trace_file", tracePath, "--memory_file", memoryPath, "--profile_output", profilePath, "--report_output", reportPath, "--call_graph_output", callGraphPath}
+	if code := run(args, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	// Each trace entry is encoded as three u64 values
+	if info, err := os.Stat(tracePath); err != nil || info.Size() == 0 || info.Size()%24 != 0 {
+		t.Errorf("The trace should have been written to %s", tracePath)
+	}
+	// Each memory cell is encoded as an 8 byte address and a 32 byte value
+	if info, err := os.Stat(memoryPath); err != nil || info.Size() == 0 || info.Size()%40 != 0 {
+		t.Errorf("The memory should have been written to %s", memoryPath)
+	}
+	if info, err := os.Stat(profilePath); err != nil || info.Size() == 0 {
+		t.Errorf("The profile should have been written to %s", profilePath)
+	}
+	if info, err := os.Stat(reportPath); err != nil || info.Size() == 0 {
+		t.Errorf("The report should have been written to %s", reportPath)
+	}
+	if info, err := os.Stat(callGraphPath); err != nil || info.Size() == 0 {
+		t.Errorf("The call graph should have been written to %s", callGraphPath)
+	}
+}
+
+func TestRunSpillTraceWithReport(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--spill_trace", "--report_output", "a.html", "a.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
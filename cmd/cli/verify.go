@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Runs the verify subcommand, which replays a relocated trace and memory against the program
+// they were produced from, checking every transition. Returns exitRunFailure if they don't match
+func runVerify(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cairo-run verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cairo-run verify --trace_file TRACE --memory_file MEMORY COMPILED_JSON")
+		flags.PrintDefaults()
+	}
+	traceFile := flags.String("trace_file", "", "relocated trace to verify, in the cairo-lang binary format")
+	memoryFile := flags.String("memory_file", "", "relocated memory to verify, in the cairo-lang binary format")
+
+	programPath, err := parseArgs(flags, args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+			flags.Usage()
+		}
+		return exitUsageError
+	}
+	if *traceFile == "" || *memoryFile == "" {
+		fmt.Fprintln(stderr, "verify requires --trace_file and --memory_file")
+		flags.Usage()
+		return exitUsageError
+	}
+
+	programFile, err := os.Open(programPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	defer programFile.Close()
+	program, err := vm.ParseProgramStream(bufio.NewReader(programFile))
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed with error: %s\n", err)
+		return exitRunFailure
+	}
+	trace, _, err := loadPair([]string{*traceFile}, vm.DecodeTrace)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load trace: %s\n", err)
+		return exitRunFailure
+	}
+	memory, _, err := loadPair([]string{*memoryFile}, vm.DecodeMemory)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load memory: %s\n", err)
+		return exitRunFailure
+	}
+
+	if err := vm.VerifyTrace(program, trace, memory); err != nil {
+		fmt.Fprintf(stdout, "Verification failed: %s\n", err)
+		return exitRunFailure
+	}
+	fmt.Fprintf(stdout, "Trace verified (%d steps)\n", len(trace))
+	return exitOk
+}
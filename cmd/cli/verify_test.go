@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Writes a program running [ap] = 5; ap++ followed by ret
+func writeVerifyProgram(t *testing.T) string {
+	program := `{"data": ["0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe"], "builtins": [], "identifiers": {}, "hints": {}, "reference_manager": {"references": []}}`
+	path := filepath.Join(t.TempDir(), "program.json")
+	os.WriteFile(path, []byte(program), 0644)
+	return path
+}
+
+func verifyMemory(result uint64) []*lambdaworks.Felt {
+	felt := func(value uint64) *lambdaworks.Felt {
+		f := lambdaworks.FeltFromUint64(value)
+		return &f
+	}
+	return []*lambdaworks.Felt{nil, felt(0x480680017fff8000), felt(5), felt(0x208b7fff7fff7ffe), felt(0), felt(0), felt(result)}
+}
+
+func TestVerifyValidTrace(t *testing.T) {
+	tracePath := writeTrace(t, []vm.RelocatedTraceEntry{traceEntry(1, 6, 6), traceEntry(3, 7, 6)})
+	memoryPath := writeMemory(t, verifyMemory(5))
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"verify", "--trace_file", tracePath, "--memory_file", memoryPath, writeVerifyProgram(t)}, &stdout, &stderr); code != exitOk {
+		t.Fatalf("Expected exit code %d, got %d: %s%s", exitOk, code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Trace verified (2 steps)") {
+		t.Errorf("Wrong output: %s", stdout.String())
+	}
+}
+
+func TestVerifyFailedAssertion(t *testing.T) {
+	tracePath := writeTrace(t, []vm.RelocatedTraceEntry{traceEntry(1, 6, 6), traceEntry(3, 7, 6)})
+	memoryPath := writeMemory(t, verifyMemory(6))
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"verify", "--trace_file", tracePath, "--memory_file", memoryPath, writeVerifyProgram(t)}, &stdout, &stderr); code != exitRunFailure {
+		t.Fatalf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+	if !strings.Contains(stdout.String(), "step 0") {
+		t.Errorf("The failing step should be reported: %s", stdout.String())
+	}
+}
+
+func TestVerifyWrongRegisters(t *testing.T) {
+	tracePath := writeTrace(t, []vm.RelocatedTraceEntry{traceEntry(1, 6, 6), traceEntry(3, 8, 6)})
+	memoryPath := writeMemory(t, verifyMemory(5))
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"verify", "--trace_file", tracePath, "--memory_file", memoryPath, writeVerifyProgram(t)}, &stdout, &stderr); code != exitRunFailure {
+		t.Errorf("Expected exit code %d, got %d", exitRunFailure, code)
+	}
+}
+
+func TestVerifyWithoutFiles(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"verify", "program.json"}, &stdout, &stderr); code != exitUsageError {
+		t.Errorf("Expected exit code %d, got %d", exitUsageError, code)
+	}
+}
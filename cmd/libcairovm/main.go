@@ -0,0 +1,107 @@
+// Exposes the VM through a C ABI, so that it can be embedded by applications written in other
+// languages. Build it as a shared library, which also generates the libcairovm.h header:
+//
+//	go build -buildmode=c-shared -o libcairovm.so ./cmd/libcairovm
+//
+// Functions return 0 on success and -1 on failure. On failure, the error message is stored in
+// *error if error is not NULL. Strings and buffers returned by the library belong to the caller,
+// which releases them with cairo_vm_free. Runs are released with cairo_vm_free_run.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+func main() {}
+
+func setError(errOut **C.char, err error) C.int {
+	if errOut != nil {
+		*errOut = C.CString(err.Error())
+	}
+	return -1
+}
+
+// Copies data into a buffer allocated by C
+func setBuffer(out **C.uint8_t, outLen *C.size_t, data []byte) C.int {
+	*out = (*C.uint8_t)(C.CBytes(data))
+	*outLen = C.size_t(len(data))
+	return 0
+}
+
+// Runs the compiled program at program_path, storing a handle to the run in *run
+//
+//export cairo_vm_run_program
+func cairo_vm_run_program(programPath *C.char, proofMode C.int, run *C.uintptr_t, errOut **C.char) C.int {
+	handle, err := runProgram(C.GoString(programPath), proofMode != 0)
+	if err != nil {
+		return setError(errOut, err)
+	}
+	*run = C.uintptr_t(handle)
+	return 0
+}
+
+// Stores the relocated trace of the run in *out, in the cairo-lang binary format
+//
+//export cairo_vm_get_trace
+func cairo_vm_get_trace(run C.uintptr_t, out **C.uint8_t, outLen *C.size_t, errOut **C.char) C.int {
+	trace, err := encodedTrace(uintptr(run))
+	if err != nil {
+		return setError(errOut, err)
+	}
+	return setBuffer(out, outLen, trace)
+}
+
+// Stores the relocated memory of the run in *out, in the cairo-lang binary format
+//
+//export cairo_vm_get_memory
+func cairo_vm_get_memory(run C.uintptr_t, out **C.uint8_t, outLen *C.size_t, errOut **C.char) C.int {
+	memory, err := encodedMemory(uintptr(run))
+	if err != nil {
+		return setError(errOut, err)
+	}
+	return setBuffer(out, outLen, memory)
+}
+
+// Stores the AIR public input of a proof mode run in *out, as a JSON string
+//
+//export cairo_vm_get_air_public_input
+func cairo_vm_get_air_public_input(run C.uintptr_t, out **C.char, errOut **C.char) C.int {
+	publicInput, err := airPublicInput(uintptr(run))
+	if err != nil {
+		return setError(errOut, err)
+	}
+	*out = C.CString(string(publicInput))
+	return 0
+}
+
+// Stores the number of steps run in *out
+//
+//export cairo_vm_get_steps
+func cairo_vm_get_steps(run C.uintptr_t, out *C.uint64_t, errOut **C.char) C.int {
+	n, err := steps(uintptr(run))
+	if err != nil {
+		return setError(errOut, err)
+	}
+	*out = C.uint64_t(n)
+	return 0
+}
+
+// Releases a run. Its handle is invalid afterwards
+//
+//export cairo_vm_free_run
+func cairo_vm_free_run(run C.uintptr_t) {
+	registry.remove(uintptr(run))
+}
+
+// Releases a string or buffer returned by the library
+//
+//export cairo_vm_free
+func cairo_vm_free(ptr unsafe.Pointer) {
+	C.free(ptr)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+// The finished runs held by the library, referred to from C by handles. Handles are never
+// reused, and 0 is never a valid one so that it can signal failures.
+type runRegistry struct {
+	mutex sync.Mutex
+	last  uintptr
+	runs  map[uintptr]*runners.CairoRunner
+}
+
+var registry = runRegistry{runs: make(map[uintptr]*runners.CairoRunner)}
+
+func (r *runRegistry) add(runner *runners.CairoRunner) uintptr {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.last++
+	r.runs[r.last] = runner
+	return r.last
+}
+
+func (r *runRegistry) get(handle uintptr) (*runners.CairoRunner, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	runner, ok := r.runs[handle]
+	if !ok {
+		return nil, errors.New("Invalid run handle")
+	}
+	return runner, nil
+}
+
+// Removes the run from the registry, returns false if there was no such run
+func (r *runRegistry) remove(handle uintptr) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, ok := r.runs[handle]
+	delete(r.runs, handle)
+	return ok
+}
+
+// Runs the compiled program at programPath and registers the run, returning its handle
+func runProgram(programPath string, proofMode bool) (uintptr, error) {
+	runner, err := cairo_run.CairoRun(programPath, cairo_run.CairoRunConfig{ProofMode: proofMode})
+	if err != nil {
+		return 0, err
+	}
+	return registry.add(runner), nil
+}
+
+// Returns the relocated trace of a run, in the cairo-lang binary format
+func encodedTrace(handle uintptr) ([]byte, error) {
+	runner, err := registry.get(handle)
+	if err != nil {
+		return nil, err
+	}
+	var trace bytes.Buffer
+	err = runner.Vm.WriteEncodedTrace(&trace)
+	return trace.Bytes(), err
+}
+
+// Returns the relocated memory of a run, in the cairo-lang binary format
+func encodedMemory(handle uintptr) ([]byte, error) {
+	runner, err := registry.get(handle)
+	if err != nil {
+		return nil, err
+	}
+	var memory bytes.Buffer
+	err = runner.Vm.WriteEncodedMemory(&memory)
+	return memory.Bytes(), err
+}
+
+// Returns the AIR public input of a proof mode run, as JSON
+func airPublicInput(handle uintptr) ([]byte, error) {
+	runner, err := registry.get(handle)
+	if err != nil {
+		return nil, err
+	}
+	publicInput, err := runner.GetAirPublicInput()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(publicInput)
+}
+
+// Returns the number of steps run
+func steps(handle uintptr) (uint, error) {
+	runner, err := registry.get(handle)
+	if err != nil {
+		return 0, err
+	}
+	return runner.Vm.CurrentStep, nil
+}
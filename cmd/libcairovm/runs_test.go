@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+const fibonacciPath = "../../cairo_programs/fibonacci.json"
+
+func runFibonacci(t *testing.T) uintptr {
+	if _, err := os.Stat(fibonacciPath); err != nil {
+		t.Skip("fibonacci.json is not compiled")
+	}
+	handle, err := runProgram(fibonacciPath, false)
+	if err != nil {
+		t.Fatalf("runProgram failed with error: %s", err)
+	}
+	t.Cleanup(func() { registry.remove(handle) })
+	return handle
+}
+
+func TestRunProgramOutputs(t *testing.T) {
+	handle := runFibonacci(t)
+	n, err := steps(handle)
+	if err != nil || n == 0 {
+		t.Fatalf("Expected some steps, got %d, err: %v", n, err)
+	}
+	trace, err := encodedTrace(handle)
+	if err != nil {
+		t.Fatalf("encodedTrace failed with error: %s", err)
+	}
+	// Each trace entry holds three 8 byte registers
+	if uint(len(trace)) != n*24 {
+		t.Errorf("Expected %d bytes of trace, got %d", n*24, len(trace))
+	}
+	memory, err := encodedMemory(handle)
+	if err != nil || len(memory) == 0 {
+		t.Fatalf("Expected some memory, got %d bytes, err: %v", len(memory), err)
+	}
+	if _, err := vm.DecodeMemory(bytes.NewReader(memory)); err != nil {
+		t.Errorf("The memory should be decodable: %s", err)
+	}
+}
+
+func TestRunProgramMissingFile(t *testing.T) {
+	if handle, err := runProgram("missing.json", false); err == nil || handle != 0 {
+		t.Errorf("runProgram should fail for a missing program")
+	}
+}
+
+func TestAirPublicInputRequiresProofMode(t *testing.T) {
+	handle := runFibonacci(t)
+	if _, err := airPublicInput(handle); err == nil {
+		t.Errorf("airPublicInput should fail outside of proof mode")
+	}
+}
+
+func TestFreedRunHandle(t *testing.T) {
+	handle := runFibonacci(t)
+	if !registry.remove(handle) || registry.remove(handle) {
+		t.Fatalf("A run should be removed exactly once")
+	}
+	if _, err := encodedTrace(handle); err == nil {
+		t.Errorf("A freed handle should be invalid")
+	}
+	if _, err := steps(0); err == nil {
+		t.Errorf("0 should never be a valid handle")
+	}
+}
@@ -0,0 +1,26 @@
+// A JSON-RPC 2.0 service running the Cairo programs it receives over HTTP. See Server for the methods
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+)
+
+func main() {
+	flags := flag.NewFlagSet("cairo-vm-server", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	maxSteps := flags.Uint("max_steps", 10_000_000, "abort runs after this many steps, 0 for no limit")
+	maxConcurrentRuns := flags.Int("max_concurrent_runs", runtime.NumCPU(), "runs handled at the same time, other requests wait")
+	maxRequestBytes := flags.Int64("max_request_bytes", 64<<20, "size limit of request bodies")
+	flags.Parse(os.Args[1:])
+
+	server := NewServer(Config{MaxSteps: *maxSteps, MaxConcurrentRuns: *maxConcurrentRuns, MaxRequestBytes: *maxRequestBytes})
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed with error: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	// The program was received but couldn't be run
+	codeRunFailed = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(err error) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("Invalid params: %s", err)}
+}
+
+func runFailed(err error) *rpcError {
+	return &rpcError{Code: codeRunFailed, Message: fmt.Sprintf("Run failed: %s", err)}
+}
+
+// A JSON-RPC method, returning a result or an error, which is reported as a run failure unless it is an *rpcError
+type rpcMethod func(params json.RawMessage) (any, error)
+
+// Serves JSON-RPC 2.0 requests sent by POST. Batches aren't supported
+type rpcHandler struct {
+	methods         map[string]rpcMethod
+	maxRequestBytes int64
+}
+
+func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC requests must be sent by POST", http.StatusMethodNotAllowed)
+		return
+	}
+	body := r.Body
+	if h.maxRequestBytes > 0 {
+		body = http.MaxBytesReader(w, body, h.maxRequestBytes)
+	}
+	response := h.handle(body)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *rpcHandler) handle(body io.Reader) rpcResponse {
+	response := rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null")}
+	var request rpcRequest
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		response.Error = &rpcError{Code: codeParseError, Message: fmt.Sprintf("Parse error: %s", err)}
+		return response
+	}
+	if request.ID != nil {
+		response.ID = request.ID
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		response.Error = &rpcError{Code: codeInvalidRequest, Message: "Invalid request: expected a JSON-RPC 2.0 request with a method"}
+		return response
+	}
+	method, ok := h.methods[request.Method]
+	if !ok {
+		response.Error = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("Method %s not found", request.Method)}
+		return response
+	}
+
+	result, err := method(request.Params)
+	if err != nil {
+		var methodErr *rpcError
+		if !errors.As(err, &methodErr) {
+			methodErr = runFailed(err)
+		}
+		response.Error = methodErr
+		return response
+	}
+	response.Result = result
+	return response
+}
+
+// Decodes the params of a request into params, rejecting unknown fields
+func decodeParams(raw json.RawMessage, params any) error {
+	if raw == nil {
+		return invalidParams(errors.New("missing params"))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return invalidParams(err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/cairovm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Limits applied to the requests handled by a Server
+type Config struct {
+	// Runs are aborted after this many steps, 0 means no limit
+	MaxSteps uint
+	// Runs past this count wait for another run to finish
+	MaxConcurrentRuns int
+	// Size limit of request bodies, programs included, 0 means no limit
+	MaxRequestBytes int64
+}
+
+// An http.Handler running the programs it receives through JSON-RPC. Each request gets its own
+// runner, so requests are handled concurrently, up to Config.MaxConcurrentRuns at a time
+type Server struct {
+	config  Config
+	slots   chan struct{}
+	handler rpcHandler
+}
+
+func NewServer(config Config) *Server {
+	if config.MaxConcurrentRuns <= 0 {
+		config.MaxConcurrentRuns = 1
+	}
+	s := &Server{config: config, slots: make(chan struct{}, config.MaxConcurrentRuns)}
+	s.handler = rpcHandler{
+		methods: map[string]rpcMethod{
+			"run_program": s.runProgram,
+			"run_cairo1":  s.runCairo1,
+		},
+		maxRequestBytes: config.MaxRequestBytes,
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Waits for a run slot, returns the function releasing it
+func (s *Server) acquire() func() {
+	s.slots <- struct{}{}
+	return func() { <-s.slots }
+}
+
+// Artifacts returned on request by both methods
+type artifactParams struct {
+	IncludeTrace  bool `json:"include_trace"`
+	IncludeMemory bool `json:"include_memory"`
+}
+
+// Resources used by a run, and its trace and memory in the cairo-lang binary format, base64 encoded
+type runResources struct {
+	runners.ExecutionResources
+	Trace  []byte `json:"trace,omitempty"`
+	Memory []byte `json:"memory,omitempty"`
+}
+
+func newRunResources(runner *runners.CairoRunner, params artifactParams) (runResources, error) {
+	resources := runResources{ExecutionResources: runner.GetExecutionResources()}
+	if params.IncludeTrace {
+		var trace bytes.Buffer
+		if err := runner.Vm.WriteEncodedTrace(&trace); err != nil {
+			return runResources{}, err
+		}
+		resources.Trace = trace.Bytes()
+	}
+	if params.IncludeMemory {
+		var memory bytes.Buffer
+		if err := runner.Vm.WriteEncodedMemory(&memory); err != nil {
+			return runResources{}, err
+		}
+		resources.Memory = memory.Bytes()
+	}
+	return resources, nil
+}
+
+type runProgramParams struct {
+	// The compiled program, as output by cairo-compile
+	Program json.RawMessage `json:"program"`
+	// Function to run instead of main
+	Entrypoint string `json:"entrypoint"`
+	// Felt arguments of the entrypoint, in decimal or 0x prefixed hex
+	Args      []string `json:"args"`
+	ProofMode bool     `json:"proof_mode"`
+	// Layout the program runs on, plain if empty
+	Layout string `json:"layout"`
+	artifactParams
+}
+
+type runProgramResult struct {
+	runResources
+	// Felts written to the output builtin, in decimal
+	Output         []string                `json:"output"`
+	AirPublicInput *runners.AirPublicInput `json:"air_public_input,omitempty"`
+}
+
+// Runs a Cairo 0 program from its main function, another entrypoint or __start__ in proof mode
+func (s *Server) runProgram(raw json.RawMessage) (any, error) {
+	var params runProgramParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Program == nil {
+		return nil, invalidParams(errors.New("missing program"))
+	}
+	if params.ProofMode && (params.Entrypoint != "" || params.Args != nil) {
+		return nil, invalidParams(errors.New("proof mode programs run from __start__, entrypoint and args can't be set"))
+	}
+	program, err := vm.ParseProgramStream(bytes.NewReader(params.Program))
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	if params.Layout != "" {
+		if _, err := runners.GetLayout(params.Layout); err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+	if params.Entrypoint != "" {
+		if _, err := program.GetEntrypoint(params.Entrypoint); err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+	options := cairovm.RunOptions{Entrypoint: params.Entrypoint, ProofMode: params.ProofMode, Layout: params.Layout, MaxSteps: s.config.MaxSteps}
+	if params.Args != nil {
+		args, err := parseFelts(params.Args)
+		if err != nil {
+			return nil, invalidParams(fmt.Errorf("invalid args: %w", err))
+		}
+		for _, arg := range args {
+			options.Args = append(options.Args, arg)
+		}
+	}
+
+	defer s.acquire()()
+	run := cairovm.RunProgram(program, options)
+	if run.Err != nil {
+		return nil, run.Err
+	}
+	result := runProgramResult{Output: feltStrings(run.Output)}
+	if result.runResources, err = newRunResources(run.Runner, params.artifactParams); err != nil {
+		return nil, err
+	}
+	if params.ProofMode {
+		publicInput, err := run.Runner.GetAirPublicInput()
+		if err != nil {
+			return nil, err
+		}
+		result.AirPublicInput = &publicInput
+	}
+	return result, nil
+}
+
+// Parses felts written in decimal or 0x prefixed hex
+func parseFelts(values []string) ([]lambdaworks.Felt, error) {
+	felts := make([]lambdaworks.Felt, 0, len(values))
+	for _, value := range values {
+		var felt lambdaworks.Felt
+		if err := felt.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("invalid felt %q", value)
+		}
+		felts = append(felts, felt)
+	}
+	return felts, nil
+}
+
+// Formats felts in decimal, returning an empty slice rather than nil so it's encoded as []
+func feltStrings(felts []lambdaworks.Felt) []string {
+	values := make([]string, 0, len(felts))
+	for _, felt := range felts {
+		values = append(values, felt.String())
+	}
+	return values
+}
+
+type runCairo1Params struct {
+	// The compiled contract class, as found in *.casm.json files
+	Casm json.RawMessage `json:"casm"`
+	// Selector of the external entry point to call, can be omitted if there is only one
+	Selector string `json:"selector"`
+	// Felts in decimal or 0x prefixed hex
+	Calldata []string `json:"calldata"`
+	Gas      uint64   `json:"gas"`
+	artifactParams
+}
+
+type runCairo1Result struct {
+	runResources
+	Failed  bool     `json:"failed"`
+	Retdata []string `json:"retdata"`
+	GasLeft uint64   `json:"gas_left"`
+	// Felts written to the output builtin, in decimal
+	Output []string `json:"output"`
+}
+
+// Calls an external entry point of a Cairo 1 contract class, which runs against empty storage.
+// A panic isn't an error: the result is returned with failed set
+func (s *Server) runCairo1(raw json.RawMessage) (any, error) {
+	var params runCairo1Params
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	var casm parser.CasmContractClass
+	if err := json.Unmarshal(params.Casm, &casm); err != nil {
+		return nil, invalidParams(fmt.Errorf("invalid casm: %w", err))
+	}
+	program, err := vm.DeserializeCasmContractClass(casm)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	if _, err := casm.EntryPointsByType.GetExternal(params.Selector); err != nil {
+		return nil, invalidParams(err)
+	}
+	calldata, err := parseFelts(params.Calldata)
+	if err != nil {
+		return nil, invalidParams(fmt.Errorf("invalid calldata: %w", err))
+	}
+
+	defer s.acquire()()
+	options := cairovm.RunOptions{Selector: params.Selector, Calldata: calldata, InitialGas: params.Gas, MaxSteps: s.config.MaxSteps}
+	run := cairovm.RunProgram(program, options)
+	if run.Err != nil {
+		return nil, run.Err
+	}
+
+	result := runCairo1Result{Failed: run.Failed, GasLeft: run.Gas, Retdata: feltStrings(run.Retdata), Output: feltStrings(run.Output)}
+	if result.runResources, err = newRunResources(run.Runner, params.artifactParams); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
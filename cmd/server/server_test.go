@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// main runs [ap] = 5; ap++ then ret, loop jumps to itself forever and add(a, b) writes a + b
+const testProgram = `{
+	"data": ["0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe", "0x10780017fff7fff", "0x0", "0x482a7ffd7ffc8000", "0x208b7fff7fff7ffe"],
+	"builtins": [],
+	"identifiers": {
+		"__main__.main": {"type": "function", "pc": 0, "decorators": []},
+		"__main__.loop": {"type": "function", "pc": 3, "decorators": []},
+		"__main__.add": {"type": "function", "pc": 5, "decorators": []}
+	},
+	"hints": {},
+	"reference_manager": {"references": []}
+}`
+
+// main writes 5 to the output builtin
+const testOutputProgram = `{
+	"data": ["0x480680017fff8000", "0x5", "0x400280007ffd7fff", "0x482680017ffd8000", "0x1", "0x208b7fff7fff7ffe"],
+	"builtins": ["output"],
+	"identifiers": {"__main__.main": {"type": "function", "pc": 0, "decorators": []}},
+	"hints": {},
+	"reference_manager": {"references": []}
+}`
+
+// Its entry point returns its calldata, or panics with it for the 0x2 selector
+const testCasm = `{
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"compiler_version": "2.1.0",
+	"bytecode": [
+		"0x480a7ffa7fff8000", "0x480a7ffb7fff8000", "0x480680017fff8000", "0x0", "0x480a7ffc7fff8000", "0x480a7ffd7fff8000", "0x208b7fff7fff7ffe",
+		"0x480a7ffa7fff8000", "0x480a7ffb7fff8000", "0x480680017fff8000", "0x1", "0x480a7ffc7fff8000", "0x480a7ffd7fff8000", "0x208b7fff7fff7ffe"
+	],
+	"hints": [],
+	"entry_points_by_type": {
+		"EXTERNAL": [{"selector": "0x1", "offset": 0, "builtins": []}, {"selector": "0x2", "offset": 7, "builtins": []}],
+		"L1_HANDLER": [],
+		"CONSTRUCTOR": []
+	}
+}`
+
+type testResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func call(t *testing.T, server http.Handler, body string) testResponse {
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	var response testResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response %q: %s", recorder.Body.String(), err)
+	}
+	return response
+}
+
+func rpcCall(t *testing.T, server http.Handler, method string, params string) testResponse {
+	return call(t, server, `{"jsonrpc": "2.0", "id": 1, "method": "`+method+`", "params": `+params+`}`)
+}
+
+func TestRunProgram(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	response := rpcCall(t, server, "run_program", `{"program": `+testProgram+`, "include_trace": true, "include_memory": true}`)
+	if response.Error != nil {
+		t.Fatalf("run_program failed with error: %s", response.Error.Message)
+	}
+	var result runProgramResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Invalid result: %s", err)
+	}
+	if result.NSteps != 2 || result.NMemoryHoles != 0 || len(result.Trace) != 2*24 {
+		t.Errorf("Wrong resources: %+v", result.runResources)
+	}
+	memory, err := vm.DecodeMemory(bytes.NewReader(result.Memory))
+	if err != nil {
+		t.Fatalf("Invalid memory: %s", err)
+	}
+	if memory[len(memory)-1].String() != "5" {
+		t.Errorf("Expected the run to write 5 last, got %s", memory[len(memory)-1].String())
+	}
+	if string(response.ID) != "1" {
+		t.Errorf("The response should have the request id, got %s", response.ID)
+	}
+}
+
+func TestRunProgramOutput(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	response := rpcCall(t, server, "run_program", `{"program": `+testOutputProgram+`, "layout": "small"}`)
+	if response.Error != nil {
+		t.Fatalf("run_program failed with error: %s", response.Error.Message)
+	}
+	var result runProgramResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Invalid result: %s", err)
+	}
+	if strings.Join(result.Output, ",") != "5" {
+		t.Errorf("Expected the output to be 5, got %v", result.Output)
+	}
+}
+
+func TestRunProgramArgs(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	response := rpcCall(t, server, "run_program", `{"program": `+testProgram+`, "entrypoint": "add", "args": ["010", "0x4"], "include_memory": true}`)
+	if response.Error != nil {
+		t.Fatalf("run_program failed with error: %s", response.Error.Message)
+	}
+	var result runProgramResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Invalid result: %s", err)
+	}
+	memory, err := vm.DecodeMemory(bytes.NewReader(result.Memory))
+	if err != nil || memory[len(memory)-1].String() != "14" {
+		t.Errorf("Expected add to write 14, as 010 is decimal, got %v, err: %v", memory[len(memory)-1], err)
+	}
+}
+
+func TestRunProgramStepLimit(t *testing.T) {
+	server := NewServer(Config{MaxSteps: 100, MaxConcurrentRuns: 1})
+	response := rpcCall(t, server, "run_program", `{"program": `+testProgram+`, "entrypoint": "loop"}`)
+	if response.Error == nil || response.Error.Code != codeRunFailed || !strings.Contains(response.Error.Message, "Step limit") {
+		t.Errorf("Expected the step limit to abort the run, got %+v", response.Error)
+	}
+}
+
+func TestRunProgramInvalidParams(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	for _, params := range []string{`{}`, `{"program": {"data": ["0xzz"]}}`, `{"program": ` + testProgram + `, "entrypoint": "missing"}`, `{"unknown": 1}`, `{"program": ` + testProgram + `, "layout": "missing"}`, `{"program": ` + testProgram + `, "args": ["x"]}`, `{"program": ` + testProgram + `, "entrypoint": "add", "args": ["1_000", "0b1"]}`} {
+		response := rpcCall(t, server, "run_program", params)
+		if response.Error == nil || response.Error.Code != codeInvalidParams {
+			t.Errorf("Expected invalid params for %s, got %+v", params, response.Error)
+		}
+	}
+}
+
+func TestRunCairo1(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	response := rpcCall(t, server, "run_cairo1", `{"casm": `+testCasm+`, "selector": "0x1", "calldata": ["3", "0x10"], "gas": 1000}`)
+	if response.Error != nil {
+		t.Fatalf("run_cairo1 failed with error: %s", response.Error.Message)
+	}
+	var result runCairo1Result
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Invalid result: %s", err)
+	}
+	if result.Failed || result.GasLeft != 1000 || strings.Join(result.Retdata, ",") != "3,16" || result.Output == nil || result.NSteps == 0 {
+		t.Errorf("Wrong result: %+v", result)
+	}
+
+	response = rpcCall(t, server, "run_cairo1", `{"casm": `+testCasm+`, "selector": "0x2", "calldata": ["7"]}`)
+	if err := json.Unmarshal(response.Result, &result); err != nil || !result.Failed {
+		t.Errorf("Expected a panic, got %+v, err: %v", result, err)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1})
+	cases := map[string]int{
+		`{"jsonrpc": "2.0", "id": 1, "method": "run_program"`: codeParseError,
+		`{"id": 1, "method": "run_program", "params": {}}`:    codeInvalidRequest,
+		`{"jsonrpc": "2.0", "id": 1, "method": "unknown"}`:    codeMethodNotFound,
+		`{"jsonrpc": "2.0", "id": 1, "method": "run_cairo1"}`: codeInvalidParams,
+	}
+	for body, code := range cases {
+		response := call(t, server, body)
+		if response.Error == nil || response.Error.Code != code {
+			t.Errorf("Expected error code %d for %s, got %+v", code, body, response.Error)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET requests to be rejected, got status %d", recorder.Code)
+	}
+}
+
+func TestRequestSizeLimit(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 1, MaxRequestBytes: 64})
+	response := rpcCall(t, server, "run_program", `{"program": `+testProgram+`}`)
+	if response.Error == nil || response.Error.Code != codeParseError {
+		t.Errorf("Expected oversized requests to be rejected, got %+v", response.Error)
+	}
+}
+
+func TestConcurrentRuns(t *testing.T) {
+	server := NewServer(Config{MaxConcurrentRuns: 2})
+	var wg sync.WaitGroup
+	errors := make(chan string, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if response := rpcCall(t, server, "run_program", `{"program": `+testProgram+`}`); response.Error != nil {
+				errors <- response.Error.Message
+			}
+		}()
+	}
+	wg.Wait()
+	close(errors)
+	for err := range errors {
+		t.Errorf("Concurrent run failed with error: %s", err)
+	}
+}
@@ -2,6 +2,10 @@ package builtins
 
 import "github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 
+// Names of the builtins a program can declare, in the order it must declare them
+var BuiltinNames = []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op", "keccak", "poseidon",
+	"range_check96", "add_mod", "mul_mod"}
+
 type BuiltinRunner interface {
 	// Returns the first address of the builtin's memory segment
 	Base() memory.Relocatable
@@ -17,6 +21,9 @@ type BuiltinRunner interface {
 	// Adds a validation rule to the memory
 	// Validation rules are applied when a value is inserted into the builtin's segment
 	AddValidationRule(*memory.Memory)
+	// Reads the builtin's final pointer, returned by the program right before pointer, checks it
+	// against the cells the builtin used and returns the address of the final pointer
+	FinalStack(*memory.MemorySegmentManager, memory.Relocatable) (memory.Relocatable, error)
 	// TODO: Later additions -> Some of them could depend on a Default Implementation
 	// // Most of them depend on Layouts being implemented
 	// // Use cases:
@@ -28,8 +35,6 @@ type BuiltinRunner interface {
 	// // Returns the list of memory addresses used by the builtin
 	// GetMemoryAccesses(*memory.MemorySegmentManager) ([]memory.Relocatable, error) // proof-mode end_run logic
 	// GetUsedCells(*memory.MemorySegmentManager) (uint, error)                      // proof-mode end_run logic
-	// GetRangeCheckUsage(*memory.Memory) (*uint, *uint)                             // proof-mode end_run logic
-	// GetUsedPermRangeCheckLimits(*vm.VirtualMachine) (uint, error)                 // proof-mode end_run logic
 	// GetUsedDilutedCheckUnits(diluted_spacing uint, diluted_n_bits uint) uint      // proof-mode end_run logic
 	// GetUsedCellsAndAllocatedSizes(*vm.VirtualMachine) (uint, uint, error)         // proof-mode end_run logic + finalize_segments
 	// // II. SECURITY (secure-run flag cairo-run || verify-secure flag run_from_entrypoint)
@@ -38,6 +43,14 @@ type BuiltinRunner interface {
 	// GetMemorySegmentAddresses() (memory.Relocatable, *memory.Relocatable) //verify_secure_runner logic
 	// // III. STARKNET-SPECIFIC
 	// GetUsedInstances(*memory.MemorySegmentManager) (uint, error) // get_execution_resources (starknet use case)
-	// // IV. GENERAL CASE (but not critical)
-	// FinalStack(*memory.MemorySegmentManager, memory.Relocatable) (memory.Relocatable, error) // read_return_values
+}
+
+// Implemented by builtins whose values the prover range checks, along with the instructions'
+// offsets
+type RangeCheckUser interface {
+	// Returns the smallest and largest 16-bit parts of the builtin's values, ok is false if the
+	// builtin holds no value
+	GetRangeCheckUsage(*memory.Memory) (rcMin uint, rcMax uint, ok bool)
+	// Returns the number of range check units the builtin's values take
+	GetUsedPermRangeCheckUnits(*memory.MemorySegmentManager) uint
 }
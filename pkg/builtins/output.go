@@ -0,0 +1,149 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// A range of the output segment, given by its offset within the segment and its size
+type OutputPage struct {
+	Start uint
+	Size  uint
+}
+
+// The output builtin, whose segment holds the values output by the program.
+// The output can be split into pages, which the fact registry hashes separately, and carry
+// attributes such as the bootloader's fact topologies. Cells outside of every page belong to page 0
+type OutputBuiltinRunner struct {
+	base       memory.Relocatable
+	pages      map[uint]OutputPage
+	attributes map[string][]lambdaworks.Felt
+}
+
+func NewOutputBuiltinRunner() *OutputBuiltinRunner {
+	return &OutputBuiltinRunner{pages: make(map[uint]OutputPage), attributes: make(map[string][]lambdaworks.Felt)}
+}
+
+func (o *OutputBuiltinRunner) Base() memory.Relocatable {
+	return o.base
+}
+
+func (o *OutputBuiltinRunner) Name() string {
+	return "output"
+}
+
+func (o *OutputBuiltinRunner) InitializeSegments(segments *memory.MemorySegmentManager) {
+	o.base = segments.AddSegment()
+}
+
+func (o *OutputBuiltinRunner) InitialStack() []memory.MaybeRelocatable {
+	return []memory.MaybeRelocatable{*memory.NewMaybeRelocatableRelocatable(o.base)}
+}
+
+// Output cells are written by the program, they can't be deduced
+func (o *OutputBuiltinRunner) DeduceMemoryCell(memory.Relocatable, *memory.Memory) (*memory.MaybeRelocatable, error) {
+	return nil, nil
+}
+
+// Any value can be output
+func (o *OutputBuiltinRunner) AddValidationRule(*memory.Memory) {}
+
+// Reads the output pointer returned by the program at pointer - 1, which must point right after
+// the last value output. Returns its address
+func (o *OutputBuiltinRunner) FinalStack(segments *memory.MemorySegmentManager, pointer memory.Relocatable) (memory.Relocatable, error) {
+	stopPointerAddr, err := pointer.SubUint(1)
+	if err != nil {
+		return memory.Relocatable{}, errors.New("Missing stop pointer of the output builtin")
+	}
+	stopPointer, err := segments.Memory.GetRelocatable(stopPointerAddr)
+	if err != nil {
+		return memory.Relocatable{}, fmt.Errorf("Missing stop pointer of the output builtin at %d:%d: %w", stopPointerAddr.SegmentIndex, stopPointerAddr.Offset, err)
+	}
+	if stopPointer.SegmentIndex != o.base.SegmentIndex {
+		return memory.Relocatable{}, fmt.Errorf("Invalid stop pointer index for the output builtin: got %d, expected %d", stopPointer.SegmentIndex, o.base.SegmentIndex)
+	}
+	used := segments.SegmentUsedSize(uint(o.base.SegmentIndex))
+	if stopPointer.Offset != used {
+		return memory.Relocatable{}, fmt.Errorf("Invalid stop pointer for the output builtin: it is at offset %d but the builtin used %d cells", stopPointer.Offset, used)
+	}
+	return stopPointerAddr, nil
+}
+
+// Declares the pageSize cells starting at pageStart as the page pageId of the output, page ids
+// start at 1
+func (o *OutputBuiltinRunner) AddPage(pageId uint, pageStart memory.Relocatable, pageSize uint) error {
+	if pageStart.SegmentIndex != o.base.SegmentIndex {
+		return fmt.Errorf("Page start %d:%d is not in the output segment %d", pageStart.SegmentIndex, pageStart.Offset, o.base.SegmentIndex)
+	}
+	if pageId == 0 {
+		return errors.New("Page 0 holds the cells outside of every page, it can't be added")
+	}
+	if _, ok := o.pages[pageId]; ok {
+		return fmt.Errorf("Page %d was already added", pageId)
+	}
+	o.pages[pageId] = OutputPage{Start: pageStart.Offset, Size: pageSize}
+	return nil
+}
+
+// Returns the pages added so far, indexed by page id
+func (o *OutputBuiltinRunner) GetPages() map[uint]OutputPage {
+	return o.pages
+}
+
+// Sets an attribute of the output, replacing any previous value. The VM doesn't interpret them
+func (o *OutputBuiltinRunner) AddAttribute(name string, value []lambdaworks.Felt) {
+	o.attributes[name] = value
+}
+
+// Returns the attributes added so far, indexed by name
+func (o *OutputBuiltinRunner) GetAttributes() map[string][]lambdaworks.Felt {
+	return o.attributes
+}
+
+// Returns the offsets of the output segment, which is public as a whole, along with the page
+// each of them belongs to. Fails if a page lies past the end of the output or overlaps another one
+func (o *OutputBuiltinRunner) GetPublicMemory(segments *memory.MemorySegmentManager) ([]memory.PublicMemoryOffset, error) {
+	size := segments.GetSegmentSize(uint(o.base.SegmentIndex))
+	publicMemory := make([]memory.PublicMemoryOffset, size)
+	for i := range publicMemory {
+		publicMemory[i].Offset = uint(i)
+	}
+	pageIds := make([]uint, 0, len(o.pages))
+	for pageId := range o.pages {
+		pageIds = append(pageIds, pageId)
+	}
+	sort.Slice(pageIds, func(i, j int) bool { return pageIds[i] < pageIds[j] })
+	for _, pageId := range pageIds {
+		page := o.pages[pageId]
+		if page.Start+page.Size > size {
+			return nil, fmt.Errorf("Page %d spans offsets %d to %d, past the end of the output (size %d)", pageId, page.Start, page.Start+page.Size, size)
+		}
+		for offset := page.Start; offset < page.Start+page.Size; offset++ {
+			if publicMemory[offset].Page != 0 {
+				return nil, fmt.Errorf("Page %d overlaps page %d at offset %d", pageId, publicMemory[offset].Page, offset)
+			}
+			publicMemory[offset].Page = pageId
+		}
+	}
+	return publicMemory, nil
+}
+
+// Pages and attributes of the output, in the format cairo-lang stores them in the additional
+// data of a Cairo PIE: pages map their id to [start, size]
+type OutputBuiltinAdditionalData struct {
+	Pages      map[string][2]uint            `json:"pages"`
+	Attributes map[string][]lambdaworks.Felt `json:"attributes"`
+}
+
+func (o *OutputBuiltinRunner) GetAdditionalData() OutputBuiltinAdditionalData {
+	data := OutputBuiltinAdditionalData{Pages: make(map[string][2]uint, len(o.pages)), Attributes: o.attributes}
+	for pageId, page := range o.pages {
+		data.Pages[strconv.FormatUint(uint64(pageId), 10)] = [2]uint{page.Start, page.Size}
+	}
+	return data
+}
@@ -0,0 +1,122 @@
+package builtins_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Returns an output builtin whose segment holds size values
+func outputWithSize(t *testing.T, size uint) (*builtins.OutputBuiltinRunner, memory.MemorySegmentManager) {
+	segments := memory.NewMemorySegmentManager()
+	output := builtins.NewOutputBuiltinRunner()
+	output.InitializeSegments(&segments)
+	for i := uint(0); i < size; i++ {
+		addr := memory.NewRelocatable(output.Base().SegmentIndex, i)
+		if err := segments.Memory.Insert(addr, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return output, segments
+}
+
+func TestOutputInitialStack(t *testing.T) {
+	output, _ := outputWithSize(t, 0)
+	stack := output.InitialStack()
+	if len(stack) != 1 || stack[0] != *memory.NewMaybeRelocatableRelocatable(output.Base()) || output.Name() != "output" {
+		t.Errorf("Wrong initial stack %v for base %+v", stack, output.Base())
+	}
+}
+
+func TestOutputAddPageErrors(t *testing.T) {
+	output, _ := outputWithSize(t, 4)
+	segment := output.Base().SegmentIndex
+	if err := output.AddPage(1, memory.NewRelocatable(segment, 0), 2); err != nil {
+		t.Fatalf("AddPage error in test: %s", err)
+	}
+	if err := output.AddPage(1, memory.NewRelocatable(segment, 2), 2); err == nil || err.Error() != "Page 1 was already added" {
+		t.Errorf("Adding a page twice should fail, got %v", err)
+	}
+	if err := output.AddPage(0, memory.NewRelocatable(segment, 2), 2); err == nil {
+		t.Errorf("Adding page 0 should fail")
+	}
+	if err := output.AddPage(2, memory.NewRelocatable(segment+1, 0), 2); err == nil {
+		t.Errorf("Adding a page outside of the output segment should fail")
+	}
+}
+
+func TestOutputGetPublicMemory(t *testing.T) {
+	output, segments := outputWithSize(t, 5)
+	segment := output.Base().SegmentIndex
+	if err := output.AddPage(2, memory.NewRelocatable(segment, 3), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.AddPage(1, memory.NewRelocatable(segment, 1), 2); err != nil {
+		t.Fatal(err)
+	}
+	publicMemory, err := output.GetPublicMemory(&segments)
+	expected := []memory.PublicMemoryOffset{{Offset: 0, Page: 0}, {Offset: 1, Page: 1}, {Offset: 2, Page: 1}, {Offset: 3, Page: 2}, {Offset: 4, Page: 2}}
+	if err != nil || !reflect.DeepEqual(publicMemory, expected) {
+		t.Errorf("Wrong public memory, expected %+v, got %+v, err: %v", expected, publicMemory, err)
+	}
+}
+
+func TestOutputGetPublicMemoryInvalidPages(t *testing.T) {
+	output, segments := outputWithSize(t, 3)
+	segment := output.Base().SegmentIndex
+	output.AddPage(1, memory.NewRelocatable(segment, 2), 2)
+	if _, err := output.GetPublicMemory(&segments); err == nil || err.Error() != "Page 1 spans offsets 2 to 4, past the end of the output (size 3)" {
+		t.Errorf("A page past the end of the output should fail, got %v", err)
+	}
+
+	output, segments = outputWithSize(t, 3)
+	output.AddPage(1, memory.NewRelocatable(segment, 0), 2)
+	output.AddPage(2, memory.NewRelocatable(segment, 1), 2)
+	if _, err := output.GetPublicMemory(&segments); err == nil || err.Error() != "Page 2 overlaps page 1 at offset 1" {
+		t.Errorf("Overlapping pages should fail, got %v", err)
+	}
+}
+
+func TestOutputAdditionalData(t *testing.T) {
+	output, _ := outputWithSize(t, 3)
+	output.AddPage(1, memory.NewRelocatable(output.Base().SegmentIndex, 1), 2)
+	output.AddAttribute("gps_fact_topology", []lambdaworks.Felt{lambdaworks.FeltFromUint64(2), lambdaworks.FeltFromUint64(1)})
+	data, err := json.Marshal(output.GetAdditionalData())
+	expected := `{"pages":{"1":[1,2]},"attributes":{"gps_fact_topology":["0x2","0x1"]}}`
+	if err != nil || string(data) != expected {
+		t.Errorf("Wrong additional data, expected %s, got %s, err: %v", expected, data, err)
+	}
+}
+
+func TestOutputFinalStack(t *testing.T) {
+	output, segments := outputWithSize(t, 2)
+	stack := segments.AddSegment()
+	cases := []struct {
+		stopPointer memory.Relocatable
+		err         string
+	}{
+		{memory.NewRelocatable(output.Base().SegmentIndex, 2), ""},
+		{memory.NewRelocatable(output.Base().SegmentIndex, 1), "Invalid stop pointer for the output builtin: it is at offset 1 but the builtin used 2 cells"},
+		{memory.NewRelocatable(stack.SegmentIndex, 2), "Invalid stop pointer index for the output builtin: got 1, expected 0"},
+	}
+	for i, c := range cases {
+		addr := memory.NewRelocatable(stack.SegmentIndex, uint(i))
+		if err := segments.Memory.Insert(addr, memory.NewMaybeRelocatableRelocatable(c.stopPointer)); err != nil {
+			t.Fatal(err)
+		}
+		pointer, err := output.FinalStack(&segments, memory.NewRelocatable(stack.SegmentIndex, uint(i)+1))
+		if c.err == "" && (err != nil || pointer != addr) {
+			t.Errorf("Expected the final stack to end at %+v, got %+v, err: %v", addr, pointer, err)
+		}
+		if c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("Expected stop pointer %+v to fail with %q, got %v", c.stopPointer, c.err, err)
+		}
+	}
+	if _, err := output.FinalStack(&segments, memory.NewRelocatable(stack.SegmentIndex, 0)); err == nil {
+		t.Errorf("A pointer at the start of a segment should have no stop pointer before it")
+	}
+}
@@ -0,0 +1,236 @@
+// Runs Cairo programs in a single call, for Go projects embedding the VM. The steps done by Run
+// (parsing, initializing a runner, running, relocating) are available separately in the parser,
+// runners and vm packages.
+package cairovm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Builds the hooks running the hints of a program, e.g. a syscall hint processor:
+//
+//	func(program *vm.Program) vm.Hooks {
+//		return vm.Hooks{PreStep: starknet.NewDeprecatedSyscallHintProcessor(handler, program).PreStep}
+//	}
+type HintProcessor func(program *vm.Program) vm.Hooks
+
+type RunOptions struct {
+	// Name of the function to run, main if empty. Can't be set in proof mode, where the program runs from __start__
+	Entrypoint string
+	// Arguments of the entrypoint, passed after its builtins. See MemorySegmentManager.GenArg for the supported types
+	Args      []any
+	ProofMode bool
+	// Layout the program runs on, empty means plain
+	Layout string
+	// Runs the hints of the program, can be nil. Cairo 1 contracts run their syscalls against an
+	// empty state when nil
+	HintProcessor HintProcessor
+	// The run fails after this many steps, 0 means no limit
+	MaxSteps uint
+	// Cairo 1 contracts only: selector of the external entry point to call, which can be empty if
+	// there is a single one, its calldata and the gas it starts with
+	Selector   string
+	Calldata   []lambdaworks.Felt
+	InitialGas uint64
+}
+
+type RunResult struct {
+	// Felts written to the output builtin segment, in order. Nil if the program doesn't use the output builtin
+	Output []lambdaworks.Felt
+	// The relocated trace and memory, nil if the run failed
+	Trace  []vm.RelocatedTraceEntry
+	Memory []*lambdaworks.Felt
+	// Resources used by the run, up to the failure if it failed
+	Resources runners.ExecutionResources
+	// The runner, to inspect the run further. Nil if the program couldn't be loaded
+	Runner *runners.CairoRunner
+	Err    error
+	// Cairo 1 contracts only: the gas left after the run, which syscalls are charged from, whether
+	// the entry point panicked, and its retdata or panic reason
+	Gas     uint64
+	Failed  bool
+	Retdata []lambdaworks.Felt
+}
+
+// Parses a compiled program and runs it according to options
+func Run(program []byte, options RunOptions) RunResult {
+	parsed, err := vm.ParseProgramStream(bytes.NewReader(program))
+	if err != nil {
+		return RunResult{Err: err}
+	}
+	return RunProgram(parsed, options)
+}
+
+// Parses a Cairo 1 contract class, as found in *.casm.json files, and calls one of its external
+// entry points according to options
+func RunCasm(casm []byte, options RunOptions) RunResult {
+	var contractClass parser.CasmContractClass
+	if err := json.Unmarshal(casm, &contractClass); err != nil {
+		return RunResult{Err: fmt.Errorf("Failed to parse casm contract class: %w", err)}
+	}
+	program, err := vm.DeserializeCasmContractClass(contractClass)
+	if err != nil {
+		return RunResult{Err: err}
+	}
+	return RunProgram(program, options)
+}
+
+// Runs an already parsed program according to options. Cairo 1 contracts, parsed with
+// vm.DeserializeCasmContractClass, run one of their external entry points
+func RunProgram(program vm.Program, options RunOptions) RunResult {
+	return runProgram(program, options, runSizes{})
+}
+
+// Sizes of a previous run of a program, used to preallocate the next ones
+type runSizes struct {
+	steps          uint
+	executionCells uint
+}
+
+func runProgram(program vm.Program, options RunOptions, sizes runSizes) RunResult {
+	if options.ProofMode && (options.Entrypoint != "" || options.Args != nil) {
+		return RunResult{Err: errors.New("Proof mode runs start from __start__ and take no arguments")}
+	}
+	runner, err := newRunner(program, options)
+	if err != nil {
+		return RunResult{Err: err}
+	}
+	runner.ExecutionCapacity = sizes.executionCells
+	runner.Vm.Trace = make([]vm.TraceEntry, 0, sizes.steps)
+	result := RunResult{Runner: runner}
+	result.Err = run(runner, options)
+	result.Resources = runner.GetExecutionResources()
+	if result.Err != nil {
+		return result
+	}
+	if program.EntryPointsByType != nil {
+		cairo1Result, err := runner.GetCairo1Result()
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Gas, result.Failed, result.Retdata = cairo1Result.Gas, cairo1Result.Failed, cairo1Result.Retdata
+	}
+	result.Output, result.Err = readOutput(runner)
+	result.Trace = runner.Vm.RelocatedTrace
+	result.Memory = runner.Vm.RelocatedMemory
+	return result
+}
+
+func newRunner(program vm.Program, options RunOptions) (*runners.CairoRunner, error) {
+	if program.EntryPointsByType == nil {
+		if options.Selector != "" || options.Calldata != nil || options.InitialGas != 0 {
+			return nil, errors.New("Selector, calldata and gas can only be set for Cairo 1 contracts")
+		}
+		layout := options.Layout
+		if layout == "" {
+			layout = "plain"
+		}
+		return runners.NewCairoRunnerWithLayout(program, layout, options.ProofMode)
+	}
+	if options.ProofMode || options.Entrypoint != "" || options.Args != nil {
+		return nil, errors.New("Cairo 1 contracts can't run in proof mode, their entry points are chosen by selector and take calldata")
+	}
+	entryPoint, err := program.EntryPointsByType.GetExternal(options.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return runners.NewCairo1Runner(program, entryPoint, options.Calldata, options.InitialGas)
+}
+
+func run(runner *runners.CairoRunner, options RunOptions) error {
+	var end memory.Relocatable
+	var err error
+	if options.Entrypoint != "" || options.Args != nil {
+		name := options.Entrypoint
+		if name == "" {
+			name = "main"
+		}
+		entrypoint, err := runner.Program.GetEntrypoint(name)
+		if err != nil {
+			return err
+		}
+		end, err = runner.InitializeFromEntrypoint(entrypoint, options.Args)
+		if err != nil {
+			return err
+		}
+	} else if end, err = runner.Initialize(); err != nil {
+		return err
+	}
+
+	if options.HintProcessor != nil {
+		runner.Vm.Hooks = options.HintProcessor(&runner.Program)
+	} else if runner.Program.EntryPointsByType != nil {
+		handler := starknet.NewBaseSyscallHandler(starknet.EmptyState{}, starknet.ExecutionInfo{})
+		runner.Vm.Hooks.PreStep = starknet.NewSyscallHintProcessor(handler, &runner.Program).PreStep
+	}
+	if options.MaxSteps != 0 {
+		runner.Vm.Hooks.PreStep = limitSteps(options.MaxSteps, runner.Vm.Hooks.PreStep)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		return err
+	}
+	if err := runner.EndRun(); err != nil {
+		return err
+	}
+	// main returns the final pointers of all the builtins, other entry points only those of the
+	// builtins they take
+	if options.Entrypoint == "" && options.Args == nil && runner.Program.EntryPointsByType == nil {
+		if err := runner.ReadReturnValues(); err != nil {
+			return err
+		}
+	}
+	if options.ProofMode {
+		if err := runner.FinalizeSegments(); err != nil {
+			return err
+		}
+	}
+	return runner.Vm.Relocate()
+}
+
+// Wraps preStep so that the run fails once it reached maxSteps
+func limitSteps(maxSteps uint, preStep func(v *vm.VirtualMachine) error) func(v *vm.VirtualMachine) error {
+	return func(v *vm.VirtualMachine) error {
+		if v.CurrentStep >= maxSteps {
+			return fmt.Errorf("Step limit of %d reached", maxSteps)
+		}
+		if preStep != nil {
+			return preStep(v)
+		}
+		return nil
+	}
+}
+
+// Reads the cells written to the output builtin segment, in order
+func readOutput(runner *runners.CairoRunner) ([]lambdaworks.Felt, error) {
+	builtin, err := runner.Vm.GetBuiltinRunner("output")
+	if err != nil {
+		// Programs without the output builtin output nothing
+		return nil, nil
+	}
+	base := builtin.Base()
+	size := runner.Vm.Segments.GetSegmentSize(uint(base.SegmentIndex))
+	output := make([]lambdaworks.Felt, 0, size)
+	for offset := uint(0); offset < size; offset++ {
+		value, err := runner.Vm.Segments.Memory.Get(memory.NewRelocatable(base.SegmentIndex, offset))
+		if err != nil {
+			return nil, err
+		}
+		felt, ok := value.GetFelt()
+		if !ok {
+			return nil, fmt.Errorf("Output cell %d is not a felt", offset)
+		}
+		output = append(output, felt)
+	}
+	return output, nil
+}
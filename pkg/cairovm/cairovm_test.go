@@ -0,0 +1,151 @@
+package cairovm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/cairovm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// main writes 5 then returns, add(a, b) writes a + b then returns and loop jumps to itself forever
+const testProgram = `{
+	"data": ["0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe", "0x482a7ffd7ffc8000", "0x208b7fff7fff7ffe", "0x10780017fff7fff", "0x0"],
+	"builtins": [],
+	"identifiers": {
+		"__main__.main": {"type": "function", "pc": 0, "decorators": []},
+		"__main__.add": {"type": "function", "pc": 3, "decorators": []},
+		"__main__.loop": {"type": "function", "pc": 5, "decorators": []}
+	},
+	"hints": {},
+	"reference_manager": {"references": []}
+}`
+
+func lastValue(memory []*lambdaworks.Felt) string {
+	return memory[len(memory)-1].String()
+}
+
+func TestRunMain(t *testing.T) {
+	result := cairovm.Run([]byte(testProgram), cairovm.RunOptions{})
+	if result.Err != nil {
+		t.Fatalf("Run failed with error: %s", result.Err)
+	}
+	if len(result.Trace) != 2 || result.Resources.NSteps != 2 || lastValue(result.Memory) != "5" {
+		t.Errorf("Wrong result: trace %v, resources %+v, last value %s", result.Trace, result.Resources, lastValue(result.Memory))
+	}
+	if result.Output != nil || result.Runner == nil {
+		t.Errorf("Expected no output and a runner, got %v and %v", result.Output, result.Runner)
+	}
+}
+
+func TestRunEntrypointWithArgs(t *testing.T) {
+	args := []any{lambdaworks.FeltFromUint64(3), lambdaworks.FeltFromUint64(4)}
+	result := cairovm.Run([]byte(testProgram), cairovm.RunOptions{Entrypoint: "add", Args: args})
+	if result.Err != nil {
+		t.Fatalf("Run failed with error: %s", result.Err)
+	}
+	if lastValue(result.Memory) != "7" {
+		t.Errorf("Expected add to write 7, got %s", lastValue(result.Memory))
+	}
+}
+
+func TestRunHintProcessor(t *testing.T) {
+	pcs := []uint{}
+	hints := func(program *vm.Program) vm.Hooks {
+		return vm.Hooks{PreStep: func(v *vm.VirtualMachine) error {
+			pcs = append(pcs, v.RunContext.Pc.Offset)
+			return nil
+		}}
+	}
+	result := cairovm.Run([]byte(testProgram), cairovm.RunOptions{HintProcessor: hints, MaxSteps: 10})
+	if result.Err != nil || len(pcs) != 2 || pcs[0] != 0 || pcs[1] != 2 {
+		t.Errorf("The hint processor should run before each step, got pcs %v, err: %v", pcs, result.Err)
+	}
+}
+
+func TestRunStepLimit(t *testing.T) {
+	result := cairovm.Run([]byte(testProgram), cairovm.RunOptions{Entrypoint: "loop", MaxSteps: 50})
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "Step limit") {
+		t.Fatalf("Expected the step limit to stop the run, got %v", result.Err)
+	}
+	if result.Resources.NSteps != 50 || result.Trace != nil {
+		t.Errorf("Expected the resources of the failed run and no trace, got %+v and %d entries", result.Resources, len(result.Trace))
+	}
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	invalid := []cairovm.RunOptions{
+		{Layout: "fake_layout"},
+		{ProofMode: true, Entrypoint: "main"},
+		{Entrypoint: "missing"},
+		{Entrypoint: "add", Args: []any{"not a felt"}},
+		{InitialGas: 100},
+	}
+	for _, options := range invalid {
+		if result := cairovm.Run([]byte(testProgram), options); result.Err == nil {
+			t.Errorf("Run should fail with options %+v", options)
+		}
+	}
+	if result := cairovm.Run([]byte(`{"data": ["0xzz"]}`), cairovm.RunOptions{}); result.Err == nil || result.Runner != nil {
+		t.Errorf("Run should fail without a runner for an invalid program")
+	}
+}
+
+// The entry point at selector 0x1 reads storage key 7 through the storage_read syscall, and
+// returns the gas left and failure flag of the syscall's response
+const testCasm = `{
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"compiler_version": "2.1.0",
+	"bytecode": [
+		"0x480680017fff8000", "0x53746f7261676552656164", "0x400280007ffb7fff", "0x400380017ffb7ffa",
+		"0x480680017fff8000", "0x0", "0x400280027ffb7fff", "0x480680017fff8000", "0x7", "0x400280037ffb7fff",
+		"0x480280047ffb8000", "0x482680017ffb8000", "0x7", "0x480680017fff8000", "0x0",
+		"0x482680017ffb8000", "0x4", "0x482680017ffb8000", "0x6", "0x208b7fff7fff7ffe"
+	],
+	"hints": [[10, [{"SystemCall": {"system": {"Deref": {"register": "FP", "offset": -5}}}}]]],
+	"entry_points_by_type": {
+		"EXTERNAL": [{"selector": "0x1", "offset": 0, "builtins": []}],
+		"L1_HANDLER": [],
+		"CONSTRUCTOR": []
+	}
+}`
+
+func TestRunCasmChargesSyscallGas(t *testing.T) {
+	result := cairovm.RunCasm([]byte(testCasm), cairovm.RunOptions{InitialGas: 100000})
+	if result.Err != nil {
+		t.Fatalf("RunCasm failed with error: %s", result.Err)
+	}
+	gas := lambdaworks.FeltFromUint64(100000 - starknet.StorageReadGasCost)
+	if result.Gas != 100000-starknet.StorageReadGasCost || result.Failed {
+		t.Errorf("Expected %d gas left, got %d, failed: %v", 100000-starknet.StorageReadGasCost, result.Gas, result.Failed)
+	}
+	if len(result.Retdata) != 2 || result.Retdata[0] != gas || !result.Retdata[1].IsZero() {
+		t.Errorf("Expected the syscall to succeed, got response %v", result.Retdata)
+	}
+}
+
+func TestRunCasmOutOfGas(t *testing.T) {
+	result := cairovm.RunCasm([]byte(testCasm), cairovm.RunOptions{Selector: "0x1", InitialGas: 100})
+	if result.Err != nil {
+		t.Fatalf("RunCasm failed with error: %s", result.Err)
+	}
+	// The syscall fails without charging any gas
+	if result.Gas != 100 || len(result.Retdata) != 2 || result.Retdata[1] != lambdaworks.FeltOne() {
+		t.Errorf("Expected the syscall to run out of gas, got gas: %d, response: %v", result.Gas, result.Retdata)
+	}
+}
+
+func TestRunCasmInvalidOptions(t *testing.T) {
+	invalid := []cairovm.RunOptions{
+		{Selector: "0x2"},
+		{ProofMode: true},
+		{Entrypoint: "main"},
+	}
+	for _, options := range invalid {
+		if result := cairovm.RunCasm([]byte(testCasm), options); result.Err == nil {
+			t.Errorf("RunCasm should fail with options %+v", options)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cairovm
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parallel"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// A program to run in a RunnerPool, along with the options to run it with
+type Job struct {
+	Program *vm.Program
+	Options RunOptions
+}
+
+// Runs many independent programs concurrently, on a bounded number of workers.
+//
+// Jobs can share a parsed Program: runners only read it, copying its data into their own
+// memory, so a program is parsed once and run by any number of jobs at the same time. Programs
+// must not be modified while the pool runs them. The Args of a job are only read as well.
+//
+// Everything a run writes to belongs to its job: the runner, its memory and trace, and its
+// hooks, which jobs can't pass directly but only through a HintProcessor, called once per run.
+// HintProcessors, and what they capture, are shared by the jobs using them and must be safe for
+// concurrent use.
+//
+// The pool remembers the steps and execution segment size of the last successful run of each
+// program and preallocates the trace and execution segment of its next runs accordingly
+type RunnerPool struct {
+	workers int
+	mutex   sync.Mutex
+	sizes   map[*vm.Program]runSizes
+}
+
+// Creates a pool running at most workers programs at a time, GOMAXPROCS if workers is 0 or less
+func NewRunnerPool(workers int) *RunnerPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &RunnerPool{workers: workers, sizes: make(map[*vm.Program]runSizes)}
+}
+
+// Runs every job and returns their results, in the order of the jobs. A failed job doesn't stop
+// the others. Run can be called concurrently, each call being bounded by the pool's workers
+func (p *RunnerPool) Run(jobs []Job) []RunResult {
+	results := make([]RunResult, len(jobs))
+	parallel.ForWorkers(len(jobs), p.workers, func(i int) {
+		results[i] = p.runJob(jobs[i])
+	})
+	return results
+}
+
+func (p *RunnerPool) runJob(job Job) RunResult {
+	if job.Program == nil {
+		return RunResult{Err: errors.New("Job has no program")}
+	}
+	p.mutex.Lock()
+	sizes := p.sizes[job.Program]
+	p.mutex.Unlock()
+
+	result := runProgram(*job.Program, job.Options, sizes)
+	if result.Err != nil {
+		return result
+	}
+	sizes = runSizes{steps: result.Runner.Vm.CurrentStep}
+	if segmentSizes := result.Runner.Vm.Segments.GetSegmentSizes(); len(segmentSizes) > 1 {
+		// The execution segment always comes right after the program segment
+		sizes.executionCells = segmentSizes[1]
+	}
+	p.mutex.Lock()
+	p.sizes[job.Program] = sizes
+	p.mutex.Unlock()
+	return result
+}
@@ -0,0 +1,62 @@
+package cairovm_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/cairovm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+func TestRunnerPoolSharesPrograms(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	program, err := vm.ParseProgramStream(bytes.NewReader([]byte(testProgram)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hookRuns int32
+	hints := func(program *vm.Program) vm.Hooks {
+		atomic.AddInt32(&hookRuns, 1)
+		return vm.Hooks{}
+	}
+	jobs := make([]cairovm.Job, 0, 40)
+	for i := uint64(0); i < 40; i++ {
+		args := []any{lambdaworks.FeltFromUint64(i), lambdaworks.FeltFromUint64(1)}
+		jobs = append(jobs, cairovm.Job{Program: &program, Options: cairovm.RunOptions{Entrypoint: "add", Args: args, HintProcessor: hints}})
+	}
+	jobs = append(jobs, cairovm.Job{Program: &program, Options: cairovm.RunOptions{Entrypoint: "loop", MaxSteps: 10}})
+
+	pool := cairovm.NewRunnerPool(3)
+	// The second round reuses the sizes recorded in the first one
+	for round := 0; round < 2; round++ {
+		results := pool.Run(jobs)
+		if len(results) != len(jobs) {
+			t.Fatalf("Expected %d results, got %d", len(jobs), len(results))
+		}
+		for i, result := range results[:40] {
+			if result.Err != nil {
+				t.Fatalf("Job %d failed with error: %s", i, result.Err)
+			}
+			if lastValue(result.Memory) != fmt.Sprint(i+1) {
+				t.Errorf("Expected job %d to write %d, got %s", i, i+1, lastValue(result.Memory))
+			}
+		}
+		if results[40].Err == nil {
+			t.Errorf("The looping job should hit its step limit")
+		}
+	}
+	if hookRuns != 80 {
+		t.Errorf("Expected the hint processor to be called once per run, got %d calls", hookRuns)
+	}
+}
+
+func TestRunnerPoolJobWithoutProgram(t *testing.T) {
+	results := cairovm.NewRunnerPool(0).Run([]cairovm.Job{{}})
+	if results[0].Err == nil {
+		t.Errorf("A job without a program should fail")
+	}
+}
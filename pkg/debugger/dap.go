@@ -0,0 +1,398 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// A Debug Adapter Protocol message. Only the fields used by the requests this server
+// handles are decoded, see https://microsoft.github.io/debug-adapter-protocol/specification
+type dapMessage struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Command    string          `json:"command,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	RequestSeq int             `json:"request_seq,omitempty"`
+	Success    *bool           `json:"success,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Event      string          `json:"event,omitempty"`
+	Body       any             `json:"body,omitempty"`
+}
+
+type dapSource struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type dapStackFrame struct {
+	Id                          int        `json:"id"`
+	Name                        string     `json:"name"`
+	Source                      *dapSource `json:"source,omitempty"`
+	Line                        int        `json:"line"`
+	Column                      int        `json:"column"`
+	InstructionPointerReference string     `json:"instructionPointerReference"`
+}
+
+type dapScope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// Scopes of each frame, variables references are frameId*scopesPerFrame + scope + 1
+const (
+	scopeLocals = iota
+	scopeRegisters
+	scopeMemory
+	scopesPerFrame
+)
+
+// Serves a single debugging session over the Debug Adapter Protocol, so that programs can be
+// debugged from editors such as VS Code. Source breakpoints are mapped to pcs through the
+// program's debug info.
+type DAPServer struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	seq      int
+	debugger *Debugger
+	// Breakpoint pcs of each source file, as they are replaced as a whole by setBreakpoints
+	sourceBreakpoints map[string][]uint
+	stopOnEntry       bool
+	done              bool
+}
+
+func NewDAPServer(r io.Reader, w io.Writer) *DAPServer {
+	return &DAPServer{reader: bufio.NewReader(r), writer: w, sourceBreakpoints: make(map[string][]uint)}
+}
+
+// Handles requests until the client disconnects or closes the connection
+func (s *DAPServer) Serve() error {
+	for !s.done {
+		request, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if request.Type != "request" {
+			continue
+		}
+		if err := s.handle(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DAPServer) readMessage() (dapMessage, error) {
+	contentLength := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && contentLength < 0 {
+				return dapMessage{}, io.EOF
+			}
+			return dapMessage{}, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := cutPrefix(line, "Content-Length:"); ok {
+			if contentLength, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return dapMessage{}, fmt.Errorf("invalid Content-Length header: %s", line)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return dapMessage{}, errors.New("missing Content-Length header")
+	}
+	content := make([]byte, contentLength)
+	if _, err := io.ReadFull(s.reader, content); err != nil {
+		return dapMessage{}, err
+	}
+	var message dapMessage
+	if err := json.Unmarshal(content, &message); err != nil {
+		return dapMessage{}, fmt.Errorf("invalid message: %w", err)
+	}
+	return message, nil
+}
+
+func cutPrefix(s string, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func (s *DAPServer) send(message dapMessage) error {
+	s.seq++
+	message.Seq = s.seq
+	content, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	return err
+}
+
+func (s *DAPServer) respond(request dapMessage, body any, requestErr error) error {
+	success := requestErr == nil
+	response := dapMessage{Type: "response", Command: request.Command, RequestSeq: request.Seq, Success: &success, Body: body}
+	if requestErr != nil {
+		response.Message = requestErr.Error()
+	}
+	return s.send(response)
+}
+
+func (s *DAPServer) sendEvent(event string, body any) error {
+	return s.send(dapMessage{Type: "event", Event: event, Body: body})
+}
+
+func (s *DAPServer) handle(request dapMessage) error {
+	switch request.Command {
+	case "initialize":
+		capabilities := map[string]bool{"supportsConfigurationDoneRequest": true}
+		if err := s.respond(request, capabilities, nil); err != nil {
+			return err
+		}
+		return s.sendEvent("initialized", nil)
+	case "launch":
+		return s.respond(request, nil, s.launch(request.Arguments))
+	case "setBreakpoints":
+		body, err := s.setBreakpoints(request.Arguments)
+		return s.respond(request, body, err)
+	case "configurationDone":
+		if err := s.respond(request, nil, s.requireDebugger()); err != nil || s.debugger == nil {
+			return err
+		}
+		if s.stopOnEntry {
+			return s.sendEvent("stopped", map[string]any{"reason": "entry", "threadId": 1})
+		}
+		return s.resume(s.debugger.Continue)
+	case "threads":
+		return s.respond(request, map[string]any{"threads": []map[string]any{{"id": 1, "name": "main"}}}, nil)
+	case "stackTrace":
+		body, err := s.stackTrace()
+		return s.respond(request, body, err)
+	case "scopes":
+		body, err := s.scopes(request.Arguments)
+		return s.respond(request, body, err)
+	case "variables":
+		body, err := s.variables(request.Arguments)
+		return s.respond(request, body, err)
+	case "continue", "next", "stepIn":
+		if err := s.requireDebugger(); err != nil {
+			return s.respond(request, nil, err)
+		}
+		if err := s.respond(request, nil, nil); err != nil {
+			return err
+		}
+		run := map[string]func() (StopReason, error){"continue": s.debugger.Continue, "next": s.debugger.Next, "stepIn": s.debugger.Step}
+		return s.resume(run[request.Command])
+	case "disconnect":
+		s.done = true
+		return s.respond(request, nil, nil)
+	default:
+		return s.respond(request, nil, fmt.Errorf("Unsupported request %s", request.Command))
+	}
+}
+
+func (s *DAPServer) requireDebugger() error {
+	if s.debugger == nil {
+		return errors.New("No program launched")
+	}
+	return nil
+}
+
+func (s *DAPServer) launch(arguments json.RawMessage) error {
+	var args struct {
+		Program     string `json:"program"`
+		StopOnEntry bool   `json:"stopOnEntry"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return err
+	}
+	programFile, err := os.Open(args.Program)
+	if err != nil {
+		return err
+	}
+	defer programFile.Close()
+	program, err := vm.ParseProgramStream(bufio.NewReader(programFile))
+	if err != nil {
+		return err
+	}
+	d, err := NewDebugger(program)
+	if err != nil {
+		return err
+	}
+	s.debugger = d
+	s.stopOnEntry = args.StopOnEntry
+	return nil
+}
+
+func (s *DAPServer) setBreakpoints(arguments json.RawMessage) (any, error) {
+	if err := s.requireDebugger(); err != nil {
+		return nil, err
+	}
+	var args struct {
+		Source      dapSource `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	for _, pc := range s.sourceBreakpoints[args.Source.Path] {
+		s.debugger.RemoveBreakpoint(pc)
+	}
+	// Debug info paths are relative to the compilation directory, so match on the file name
+	file := filepath.Base(args.Source.Path)
+	pcs := make([]uint, 0, len(args.Breakpoints))
+	breakpoints := make([]map[string]any, 0, len(args.Breakpoints))
+	for _, breakpoint := range args.Breakpoints {
+		pc, err := s.debugger.PCForLine(file, breakpoint.Line)
+		if err != nil {
+			breakpoints = append(breakpoints, map[string]any{"verified": false, "line": breakpoint.Line, "message": err.Error()})
+			continue
+		}
+		s.debugger.AddBreakpoint(pc)
+		pcs = append(pcs, pc)
+		breakpoints = append(breakpoints, map[string]any{"verified": true, "line": breakpoint.Line})
+	}
+	s.sourceBreakpoints[args.Source.Path] = pcs
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+// Resumes the execution and reports why it stopped
+func (s *DAPServer) resume(run func() (StopReason, error)) error {
+	reason, err := run()
+	if err != nil {
+		if err := s.sendEvent("output", map[string]any{"category": "stderr", "output": err.Error() + "\n"}); err != nil {
+			return err
+		}
+		if err := s.sendEvent("exited", map[string]any{"exitCode": 1}); err != nil {
+			return err
+		}
+		return s.sendEvent("terminated", nil)
+	}
+	switch reason {
+	case StopBreakpoint:
+		return s.sendEvent("stopped", map[string]any{"reason": "breakpoint", "threadId": 1})
+	case StopStep:
+		return s.sendEvent("stopped", map[string]any{"reason": "step", "threadId": 1})
+	default:
+		if err := s.sendEvent("exited", map[string]any{"exitCode": 0}); err != nil {
+			return err
+		}
+		return s.sendEvent("terminated", nil)
+	}
+}
+
+func (s *DAPServer) stackTrace() (any, error) {
+	if err := s.requireDebugger(); err != nil {
+		return nil, err
+	}
+	frames := s.debugger.Frames()
+	stackFrames := make([]dapStackFrame, 0, len(frames))
+	for i, frame := range frames {
+		stackFrame := dapStackFrame{Id: i, Name: s.debugger.FunctionAt(frame.PC), InstructionPointerReference: strconv.FormatUint(uint64(frame.PC), 10)}
+		if stackFrame.Name == "" {
+			stackFrame.Name = fmt.Sprintf("pc %d", frame.PC)
+		}
+		if location, ok := s.debugger.Runner.Program.GetLocation(frame.PC); ok {
+			filename := location.InputFile.Filename
+			stackFrame.Source = &dapSource{Name: filepath.Base(filename), Path: filename}
+			stackFrame.Line, stackFrame.Column = location.StartLine, location.StartCol
+		}
+		stackFrames = append(stackFrames, stackFrame)
+	}
+	return map[string]any{"stackFrames": stackFrames, "totalFrames": len(stackFrames)}, nil
+}
+
+func (s *DAPServer) scopes(arguments json.RawMessage) (any, error) {
+	if err := s.requireDebugger(); err != nil {
+		return nil, err
+	}
+	var args struct {
+		FrameId int `json:"frameId"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	reference := func(scope int) int { return args.FrameId*scopesPerFrame + scope + 1 }
+	scopes := []dapScope{
+		{Name: "Registers", VariablesReference: reference(scopeRegisters)},
+		{Name: "Memory", VariablesReference: reference(scopeMemory)},
+	}
+	// References can only be resolved with the current registers
+	if args.FrameId == 0 {
+		scopes = append([]dapScope{{Name: "Locals", VariablesReference: reference(scopeLocals)}}, scopes...)
+	}
+	return map[string]any{"scopes": scopes}, nil
+}
+
+func (s *DAPServer) variables(arguments json.RawMessage) (any, error) {
+	if err := s.requireDebugger(); err != nil {
+		return nil, err
+	}
+	var args struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	frames := s.debugger.Frames()
+	frameId, scope := (args.VariablesReference-1)/scopesPerFrame, (args.VariablesReference-1)%scopesPerFrame
+	if args.VariablesReference <= 0 || frameId >= len(frames) {
+		return nil, fmt.Errorf("Unknown variables reference %d", args.VariablesReference)
+	}
+	frame := frames[frameId]
+
+	variables := make([]dapVariable, 0)
+	switch scope {
+	case scopeLocals:
+		for _, id := range s.debugger.Ids() {
+			value := fmt.Sprintf("<%s>", id.Err)
+			if id.Err == nil {
+				value = FormatValue(&id.Value)
+			}
+			variables = append(variables, dapVariable{Name: id.Name, Value: value})
+		}
+	case scopeRegisters:
+		variables = append(variables,
+			dapVariable{Name: "pc", Value: strconv.FormatUint(uint64(frame.PC), 10)},
+			dapVariable{Name: "fp", Value: FormatAddress(frame.Fp)})
+		if frameId == 0 {
+			variables = append(variables, dapVariable{Name: "ap", Value: FormatAddress(frame.End)})
+		}
+	case scopeMemory:
+		if frame.End.Offset > frame.Fp.Offset {
+			cells, err := s.debugger.ReadMemory(frame.Fp, frame.End.Offset-frame.Fp.Offset)
+			if err != nil {
+				return nil, err
+			}
+			for i, cell := range cells {
+				variables = append(variables, dapVariable{Name: fmt.Sprintf("[fp + %d]", i), Value: FormatValue(cell)})
+			}
+		}
+	}
+	return map[string]any{"variables": variables}, nil
+}
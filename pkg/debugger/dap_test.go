@@ -0,0 +1,147 @@
+package debugger_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/debugger"
+)
+
+// main calls a function at pc 3 (line 7 of program.cairo) that writes 5 at ap, and returns
+const dapProgram = `{
+	"data": ["0x1104800180018000", "0x3", "0x208b7fff7fff7ffe", "0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe"],
+	"builtins": [],
+	"identifiers": {
+		"__main__.main": {"type": "function", "pc": 0, "decorators": []},
+		"__main__.f": {"type": "function", "pc": 3, "decorators": []}
+	},
+	"hints": {},
+	"reference_manager": {"references": []},
+	"debug_info": {
+		"file_contents": {},
+		"instruction_locations": {
+			"3": {
+				"accessible_scopes": ["__main__.f"],
+				"flow_tracking_data": {"ap_tracking": {"group": 1, "offset": 0}, "reference_ids": {}},
+				"hints": [],
+				"inst": {"start_line": 7, "start_col": 5, "end_line": 7, "end_col": 18, "input_file": {"filename": "src/program.cairo"}}
+			}
+		}
+	}
+}`
+
+type dapResponse struct {
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Event   string          `json:"event"`
+	Success bool            `json:"success"`
+	Body    json.RawMessage `json:"body"`
+}
+
+func encodeRequests(requests ...string) io.Reader {
+	var buffer bytes.Buffer
+	for i, request := range requests {
+		content := fmt.Sprintf(`{"seq": %d, "type": "request", %s}`, i+1, request)
+		fmt.Fprintf(&buffer, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	}
+	return &buffer
+}
+
+func decodeMessages(t *testing.T, output []byte) []dapResponse {
+	reader := bufio.NewReader(bytes.NewReader(output))
+	var messages []dapResponse
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return messages
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		if err != nil {
+			t.Fatalf("Invalid header %q", header)
+		}
+		reader.ReadString('\n')
+		content := make([]byte, length)
+		io.ReadFull(reader, content)
+		var message dapResponse
+		if err := json.Unmarshal(content, &message); err != nil {
+			t.Fatalf("Invalid message %q", content)
+		}
+		messages = append(messages, message)
+	}
+}
+
+func TestDAPSession(t *testing.T) {
+	programPath := filepath.Join(t.TempDir(), "program.json")
+	os.WriteFile(programPath, []byte(dapProgram), 0644)
+	input := encodeRequests(
+		`"command": "initialize", "arguments": {}`,
+		fmt.Sprintf(`"command": "launch", "arguments": {"program": %q}`, programPath),
+		`"command": "setBreakpoints", "arguments": {"source": {"path": "/home/user/src/program.cairo"}, "breakpoints": [{"line": 7}, {"line": 9}]}`,
+		`"command": "configurationDone"`,
+		`"command": "stackTrace", "arguments": {"threadId": 1}`,
+		`"command": "variables", "arguments": {"variablesReference": 2}`,
+		`"command": "continue", "arguments": {"threadId": 1}`,
+		`"command": "disconnect"`,
+	)
+	var output bytes.Buffer
+	if err := debugger.NewDAPServer(input, &output).Serve(); err != nil {
+		t.Fatalf("Serve error in test: %s", err)
+	}
+	messages := decodeMessages(t, output.Bytes())
+
+	var sequence []string
+	for _, message := range messages {
+		if message.Type == "event" {
+			sequence = append(sequence, "event:"+message.Event)
+			continue
+		}
+		if !message.Success {
+			t.Errorf("Request %s failed: %s", message.Command, message.Body)
+		}
+		sequence = append(sequence, message.Command)
+		switch message.Command {
+		case "setBreakpoints":
+			expected := `{"breakpoints":[{"line":7,"verified":true},{"line":9,"message":"No instruction found for this line, the program may lack debug info","verified":false}]}`
+			if string(message.Body) != expected {
+				t.Errorf("Wrong breakpoints. Expected %s, got %s", expected, message.Body)
+			}
+		case "stackTrace":
+			if !strings.Contains(string(message.Body), `"name":"__main__.f"`) || !strings.Contains(string(message.Body), `"name":"__main__.main"`) {
+				t.Errorf("Wrong stack trace, got %s", message.Body)
+			}
+		case "variables":
+			expected := `{"variables":[{"name":"pc","value":"3","variablesReference":0},{"name":"fp","value":"1:4","variablesReference":0},{"name":"ap","value":"1:4","variablesReference":0}]}`
+			if string(message.Body) != expected {
+				t.Errorf("Wrong variables. Expected %s, got %s", expected, message.Body)
+			}
+		}
+	}
+	expected := []string{
+		"initialize", "event:initialized", "launch", "setBreakpoints", "configurationDone", "event:stopped",
+		"stackTrace", "variables", "continue", "event:exited", "event:terminated", "disconnect",
+	}
+	if strings.Join(sequence, ",") != strings.Join(expected, ",") {
+		t.Errorf("Wrong message sequence. Expected %v, got %v", expected, sequence)
+	}
+}
+
+func TestDAPRequestsBeforeLaunch(t *testing.T) {
+	var output bytes.Buffer
+	input := encodeRequests(`"command": "stackTrace", "arguments": {"threadId": 1}`, `"command": "unknown"`)
+	if err := debugger.NewDAPServer(input, &output).Serve(); err != nil {
+		t.Fatalf("Serve error in test: %s", err)
+	}
+	for _, message := range decodeMessages(t, output.Bytes()) {
+		if message.Success {
+			t.Errorf("Request %s should have failed", message.Command)
+		}
+	}
+}
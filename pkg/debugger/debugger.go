@@ -0,0 +1,253 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Why the execution stopped after a Step, Next or Continue
+type StopReason uint
+
+const (
+	StopStep StopReason = iota
+	StopBreakpoint
+	StopEnd
+)
+
+// Runs a program one instruction at a time, stopping at breakpoints
+type Debugger struct {
+	Runner      *runners.CairoRunner
+	end         memory.Relocatable
+	breakpoints map[uint]struct{}
+}
+
+// A reference visible at the current pc, along with its value or the error resolving it
+type Variable struct {
+	Name  string
+	Value memory.MaybeRelocatable
+	Err   error
+}
+
+// Initializes a runner for the program, stopped before its first instruction
+func NewDebugger(program vm.Program) (*Debugger, error) {
+	runner, err := runners.NewCairoRunner(program, false)
+	if err != nil {
+		return nil, err
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		return nil, err
+	}
+	return &Debugger{Runner: runner, end: end, breakpoints: make(map[uint]struct{})}, nil
+}
+
+// Returns the current pc, as an offset within the program segment
+func (d *Debugger) PC() uint {
+	return d.Runner.Vm.RunContext.Pc.Offset
+}
+
+// Returns true once the program reached its end pc
+func (d *Debugger) Finished() bool {
+	return d.Runner.Vm.RunContext.Pc == d.end
+}
+
+func (d *Debugger) AddBreakpoint(pc uint) {
+	d.breakpoints[pc] = struct{}{}
+}
+
+// Removes the breakpoint at pc, returning false if there was none
+func (d *Debugger) RemoveBreakpoint(pc uint) bool {
+	_, ok := d.breakpoints[pc]
+	delete(d.breakpoints, pc)
+	return ok
+}
+
+// Returns the pcs with a breakpoint, in increasing order
+func (d *Debugger) Breakpoints() []uint {
+	pcs := make([]uint, 0, len(d.breakpoints))
+	for pc := range d.breakpoints {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	return pcs
+}
+
+// Returns the first pc of the instructions compiled from the given source line. The file only
+// needs to match the end of the path found in the debug info
+func (d *Debugger) PCForLine(file string, line int) (uint, error) {
+	found := false
+	var first uint
+	for pc, location := range d.Runner.Program.InstructionLocations {
+		if location.Inst.StartLine != line || !strings.HasSuffix(location.Inst.InputFile.Filename, file) {
+			continue
+		}
+		if !found || pc < first {
+			first = pc
+			found = true
+		}
+	}
+	if !found {
+		return 0, errors.New("No instruction found for this line, the program may lack debug info")
+	}
+	return first, nil
+}
+
+// Returns the source location of the current instruction, if the program has debug info for it
+func (d *Debugger) Location() (parser.Location, bool) {
+	return d.Runner.Program.GetLocation(d.PC())
+}
+
+// Runs a single instruction
+func (d *Debugger) Step() (StopReason, error) {
+	if d.Finished() {
+		return StopEnd, nil
+	}
+	if err := d.Runner.Vm.Step(); err != nil {
+		return StopStep, err
+	}
+	if d.Finished() {
+		return StopEnd, nil
+	}
+	return StopStep, nil
+}
+
+// Runs a single instruction, running calls until they return
+func (d *Debugger) Next() (StopReason, error) {
+	if d.Finished() {
+		return StopEnd, nil
+	}
+	instruction, err := d.currentInstruction()
+	if err != nil {
+		return StopStep, err
+	}
+	if instruction.Opcode != vm.Call {
+		return d.Step()
+	}
+	returnPc := d.Runner.Vm.RunContext.Pc
+	returnPc.Offset += instruction.Size()
+	fp := d.Runner.Vm.RunContext.Fp
+	return d.runUntil(func() bool {
+		return d.Runner.Vm.RunContext.Pc == returnPc && d.Runner.Vm.RunContext.Fp == fp
+	})
+}
+
+// Runs until a breakpoint or the end of the program is reached
+func (d *Debugger) Continue() (StopReason, error) {
+	return d.runUntil(func() bool { return false })
+}
+
+// Steps at least once, until done returns true, a breakpoint is hit or the program ends
+func (d *Debugger) runUntil(done func() bool) (StopReason, error) {
+	for {
+		reason, err := d.Step()
+		if err != nil || reason == StopEnd {
+			return reason, err
+		}
+		if done() {
+			return StopStep, nil
+		}
+		if _, ok := d.breakpoints[d.PC()]; ok {
+			return StopBreakpoint, nil
+		}
+	}
+}
+
+func (d *Debugger) currentInstruction() (vm.Instruction, error) {
+	encoded, err := d.Runner.Vm.Segments.Memory.GetFelt(d.Runner.Vm.RunContext.Pc)
+	if err != nil {
+		return vm.Instruction{}, err
+	}
+	return vm.DecodeInstructionFelt(encoded)
+}
+
+// Reads count consecutive memory cells starting at addr, empty cells are returned as nil
+func (d *Debugger) ReadMemory(addr memory.Relocatable, count uint) ([]*memory.MaybeRelocatable, error) {
+	cells := make([]*memory.MaybeRelocatable, 0, count)
+	for i := uint(0); i < count; i++ {
+		cell, err := d.Runner.Vm.Segments.Memory.TryGet(addr)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+		addr.Offset++
+	}
+	return cells, nil
+}
+
+// Returns the references visible at the current pc, sorted by name. They are taken from the
+// scope of the hint at the current pc if there is one, and from the debug info otherwise
+func (d *Debugger) Ids() []Variable {
+	program := &d.Runner.Program
+	var flowTracking parser.FlowTrackingData
+	if hints := program.Hints[d.PC()]; len(hints) > 0 {
+		flowTracking = hints[0].FlowTrackingData
+	} else if location, ok := program.InstructionLocations[d.PC()]; ok {
+		flowTracking = location.FlowTrackingData
+	}
+
+	variables := make([]Variable, 0, len(flowTracking.ReferenceIDS))
+	for fullName, id := range flowTracking.ReferenceIDS {
+		name := fullName[strings.LastIndex(fullName, ".")+1:]
+		if id < 0 || id >= len(program.References) {
+			variables = append(variables, Variable{Name: name, Err: errors.New("Unknown reference id")})
+			continue
+		}
+		value, err := d.Runner.Vm.GetReferenceValue(program.References[id], flowTracking.APTracking)
+		variables = append(variables, Variable{Name: name, Value: value, Err: err})
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+// A call frame, found by following the chain of saved fps
+type Frame struct {
+	// pc the frame is executing, as an offset within the program segment
+	PC uint
+	Fp memory.Relocatable
+	// Address right after the last cell of the frame: ap for the current frame, and the
+	// start of the callee's frame for the others
+	End memory.Relocatable
+}
+
+// Returns the call stack, starting with the current frame
+func (d *Debugger) Frames() []Frame {
+	callFrames := d.Runner.Vm.GetCallFrames()
+	frames := make([]Frame, 0, len(callFrames))
+	end := d.Runner.Vm.RunContext.Ap
+	for _, callFrame := range callFrames {
+		frames = append(frames, Frame{PC: callFrame.Pc.Offset, Fp: callFrame.Fp, End: end})
+		// The caller's frame ends where the saved fp and return pc are stored
+		end = memory.NewRelocatable(callFrame.Fp.SegmentIndex, callFrame.Fp.Offset-2)
+	}
+	return frames
+}
+
+// Returns the full name of the function containing pc, or an empty string if it is unknown
+func (d *Debugger) FunctionAt(pc uint) string {
+	name, _ := d.Runner.Program.GetFunctionAt(pc)
+	return name
+}
+
+// Formats an address as SEGMENT:OFFSET
+func FormatAddress(addr memory.Relocatable) string {
+	return fmt.Sprintf("%d:%d", addr.SegmentIndex, addr.Offset)
+}
+
+// Formats a memory value, felts are printed as signed decimals
+func FormatValue(value *memory.MaybeRelocatable) string {
+	if value == nil {
+		return "<empty>"
+	}
+	if addr, ok := value.GetRelocatable(); ok {
+		return FormatAddress(addr)
+	}
+	felt, _ := value.GetFelt()
+	return felt.ToSignedDecString()
+}
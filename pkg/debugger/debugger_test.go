@@ -0,0 +1,157 @@
+package debugger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/debugger"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// main calls a function at pc 3 that writes 5 at ap, and returns
+func testProgram(t *testing.T) vm.Program {
+	words := []string{
+		"0x1104800180018000", "0x3", // call rel 3
+		"0x208b7fff7fff7ffe",        // ret
+		"0x480680017fff8000", "0x5", // [ap] = 5; ap++
+		"0x208b7fff7fff7ffe", // ret
+	}
+	data := make([]memory.MaybeRelocatable, 0, len(words))
+	for _, word := range words {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex(word)))
+	}
+	reference, err := parser.NewHintReference(parser.Reference{Value: "[cast(ap + (-1), felt*)]", ApTrackingData: parser.ApTrackingData{Group: 1, Offset: 1}})
+	if err != nil {
+		t.Fatalf("NewHintReference error in test: %s", err)
+	}
+	location := parser.Location{InputFile: parser.InputFile{Filename: "src/program.cairo"}, StartLine: 7}
+	return vm.Program{
+		Data:        data,
+		Identifiers: map[string]parser.Identifier{"__main__.main": {Type: parser.IdentifierFunction, PC: 0}},
+		References:  []parser.HintReference{reference},
+		InstructionLocations: map[uint]parser.InstructionLocation{
+			3: {Inst: location},
+			5: {Inst: location, FlowTrackingData: parser.FlowTrackingData{
+				APTracking:   parser.ApTrackingData{Group: 1, Offset: 1},
+				ReferenceIDS: map[string]int{"__main__.f.x": 0},
+			}},
+		},
+	}
+}
+
+func newDebugger(t *testing.T) *debugger.Debugger {
+	d, err := debugger.NewDebugger(testProgram(t))
+	if err != nil {
+		t.Fatalf("NewDebugger error in test: %s", err)
+	}
+	return d
+}
+
+func TestStepEntersCalls(t *testing.T) {
+	d := newDebugger(t)
+	reason, err := d.Step()
+	if err != nil || reason != debugger.StopStep || d.PC() != 3 {
+		t.Errorf("Step should enter the call, got pc %d, reason %d, err: %v", d.PC(), reason, err)
+	}
+}
+
+func TestNextStepsOverCalls(t *testing.T) {
+	d := newDebugger(t)
+	reason, err := d.Next()
+	if err != nil || reason != debugger.StopStep || d.PC() != 2 {
+		t.Errorf("Next should step over the call, got pc %d, reason %d, err: %v", d.PC(), reason, err)
+	}
+}
+
+func TestContinueStopsAtBreakpoints(t *testing.T) {
+	d := newDebugger(t)
+	d.AddBreakpoint(5)
+	reason, err := d.Continue()
+	if err != nil || reason != debugger.StopBreakpoint || d.PC() != 5 {
+		t.Errorf("Continue should stop at the breakpoint, got pc %d, reason %d, err: %v", d.PC(), reason, err)
+	}
+	reason, err = d.Continue()
+	if err != nil || reason != debugger.StopEnd || !d.Finished() {
+		t.Errorf("Continue should run until the end, got pc %d, reason %d, err: %v", d.PC(), reason, err)
+	}
+	if reason, _ := d.Step(); reason != debugger.StopEnd {
+		t.Errorf("Step should not run past the end")
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	d := newDebugger(t)
+	d.AddBreakpoint(5)
+	d.AddBreakpoint(3)
+	if pcs := d.Breakpoints(); len(pcs) != 2 || pcs[0] != 3 || pcs[1] != 5 {
+		t.Errorf("Wrong breakpoints, got %v", pcs)
+	}
+	if !d.RemoveBreakpoint(3) || d.RemoveBreakpoint(3) {
+		t.Errorf("RemoveBreakpoint should only remove existing breakpoints")
+	}
+}
+
+func TestPCForLine(t *testing.T) {
+	d := newDebugger(t)
+	pc, err := d.PCForLine("program.cairo", 7)
+	if err != nil || pc != 3 {
+		t.Errorf("Wrong pc for line, got %d, err: %v", pc, err)
+	}
+	if _, err := d.PCForLine("program.cairo", 8); err == nil {
+		t.Errorf("PCForLine should fail for lines without instructions")
+	}
+}
+
+func TestIds(t *testing.T) {
+	d := newDebugger(t)
+	if ids := d.Ids(); len(ids) != 0 {
+		t.Errorf("No ids should be visible at pc 0, got %+v", ids)
+	}
+	d.AddBreakpoint(5)
+	if _, err := d.Continue(); err != nil {
+		t.Fatalf("Continue error in test: %s", err)
+	}
+	ids := d.Ids()
+	expected := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))
+	if len(ids) != 1 || ids[0].Name != "x" || ids[0].Err != nil || !ids[0].Value.IsEqual(expected) {
+		t.Errorf("Wrong ids, got %+v", ids)
+	}
+}
+
+func TestReadMemory(t *testing.T) {
+	d := newDebugger(t)
+	cells, err := d.ReadMemory(memory.NewRelocatable(0, 4), 3)
+	if err != nil || len(cells) != 3 || cells[2] != nil {
+		t.Fatalf("Wrong cells, got %+v, err: %v", cells, err)
+	}
+	if felt, ok := cells[0].GetFelt(); !ok || felt != lambdaworks.FeltFromUint64(5) {
+		t.Errorf("Wrong value at 0:4, got %+v", cells[0])
+	}
+}
+
+func TestFrames(t *testing.T) {
+	d := newDebugger(t)
+	d.AddBreakpoint(5)
+	if _, err := d.Continue(); err != nil {
+		t.Fatalf("Continue error in test: %s", err)
+	}
+	frames := d.Frames()
+	// The entrypoint's frame starts at 1:2, the function's at 1:4
+	expected := []debugger.Frame{
+		{PC: 5, Fp: memory.NewRelocatable(1, 4), End: memory.NewRelocatable(1, 5)},
+		{PC: 2, Fp: memory.NewRelocatable(1, 2), End: memory.NewRelocatable(1, 2)},
+	}
+	if !reflect.DeepEqual(frames, expected) {
+		t.Errorf("Wrong frames. Expected %+v, got %+v", expected, frames)
+	}
+}
+
+func TestFunctionAt(t *testing.T) {
+	d := newDebugger(t)
+	if name := d.FunctionAt(2); name != "__main__.main" {
+		t.Errorf("Wrong function at pc 2, got %q", name)
+	}
+}
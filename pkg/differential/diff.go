@@ -0,0 +1,57 @@
+package differential
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Describes the first step at which the traces diverge, or returns an empty string if they are equal
+func DiffTraces(left []vm.RelocatedTraceEntry, right []vm.RelocatedTraceEntry) string {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] != right[i] {
+			return fmt.Sprintf("Traces diverge at step %d:\n  left:  %s\n  right: %s", i, formatTraceEntry(left[i]), formatTraceEntry(right[i]))
+		}
+	}
+	if len(left) != len(right) {
+		step := len(left)
+		if len(right) < step {
+			step = len(right)
+		}
+		return fmt.Sprintf("Traces diverge at step %d: left has %d steps, right has %d steps", step, len(left), len(right))
+	}
+	return ""
+}
+
+func formatTraceEntry(entry vm.RelocatedTraceEntry) string {
+	return fmt.Sprintf("pc=%s ap=%s fp=%s", entry.Pc.String(), entry.Ap.String(), entry.Fp.String())
+}
+
+// Describes the first address at which the memories diverge, or returns an empty string if they are equal
+func DiffMemories(left []*lambdaworks.Felt, right []*lambdaworks.Felt) string {
+	for address := 0; address < len(left) || address < len(right); address++ {
+		leftValue, rightValue := cellAt(left, address), cellAt(right, address)
+		if leftValue == nil && rightValue == nil {
+			continue
+		}
+		if leftValue == nil || rightValue == nil || *leftValue != *rightValue {
+			return fmt.Sprintf("Memories diverge at address %d:\n  left:  %s\n  right: %s", address, formatCell(leftValue), formatCell(rightValue))
+		}
+	}
+	return ""
+}
+
+func cellAt(memory []*lambdaworks.Felt, address int) *lambdaworks.Felt {
+	if address < len(memory) {
+		return memory[address]
+	}
+	return nil
+}
+
+func formatCell(value *lambdaworks.Felt) string {
+	if value == nil {
+		return "<empty>"
+	}
+	return fmt.Sprintf("%s (%s)", value.ToHexString(), value.ToSignedDecString())
+}
@@ -0,0 +1,46 @@
+package differential_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/differential"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+func traceEntry(pc, ap, fp uint64) vm.RelocatedTraceEntry {
+	return vm.RelocatedTraceEntry{Pc: lambdaworks.FeltFromUint64(pc), Ap: lambdaworks.FeltFromUint64(ap), Fp: lambdaworks.FeltFromUint64(fp)}
+}
+
+func TestDiffTraces(t *testing.T) {
+	left := []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 5, 4)}
+	if diff := differential.DiffTraces(left, left); diff != "" {
+		t.Errorf("Equal traces shouldn't diverge, got %q", diff)
+	}
+	right := []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 6, 4)}
+	expected := "Traces diverge at step 1:\n  left:  pc=3 ap=5 fp=4\n  right: pc=3 ap=6 fp=4"
+	if diff := differential.DiffTraces(left, right); diff != expected {
+		t.Errorf("Wrong diff. Expected %q, got %q", expected, diff)
+	}
+}
+
+func TestDiffTracesOfDifferentLength(t *testing.T) {
+	left := []vm.RelocatedTraceEntry{traceEntry(1, 4, 4)}
+	right := []vm.RelocatedTraceEntry{traceEntry(1, 4, 4), traceEntry(3, 5, 4)}
+	if diff := differential.DiffTraces(left, right); !strings.HasPrefix(diff, "Traces diverge at step 1") {
+		t.Errorf("Wrong diff, got %q", diff)
+	}
+}
+
+func TestDiffMemories(t *testing.T) {
+	one, two := lambdaworks.FeltOne(), lambdaworks.FeltFromUint64(2)
+	left := []*lambdaworks.Felt{nil, &one, &two}
+	if diff := differential.DiffMemories(left, []*lambdaworks.Felt{nil, &one, &two, nil}); diff != "" {
+		t.Errorf("Trailing holes shouldn't diverge, got %q", diff)
+	}
+	expected := "Memories diverge at address 2:\n  left:  0x2 (2)\n  right: <empty>"
+	if diff := differential.DiffMemories(left, []*lambdaworks.Felt{nil, &one}); diff != expected {
+		t.Errorf("Wrong diff. Expected %q, got %q", expected, diff)
+	}
+}
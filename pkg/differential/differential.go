@@ -0,0 +1,164 @@
+// Runs programs on this VM and on a reference Cairo VM, comparing the relocated traces and
+// memories they produce byte for byte and reporting the first divergence.
+package differential
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+// Returned by references that have no artifacts for a program
+var ErrNoReference = errors.New("no reference artifacts")
+
+// A reference VM, producing the relocated trace and memory of a compiled program in the
+// cairo-lang binary format
+type Reference interface {
+	Run(programPath string) (trace []byte, memory []byte, err error)
+}
+
+// Runs the reference VM as a subprocess, such as the Rust cairo-vm-cli
+type CLIReference struct {
+	// Path of the executable, taking the program path, --trace_file and --memory_file
+	Path string
+	// Extra arguments, e.g. --layout all_cairo
+	Args []string
+}
+
+func (r CLIReference) Run(programPath string) ([]byte, []byte, error) {
+	dir, err := os.MkdirTemp("", "cairo-differential")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+	tracePath, memoryPath := filepath.Join(dir, "trace"), filepath.Join(dir, "memory")
+	args := append(append([]string{}, r.Args...), programPath, "--trace_file", tracePath, "--memory_file", memoryPath)
+	if output, err := exec.Command(r.Path, args...).CombinedOutput(); err != nil {
+		return nil, nil, fmt.Errorf("%s failed: %w\n%s", r.Path, err, output)
+	}
+	return readArtifacts(tracePath, memoryPath)
+}
+
+// Reads artifacts recorded beforehand: the trace and memory of PROGRAM.json are read from
+// PROGRAM.rs.trace and PROGRAM.rs.memory, in Dir if set and next to the program otherwise.
+// These are the names used by the compare_trace_memory make target
+type GoldenReference struct {
+	Dir string
+}
+
+func (r GoldenReference) Run(programPath string) ([]byte, []byte, error) {
+	base := strings.TrimSuffix(programPath, ".json")
+	if r.Dir != "" {
+		base = filepath.Join(r.Dir, filepath.Base(base))
+	}
+	trace, memory, err := readArtifacts(base+".rs.trace", base+".rs.memory")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%s: %w", programPath, ErrNoReference)
+	}
+	return trace, memory, err
+}
+
+func readArtifacts(tracePath string, memoryPath string) ([]byte, []byte, error) {
+	trace, err := os.ReadFile(tracePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	memory, err := os.ReadFile(memoryPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return trace, memory, nil
+}
+
+// The outcome of comparing a program's runs
+type Result struct {
+	Program string
+	// First divergence of the traces and memories, empty if they match
+	TraceDiff  string
+	MemoryDiff string
+	// Set if either VM failed to run the program
+	Err error
+}
+
+func (r Result) Ok() bool {
+	return r.Err == nil && r.TraceDiff == "" && r.MemoryDiff == ""
+}
+
+func (r Result) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: %s", r.Program, r.Err)
+	case r.Ok():
+		return fmt.Sprintf("%s: trace and memory match", r.Program)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s:\n%s\n%s", r.Program, r.TraceDiff, r.MemoryDiff))
+}
+
+// Runs the program on this VM and on reference, comparing the artifacts they produce. The left
+// side of the diffs is this VM
+func Compare(programPath string, reference Reference) Result {
+	result := Result{Program: programPath}
+	runner, err := cairo_run.CairoRun(programPath, cairo_run.CairoRunConfig{})
+	if err != nil {
+		result.Err = fmt.Errorf("run failed: %w", err)
+		return result
+	}
+	var trace, memory bytes.Buffer
+	if err := runner.Vm.WriteEncodedTrace(&trace); err != nil {
+		result.Err = err
+		return result
+	}
+	if err := runner.Vm.WriteEncodedMemory(&memory); err != nil {
+		result.Err = err
+		return result
+	}
+	referenceTrace, referenceMemory, err := reference.Run(programPath)
+	if err != nil {
+		result.Err = fmt.Errorf("reference failed: %w", err)
+		return result
+	}
+
+	if !bytes.Equal(trace.Bytes(), referenceTrace) {
+		decoded, err := vm.DecodeTrace(bytes.NewReader(referenceTrace))
+		if err != nil {
+			result.Err = fmt.Errorf("invalid reference trace: %w", err)
+			return result
+		}
+		if result.TraceDiff = DiffTraces(runner.Vm.RelocatedTrace, decoded); result.TraceDiff == "" {
+			result.TraceDiff = "Traces hold the same entries but are encoded differently"
+		}
+	}
+	if !bytes.Equal(memory.Bytes(), referenceMemory) {
+		decoded, err := vm.DecodeMemory(bytes.NewReader(referenceMemory))
+		if err != nil {
+			result.Err = fmt.Errorf("invalid reference memory: %w", err)
+			return result
+		}
+		if result.MemoryDiff = DiffMemories(runner.Vm.RelocatedMemory, decoded); result.MemoryDiff == "" {
+			result.MemoryDiff = "Memories hold the same cells but are encoded differently"
+		}
+	}
+	return result
+}
+
+// Compares every compiled program (*.json) of dir, in name order
+func CompareCorpus(dir string, reference Reference) ([]Result, error) {
+	programs, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(programs)
+	results := make([]Result, 0, len(programs))
+	for _, program := range programs {
+		results = append(results, Compare(program, reference))
+	}
+	return results, nil
+}
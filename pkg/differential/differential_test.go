@@ -0,0 +1,122 @@
+package differential_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/differential"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+const corpusDir = "../../cairo_programs"
+
+var fibonacciPath = filepath.Join(corpusDir, "fibonacci.json")
+
+// Writes this VM's artifacts for the program as golden files in a new directory
+func writeGolden(t *testing.T, programPath string) string {
+	if _, err := os.Stat(programPath); err != nil {
+		t.Skipf("%s is not compiled", programPath)
+	}
+	runner, err := cairo_run.CairoRun(programPath, cairo_run.CairoRunConfig{})
+	if err != nil {
+		t.Fatalf("CairoRun failed with error: %s", err)
+	}
+	dir := t.TempDir()
+	base := filepath.Join(dir, strings.TrimSuffix(filepath.Base(programPath), ".json"))
+	for path, write := range map[string]func(*os.File) error{
+		base + ".rs.trace":  func(f *os.File) error { return runner.Vm.WriteEncodedTrace(f) },
+		base + ".rs.memory": func(f *os.File) error { return runner.Vm.WriteEncodedMemory(f) },
+	} {
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := write(file); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+	}
+	return dir
+}
+
+func TestCompareMatchingGolden(t *testing.T) {
+	dir := writeGolden(t, fibonacciPath)
+	if result := differential.Compare(fibonacciPath, differential.GoldenReference{Dir: dir}); !result.Ok() {
+		t.Errorf("Expected the artifacts to match, got %s", result)
+	}
+}
+
+func TestCompareDivergingGolden(t *testing.T) {
+	dir := writeGolden(t, fibonacciPath)
+	// Change the ap of the third step
+	tracePath := filepath.Join(dir, "fibonacci.rs.trace")
+	trace, _ := os.ReadFile(tracePath)
+	trace[2*24+8]++
+	os.WriteFile(tracePath, trace, 0644)
+	// Remove the last memory cell
+	memoryPath := filepath.Join(dir, "fibonacci.rs.memory")
+	memory, _ := os.ReadFile(memoryPath)
+	os.WriteFile(memoryPath, memory[:len(memory)-40], 0644)
+
+	result := differential.Compare(fibonacciPath, differential.GoldenReference{Dir: dir})
+	if result.Err != nil || !strings.HasPrefix(result.TraceDiff, "Traces diverge at step 2") {
+		t.Errorf("Expected the traces to diverge at step 2, got %s", result)
+	}
+	if !strings.Contains(result.MemoryDiff, "right: <empty>") {
+		t.Errorf("Expected the last cell to be missing on the right, got %q", result.MemoryDiff)
+	}
+}
+
+func TestCompareMissingGolden(t *testing.T) {
+	_, _, err := differential.GoldenReference{Dir: t.TempDir()}.Run(fibonacciPath)
+	if !errors.Is(err, differential.ErrNoReference) {
+		t.Errorf("Expected ErrNoReference, got %v", err)
+	}
+}
+
+func TestCompareCLIReference(t *testing.T) {
+	dir := writeGolden(t, fibonacciPath)
+	// A fake reference VM copying the golden files to the requested paths
+	script := filepath.Join(dir, "reference.sh")
+	content := "#!/bin/sh\n[ \"$1\" = --layout ] && shift 2\ncp " + filepath.Join(dir, "fibonacci.rs.trace") + " \"$3\"\ncp " + filepath.Join(dir, "fibonacci.rs.memory") + " \"$5\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	reference := differential.CLIReference{Path: script, Args: []string{"--layout", "all_cairo"}}
+	if result := differential.Compare(fibonacciPath, reference); !result.Ok() {
+		t.Errorf("Expected the artifacts to match, got %s", result)
+	}
+	failing := differential.CLIReference{Path: filepath.Join(dir, "missing")}
+	if result := differential.Compare(fibonacciPath, failing); result.Err == nil {
+		t.Errorf("Expected a missing reference executable to fail")
+	}
+}
+
+// Compares the whole corpus against the reference VM given by CAIRO_VM_CLI, or against the
+// golden files next to the programs. Programs without golden files are skipped
+func TestCorpus(t *testing.T) {
+	var reference differential.Reference = differential.GoldenReference{}
+	if path := os.Getenv("CAIRO_VM_CLI"); path != "" {
+		reference = differential.CLIReference{Path: path, Args: []string{"--layout", "all_cairo"}}
+	}
+	results, err := differential.CompareCorpus(corpusDir, reference)
+	if err != nil {
+		t.Fatalf("CompareCorpus failed with error: %s", err)
+	}
+	compared := 0
+	for _, result := range results {
+		if errors.Is(result.Err, differential.ErrNoReference) {
+			continue
+		}
+		compared++
+		if !result.Ok() {
+			t.Error(result)
+		}
+	}
+	if compared == 0 {
+		t.Skip("No reference artifacts, set CAIRO_VM_CLI or run make compare_trace_memory")
+	}
+}
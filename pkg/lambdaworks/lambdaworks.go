@@ -1,14 +1,21 @@
 package lambdaworks
 
 /*
-#cgo LDFLAGS: pkg/lambdaworks/lib/liblambdaworks.a -ldl
+#cgo LDFLAGS: ${SRCDIR}/lib/liblambdaworks.a -ldl
 #include "lib/lambdaworks.h"
 #include <stdlib.h>
 */
 import "C"
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"strings"
 	"unsafe"
 )
 
@@ -38,11 +45,10 @@ func fromC(result C.felt_t) Felt {
 	return Felt{limbs: limbs}
 }
 
-// Gets a Felt representing the "value" number, in Montgomery format.
+// Gets a Felt representing the "value" number.
+// Built in Go as the limbs hold the canonical value, crossing the CGO boundary would allocate.
 func FeltFromUint64(value uint64) Felt {
-	var result C.felt_t
-	C.from(&result[0], C.uint64_t(value))
-	return fromC(result)
+	return Felt{limbs: [4]Limb{0, 0, 0, Limb(value)}}
 }
 
 func FeltFromHex(value string) Felt {
@@ -63,7 +69,9 @@ func FeltFromDecString(value string) Felt {
 	return fromC(result)
 }
 
-// turns a felt to usize
+// turns a felt to u64
+// The limbs hold the canonical (non-Montgomery) value in big-endian order, so the conversion
+// succeeds exactly when the value is smaller than 2^64.
 func (felt Felt) ToU64() (uint64, error) {
 	if felt.limbs[0] == 0 && felt.limbs[1] == 0 && felt.limbs[2] == 0 {
 		return uint64(felt.limbs[3]), nil
@@ -72,6 +80,37 @@ func (felt Felt) ToU64() (uint64, error) {
 	}
 }
 
+// turns a felt to u64, keeping only the lowest 64 bits of its canonical value
+func (felt Felt) ToU64Unchecked() uint64 {
+	return uint64(felt.limbs[3])
+}
+
+// turns a felt to u32
+func (felt Felt) ToU32() (uint32, error) {
+	value, err := felt.ToU64()
+	if err != nil || value > math.MaxUint32 {
+		return 0, fmt.Errorf("Cannot convert felt %s to u32", felt)
+	}
+	return uint32(value), nil
+}
+
+// turns a felt to uint (the platform's usize)
+func (felt Felt) ToUsize() (uint, error) {
+	value, err := felt.ToU64()
+	if err != nil || uint64(uint(value)) != value {
+		return 0, fmt.Errorf("Cannot convert felt %s to usize", felt)
+	}
+	return uint(value), nil
+}
+
+// turns a felt to u128, returned as its high and low 64 bit halves
+func (felt Felt) ToU128() (high uint64, low uint64, err error) {
+	if felt.limbs[0] != 0 || felt.limbs[1] != 0 {
+		return 0, 0, fmt.Errorf("Cannot convert felt %s to u128", felt)
+	}
+	return uint64(felt.limbs[2]), uint64(felt.limbs[3]), nil
+}
+
 func (felt Felt) ToLeBytes() *[32]byte {
 	var result_c [32]C.uint8_t
 	var value C.felt_t = felt.toC()
@@ -108,24 +147,57 @@ func FeltFromBeBytes(bytes *[32]byte) Felt {
 
 // Gets a Felt representing 0.
 func FeltZero() Felt {
-	var result C.felt_t
-	C.zero(&result[0])
-	return fromC(result)
+	return Felt{}
+}
+
+// Gets a Felt representing 2.
+func FeltTwo() Felt {
+	return Felt{limbs: [4]Limb{0, 0, 0, 2}}
+}
+
+// Gets a Felt representing 2^128.
+func FeltTwoPow128() Felt {
+	return Felt{limbs: [4]Limb{0, 1, 0, 0}}
+}
+
+// Gets the biggest Felt value, P - 1.
+func FeltMax() Felt {
+	return Felt{limbs: [4]Limb{0x0800000000000011, 0, 0, 0}}
+}
+
+// Gets (P - 1) / 2, the biggest Felt value interpreted as non-negative.
+func FeltPrimeHalf() Felt {
+	return Felt{limbs: [4]Limb{0x0400000000000008, 0x8000000000000000, 0, 0}}
+}
+
+// Returns the field prime P as a big.Int. A new value is returned on each call so it can be
+// freely modified by the caller.
+func Prime() *big.Int {
+	return new(big.Int).Set(primeBig)
 }
 
 // Gets a Felt representing 1.
 func FeltOne() Felt {
-	var result C.felt_t
-	C.one(&result[0])
-	return fromC(result)
+	return Felt{limbs: [4]Limb{0, 0, 0, 1}}
 }
 
 func (f Felt) IsZero() bool {
 	return f == FeltZero()
 }
 
+// Returns true if the canonical value of the felt fits in a u64.
+// Small felts are operated on in Go, avoiding the cost of crossing the CGO boundary.
+func (f Felt) isSmall() bool {
+	return f.limbs[0] == 0 && f.limbs[1] == 0 && f.limbs[2] == 0
+}
+
 // Writes the result variable with the sum of a and b felts.
 func (a Felt) Add(b Felt) Felt {
+	if a.isSmall() && b.isSmall() {
+		// The sum of two u64 values is always smaller than the prime
+		sum, carry := bits.Add64(uint64(a.limbs[3]), uint64(b.limbs[3]), 0)
+		return Felt{limbs: [4]Limb{0, 0, Limb(carry), Limb(sum)}}
+	}
 	var result C.felt_t
 	var a_c C.felt_t = a.toC()
 	var b_c C.felt_t = b.toC()
@@ -135,6 +207,22 @@ func (a Felt) Add(b Felt) Felt {
 
 // Writes the result variable with a - b.
 func (a Felt) Sub(b Felt) Felt {
+	if a.isSmall() && b.isSmall() {
+		if a.limbs[3] >= b.limbs[3] {
+			return Felt{limbs: [4]Limb{0, 0, 0, a.limbs[3] - b.limbs[3]}}
+		}
+		// a - b = P - (b - a)
+		result := primeLimbs
+		var borrow uint64
+		diff := uint64(b.limbs[3] - a.limbs[3])
+		for i := 3; i >= 0; i-- {
+			var limb uint64
+			limb, borrow = bits.Sub64(uint64(result[i]), diff, borrow)
+			result[i] = Limb(limb)
+			diff = 0
+		}
+		return Felt{limbs: result}
+	}
 	var result C.felt_t
 	var a_c C.felt_t = a.toC()
 	var b_c C.felt_t = b.toC()
@@ -144,6 +232,11 @@ func (a Felt) Sub(b Felt) Felt {
 
 // Writes the result variable with a * b.
 func (a Felt) Mul(b Felt) Felt {
+	if a.isSmall() && b.isSmall() {
+		// The product of two u64 values fits in 128 bits, which is smaller than the prime
+		high, low := bits.Mul64(uint64(a.limbs[3]), uint64(b.limbs[3]))
+		return Felt{limbs: [4]Limb{0, 0, Limb(high), Limb(low)}}
+	}
 	var result C.felt_t
 	var a_c C.felt_t = a.toC()
 	var b_c C.felt_t = b.toC()
@@ -159,3 +252,340 @@ func (a Felt) Div(b Felt) Felt {
 	C.lw_div(&a_c[0], &b_c[0], &result[0])
 	return fromC(result)
 }
+
+var ErrDivisionByZero = errors.New("Division by zero")
+
+// Returns the multiplicative inverse of a, or an error if a is zero.
+func (a Felt) Inverse() (Felt, error) {
+	if a.IsZero() {
+		return FeltZero(), ErrDivisionByZero
+	}
+	return FeltOne().Div(a), nil
+}
+
+// Returns a / b, or an error if b is zero.
+func (a Felt) CheckedDiv(b Felt) (Felt, error) {
+	if b.IsZero() {
+		return FeltZero(), ErrDivisionByZero
+	}
+	return a.Div(b), nil
+}
+
+// Compares the canonical values of a and b.
+// Returns -1 if a < b, 0 if a == b and 1 if a > b.
+func (a Felt) Cmp(b Felt) int {
+	// Limbs hold the canonical value, most significant limb first
+	for i := range a.limbs {
+		if a.limbs[i] < b.limbs[i] {
+			return -1
+		}
+		if a.limbs[i] > b.limbs[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Returns true if a < b
+func (a Felt) Lt(b Felt) bool {
+	return a.Cmp(b) < 0
+}
+
+// Returns true if a <= b
+func (a Felt) Le(b Felt) bool {
+	return a.Cmp(b) <= 0
+}
+
+// Returns true if a > b
+func (a Felt) Gt(b Felt) bool {
+	return a.Cmp(b) > 0
+}
+
+// Returns true if a >= b
+func (a Felt) Ge(b Felt) bool {
+	return a.Cmp(b) >= 0
+}
+
+// Writes the result variable with a^exp.
+func (a Felt) Pow(exp uint) Felt {
+	return a.PowFelt(FeltFromUint64(uint64(exp)))
+}
+
+// Writes the result variable with a^exp, using the canonical value of exp as exponent.
+func (a Felt) PowFelt(exp Felt) Felt {
+	var result C.felt_t
+	var a_c C.felt_t = a.toC()
+	var exp_c C.felt_t = exp.toC()
+	C.lw_pow(&a_c[0], &exp_c[0], &result[0])
+	return fromC(result)
+}
+
+// The field prime satisfies P - 1 = 2^192 * Q, with Q = 2^59 + 17 odd.
+const primeTwoAdicity = 192
+
+var (
+	// Q and (Q + 1) / 2
+	primeOddFactor        = FeltFromHex("0x800000000000011")
+	primeOddFactorPlusOne = FeltFromHex("0x400000000000009")
+	// 3 generates the multiplicative group, so it is a quadratic non-residue
+	quadraticNonResidue = FeltFromUint64(3)
+)
+
+// The field prime as a big.Int
+var primeBig, _ = new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+
+// Returns the canonical value of the felt as a big.Int.
+func (f Felt) ToBigInt() *big.Int {
+	return new(big.Int).SetBytes(f.ToBeBytes()[:])
+}
+
+// Converts a big.Int into a felt, reducing it modulo P (negative values wrap around).
+func FeltFromBigInt(n *big.Int) Felt {
+	var bytes [32]byte
+	new(big.Int).Mod(n, primeBig).FillBytes(bytes[:])
+	return FeltFromBeBytes(&bytes)
+}
+
+// Returns the decimal representation of the felt.
+func (f Felt) String() string {
+	return f.ToBigInt().String()
+}
+
+// Returns the hexadecimal representation of the felt, prefixed with "0x".
+func (f Felt) ToHexString() string {
+	return "0x" + f.ToBigInt().Text(16)
+}
+
+// Returns the decimal representation of the felt, interpreting values above P / 2 as negative.
+func (f Felt) ToSignedDecString() string {
+	return f.ToSigned().String()
+}
+
+// Parses a felt from a hexadecimal (0x prefixed) or decimal string, which may be negative.
+// Unlike FeltFromHex and FeltFromDecString, malformed input is reported as an error.
+func feltFromString(value string) (Felt, error) {
+	negative := strings.HasPrefix(value, "-")
+	digits := strings.TrimPrefix(value, "-")
+	base := 10
+	if strings.HasPrefix(digits, "0x") {
+		digits, base = strings.TrimPrefix(digits, "0x"), 16
+	}
+	// SetString accepts its own sign, which would let "--1" through
+	var n *big.Int
+	ok := digits != "" && digits[0] != '-' && digits[0] != '+'
+	if ok {
+		n, ok = new(big.Int).SetString(digits, base)
+	}
+	if !ok {
+		return FeltZero(), fmt.Errorf("Invalid felt value: %q", value)
+	}
+	if negative {
+		n.Neg(n)
+	}
+	return FeltFromBigInt(n), nil
+}
+
+// Implements encoding.TextMarshaler, encoding the felt as a 0x prefixed hex string.
+func (f Felt) MarshalText() ([]byte, error) {
+	return []byte(f.ToHexString()), nil
+}
+
+// Implements encoding.TextUnmarshaler, accepting hex (0x prefixed) or decimal strings.
+func (f *Felt) UnmarshalText(text []byte) error {
+	felt, err := feltFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*f = felt
+	return nil
+}
+
+// Implements json.Marshaler, encoding the felt as a 0x prefixed hex string.
+func (f Felt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.ToHexString())
+}
+
+// Implements json.Unmarshaler, accepting either a string (hex or decimal) or a JSON number.
+func (f *Felt) UnmarshalJSON(data []byte) error {
+	var value string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	} else {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+		value = number.String()
+	}
+	return f.UnmarshalText([]byte(value))
+}
+
+// Returns the additive inverse of a (P - a).
+func (a Felt) Neg() Felt {
+	return FeltZero().Sub(a)
+}
+
+// Returns true if a is greater than P / 2, meaning it represents a negative value.
+func (a Felt) IsNegative() bool {
+	return a.Gt(FeltPrimeHalf())
+}
+
+// Returns the signed interpretation of a: values above P / 2 are mapped to a - P.
+func (a Felt) ToSigned() *big.Int {
+	if a.IsNegative() {
+		abs := a.Neg().ToBigInt()
+		return abs.Neg(abs)
+	}
+	return a.ToBigInt()
+}
+
+// Returns true if a is a square modulo the field prime (Euler's criterion).
+// Zero is considered a quadratic residue.
+func (a Felt) IsQuadResidue() bool {
+	if a.IsZero() {
+		return true
+	}
+	return a.PowFelt(FeltPrimeHalf()) == FeltOne()
+}
+
+// Returns the square root of a using Tonelli-Shanks, choosing the smallest of the two roots,
+// and false if a is not a quadratic residue.
+func (a Felt) Sqrt() (Felt, bool) {
+	if a.IsZero() {
+		return FeltZero(), true
+	}
+	if !a.IsQuadResidue() {
+		return FeltZero(), false
+	}
+	m := primeTwoAdicity
+	c := quadraticNonResidue.PowFelt(primeOddFactor)
+	t := a.PowFelt(primeOddFactor)
+	root := a.PowFelt(primeOddFactorPlusOne)
+	one := FeltOne()
+	for t != one {
+		// Find the least i such that t^(2^i) == 1
+		i := 0
+		for t2i := t; t2i != one; i++ {
+			t2i = t2i.Mul(t2i)
+		}
+		b := c
+		for j := 0; j < m-i-1; j++ {
+			b = b.Mul(b)
+		}
+		m = i
+		c = b.Mul(b)
+		t = t.Mul(c)
+		root = root.Mul(b)
+	}
+	if negRoot := FeltZero().Sub(root); negRoot.Lt(root) {
+		return negRoot, true
+	}
+	return root, true
+}
+
+// Returns the number of bits needed to represent the canonical value of a (0 for zero).
+func (a Felt) NumBits() uint {
+	for i, limb := range a.limbs {
+		if limb != 0 {
+			return uint(64*(len(a.limbs)-i) - bits.LeadingZeros64(uint64(limb)))
+		}
+	}
+	return 0
+}
+
+// Returns the i-th bit (starting from the least significant one) of the canonical value of a.
+func (a Felt) Bit(i uint) bool {
+	if i >= 256 {
+		return false
+	}
+	return (a.limbs[3-i/64]>>(i%64))&1 == 1
+}
+
+// Returns the bits of the canonical value of a, from least to most significant, up to the
+// most significant set bit.
+func (a Felt) Bits() []bool {
+	result := make([]bool, a.NumBits())
+	for i := range result {
+		result[i] = a.Bit(uint(i))
+	}
+	return result
+}
+
+// Limbs of the field prime P = 2^251 + 17 * 2^192 + 1, most significant limb first
+var primeLimbs = [4]Limb{0x0800000000000011, 0, 0, 1}
+
+// Builds a Felt from canonical limbs that may exceed the prime by less than P, subtracting P if needed
+func feltFromLimbsReduced(limbs [4]Limb) Felt {
+	felt := Felt{limbs: limbs}
+	if felt.Cmp(Felt{limbs: primeLimbs}) < 0 {
+		return felt
+	}
+	var borrow uint64
+	for i := 3; i >= 0; i-- {
+		var limb uint64
+		limb, borrow = bits.Sub64(uint64(felt.limbs[i]), uint64(primeLimbs[i]), borrow)
+		felt.limbs[i] = Limb(limb)
+	}
+	return felt
+}
+
+// Returns the bitwise and of the canonical values of a and b.
+func (a Felt) And(b Felt) Felt {
+	var limbs [4]Limb
+	for i := range limbs {
+		limbs[i] = a.limbs[i] & b.limbs[i]
+	}
+	return Felt{limbs: limbs}
+}
+
+// Returns the bitwise or of the canonical values of a and b.
+// Operands are expected to fit in 251 bits, as enforced by the bitwise builtin. Otherwise the
+// result is reduced modulo the field prime.
+func (a Felt) Or(b Felt) Felt {
+	var limbs [4]Limb
+	for i := range limbs {
+		limbs[i] = a.limbs[i] | b.limbs[i]
+	}
+	return feltFromLimbsReduced(limbs)
+}
+
+// Returns the bitwise xor of the canonical values of a and b.
+// Operands are expected to fit in 251 bits, as enforced by the bitwise builtin. Otherwise the
+// result is reduced modulo the field prime.
+func (a Felt) Xor(b Felt) Felt {
+	var limbs [4]Limb
+	for i := range limbs {
+		limbs[i] = a.limbs[i] ^ b.limbs[i]
+	}
+	return feltFromLimbsReduced(limbs)
+}
+
+// Returns the bitwise not of the lower 251 bits of the canonical value of a.
+// Bits above the 251st are ignored, so the result always fits in 251 bits.
+func (a Felt) Not() Felt {
+	var limbs [4]Limb
+	for i := range limbs {
+		limbs[i] = ^a.limbs[i]
+	}
+	// 251 = 3 * 64 + 59, keep the lower 59 bits of the most significant limb
+	limbs[0] &= (1 << 59) - 1
+	return Felt{limbs: limbs}
+}
+
+// Returns a uniformly distributed random felt drawn from rng, so that tests seeding rng
+// get reproducible values. Uses rejection sampling over 252 bit candidates.
+func RandFelt(rng *rand.Rand) Felt {
+	prime := Felt{limbs: primeLimbs}
+	for {
+		var felt Felt
+		for i := range felt.limbs {
+			felt.limbs[i] = Limb(rng.Uint64())
+		}
+		felt.limbs[0] &= 0x0fffffffffffffff
+		if felt.Lt(prime) {
+			return felt
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package lambdaworks_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
 	"reflect"
 	"testing"
 
@@ -167,3 +172,491 @@ func TestFeltDiv4Error(t *testing.T) {
 		t.Errorf("TestFeltDiv4Error failed. Expected: %v, Got: %v", expected, result)
 	}
 }
+
+func TestFeltCmp(t *testing.T) {
+	small := lambdaworks.FeltFromUint64(5)
+	big := lambdaworks.FeltFromHex("0x10000000000000000")
+	max := lambdaworks.FeltFromDecString("-1")
+
+	if small.Cmp(big) != -1 || big.Cmp(small) != 1 || small.Cmp(small) != 0 {
+		t.Errorf("TestFeltCmp failed comparing %v and %v", small, big)
+	}
+	if max.Cmp(big) != 1 {
+		t.Errorf("TestFeltCmp failed. -1 should be the greatest felt")
+	}
+}
+
+func TestFeltOrderingHelpers(t *testing.T) {
+	one := lambdaworks.FeltOne()
+	two := lambdaworks.FeltFromUint64(2)
+
+	if !one.Lt(two) || two.Lt(one) || one.Lt(one) {
+		t.Errorf("TestFeltOrderingHelpers failed for Lt")
+	}
+	if !one.Le(two) || !one.Le(one) || two.Le(one) {
+		t.Errorf("TestFeltOrderingHelpers failed for Le")
+	}
+	if !two.Gt(one) || one.Gt(two) || one.Gt(one) {
+		t.Errorf("TestFeltOrderingHelpers failed for Gt")
+	}
+	if !two.Ge(one) || !one.Ge(one) || one.Ge(two) {
+		t.Errorf("TestFeltOrderingHelpers failed for Ge")
+	}
+}
+
+func TestFeltAnd(t *testing.T) {
+	a := lambdaworks.FeltFromHex("0xff00ff00ff00ff00ff00ff00ff00ff00")
+	b := lambdaworks.FeltFromHex("0x0ff00ff00ff00ff00ff00ff00ff00ff0")
+	expected := lambdaworks.FeltFromHex("0x0f000f000f000f000f000f000f000f00")
+	if result := a.And(b); result != expected {
+		t.Errorf("TestFeltAnd failed. Expected: %v, Got: %v", expected, result)
+	}
+}
+
+func TestFeltOr(t *testing.T) {
+	a := lambdaworks.FeltFromUint64(0b1010)
+	b := lambdaworks.FeltFromUint64(0b0110)
+	expected := lambdaworks.FeltFromUint64(0b1110)
+	if result := a.Or(b); result != expected {
+		t.Errorf("TestFeltOr failed. Expected: %v, Got: %v", expected, result)
+	}
+}
+
+func TestFeltXor(t *testing.T) {
+	a := lambdaworks.FeltFromUint64(0b1010)
+	b := lambdaworks.FeltFromUint64(0b0110)
+	expected := lambdaworks.FeltFromUint64(0b1100)
+	if result := a.Xor(b); result != expected {
+		t.Errorf("TestFeltXor failed. Expected: %v, Got: %v", expected, result)
+	}
+}
+
+func TestFeltXorReducesModPrime(t *testing.T) {
+	// P - 1 = 2^251 + 17 * 2^192, xoring it with 2^193 gives P - 1 + 2^193, which reduces to 2^193 - 1
+	a := lambdaworks.FeltFromDecString("-1")
+	b := lambdaworks.FeltFromHex("0x2000000000000000000000000000000000000000000000000")
+	expected := lambdaworks.FeltFromHex("0x1ffffffffffffffffffffffffffffffffffffffffffffffff")
+	if result := a.Xor(b); result != expected {
+		t.Errorf("TestFeltXorReducesModPrime failed. Expected: %v, Got: %v", expected, result)
+	}
+}
+
+func TestFeltNot(t *testing.T) {
+	max251 := lambdaworks.FeltFromHex("0x7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	if result := lambdaworks.FeltZero().Not(); result != max251 {
+		t.Errorf("TestFeltNot failed. Expected: %v, Got: %v", max251, result)
+	}
+	if result := max251.Not(); result != lambdaworks.FeltZero() {
+		t.Errorf("TestFeltNot failed. Expected 0, Got: %v", result)
+	}
+}
+
+func TestFeltPow(t *testing.T) {
+	three := lambdaworks.FeltFromUint64(3)
+	if result := three.Pow(4); result != lambdaworks.FeltFromUint64(81) {
+		t.Errorf("TestFeltPow failed. Expected 81, Got: %v", result)
+	}
+	if result := three.Pow(0); result != lambdaworks.FeltOne() {
+		t.Errorf("TestFeltPow failed. Expected 1, Got: %v", result)
+	}
+}
+
+func TestFeltPowFeltFermat(t *testing.T) {
+	// a^(P-1) == 1 for every non-zero a
+	pMinusOne := lambdaworks.FeltFromDecString("-1")
+	a := lambdaworks.FeltFromUint64(123456789)
+	if result := a.PowFelt(pMinusOne); result != lambdaworks.FeltOne() {
+		t.Errorf("TestFeltPowFeltFermat failed. Expected 1, Got: %v", result)
+	}
+}
+
+func TestFeltSqrtSmallSquare(t *testing.T) {
+	root, ok := lambdaworks.FeltFromUint64(49).Sqrt()
+	if !ok || root != lambdaworks.FeltFromUint64(7) {
+		t.Errorf("TestFeltSqrtSmallSquare failed. Expected 7, Got: %v", root)
+	}
+}
+
+func TestFeltSqrtReturnsSmallestRoot(t *testing.T) {
+	// -5 and 5 have the same square, 5 is the smallest root
+	minusFive := lambdaworks.FeltFromDecString("-5")
+	root, ok := minusFive.Mul(minusFive).Sqrt()
+	if !ok || root != lambdaworks.FeltFromUint64(5) {
+		t.Errorf("TestFeltSqrtReturnsSmallestRoot failed. Expected 5, Got: %v", root)
+	}
+}
+
+func TestFeltSqrtBigValue(t *testing.T) {
+	a := lambdaworks.FeltFromHex("0x1234567890abcdef1234567890abcdef1234567890abcdef")
+	square := a.Mul(a)
+	root, ok := square.Sqrt()
+	if !ok || root.Mul(root) != square {
+		t.Errorf("TestFeltSqrtBigValue failed. Got: %v", root)
+	}
+}
+
+func TestFeltSqrtNonResidue(t *testing.T) {
+	three := lambdaworks.FeltFromUint64(3)
+	if three.IsQuadResidue() {
+		t.Errorf("3 should not be a quadratic residue")
+	}
+	if _, ok := three.Sqrt(); ok {
+		t.Errorf("Sqrt of a non residue should fail")
+	}
+}
+
+func TestFeltIsQuadResidue(t *testing.T) {
+	if !lambdaworks.FeltZero().IsQuadResidue() || !lambdaworks.FeltFromUint64(4).IsQuadResidue() {
+		t.Errorf("0 and 4 should be quadratic residues")
+	}
+}
+
+func TestFeltNeg(t *testing.T) {
+	five := lambdaworks.FeltFromUint64(5)
+	if five.Neg() != lambdaworks.FeltFromDecString("-5") {
+		t.Errorf("TestFeltNeg failed. Got: %v", five.Neg())
+	}
+	if !five.Add(five.Neg()).IsZero() {
+		t.Errorf("TestFeltNeg failed. a + -a should be zero")
+	}
+	if !lambdaworks.FeltZero().Neg().IsZero() {
+		t.Errorf("TestFeltNeg failed. -0 should be zero")
+	}
+}
+
+func TestFeltIsNegative(t *testing.T) {
+	if lambdaworks.FeltFromUint64(5).IsNegative() {
+		t.Errorf("5 should not be negative")
+	}
+	if !lambdaworks.FeltFromDecString("-5").IsNegative() {
+		t.Errorf("-5 should be negative")
+	}
+	if lambdaworks.FeltZero().IsNegative() {
+		t.Errorf("0 should not be negative")
+	}
+}
+
+func TestFeltToSigned(t *testing.T) {
+	if got := lambdaworks.FeltFromDecString("-5").ToSigned(); got.Cmp(big.NewInt(-5)) != 0 {
+		t.Errorf("TestFeltToSigned failed. Expected -5, Got: %v", got)
+	}
+	if got := lambdaworks.FeltFromUint64(5).ToSigned(); got.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("TestFeltToSigned failed. Expected 5, Got: %v", got)
+	}
+}
+
+func TestFeltString(t *testing.T) {
+	if got := lambdaworks.FeltFromUint64(26).String(); got != "26" {
+		t.Errorf("TestFeltString failed. Expected 26, Got: %s", got)
+	}
+	if got := fmt.Sprintf("%v", lambdaworks.FeltZero()); got != "0" {
+		t.Errorf("TestFeltString failed. Expected 0, Got: %s", got)
+	}
+}
+
+func TestFeltToHexString(t *testing.T) {
+	if got := lambdaworks.FeltFromUint64(26).ToHexString(); got != "0x1a" {
+		t.Errorf("TestFeltToHexString failed. Expected 0x1a, Got: %s", got)
+	}
+	if got := lambdaworks.FeltZero().ToHexString(); got != "0x0" {
+		t.Errorf("TestFeltToHexString failed. Expected 0x0, Got: %s", got)
+	}
+}
+
+func TestFeltToSignedDecString(t *testing.T) {
+	if got := lambdaworks.FeltFromDecString("-26").ToSignedDecString(); got != "-26" {
+		t.Errorf("TestFeltToSignedDecString failed. Expected -26, Got: %s", got)
+	}
+	if got := lambdaworks.FeltFromUint64(26).ToSignedDecString(); got != "26" {
+		t.Errorf("TestFeltToSignedDecString failed. Expected 26, Got: %s", got)
+	}
+}
+
+func TestFeltToBigInt(t *testing.T) {
+	expected, _ := new(big.Int).SetString("1234567890abcdef1234567890abcdef", 16)
+	if got := lambdaworks.FeltFromHex("0x1234567890abcdef1234567890abcdef").ToBigInt(); got.Cmp(expected) != 0 {
+		t.Errorf("TestFeltToBigInt failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestFeltFromBigInt(t *testing.T) {
+	if got := lambdaworks.FeltFromBigInt(big.NewInt(26)); got != lambdaworks.FeltFromUint64(26) {
+		t.Errorf("TestFeltFromBigInt failed. Expected 26, Got: %v", got)
+	}
+}
+
+func TestFeltFromBigIntNegative(t *testing.T) {
+	if got := lambdaworks.FeltFromBigInt(big.NewInt(-5)); got != lambdaworks.FeltFromDecString("-5") {
+		t.Errorf("TestFeltFromBigIntNegative failed. Expected -5, Got: %v", got)
+	}
+}
+
+func TestFeltFromBigIntReducesModPrime(t *testing.T) {
+	prime, _ := new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+	n := new(big.Int).Add(prime, big.NewInt(3))
+	if got := lambdaworks.FeltFromBigInt(n); got != lambdaworks.FeltFromUint64(3) {
+		t.Errorf("TestFeltFromBigIntReducesModPrime failed. Expected 3, Got: %v", got)
+	}
+}
+
+func TestFeltToU32(t *testing.T) {
+	got, err := lambdaworks.FeltFromUint64(math.MaxUint32).ToU32()
+	if err != nil || got != math.MaxUint32 {
+		t.Errorf("TestFeltToU32 failed. Got: %v, err: %v", got, err)
+	}
+}
+
+func TestFeltToU32TooBig(t *testing.T) {
+	_, err := lambdaworks.FeltFromUint64(math.MaxUint32 + 1).ToU32()
+	if err == nil || err.Error() != "Cannot convert felt 4294967296 to u32" {
+		t.Errorf("TestFeltToU32TooBig should fail with a descriptive error, got: %v", err)
+	}
+}
+
+func TestFeltToUsize(t *testing.T) {
+	got, err := lambdaworks.FeltFromUint64(26).ToUsize()
+	if err != nil || got != 26 {
+		t.Errorf("TestFeltToUsize failed. Got: %v, err: %v", got, err)
+	}
+	if _, err := lambdaworks.FeltFromDecString("-1").ToUsize(); err == nil {
+		t.Errorf("TestFeltToUsize should fail for -1")
+	}
+}
+
+func TestFeltToU128(t *testing.T) {
+	high, low, err := lambdaworks.FeltFromHex("0x1234567890abcdef0fedcba098765432").ToU128()
+	if err != nil || high != 0x1234567890abcdef || low != 0x0fedcba098765432 {
+		t.Errorf("TestFeltToU128 failed. Got: %x %x, err: %v", high, low, err)
+	}
+}
+
+func TestFeltToU128TooBig(t *testing.T) {
+	if _, _, err := lambdaworks.FeltFromHex("0x100000000000000000000000000000000").ToU128(); err == nil {
+		t.Errorf("TestFeltToU128TooBig should fail")
+	}
+}
+
+func TestFeltInverse(t *testing.T) {
+	a := lambdaworks.FeltFromUint64(7)
+	inv, err := a.Inverse()
+	if err != nil || a.Mul(inv) != lambdaworks.FeltOne() {
+		t.Errorf("TestFeltInverse failed. Got: %v, err: %v", inv, err)
+	}
+}
+
+func TestFeltInverseOfZero(t *testing.T) {
+	if _, err := lambdaworks.FeltZero().Inverse(); err != lambdaworks.ErrDivisionByZero {
+		t.Errorf("TestFeltInverseOfZero should fail with ErrDivisionByZero, got: %v", err)
+	}
+}
+
+func TestFeltCheckedDiv(t *testing.T) {
+	got, err := lambdaworks.FeltFromUint64(21).CheckedDiv(lambdaworks.FeltFromUint64(7))
+	if err != nil || got != lambdaworks.FeltFromUint64(3) {
+		t.Errorf("TestFeltCheckedDiv failed. Expected 3, Got: %v, err: %v", got, err)
+	}
+}
+
+func TestFeltCheckedDivByZero(t *testing.T) {
+	if _, err := lambdaworks.FeltOne().CheckedDiv(lambdaworks.FeltZero()); err != lambdaworks.ErrDivisionByZero {
+		t.Errorf("TestFeltCheckedDivByZero should fail with ErrDivisionByZero, got: %v", err)
+	}
+}
+
+func TestFeltMarshalJSON(t *testing.T) {
+	data, err := json.Marshal([]lambdaworks.Felt{lambdaworks.FeltFromUint64(26), lambdaworks.FeltZero()})
+	if err != nil || string(data) != `["0x1a","0x0"]` {
+		t.Errorf("TestFeltMarshalJSON failed. Got: %s, err: %v", data, err)
+	}
+}
+
+func TestFeltUnmarshalJSON(t *testing.T) {
+	var felts []lambdaworks.Felt
+	err := json.Unmarshal([]byte(`["0x1a", "26", 26, "-1"]`), &felts)
+	expected := []lambdaworks.Felt{
+		lambdaworks.FeltFromUint64(26),
+		lambdaworks.FeltFromUint64(26),
+		lambdaworks.FeltFromUint64(26),
+		lambdaworks.FeltFromDecString("-1"),
+	}
+	if err != nil || !reflect.DeepEqual(felts, expected) {
+		t.Errorf("TestFeltUnmarshalJSON failed. Expected %v, Got: %v, err: %v", expected, felts, err)
+	}
+}
+
+func TestFeltUnmarshalJSONInvalid(t *testing.T) {
+	var felt lambdaworks.Felt
+	if err := json.Unmarshal([]byte(`"0xzz"`), &felt); err == nil {
+		t.Errorf("TestFeltUnmarshalJSONInvalid should fail")
+	}
+}
+
+func TestFeltUnmarshalTextBases(t *testing.T) {
+	valid := map[string]lambdaworks.Felt{
+		"010":  lambdaworks.FeltFromUint64(10),
+		"0x10": lambdaworks.FeltFromUint64(16),
+		"-0x1": lambdaworks.FeltFromDecString("-1"),
+		"-5":   lambdaworks.FeltFromDecString("-5"),
+	}
+	for text, expected := range valid {
+		var felt lambdaworks.Felt
+		if err := felt.UnmarshalText([]byte(text)); err != nil || felt != expected {
+			t.Errorf("Wrong felt for %q. Expected %v, got %v, err: %v", text, expected, felt, err)
+		}
+	}
+	for _, text := range []string{"1_000", "0b101", "0o7", "0x", "", "--1", "+1", "-+1"} {
+		var felt lambdaworks.Felt
+		if err := felt.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText should fail for %q, got %v", text, felt)
+		}
+	}
+}
+
+func TestFeltTextRoundTrip(t *testing.T) {
+	felt := lambdaworks.FeltFromDecString("-26")
+	text, _ := felt.MarshalText()
+	var decoded lambdaworks.Felt
+	if err := decoded.UnmarshalText(text); err != nil || decoded != felt {
+		t.Errorf("TestFeltTextRoundTrip failed. Expected %v, Got: %v, err: %v", felt, decoded, err)
+	}
+}
+
+func TestFeltNumBits(t *testing.T) {
+	if got := lambdaworks.FeltZero().NumBits(); got != 0 {
+		t.Errorf("TestFeltNumBits failed for 0. Got: %d", got)
+	}
+	if got := lambdaworks.FeltFromUint64(5).NumBits(); got != 3 {
+		t.Errorf("TestFeltNumBits failed for 5. Got: %d", got)
+	}
+	if got := lambdaworks.FeltFromDecString("-1").NumBits(); got != 252 {
+		t.Errorf("TestFeltNumBits failed for P - 1. Got: %d", got)
+	}
+}
+
+func TestFeltBit(t *testing.T) {
+	a := lambdaworks.FeltFromHex("0x10000000000000005")
+	if !a.Bit(0) || a.Bit(1) || !a.Bit(2) || !a.Bit(64) || a.Bit(65) || a.Bit(300) {
+		t.Errorf("TestFeltBit failed for %v", a)
+	}
+}
+
+func TestFeltBits(t *testing.T) {
+	expected := []bool{false, true, false, true}
+	if got := lambdaworks.FeltFromUint64(10).Bits(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("TestFeltBits failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestRandFeltIsDeterministic(t *testing.T) {
+	a := lambdaworks.RandFelt(rand.New(rand.NewSource(42)))
+	b := lambdaworks.RandFelt(rand.New(rand.NewSource(42)))
+	if a != b {
+		t.Errorf("TestRandFeltIsDeterministic failed. %v != %v", a, b)
+	}
+}
+
+func TestRandFeltIsCanonical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		felt := lambdaworks.RandFelt(rng)
+		// Values below P survive a round trip through the field unchanged
+		if felt.Add(lambdaworks.FeltZero()) != felt || felt.NumBits() > 252 {
+			t.Errorf("TestRandFeltIsCanonical failed for %v", felt)
+		}
+	}
+}
+
+func TestFeltConstants(t *testing.T) {
+	if lambdaworks.FeltTwo() != lambdaworks.FeltFromUint64(2) {
+		t.Errorf("FeltTwo should be 2, got: %v", lambdaworks.FeltTwo())
+	}
+	if lambdaworks.FeltTwoPow128() != lambdaworks.FeltFromHex("0x100000000000000000000000000000000") {
+		t.Errorf("FeltTwoPow128 should be 2^128, got: %v", lambdaworks.FeltTwoPow128())
+	}
+	if lambdaworks.FeltMax() != lambdaworks.FeltFromDecString("-1") {
+		t.Errorf("FeltMax should be P - 1, got: %v", lambdaworks.FeltMax())
+	}
+	half := lambdaworks.FeltPrimeHalf()
+	if half.Add(half) != lambdaworks.FeltMax() {
+		t.Errorf("FeltPrimeHalf should be (P - 1) / 2, got: %v", half)
+	}
+}
+
+func TestPrime(t *testing.T) {
+	prime := lambdaworks.Prime()
+	expected := new(big.Int).Add(lambdaworks.FeltMax().ToBigInt(), big.NewInt(1))
+	if prime.Cmp(expected) != 0 {
+		t.Errorf("TestPrime failed. Expected %v, Got: %v", expected, prime)
+	}
+	// Modifying the returned value must not affect later calls
+	prime.SetInt64(0)
+	if lambdaworks.Prime().Cmp(expected) != 0 {
+		t.Errorf("TestPrime failed. Prime was modified by the caller")
+	}
+}
+
+func TestFeltToU64EdgeCases(t *testing.T) {
+	got, err := lambdaworks.FeltFromHex("0xffffffffffffffff").ToU64()
+	if err != nil || got != 0xffffffffffffffff {
+		t.Errorf("TestFeltToU64EdgeCases failed for 2^64 - 1. Got: %d, err: %v", got, err)
+	}
+	if _, err := lambdaworks.FeltFromHex("0x10000000000000000").ToU64(); err == nil {
+		t.Errorf("TestFeltToU64EdgeCases should fail for 2^64")
+	}
+	// P - 1 has a zero lowest limb in canonical form, but doesn't fit in 64 bits
+	if _, err := lambdaworks.FeltMax().ToU64(); err == nil {
+		t.Errorf("TestFeltToU64EdgeCases should fail for P - 1")
+	}
+}
+
+func TestFeltToU64Unchecked(t *testing.T) {
+	if got := lambdaworks.FeltFromHex("0x1000000000000001a").ToU64Unchecked(); got != 26 {
+		t.Errorf("TestFeltToU64Unchecked failed. Expected 26, Got: %d", got)
+	}
+}
+
+func TestFeltSmallAddOverflowingU64(t *testing.T) {
+	a := lambdaworks.FeltFromHex("0xffffffffffffffff")
+	expected := lambdaworks.FeltFromHex("0x1fffffffffffffffe")
+	if got := a.Add(a); got != expected {
+		t.Errorf("TestFeltSmallAddOverflowingU64 failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestFeltSmallSubUnderflow(t *testing.T) {
+	expected := lambdaworks.FeltFromDecString("-3")
+	if got := lambdaworks.FeltFromUint64(2).Sub(lambdaworks.FeltFromUint64(5)); got != expected {
+		t.Errorf("TestFeltSmallSubUnderflow failed. Expected %v, Got: %v", expected, got)
+	}
+	expected = lambdaworks.FeltFromDecString("-18446744073709551615")
+	if got := lambdaworks.FeltZero().Sub(lambdaworks.FeltFromHex("0xffffffffffffffff")); got != expected {
+		t.Errorf("TestFeltSmallSubUnderflow failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestFeltSmallMulOverflowingU64(t *testing.T) {
+	a := lambdaworks.FeltFromHex("0xffffffffffffffff")
+	expected := lambdaworks.FeltFromHex("0xfffffffffffffffe0000000000000001")
+	if got := a.Mul(a); got != expected {
+		t.Errorf("TestFeltSmallMulOverflowingU64 failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestFeltSmallFastPathMatchesFFI(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	large := lambdaworks.FeltFromHex("0x10000000000000000")
+	for i := 0; i < 50; i++ {
+		a := lambdaworks.FeltFromUint64(rng.Uint64())
+		b := lambdaworks.FeltFromUint64(rng.Uint64())
+		// Going through a big felt forces the FFI path for the reference value
+		if a.Add(b) != a.Add(large).Add(b).Sub(large) {
+			t.Errorf("Add fast path mismatch for %v, %v", a, b)
+		}
+		if a.Sub(b) != a.Add(large).Sub(b).Sub(large) {
+			t.Errorf("Sub fast path mismatch for %v, %v", a, b)
+		}
+		if a.Mul(b) != a.Mul(large).Mul(b).Div(large) {
+			t.Errorf("Mul fast path mismatch for %v, %v", a, b)
+		}
+	}
+}
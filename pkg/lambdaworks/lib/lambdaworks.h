@@ -46,3 +46,7 @@ void mul(felt_t a, felt_t b, felt_t result);
 
 /* Writes the result variable with a / b. */
 void lw_div(felt_t a, felt_t b, felt_t result);
+
+/* Writes the result variable with a raised to the exponent, where the exponent
+ * is interpreted as an unsigned integer. */
+void lw_pow(felt_t a, felt_t exponent, felt_t result);
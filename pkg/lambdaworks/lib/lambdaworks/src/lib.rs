@@ -27,6 +27,16 @@ fn felt_to_limbs(felt: Felt, limbs: Limbs) {
     }
 }
 
+// Receives a C representation of a limbs array and returns the unsigned integer it holds,
+// without reducing it modulo the field prime.
+fn limbs_to_uint(limbs: Limbs) -> U256 {
+    unsafe {
+        let slice: &mut [u64] = std::slice::from_raw_parts_mut(limbs, 4);
+        let array: [u64; 4] = slice.try_into().unwrap();
+        UnsignedInteger::from_limbs(array)
+    }
+}
+
 // Receives a C representation of a limbs array and returns a felt representing
 // the same number.
 fn limbs_to_felt(limbs: Limbs) -> Felt {
@@ -124,3 +134,8 @@ pub extern "C" fn mul(a: Limbs, b: Limbs, result: Limbs) {
 pub extern "C" fn lw_div(a: Limbs, b: Limbs, result: Limbs) {
     felt_to_limbs(limbs_to_felt(a) / limbs_to_felt(b), result)
 }
+
+#[no_mangle]
+pub extern "C" fn lw_pow(a: Limbs, exponent: Limbs, result: Limbs) {
+    felt_to_limbs(limbs_to_felt(a).pow(limbs_to_uint(exponent)), result)
+}
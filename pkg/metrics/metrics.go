@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// A monotonically increasing value, such as prometheus.Counter
+type Counter interface {
+	Add(float64)
+}
+
+// A value that can go up and down, such as prometheus.Gauge
+type Gauge interface {
+	Set(float64)
+}
+
+// A distribution of values, such as prometheus.Histogram or prometheus.Summary
+type Observer interface {
+	Observe(float64)
+}
+
+// Metrics recorded for each run. The metrics are interfaces satisfied by the Prometheus client
+// types, so embedders can bind them directly; nil metrics aren't recorded
+type Metrics struct {
+	// Instructions executed, over all runs
+	StepsExecuted Counter
+	// Hints executed, over all runs. The VM doesn't run hints yet, so it is never incremented
+	HintsExecuted Counter
+	// Memory cells written during the last run
+	MemoryCells Gauge
+	// Memory segments created during the last run
+	Segments Gauge
+	// Time spent relocating the memory and trace, in seconds
+	RelocationSeconds Observer
+}
+
+// Records the metrics of a finished run
+func (m *Metrics) RecordRun(v *vm.VirtualMachine) {
+	if m == nil {
+		return
+	}
+	if m.StepsExecuted != nil {
+		m.StepsExecuted.Add(float64(v.CurrentStep))
+	}
+	if m.MemoryCells != nil {
+		cells := 0
+		v.Segments.Memory.RangeAll(func(_ memory.Relocatable, _ memory.MaybeRelocatable) bool {
+			cells++
+			return true
+		})
+		m.MemoryCells.Set(float64(cells))
+	}
+	if m.Segments != nil {
+		m.Segments.Set(float64(v.Segments.NumSegments()))
+	}
+}
+
+// Records the time spent relocating a run
+func (m *Metrics) ObserveRelocation(duration time.Duration) {
+	if m == nil || m.RelocationSeconds == nil {
+		return
+	}
+	m.RelocationSeconds.Observe(duration.Seconds())
+}
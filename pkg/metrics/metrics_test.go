@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/metrics"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+type value struct {
+	total        float64
+	observations int
+}
+
+func (v *value) Add(delta float64) { v.total += delta }
+func (v *value) Set(x float64)     { v.total = x }
+func (v *value) Observe(x float64) { v.total += x; v.observations++ }
+
+func TestRecordRun(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	base := virtualMachine.Segments.AddSegment()
+	data := []memory.MaybeRelocatable{*memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne()), *memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne())}
+	if _, err := virtualMachine.Segments.LoadData(base, data); err != nil {
+		t.Fatalf("LoadData error in test: %s", err)
+	}
+	virtualMachine.CurrentStep = 7
+
+	var steps, cells, segments value
+	m := metrics.Metrics{StepsExecuted: &steps, MemoryCells: &cells, Segments: &segments}
+	m.RecordRun(virtualMachine)
+	m.RecordRun(virtualMachine)
+	if steps.total != 14 || cells.total != 2 || segments.total != 2 {
+		t.Errorf("Wrong metrics: steps %v, cells %v, segments %v", steps.total, cells.total, segments.total)
+	}
+}
+
+func TestObserveRelocation(t *testing.T) {
+	var relocation value
+	m := metrics.Metrics{RelocationSeconds: &relocation}
+	m.ObserveRelocation(1500 * time.Millisecond)
+	if relocation.total != 1.5 || relocation.observations != 1 {
+		t.Errorf("Wrong relocation time, got %v", relocation.total)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *metrics.Metrics
+	m.RecordRun(vm.NewVirtualMachine())
+	m.ObserveRelocation(time.Second)
+	// Metrics left unset are skipped
+	(&metrics.Metrics{}).RecordRun(vm.NewVirtualMachine())
+}
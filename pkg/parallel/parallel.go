@@ -0,0 +1,51 @@
+// Helpers to spread independent work over the available CPUs.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Calls fn(i) for every i in [0, n), spread over GOMAXPROCS goroutines. Calls must be
+// independent of each other, as they run in no particular order. Returns once all of them did
+func For(n int, fn func(i int)) {
+	ForWorkers(n, runtime.GOMAXPROCS(0), fn)
+}
+
+// Calls fn(i) for every i in [0, n) as For does, on at most workers goroutines
+func ForWorkers(n int, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Splits [0, n) into chunks of at most chunkSize elements and calls fn(start, end) for each of
+// them, in parallel as For does
+func Chunks(n int, chunkSize int, fn func(start int, end int)) {
+	For((n+chunkSize-1)/chunkSize, func(i int) {
+		end := (i + 1) * chunkSize
+		if end > n {
+			end = n
+		}
+		fn(i*chunkSize, end)
+	})
+}
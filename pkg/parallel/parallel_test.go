@@ -0,0 +1,58 @@
+package parallel_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parallel"
+)
+
+func TestForCallsEachIndexOnce(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	calls := make([]int32, 1000)
+	parallel.For(len(calls), func(i int) { atomic.AddInt32(&calls[i], 1) })
+	for i, n := range calls {
+		if n != 1 {
+			t.Fatalf("Index %d was called %d times", i, n)
+		}
+	}
+	parallel.For(0, func(i int) { t.Errorf("fn shouldn't be called when n is 0") })
+}
+
+func TestForWorkersBoundsConcurrency(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	var running, maxRunning int32
+	parallel.ForWorkers(100, 2, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		runtime.Gosched()
+		atomic.AddInt32(&running, -1)
+	})
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 calls at a time, got %d", maxRunning)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	covered := make([]int32, 10)
+	parallel.Chunks(len(covered), 3, func(start int, end int) {
+		if end-start > 3 {
+			t.Errorf("Chunk [%d, %d) is bigger than 3", start, end)
+		}
+		for i := start; i < end; i++ {
+			atomic.AddInt32(&covered[i], 1)
+		}
+	})
+	for i, n := range covered {
+		if n != 1 {
+			t.Fatalf("Index %d was covered %d times", i, n)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Compiled Cairo 1 contract class, as found in *.casm.json files
+type CasmContractClass struct {
+	Prime             string                `json:"prime"`
+	CompilerVersion   string                `json:"compiler_version"`
+	Bytecode          []string              `json:"bytecode"`
+	Hints             []CasmHintsAtOffset   `json:"hints"`
+	PythonicHints     json.RawMessage       `json:"pythonic_hints,omitempty"`
+	EntryPointsByType CasmEntryPointsByType `json:"entry_points_by_type"`
+}
+
+type CasmEntryPointsByType struct {
+	External    []CasmEntryPoint `json:"EXTERNAL"`
+	L1Handler   []CasmEntryPoint `json:"L1_HANDLER"`
+	Constructor []CasmEntryPoint `json:"CONSTRUCTOR"`
+}
+
+type CasmEntryPoint struct {
+	Selector string   `json:"selector"`
+	Offset   uint     `json:"offset"`
+	Builtins []string `json:"builtins"`
+}
+
+// Returns the external entry point with the given selector, in decimal or 0x prefixed hex.
+// An empty selector picks the only external entry point, if there is a single one
+func (e *CasmEntryPointsByType) GetExternal(selector string) (CasmEntryPoint, error) {
+	if selector == "" {
+		if len(e.External) != 1 {
+			return CasmEntryPoint{}, fmt.Errorf("The contract has %d external entry points, a selector is required", len(e.External))
+		}
+		return e.External[0], nil
+	}
+	wanted, ok := new(big.Int).SetString(selector, 0)
+	if !ok {
+		return CasmEntryPoint{}, fmt.Errorf("Invalid selector %s", selector)
+	}
+	for _, entryPoint := range e.External {
+		if value, ok := new(big.Int).SetString(entryPoint.Selector, 0); ok && value.Cmp(wanted) == 0 {
+			return entryPoint, nil
+		}
+	}
+	return CasmEntryPoint{}, fmt.Errorf("No external entry point with selector %s", selector)
+}
+
+// The hints that run at a given bytecode offset. Serialized as an [offset, [hints]] pair.
+type CasmHintsAtOffset struct {
+	Offset uint
+	Hints  []CasmHint
+}
+
+func (h *CasmHintsAtOffset) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("hints should be an [offset, hints] pair, got %d elements", len(pair))
+	}
+	if err := json.Unmarshal(pair[0], &h.Offset); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &h.Hints)
+}
+
+func (h CasmHintsAtOffset) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{h.Offset, h.Hints})
+}
+
+// A structured Cairo 1 hint. Serialized as an object with a single key, the hint's name,
+// holding its arguments, e.g. {"AllocSegment": {"dst": {...}}}.
+type CasmHint struct {
+	Name string
+	Args json.RawMessage
+}
+
+func (h *CasmHint) UnmarshalJSON(data []byte) error {
+	var hint map[string]json.RawMessage
+	if err := json.Unmarshal(data, &hint); err != nil {
+		return err
+	}
+	if len(hint) != 1 {
+		return fmt.Errorf("a hint should have a single name, got %d", len(hint))
+	}
+	for name, args := range hint {
+		h.Name = name
+		h.Args = args
+	}
+	return nil
+}
+
+func (h CasmHint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]json.RawMessage{h.Name: h.Args})
+}
+
+// Parses a Cairo 1 casm contract class file
+func ParseCasm(jsonPath string) (CasmContractClass, error) {
+	var casm CasmContractClass
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return casm, err
+	}
+	if err := json.Unmarshal(data, &casm); err != nil {
+		return casm, fmt.Errorf("Failed to parse casm contract class %s: %w", jsonPath, err)
+	}
+	return casm, nil
+}
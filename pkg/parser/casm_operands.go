@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// A cell given by a register and an offset, serialized as {"register": "AP", "offset": -1}
+type CellRef struct {
+	Register Register
+	Offset   int
+}
+
+func (c *CellRef) UnmarshalJSON(data []byte) error {
+	var cell struct {
+		Register string `json:"register"`
+		Offset   int    `json:"offset"`
+	}
+	if err := json.Unmarshal(data, &cell); err != nil {
+		return err
+	}
+	switch cell.Register {
+	case "AP":
+		c.Register = AP
+	case "FP":
+		c.Register = FP
+	default:
+		return fmt.Errorf("invalid register %q", cell.Register)
+	}
+	c.Offset = cell.Offset
+	return nil
+}
+
+type ResOperandKind uint
+
+const (
+	// The value of a cell: [cell]
+	ResOperandDeref ResOperandKind = iota
+	// The value pointed by a cell plus an offset: [[cell] + offset]
+	ResOperandDoubleDeref
+	// A constant
+	ResOperandImmediate
+	// The sum or product of a cell and either a cell or a constant: [cell] op [b] or [cell] op imm
+	ResOperandBinOp
+)
+
+// An operand of a Cairo 1 hint. Serialized as an object with a single key, its kind, e.g.
+// {"Deref": {...}}, {"DoubleDeref": [{...}, 1]}, {"Immediate": "0x1"} or
+// {"BinOp": {"op": "Add", "a": {...}, "b": {"Immediate": "0x1"}}}
+type ResOperand struct {
+	Kind ResOperandKind
+	// The cell read, or the left operand of a BinOp
+	Cell CellRef
+	// DoubleDeref only
+	Offset int
+	// Immediate only, or the right operand of a BinOp if it is a constant
+	Immediate *big.Int
+	// BinOp only: Add or Mul
+	Op string
+	// BinOp only: the right operand if it is a cell, nil if it is a constant
+	B *CellRef
+}
+
+func (r *ResOperand) UnmarshalJSON(data []byte) error {
+	var operand map[string]json.RawMessage
+	if err := json.Unmarshal(data, &operand); err != nil {
+		return err
+	}
+	if len(operand) != 1 {
+		return fmt.Errorf("an operand should have a single kind, got %d", len(operand))
+	}
+	for kind, value := range operand {
+		switch kind {
+		case "Deref":
+			r.Kind = ResOperandDeref
+			return json.Unmarshal(value, &r.Cell)
+		case "DoubleDeref":
+			r.Kind = ResOperandDoubleDeref
+			var pair []json.RawMessage
+			if err := json.Unmarshal(value, &pair); err != nil {
+				return err
+			}
+			if len(pair) != 2 {
+				return fmt.Errorf("DoubleDeref should be a [cell, offset] pair, got %d elements", len(pair))
+			}
+			if err := json.Unmarshal(pair[0], &r.Cell); err != nil {
+				return err
+			}
+			return json.Unmarshal(pair[1], &r.Offset)
+		case "Immediate":
+			r.Kind = ResOperandImmediate
+			immediate, err := parseCasmBigInt(value)
+			r.Immediate = immediate
+			return err
+		case "BinOp":
+			r.Kind = ResOperandBinOp
+			var binOp struct {
+				Op string          `json:"op"`
+				A  CellRef         `json:"a"`
+				B  json.RawMessage `json:"b"`
+			}
+			if err := json.Unmarshal(value, &binOp); err != nil {
+				return err
+			}
+			if binOp.Op != "Add" && binOp.Op != "Mul" {
+				return fmt.Errorf("invalid operation %q", binOp.Op)
+			}
+			r.Op, r.Cell = binOp.Op, binOp.A
+			var b ResOperand
+			if err := json.Unmarshal(binOp.B, &b); err != nil {
+				return err
+			}
+			switch b.Kind {
+			case ResOperandDeref:
+				r.B = &b.Cell
+			case ResOperandImmediate:
+				r.Immediate = b.Immediate
+			default:
+				return fmt.Errorf("the right operand of a BinOp should be a Deref or an Immediate")
+			}
+			return nil
+		default:
+			return fmt.Errorf("invalid operand kind %q", kind)
+		}
+	}
+	return nil
+}
+
+// Parses an integer serialized either as a JSON number or as a (hex or decimal) string
+func parseCasmBigInt(data json.RawMessage) (*big.Int, error) {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		str = string(data)
+	}
+	value, ok := new(big.Int).SetString(str, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %s", data)
+	}
+	return value, nil
+}
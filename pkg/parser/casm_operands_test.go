@@ -0,0 +1,51 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+)
+
+func TestUnmarshalResOperand(t *testing.T) {
+	cases := map[string]parser.ResOperand{
+		`{"Deref": {"register": "FP", "offset": -3}}`: {Kind: parser.ResOperandDeref, Cell: parser.CellRef{Register: parser.FP, Offset: -3}},
+		`{"DoubleDeref": [{"register": "AP", "offset": 1}, 2]}`: {
+			Kind: parser.ResOperandDoubleDeref, Cell: parser.CellRef{Register: parser.AP, Offset: 1}, Offset: 2,
+		},
+		`{"Immediate": "0x10"}`: {Kind: parser.ResOperandImmediate, Immediate: big.NewInt(16)},
+		`{"Immediate": 17}`:     {Kind: parser.ResOperandImmediate, Immediate: big.NewInt(17)},
+		`{"BinOp": {"op": "Add", "a": {"register": "FP", "offset": 0}, "b": {"Immediate": "0x2"}}}`: {
+			Kind: parser.ResOperandBinOp, Op: "Add", Cell: parser.CellRef{Register: parser.FP}, Immediate: big.NewInt(2),
+		},
+		`{"BinOp": {"op": "Mul", "a": {"register": "AP", "offset": 0}, "b": {"Deref": {"register": "FP", "offset": 1}}}}`: {
+			Kind: parser.ResOperandBinOp, Op: "Mul", Cell: parser.CellRef{Register: parser.AP}, B: &parser.CellRef{Register: parser.FP, Offset: 1},
+		},
+	}
+	for data, expected := range cases {
+		var operand parser.ResOperand
+		if err := json.Unmarshal([]byte(data), &operand); err != nil || !reflect.DeepEqual(operand, expected) {
+			t.Errorf("Wrong operand for %s. Expected %+v, got %+v, err: %v", data, expected, operand, err)
+		}
+	}
+}
+
+func TestUnmarshalResOperandInvalid(t *testing.T) {
+	invalid := []string{
+		`{"Deref": {"register": "SP", "offset": 0}}`,
+		`{"DoubleDeref": [{"register": "AP", "offset": 1}]}`,
+		`{"Immediate": "x"}`,
+		`{"BinOp": {"op": "Sub", "a": {"register": "FP", "offset": 0}, "b": {"Immediate": "0x2"}}}`,
+		`{"BinOp": {"op": "Add", "a": {"register": "FP", "offset": 0}, "b": {"DoubleDeref": [{"register": "AP", "offset": 1}, 2]}}}`,
+		`{"Deref": {"register": "FP", "offset": 0}, "Immediate": "0x1"}`,
+		`{"Unknown": {}}`,
+	}
+	for _, data := range invalid {
+		var operand parser.ResOperand
+		if err := json.Unmarshal([]byte(data), &operand); err == nil {
+			t.Errorf("Unmarshal should fail for %s", data)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+)
+
+const casmJson = `{
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"compiler_version": "2.0.0",
+	"bytecode": ["0xa0680017fff8000", "0x7", "0x482680017ffa8000"],
+	"hints": [
+		[0, [{"TestLessThanOrEqual": {"lhs": {"Immediate": "0x0"}, "rhs": {"Deref": {"register": "FP", "offset": -6}}, "dst": {"register": "AP", "offset": 0}}}]],
+		[2, [{"AllocSegment": {"dst": {"register": "AP", "offset": 0}}}]]
+	],
+	"entry_points_by_type": {
+		"EXTERNAL": [{"selector": "0x362398bec32bc0ebb411203221a35a0301193a96f317ebe5e40be9f60d15320", "offset": 0, "builtins": ["range_check"]}],
+		"L1_HANDLER": [],
+		"CONSTRUCTOR": []
+	}
+}`
+
+func TestParseCasm(t *testing.T) {
+	got, err := parser.ParseCasm(writeProgram(t, casmJson))
+	if err != nil {
+		t.Fatalf("ParseCasm failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(got.Bytecode, []string{"0xa0680017fff8000", "0x7", "0x482680017ffa8000"}) {
+		t.Errorf("Wrong bytecode: %v", got.Bytecode)
+	}
+	if got.CompilerVersion != "2.0.0" {
+		t.Errorf("Wrong compiler version: %s", got.CompilerVersion)
+	}
+	if len(got.Hints) != 2 || got.Hints[1].Offset != 2 || got.Hints[1].Hints[0].Name != "AllocSegment" {
+		t.Errorf("Wrong hints: %+v", got.Hints)
+	}
+	expectedEntrypoint := parser.CasmEntryPoint{
+		Selector: "0x362398bec32bc0ebb411203221a35a0301193a96f317ebe5e40be9f60d15320",
+		Offset:   0,
+		Builtins: []string{"range_check"},
+	}
+	if len(got.EntryPointsByType.External) != 1 || !reflect.DeepEqual(got.EntryPointsByType.External[0], expectedEntrypoint) {
+		t.Errorf("Wrong entrypoints: %+v", got.EntryPointsByType)
+	}
+}
+
+func TestCasmHintRoundTrip(t *testing.T) {
+	data := []byte(`{"AllocSegment":{"dst":{"register":"AP","offset":0}}}`)
+	var hint parser.CasmHint
+	if err := json.Unmarshal(data, &hint); err != nil {
+		t.Fatalf("Unmarshal failed with error: %s", err)
+	}
+	encoded, err := json.Marshal(hint)
+	if err != nil || string(encoded) != string(data) {
+		t.Errorf("Hint round trip failed. Expected %s, got %s, err: %v", data, encoded, err)
+	}
+}
+
+func TestParseCasmInvalidHint(t *testing.T) {
+	invalid := `{"bytecode": [], "hints": [[0, [{"A": {}, "B": {}}]]]}`
+	if _, err := parser.ParseCasm(writeProgram(t, invalid)); err == nil {
+		t.Errorf("ParseCasm should fail with a hint with two names")
+	}
+}
+
+func TestParseCasmMissingFile(t *testing.T) {
+	if _, err := parser.ParseCasm("missing.casm.json"); err == nil {
+		t.Errorf("ParseCasm should fail with a missing file")
+	}
+}
+
+func TestGetExternalEntryPoint(t *testing.T) {
+	entryPoints := parser.CasmEntryPointsByType{External: []parser.CasmEntryPoint{{Selector: "0x1a", Offset: 0}, {Selector: "0x2b", Offset: 5}}}
+	got, err := entryPoints.GetExternal("43")
+	if err != nil || got.Offset != 5 {
+		t.Errorf("Expected the entry point at offset 5, got %+v, err: %v", got, err)
+	}
+	for _, selector := range []string{"", "0x3c", "zz"} {
+		if _, err := entryPoints.GetExternal(selector); err == nil {
+			t.Errorf("GetExternal should fail for selector %q", selector)
+		}
+	}
+	single := parser.CasmEntryPointsByType{External: entryPoints.External[:1]}
+	if got, err := single.GetExternal(""); err != nil || got.Selector != "0x1a" {
+		t.Errorf("An empty selector should pick the only entry point, got %+v, err: %v", got, err)
+	}
+}
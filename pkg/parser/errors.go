@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Error found while parsing a program, along with the JSON path of the offending value,
+// e.g. data[1042]: invalid hex "0xzz"
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Builds a ParseError for a failure decoding the value at path, extending the path with the
+// location of the mismatched field reported by encoding/json, if any.
+func decodeError(path string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field != "" {
+			path = path + "." + typeErr.Field
+		}
+		return &ParseError{Path: path, Err: fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+	return &ParseError{Path: path, Err: err}
+}
+
+var hexFeltRegexp = regexp.MustCompile("^0x[0-9a-fA-F]{1,64}$")
+
+// Checks that value is a 0x prefixed hex string of at most 256 bits, as used for felts
+func CheckHexFelt(value string) error {
+	if !hexFeltRegexp.MatchString(value) {
+		return fmt.Errorf("invalid hex %q", value)
+	}
+	return nil
+}
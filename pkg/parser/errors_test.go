@@ -0,0 +1,48 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+)
+
+func TestParseErrorInvalidHexData(t *testing.T) {
+	_, err := parser.ParseBytes([]byte(`{"data": ["0x1", "0xzz"]}`))
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Path != "data[1]" {
+		t.Fatalf("Expected a ParseError at data[1], got: %v", err)
+	}
+	if err.Error() != `data[1]: invalid hex "0xzz"` {
+		t.Errorf("Wrong error message: %s", err)
+	}
+}
+
+func TestParseErrorWrongFieldType(t *testing.T) {
+	_, err := parser.ParseBytes([]byte(`{"identifiers": {"__main__.main": {"pc": "zero", "type": "function"}}}`))
+	if err == nil || err.Error() != "identifiers.__main__.main.pc: expected int, got string" {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func TestParseErrorDataNotAnArray(t *testing.T) {
+	_, err := parser.ParseBytes([]byte(`{"data": "0x1"}`))
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Path != "data" {
+		t.Errorf("Expected a ParseError at data, got: %v", err)
+	}
+}
+
+func TestCheckHexFelt(t *testing.T) {
+	for _, valid := range []string{"0x1", "0xABCdef", "0x800000000000011000000000000000000000000000000000000000000000000"} {
+		if err := parser.CheckHexFelt(valid); err != nil {
+			t.Errorf("%s should be a valid hex felt, got: %s", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "0x", "1", "0xg", "0x" + strings.Repeat("f", 65)} {
+		if err := parser.CheckHexFelt(invalid); err == nil {
+			t.Errorf("%q should not be a valid hex felt", invalid)
+		}
+	}
+}
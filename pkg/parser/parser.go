@@ -1,30 +1,73 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"math/big"
 	"os"
 )
 
 type FlowTrackingData struct {
-	APTracking   map[string]int `json:"ap_tracking"`
+	APTracking   ApTrackingData `json:"ap_tracking"`
 	ReferenceIDS map[string]int `json:"reference_ids"`
 }
 
-type Instructions struct {
-	EndCol    int               `json:"end_col"`
-	EndLine   int               `json:"end_line"`
-	InputFile map[string]string `json:"input_file"`
-	StartCol  int               `json:"start_col"`
-	StartLine int               `json:"start_line"`
+type InputFile struct {
+	Filename string `json:"filename"`
+}
+
+// Location of a piece of code in a Cairo source file
+type Location struct {
+	EndCol         int             `json:"end_col"`
+	EndLine        int             `json:"end_line"`
+	InputFile      InputFile       `json:"input_file"`
+	ParentLocation *ParentLocation `json:"parent_location"`
+	StartCol       int             `json:"start_col"`
+	StartLine      int             `json:"start_line"`
+}
+
+// Location from which code was inlined (e.g. by a macro or a with_attr block), along with a
+// message describing it. Serialized as a [location, message] pair.
+type ParentLocation struct {
+	Location Location
+	Message  string
+}
+
+func (p *ParentLocation) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("parent_location should be a [location, message] pair, got %d elements", len(pair))
+	}
+	if err := json.Unmarshal(pair[0], &p.Location); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &p.Message)
+}
+
+func (p ParentLocation) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{p.Location, p.Message})
+}
+
+// Returns the location as filename:line:col
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.InputFile.Filename, l.StartLine, l.StartCol)
+}
+
+type HintLocation struct {
+	Location        Location `json:"location"`
+	NPrefixNewlines int      `json:"n_prefix_newlines"`
 }
 
 type InstructionLocation struct {
 	AccessibleScopes []string         `json:"accessible_scopes"`
 	FlowTrackingData FlowTrackingData `json:"flow_tracking_data"`
-	Hints            []string         `json:"hints"`
-	Inst             Instructions     `json:"inst"`
+	Hints            []HintLocation   `json:"hints"`
+	Inst             Location         `json:"inst"`
 }
 
 type DebugInfo struct {
@@ -32,15 +75,88 @@ type DebugInfo struct {
 	InstructionLocation map[string]InstructionLocation `json:"instruction_locations"`
 }
 
+type IdentifierType string
+
+const (
+	IdentifierFunction       IdentifierType = "function"
+	IdentifierConst          IdentifierType = "const"
+	IdentifierStruct         IdentifierType = "struct"
+	IdentifierLabel          IdentifierType = "label"
+	IdentifierReference      IdentifierType = "reference"
+	IdentifierAlias          IdentifierType = "alias"
+	IdentifierTypeDefinition IdentifierType = "type_definition"
+	IdentifierNamespace      IdentifierType = "namespace"
+)
+
+// A member of a struct identifier
+type Member struct {
+	CairoType string `json:"cairo_type"`
+	Offset    int    `json:"offset"`
+}
+
+// An identifier of the program. Which fields are set depends on its Type:
+//   - function: PC and Decorators
+//   - const: Value
+//   - struct: FullName, Members and Size
+//   - label: PC
+//   - reference: FullName, CairoType and References
+//   - alias: Destination
+//   - type_definition: CairoType
 type Identifier struct {
-	FullName   string         `json:"full_name"`
-	Members    map[string]any `json:"members"`
-	Size       int            `json:"size"`
-	Decorators []string       `json:"decorators"`
-	PC         int            `json:"pc"`
-	Type       string         `json:"type"`
-	CairoType  string         `json:"cairo_type"`
-	Value      int            `json:"value"`
+	FullName    string            `json:"full_name"`
+	Members     map[string]Member `json:"members"`
+	Size        int               `json:"size"`
+	Decorators  []string          `json:"decorators"`
+	PC          int               `json:"pc"`
+	Type        IdentifierType    `json:"type"`
+	CairoType   string            `json:"cairo_type"`
+	Value       *big.Int          `json:"value"`
+	Destination string            `json:"destination"`
+	References  []Reference       `json:"references"`
+}
+
+// Marshals only the fields relevant to the identifier's type, as cairo-lang expects
+func (i Identifier) MarshalJSON() ([]byte, error) {
+	fields := map[string]any{"type": i.Type}
+	switch i.Type {
+	case IdentifierFunction:
+		fields["pc"] = i.PC
+		fields["decorators"] = nonNil(i.Decorators)
+	case IdentifierConst:
+		fields["value"] = i.Value
+	case IdentifierStruct:
+		fields["full_name"] = i.FullName
+		fields["size"] = i.Size
+		if i.Members == nil {
+			fields["members"] = map[string]Member{}
+		} else {
+			fields["members"] = i.Members
+		}
+	case IdentifierLabel:
+		fields["pc"] = i.PC
+	case IdentifierReference:
+		fields["full_name"] = i.FullName
+		fields["cairo_type"] = i.CairoType
+		fields["references"] = nonNil(i.References)
+	case IdentifierAlias:
+		fields["destination"] = i.Destination
+	case IdentifierTypeDefinition:
+		fields["cairo_type"] = i.CairoType
+	case IdentifierNamespace:
+	default:
+		// Unknown identifier type, keep everything
+		type rawIdentifier Identifier
+		return json.Marshal(rawIdentifier(i))
+	}
+	return json.Marshal(fields)
+}
+
+// Returns an empty slice instead of nil, so that it is marshaled as [] rather than null
+func nonNil[T any](slice []T) []T {
+	if slice == nil {
+		return []T{}
+	}
+	return slice
 }
 
 type ApTrackingData struct {
@@ -58,17 +174,32 @@ type ReferenceManager struct {
 	References []Reference `json:"references"`
 }
 
+type HintParams struct {
+	Code             string           `json:"code"`
+	AccessibleScopes []string         `json:"accessible_scopes"`
+	FlowTrackingData FlowTrackingData `json:"flow_tracking_data"`
+}
+
+type Attribute struct {
+	Name             string            `json:"name"`
+	StartPc          int               `json:"start_pc"`
+	EndPc            int               `json:"end_pc"`
+	Value            string            `json:"value"`
+	FlowTrackingData *FlowTrackingData `json:"flow_tracking_data"`
+	AccessibleScopes []string          `json:"accessible_scopes"`
+}
+
 type CompiledJson struct {
-	Attributes       []string              `json:"attributes"`
-	Builtins         []string              `json:"builtins"`
-	CompilerVersion  string                `json:"compiler_version"`
-	Data             []string              `json:"data"`
-	DebugInfo        DebugInfo             `json:"debug_info"`
-	Hints            map[string]string     `json:"hints"`
-	Identifiers      map[string]Identifier `json:"identifiers"`
-	MainScope        string                `json:"main_scope"`
-	Prime            string                `json:"prime"`
-	ReferenceManager ReferenceManager      `json:"reference_manager"`
+	Attributes       []Attribute             `json:"attributes"`
+	Builtins         []string                `json:"builtins"`
+	CompilerVersion  string                  `json:"compiler_version"`
+	Data             []string                `json:"data"`
+	DebugInfo        *DebugInfo              `json:"debug_info"`
+	Hints            map[string][]HintParams `json:"hints"`
+	Identifiers      map[string]Identifier   `json:"identifiers"`
+	MainScope        string                  `json:"main_scope"`
+	Prime            string                  `json:"prime"`
+	ReferenceManager ReferenceManager        `json:"reference_manager"`
 }
 
 func Parse(jsonPath string) CompiledJson {
@@ -79,10 +210,7 @@ func Parse(jsonPath string) CompiledJson {
 	}
 	defer jsonFile.Close()
 
-	var cJson CompiledJson
-
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	err = json.Unmarshal(byteValue, &cJson)
+	cJson, err := ParseReader(jsonFile)
 
 	if err != nil {
 		fmt.Println(err)
@@ -91,3 +219,101 @@ func Parse(jsonPath string) CompiledJson {
 	return cJson
 
 }
+
+// Parses a compiled program from its JSON contents
+func ParseBytes(data []byte) (CompiledJson, error) {
+	return ParseReader(bytes.NewReader(data))
+}
+
+// Parses a compiled program read from r, e.g. a network response or an embedded file
+func ParseReader(r io.Reader) (CompiledJson, error) {
+	var data []string
+	cJson, err := ParseStream(r, func(_ int, value string) error {
+		data = append(data, value)
+		return nil
+	})
+	cJson.Data = data
+	return cJson, err
+}
+
+// Parses a compiled program read from r without holding its data in memory: each element of
+// the data array is handed to onData as it is decoded, and the returned CompiledJson has an
+// empty Data field. Used to load large programs, where the data array dominates the size.
+// Artifacts of different cairo-lang releases are normalized into a single format.
+func ParseStream(r io.Reader, onData func(index int, value string) error) (CompiledJson, error) {
+	var cJson CompiledJson
+	fields := map[string]any{
+		"attributes":        &cJson.Attributes,
+		"builtins":          &cJson.Builtins,
+		"compiler_version":  &cJson.CompilerVersion,
+		"debug_info":        &cJson.DebugInfo,
+		"hints":             &cJson.Hints,
+		"identifiers":       &cJson.Identifiers,
+		"main_scope":        &cJson.MainScope,
+		"prime":             &cJson.Prime,
+		"reference_manager": &cJson.ReferenceManager,
+	}
+
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return cJson, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return cJson, err
+		}
+		key, _ := token.(string)
+		if key == "data" {
+			if err := streamData(decoder, onData); err != nil {
+				return cJson, err
+			}
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			// Unknown fields are skipped, as encoding/json does
+			var skipped json.RawMessage
+			field = &skipped
+		}
+		if err := decoder.Decode(field); err != nil {
+			return cJson, decodeError(key, err)
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return cJson, err
+	}
+	normalizeCompiledJson(&cJson)
+	return cJson, nil
+}
+
+func streamData(decoder *json.Decoder, onData func(index int, value string) error) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return &ParseError{Path: "data", Err: err}
+	}
+	for i := 0; decoder.More(); i++ {
+		path := fmt.Sprintf("data[%d]", i)
+		var value string
+		if err := decoder.Decode(&value); err != nil {
+			return decodeError(path, err)
+		}
+		if err := CheckHexFelt(value); err != nil {
+			return &ParseError{Path: path, Err: err}
+		}
+		if err := onData(i, value); err != nil {
+			return &ParseError{Path: path, Err: err}
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s, got %v", delim, token)
+	}
+	return nil
+}
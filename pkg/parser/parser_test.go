@@ -1,7 +1,12 @@
 package parser_test
 
 import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
@@ -37,3 +42,226 @@ func TestData(t *testing.T) {
 		t.Errorf("We should have this data %s, got %s", expected, got.Data)
 	}
 }
+
+const programWithHintsJson = `{
+	"attributes": [{
+		"name": "error_message",
+		"start_pc": 2,
+		"end_pc": 4,
+		"value": "Value should be positive",
+		"flow_tracking_data": {"ap_tracking": {"group": 1, "offset": 0}, "reference_ids": {}},
+		"accessible_scopes": ["__main__", "__main__.main"]
+	}],
+	"builtins": ["output"],
+	"compiler_version": "0.11.0",
+	"data": ["0x1"],
+	"debug_info": null,
+	"hints": {
+		"0": [{
+			"accessible_scopes": ["__main__", "__main__.main"],
+			"code": "memory[ap] = segments.add()",
+			"flow_tracking_data": {"ap_tracking": {"group": 1, "offset": 0}, "reference_ids": {"__main__.main.a": 0}}
+		}]
+	},
+	"identifiers": {
+		"__main__.SHIFT": {"type": "const", "value": 340282366920938463463374607431768211456}
+	},
+	"main_scope": "__main__",
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"reference_manager": {"references": [{"ap_tracking_data": {"group": 1, "offset": 0}, "pc": 0, "value": "[cast(fp + (-3), felt*)]"}]}
+}`
+
+func writeProgram(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "program.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write program: %s", err)
+	}
+	return path
+}
+
+func TestParseHints(t *testing.T) {
+	got := parser.Parse(writeProgram(t, programWithHintsJson))
+	expected := map[string][]parser.HintParams{
+		"0": {{
+			Code:             "memory[ap] = segments.add()",
+			AccessibleScopes: []string{"__main__", "__main__.main"},
+			FlowTrackingData: parser.FlowTrackingData{
+				APTracking:   parser.ApTrackingData{Group: 1, Offset: 0},
+				ReferenceIDS: map[string]int{"__main__.main.a": 0},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(got.Hints, expected) {
+		t.Errorf("We should have these hints %+v, got %+v", expected, got.Hints)
+	}
+}
+
+func TestParseAttributesAndMetadata(t *testing.T) {
+	got := parser.Parse(writeProgram(t, programWithHintsJson))
+	if len(got.Attributes) != 1 || got.Attributes[0].Name != "error_message" || got.Attributes[0].Value != "Value should be positive" ||
+		got.Attributes[0].StartPc != 2 || got.Attributes[0].EndPc != 4 {
+		t.Errorf("Wrong attributes: %+v", got.Attributes)
+	}
+	if got.MainScope != "__main__" || got.CompilerVersion != "0.11.0" || got.DebugInfo != nil {
+		t.Errorf("Wrong program metadata: %s %s %v", got.MainScope, got.CompilerVersion, got.DebugInfo)
+	}
+	if len(got.ReferenceManager.References) != 1 || got.ReferenceManager.References[0].Value != "[cast(fp + (-3), felt*)]" {
+		t.Errorf("Wrong reference manager: %+v", got.ReferenceManager)
+	}
+}
+
+func TestParseBigConstant(t *testing.T) {
+	got := parser.Parse(writeProgram(t, programWithHintsJson))
+	expected := new(big.Int).Lsh(big.NewInt(1), 128)
+	if value := got.Identifiers["__main__.SHIFT"].Value; value == nil || value.Cmp(expected) != 0 {
+		t.Errorf("We should have the constant %v, got %v", expected, value)
+	}
+}
+
+const programWithDebugInfoJson = `{
+	"data": ["0x1"],
+	"debug_info": {
+		"file_contents": {},
+		"instruction_locations": {
+			"0": {
+				"accessible_scopes": ["__main__", "__main__.main"],
+				"flow_tracking_data": {"ap_tracking": {"group": 0, "offset": 0}, "reference_ids": {}},
+				"hints": [{"location": {"end_col": 40, "end_line": 4, "input_file": {"filename": "main.cairo"}, "start_col": 5, "start_line": 4}, "n_prefix_newlines": 1}],
+				"inst": {
+					"end_col": 20,
+					"end_line": 5,
+					"input_file": {"filename": "main.cairo"},
+					"parent_location": [
+						{"end_col": 15, "end_line": 9, "input_file": {"filename": "lib.cairo"}, "start_col": 3, "start_line": 9},
+						"While expanding the reference 'x' in:"
+					],
+					"start_col": 10,
+					"start_line": 5
+				}
+			}
+		}
+	}
+}`
+
+func TestParseDebugInfo(t *testing.T) {
+	got := parser.Parse(writeProgram(t, programWithDebugInfoJson))
+	if got.DebugInfo == nil {
+		t.Fatalf("Debug info should have been parsed")
+	}
+	location, ok := got.DebugInfo.InstructionLocation["0"]
+	if !ok {
+		t.Fatalf("Missing instruction location for pc 0")
+	}
+	expected := parser.Location{
+		EndCol:    20,
+		EndLine:   5,
+		InputFile: parser.InputFile{Filename: "main.cairo"},
+		ParentLocation: &parser.ParentLocation{
+			Location: parser.Location{EndCol: 15, EndLine: 9, InputFile: parser.InputFile{Filename: "lib.cairo"}, StartCol: 3, StartLine: 9},
+			Message:  "While expanding the reference 'x' in:",
+		},
+		StartCol:  10,
+		StartLine: 5,
+	}
+	if !reflect.DeepEqual(location.Inst, expected) {
+		t.Errorf("Wrong instruction location. Expected %+v, got %+v", expected, location.Inst)
+	}
+	if len(location.Hints) != 1 || location.Hints[0].NPrefixNewlines != 1 || location.Hints[0].Location.StartCol != 5 {
+		t.Errorf("Wrong hint locations: %+v", location.Hints)
+	}
+	if location.Inst.String() != "main.cairo:5:10" {
+		t.Errorf("Wrong location string: %s", location.Inst.String())
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	got, err := parser.ParseBytes([]byte(programWithHintsJson))
+	if err != nil {
+		t.Fatalf("ParseBytes failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(got.Data, []string{"0x1"}) || !reflect.DeepEqual(got.Builtins, []string{"output"}) {
+		t.Errorf("Wrong program parsed: %+v", got)
+	}
+}
+
+func TestParseBytesInvalid(t *testing.T) {
+	if _, err := parser.ParseBytes([]byte(`{"data": 1}`)); err == nil {
+		t.Errorf("ParseBytes should fail with invalid data")
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	got, err := parser.ParseReader(strings.NewReader(programWithHintsJson))
+	if err != nil {
+		t.Fatalf("ParseReader failed with error: %s", err)
+	}
+	if got.MainScope != "__main__" || len(got.Hints["0"]) != 1 {
+		t.Errorf("Wrong program parsed: %+v", got)
+	}
+}
+
+func TestParseTypedIdentifiers(t *testing.T) {
+	got, err := parser.ParseBytes([]byte(`{"identifiers": {
+		"__main__.fib.Args": {"full_name": "__main__.fib.Args", "members": {"n": {"cairo_type": "felt", "offset": 2}}, "size": 3, "type": "struct"},
+		"__main__.fib": {"decorators": [], "pc": 11, "type": "function"}
+	}}`))
+	if err != nil {
+		t.Fatalf("ParseBytes failed with error: %s", err)
+	}
+	args := got.Identifiers["__main__.fib.Args"]
+	if args.Type != parser.IdentifierStruct || args.Size != 3 || args.Members["n"] != (parser.Member{CairoType: "felt", Offset: 2}) {
+		t.Errorf("Wrong struct identifier: %+v", args)
+	}
+	if fib := got.Identifiers["__main__.fib"]; fib.Type != parser.IdentifierFunction || fib.PC != 11 {
+		t.Errorf("Wrong function identifier: %+v", fib)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	var data []string
+	got, err := parser.ParseStream(strings.NewReader(programWithHintsJson), func(index int, value string) error {
+		if index != len(data) {
+			t.Errorf("Data elements should be streamed in order, got index %d", index)
+		}
+		data = append(data, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseStream failed with error: %s", err)
+	}
+	expected, _ := parser.ParseBytes([]byte(programWithHintsJson))
+	if !reflect.DeepEqual(data, expected.Data) {
+		t.Errorf("Wrong streamed data. Expected %v, got %v", expected.Data, data)
+	}
+	expected.Data = nil
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseStream should parse the same program as ParseBytes.\nExpected %+v\nGot %+v", expected, got)
+	}
+}
+
+func TestParseStreamSkipsUnknownFields(t *testing.T) {
+	got, err := parser.ParseStream(strings.NewReader(`{"unknown": {"a": [1, 2]}, "main_scope": "__main__"}`), func(int, string) error { return nil })
+	if err != nil || got.MainScope != "__main__" {
+		t.Errorf("ParseStream failed. Got %+v, err: %v", got, err)
+	}
+}
+
+func TestParseStreamCallbackError(t *testing.T) {
+	_, err := parser.ParseStream(strings.NewReader(`{"data": ["0x1", "0x2"]}`), func(index int, value string) error {
+		if index == 1 {
+			return errors.New("invalid value")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "data[1]: invalid value" {
+		t.Errorf("ParseStream should fail with the callback error, got: %v", err)
+	}
+}
+
+func TestParseStreamMalformed(t *testing.T) {
+	for _, input := range []string{`[]`, `{"data": {}}`, `{"data": [1]}`, `{"prime": 1}`} {
+		if _, err := parser.ParseStream(strings.NewReader(input), func(int, string) error { return nil }); err == nil {
+			t.Errorf("ParseStream should fail for %s", input)
+		}
+	}
+}
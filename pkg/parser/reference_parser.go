@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Register a reference is relative to. Mirrors vm.Register, which can't be imported from the parser.
+type Register uint
+
+const (
+	AP Register = 0
+	FP Register = 1
+)
+
+type OffsetValueKind uint
+
+const (
+	// A constant felt, only valid as the first offset (e.g. cast(17, felt))
+	OffsetValueImmediate OffsetValueKind = iota
+	// A plain integer added to the first offset (e.g. the 2 in [fp + (-3)] + 2)
+	OffsetValueValue
+	// A register plus an offset, optionally dereferenced (e.g. [ap + 2])
+	OffsetValueReference
+)
+
+// One of the terms of a reference value expression
+type OffsetValue struct {
+	Kind        OffsetValueKind
+	Immediate   *big.Int
+	Value       int
+	Register    Register
+	Offset      int
+	Dereference bool
+}
+
+// Parsed form of a reference value string such as [cast(fp + (-4), felt*)]
+type ValueAddress struct {
+	Offset1     OffsetValue
+	Offset2     OffsetValue
+	Dereference bool
+	ValueType   string
+}
+
+// A reference ready to be resolved by hints: its parsed value along with the ap tracking
+// data needed to resolve ap based references.
+type HintReference struct {
+	Offset1        OffsetValue
+	Offset2        OffsetValue
+	Dereference    bool
+	ApTrackingData ApTrackingData
+	CairoType      string
+}
+
+// Parses the value of a reference into a HintReference.
+func NewHintReference(reference Reference) (HintReference, error) {
+	value, err := ParseValue(reference.Value)
+	if err != nil {
+		return HintReference{}, err
+	}
+	return HintReference{
+		Offset1:        value.Offset1,
+		Offset2:        value.Offset2,
+		Dereference:    value.Dereference,
+		ApTrackingData: reference.ApTrackingData,
+		CairoType:      value.ValueType,
+	}, nil
+}
+
+// Parses a reference value string. Supported forms are an optionally dereferenced cast of an
+// expression with at most two terms, e.g. [cast(fp + (-4), felt*)], cast([ap + 2] + [fp], felt),
+// [cast([fp + (-3)] + 2, felt*)] or cast(17, felt).
+func ParseValue(value string) (ValueAddress, error) {
+	var result ValueAddress
+	str := strings.TrimSpace(value)
+
+	if strings.HasPrefix(str, "[") && strings.HasSuffix(str, "]") {
+		result.Dereference = true
+		str = strings.TrimSpace(str[1 : len(str)-1])
+	}
+	if !strings.HasPrefix(str, "cast(") || !strings.HasSuffix(str, ")") {
+		return ValueAddress{}, fmt.Errorf("Invalid reference value %q: expected a cast expression", value)
+	}
+	inner := str[len("cast(") : len(str)-1]
+
+	terms, valueType, err := splitCast(inner)
+	if err != nil {
+		return ValueAddress{}, fmt.Errorf("Invalid reference value %q: %w", value, err)
+	}
+	result.ValueType = valueType
+
+	offsets, err := parseTerms(terms)
+	if err != nil {
+		return ValueAddress{}, fmt.Errorf("Invalid reference value %q: %w", value, err)
+	}
+	result.Offset1 = offsets[0]
+	result.Offset2 = offsets[1]
+	return result, nil
+}
+
+// Splits the inside of a cast into the terms of its expression and its type
+func splitCast(inner string) ([]string, string, error) {
+	comma, err := indexTopLevel(inner, ',')
+	if err != nil {
+		return nil, "", err
+	}
+	if comma < 0 || strings.TrimSpace(inner[comma+1:]) == "" {
+		return nil, "", fmt.Errorf("missing cast type")
+	}
+	terms, err := splitTerms(inner[:comma])
+	return terms, strings.TrimSpace(inner[comma+1:]), err
+}
+
+// Splits an expression into the terms added together at its top level
+func splitTerms(expr string) ([]string, error) {
+	var terms []string
+	for {
+		plus, err := indexTopLevel(expr, '+')
+		if err != nil {
+			return nil, err
+		}
+		if plus < 0 {
+			return append(terms, strings.TrimSpace(expr)), nil
+		}
+		terms = append(terms, strings.TrimSpace(expr[:plus]))
+		expr = expr[plus+1:]
+	}
+}
+
+// Returns the index of the first occurrence of sep outside of brackets and parentheses, or -1
+func indexTopLevel(expr string, sep rune) (int, error) {
+	depth := 0
+	for i, c := range expr {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth < 0 {
+				return 0, fmt.Errorf("unbalanced brackets")
+			}
+		case sep:
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// Turns the terms of a cast expression into two offsets, the second one defaulting to a zero value
+func parseTerms(terms []string) ([2]OffsetValue, error) {
+	offsets := [2]OffsetValue{}
+	for i := 0; i < len(offsets); i++ {
+		if len(terms) == 0 {
+			offsets[i] = OffsetValue{Kind: OffsetValueValue}
+			continue
+		}
+		offset, consumed, err := parseOffset(terms, i == 0)
+		if err != nil {
+			return offsets, err
+		}
+		offsets[i] = offset
+		terms = terms[consumed:]
+	}
+	if len(terms) != 0 {
+		return offsets, fmt.Errorf("too many terms")
+	}
+	return offsets, nil
+}
+
+// Parses the offset starting at terms[0], returning it with the number of terms it spans
+func parseOffset(terms []string, first bool) (OffsetValue, int, error) {
+	term := terms[0]
+	if term == "" {
+		return OffsetValue{}, 0, fmt.Errorf("empty term")
+	}
+
+	// Dereferenced register, e.g. [fp + (-3)]
+	if strings.HasPrefix(term, "[") && strings.HasSuffix(term, "]") {
+		innerTerms, err := splitTerms(term[1 : len(term)-1])
+		if err != nil {
+			return OffsetValue{}, 0, err
+		}
+		reg, ok := parseRegister(innerTerms[0])
+		if !ok {
+			return OffsetValue{}, 0, fmt.Errorf("expected a register in %s", term)
+		}
+		offset := 0
+		switch len(innerTerms) {
+		case 1:
+		case 2:
+			if offset, err = parseInt(innerTerms[1]); err != nil {
+				return OffsetValue{}, 0, err
+			}
+		default:
+			return OffsetValue{}, 0, fmt.Errorf("too many terms in %s", term)
+		}
+		return OffsetValue{Kind: OffsetValueReference, Register: reg, Offset: offset, Dereference: true}, 1, nil
+	}
+
+	// Register, optionally followed by a numeric offset, e.g. fp + (-4)
+	if reg, ok := parseRegister(term); ok {
+		if len(terms) > 1 && !isRegisterTerm(terms[1]) {
+			offset, err := parseInt(terms[1])
+			if err != nil {
+				return OffsetValue{}, 0, err
+			}
+			return OffsetValue{Kind: OffsetValueReference, Register: reg, Offset: offset}, 2, nil
+		}
+		return OffsetValue{Kind: OffsetValueReference, Register: reg}, 1, nil
+	}
+
+	// A number: an immediate in first position, a plain value otherwise
+	if first {
+		immediate, ok := new(big.Int).SetString(trimParens(term), 0)
+		if !ok {
+			return OffsetValue{}, 0, fmt.Errorf("invalid immediate %s", term)
+		}
+		return OffsetValue{Kind: OffsetValueImmediate, Immediate: immediate}, 1, nil
+	}
+	value, err := parseInt(term)
+	if err != nil {
+		return OffsetValue{}, 0, err
+	}
+	return OffsetValue{Kind: OffsetValueValue, Value: value}, 1, nil
+}
+
+func isRegisterTerm(term string) bool {
+	_, ok := parseRegister(term)
+	return ok || strings.HasPrefix(term, "[")
+}
+
+func parseRegister(term string) (Register, bool) {
+	switch term {
+	case "ap":
+		return AP, true
+	case "fp":
+		return FP, true
+	}
+	return 0, false
+}
+
+// Parses integers as written by the compiler, where negative numbers are wrapped in parentheses
+func parseInt(term string) (int, error) {
+	value, err := strconv.Atoi(trimParens(term))
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %s", term)
+	}
+	return value, nil
+}
+
+func trimParens(term string) string {
+	if strings.HasPrefix(term, "(") && strings.HasSuffix(term, ")") {
+		return strings.TrimSpace(term[1 : len(term)-1])
+	}
+	return term
+}
@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+)
+
+func TestParseValueDereferencedFpWithNegativeOffset(t *testing.T) {
+	got, err := parser.ParseValue("[cast(fp + (-4), felt*)]")
+	expected := parser.ValueAddress{
+		Offset1:     parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.FP, Offset: -4},
+		Offset2:     parser.OffsetValue{Kind: parser.OffsetValueValue},
+		Dereference: true,
+		ValueType:   "felt*",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseValue failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestParseValueSumOfDereferences(t *testing.T) {
+	got, err := parser.ParseValue("cast([ap + 2] + [fp], felt)")
+	expected := parser.ValueAddress{
+		Offset1:   parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.AP, Offset: 2, Dereference: true},
+		Offset2:   parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.FP, Dereference: true},
+		ValueType: "felt",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseValue failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestParseValueInnerDereferencePlusValue(t *testing.T) {
+	got, err := parser.ParseValue("[cast([fp + (-3)] + 2, starkware.cairo.common.cairo_builtins.HashBuiltin*)]")
+	expected := parser.ValueAddress{
+		Offset1:     parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.FP, Offset: -3, Dereference: true},
+		Offset2:     parser.OffsetValue{Kind: parser.OffsetValueValue, Value: 2},
+		Dereference: true,
+		ValueType:   "starkware.cairo.common.cairo_builtins.HashBuiltin*",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseValue failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestParseValueRegisterWithoutOffset(t *testing.T) {
+	got, err := parser.ParseValue("cast(ap, felt**)")
+	expected := parser.ValueAddress{
+		Offset1:   parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.AP},
+		Offset2:   parser.OffsetValue{Kind: parser.OffsetValueValue},
+		ValueType: "felt**",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseValue failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestParseValueImmediate(t *testing.T) {
+	got, err := parser.ParseValue("cast(17, felt)")
+	expected := parser.ValueAddress{
+		Offset1:   parser.OffsetValue{Kind: parser.OffsetValueImmediate, Immediate: big.NewInt(17)},
+		Offset2:   parser.OffsetValue{Kind: parser.OffsetValueValue},
+		ValueType: "felt",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseValue failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestParseValueTupleType(t *testing.T) {
+	got, err := parser.ParseValue("[cast(fp + (-5), (a: felt, b: felt)*)]")
+	if err != nil || got.ValueType != "(a: felt, b: felt)*" {
+		t.Errorf("ParseValue failed. Got type: %q, err: %v", got.ValueType, err)
+	}
+}
+
+func TestParseValueInvalid(t *testing.T) {
+	invalid := []string{
+		"fp + (-4)",
+		"cast(fp + (-4))",
+		"cast(sp + 1, felt)",
+		"cast([fp + (-4)] + [ap] + [ap], felt)",
+		"cast([fp + x], felt)",
+	}
+	for _, value := range invalid {
+		if _, err := parser.ParseValue(value); err == nil {
+			t.Errorf("ParseValue should fail for %q", value)
+		}
+	}
+}
+
+func TestNewHintReference(t *testing.T) {
+	reference := parser.Reference{
+		ApTrackingData: parser.ApTrackingData{Group: 1, Offset: 2},
+		Pc:             11,
+		Value:          "[cast(fp + (-5), felt*)]",
+	}
+	got, err := parser.NewHintReference(reference)
+	expected := parser.HintReference{
+		Offset1:        parser.OffsetValue{Kind: parser.OffsetValueReference, Register: parser.FP, Offset: -5},
+		Offset2:        parser.OffsetValue{Kind: parser.OffsetValueValue},
+		Dereference:    true,
+		ApTrackingData: parser.ApTrackingData{Group: 1, Offset: 2},
+		CairoType:      "felt*",
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("NewHintReference failed. Expected %+v, Got: %+v, err: %v", expected, got, err)
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The prime of the Cairo field, assumed by artifacts which don't declare one
+const CairoPrime = "0x800000000000011000000000000000000000000000000000000000000000001"
+
+// A cairo-lang release, as found in the compiler_version field of compiled programs
+type CompilerVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// The releases whose artifacts are known to parse, older and newer ones are parsed on a best
+// effort basis
+var (
+	OldestSupportedVersion = CompilerVersion{0, 10, 0}
+	NewestSupportedVersion = CompilerVersion{0, 13, 2}
+)
+
+// Parses a version such as 0.12.2 or 0.13.1a0, ignoring pre-release suffixes. Missing minor
+// and patch numbers are zero
+func ParseCompilerVersion(version string) (CompilerVersion, error) {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	numbers := [3]int{}
+	for i, part := range parts {
+		// Keep the leading digits, e.g. 1 out of 1a0 or 2 out of 2-dev
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		number, err := strconv.Atoi(part[:end])
+		if err != nil || (end != len(part) && i != len(parts)-1) {
+			return CompilerVersion{}, fmt.Errorf("Invalid compiler version %q", version)
+		}
+		numbers[i] = number
+	}
+	return CompilerVersion{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+func (v CompilerVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Returns true if v is older than other
+func (v CompilerVersion) Before(other CompilerVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// Returns the version of the compiler that produced the program, false if the artifact doesn't
+// declare a valid one
+func (c *CompiledJson) Version() (CompilerVersion, bool) {
+	version, err := ParseCompilerVersion(c.CompilerVersion)
+	return version, err == nil
+}
+
+// Returns true if the program was produced by a release whose artifacts are known to parse
+func (c *CompiledJson) IsSupportedVersion() bool {
+	version, ok := c.Version()
+	return ok && !version.Before(OldestSupportedVersion) && !NewestSupportedVersion.Before(version)
+}
+
+// Fills in the fields older releases leave out and brings the names used by different
+// toolchains to the ones used by cairo-lang, so that the rest of the vm sees a single format
+func normalizeCompiledJson(c *CompiledJson) {
+	// main_scope and prime are missing from artifacts of some releases, which always used these
+	if c.MainScope == "" {
+		c.MainScope = "__main__"
+	}
+	if c.Prime == "" {
+		c.Prime = CairoPrime
+	}
+	for i, builtin := range c.Builtins {
+		c.Builtins[i] = NormalizeBuiltinName(builtin)
+	}
+}
+
+// Returns the cairo-lang name of a builtin, e.g. range_check for range_check_builtin, the name
+// used by Cairo 1 toolchains
+func NormalizeBuiltinName(name string) string {
+	return strings.TrimSuffix(name, "_builtin")
+}
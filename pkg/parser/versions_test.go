@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+)
+
+func TestParseCompilerVersion(t *testing.T) {
+	valid := map[string]parser.CompilerVersion{
+		"0.10.3":   {0, 10, 3},
+		"0.13.1a0": {0, 13, 1},
+		"0.12":     {0, 12, 0},
+		" 0.11.0 ": {0, 11, 0},
+	}
+	for version, expected := range valid {
+		got, err := parser.ParseCompilerVersion(version)
+		if err != nil || got != expected {
+			t.Errorf("ParseCompilerVersion(%q) failed. Expected %v, got %v, err: %v", version, expected, got, err)
+		}
+	}
+	for _, version := range []string{"", "latest", "0.x.1", "0.1a.2"} {
+		if _, err := parser.ParseCompilerVersion(version); err == nil {
+			t.Errorf("ParseCompilerVersion(%q) should fail", version)
+		}
+	}
+}
+
+func TestCompilerVersionBefore(t *testing.T) {
+	if !(parser.CompilerVersion{0, 9, 1}).Before(parser.CompilerVersion{0, 10, 0}) {
+		t.Errorf("0.9.1 should be before 0.10.0")
+	}
+	if (parser.CompilerVersion{0, 13, 1}).Before(parser.CompilerVersion{0, 13, 1}) {
+		t.Errorf("A version shouldn't be before itself")
+	}
+}
+
+func TestIsSupportedVersion(t *testing.T) {
+	supported := map[string]bool{"0.10.0": true, "0.11.0.2": true, "0.13.1": true, "0.9.1": false, "0.14.0": false, "": false}
+	for version, expected := range supported {
+		compiled := parser.CompiledJson{CompilerVersion: version}
+		if compiled.IsSupportedVersion() != expected {
+			t.Errorf("IsSupportedVersion should be %v for %q", expected, version)
+		}
+	}
+}
+
+func TestParseArtifactsAcrossVersions(t *testing.T) {
+	artifacts := map[string]struct {
+		json     string
+		builtins []string
+	}{
+		// Without main_scope, prime, attributes nor debug_info
+		"0.10": {`{"compiler_version": "0.10.3", "builtins": ["output", "pedersen"], "data": ["0x1"], "hints": {}, "identifiers": {}, "reference_manager": {"references": []}}`, []string{"output", "pedersen"}},
+		// With the builtins added in 0.13.1, named as Cairo 1 toolchains do
+		"0.13": {`{"compiler_version": "0.13.1", "builtins": ["range_check_builtin", "range_check96", "add_mod", "mul_mod"], "data": ["0x1"], "debug_info": null,
+			"main_scope": "__main__", "prime": "0x800000000000011000000000000000000000000000000000000000000000001", "attributes": []}`, []string{"range_check", "range_check96", "add_mod", "mul_mod"}},
+		// Without compiler_version
+		"unknown": {`{"data": ["0x1"]}`, nil},
+	}
+	for name, artifact := range artifacts {
+		compiled, err := parser.ParseBytes([]byte(artifact.json))
+		if err != nil {
+			t.Errorf("Failed to parse the %s artifact: %s", name, err)
+			continue
+		}
+		if compiled.MainScope != "__main__" || compiled.Prime != parser.CairoPrime {
+			t.Errorf("The %s artifact should have the default main scope and prime, got %q and %q", name, compiled.MainScope, compiled.Prime)
+		}
+		if !reflect.DeepEqual(compiled.Builtins, artifact.builtins) {
+			t.Errorf("Wrong builtins for the %s artifact. Expected %v, got %v", name, artifact.builtins, compiled.Builtins)
+		}
+	}
+}
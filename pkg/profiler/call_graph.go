@@ -0,0 +1,110 @@
+package profiler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// A function of the call graph, with the steps run within it (Self) and within it or the
+// functions it called (Total)
+type CallGraphNode struct {
+	Name  string
+	Self  int64
+	Total int64
+}
+
+// A call from Caller to Callee, with the steps run within the callee while called from the caller
+type CallGraphEdge struct {
+	Caller string
+	Callee string
+	Steps  int64
+}
+
+type CallGraph struct {
+	// Sorted by decreasing total steps
+	Nodes []CallGraphNode
+	// Sorted by decreasing steps
+	Edges []CallGraphEdge
+}
+
+// Builds the call graph of the Cairo functions run while profiling. Recursive calls are only
+// counted once per stack, so totals never exceed the number of steps run
+func (p *Profiler) CallGraph(program *vm.Program) CallGraph {
+	nodes := make(map[string]*CallGraphNode)
+	edges := make(map[[2]string]int64)
+	node := func(name string) *CallGraphNode {
+		n, ok := nodes[name]
+		if !ok {
+			n = &CallGraphNode{Name: name}
+			nodes[name] = n
+		}
+		return n
+	}
+
+	for _, s := range p.stacks {
+		names := make([]string, 0, len(s.pcs))
+		for _, pc := range s.pcs {
+			names = append(names, functionName(program, pc))
+		}
+		node(names[0]).Self += s.steps
+		seenNodes := make(map[string]bool)
+		seenEdges := make(map[[2]string]bool)
+		for i, name := range names {
+			if !seenNodes[name] {
+				seenNodes[name] = true
+				node(name).Total += s.steps
+			}
+			if i+1 < len(names) {
+				edge := [2]string{names[i+1], name}
+				if !seenEdges[edge] {
+					seenEdges[edge] = true
+					edges[edge] += s.steps
+				}
+			}
+		}
+	}
+
+	var graph CallGraph
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, *n)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Total != graph.Nodes[j].Total {
+			return graph.Nodes[i].Total > graph.Nodes[j].Total
+		}
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+	for edge, steps := range edges {
+		graph.Edges = append(graph.Edges, CallGraphEdge{Caller: edge[0], Callee: edge[1], Steps: steps})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Steps != b.Steps {
+			return a.Steps > b.Steps
+		}
+		if a.Caller != b.Caller {
+			return a.Caller < b.Caller
+		}
+		return a.Callee < b.Callee
+	})
+	return graph
+}
+
+// Writes the call graph in the Graphviz DOT format
+func (g CallGraph) WriteDOT(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "digraph calls {")
+	fmt.Fprintln(writer, "  node [shape=box];")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(writer, "  %q [label=%q];\n", n.Name, fmt.Sprintf("%s\nself: %d steps\ntotal: %d steps", n.Name, n.Self, n.Total))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(writer, "  %q -> %q [label=%q];\n", e.Caller, e.Callee, fmt.Sprint(e.Steps))
+	}
+	fmt.Fprintln(writer, "}")
+	return writer.Flush()
+}
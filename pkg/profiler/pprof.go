@@ -0,0 +1,188 @@
+package profiler
+
+// Builds a profile in the pprof protobuf format, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+// Only the fields needed to describe steps and hint time attributed to Cairo functions are supported
+type pprofBuilder struct {
+	strings     []string
+	stringIds   map[string]int64
+	sampleTypes [][]byte
+	// String id of the type of the first sample type
+	defaultSampleType int64
+	samples           []byte
+	locations         []byte
+	functions         []byte
+	nLocations        uint64
+	nFunctions        uint64
+}
+
+// Field numbers of the messages of profile.proto
+const (
+	fieldProfileSampleType        = 1
+	fieldProfileSample            = 2
+	fieldProfileLocation          = 4
+	fieldProfileFunction          = 5
+	fieldProfileStringTable       = 6
+	fieldProfilePeriodType        = 11
+	fieldProfilePeriod            = 12
+	fieldProfileDefaultSampleType = 14
+
+	fieldValueTypeType = 1
+	fieldValueTypeUnit = 2
+
+	fieldSampleLocationId = 1
+	fieldSampleValue      = 2
+
+	fieldLocationId      = 1
+	fieldLocationAddress = 3
+	fieldLocationLine    = 4
+
+	fieldLineFunctionId = 1
+	fieldLineLine       = 2
+
+	fieldFunctionId       = 1
+	fieldFunctionName     = 2
+	fieldFunctionFilename = 4
+)
+
+func newPprofBuilder() *pprofBuilder {
+	// The first string of the table must be empty
+	return &pprofBuilder{strings: []string{""}, stringIds: map[string]int64{"": 0}}
+}
+
+// Returns the index of s in the string table, adding it if needed
+func (b *pprofBuilder) stringId(s string) int64 {
+	if id, ok := b.stringIds[s]; ok {
+		return id
+	}
+	id := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.stringIds[s] = id
+	return id
+}
+
+// Adds a value type to the samples, which then hold one value per type in the order they were added
+func (b *pprofBuilder) addSampleType(sampleType string, unit string) {
+	var valueType protoBuffer
+	valueType.int64Field(fieldValueTypeType, b.stringId(sampleType))
+	valueType.int64Field(fieldValueTypeUnit, b.stringId(unit))
+	if len(b.sampleTypes) == 0 {
+		b.defaultSampleType = b.stringId(sampleType)
+	}
+	b.sampleTypes = append(b.sampleTypes, valueType.bytes)
+}
+
+func (b *pprofBuilder) addFunction(name string, filename string) uint64 {
+	b.nFunctions++
+	var function protoBuffer
+	function.uint64Field(fieldFunctionId, b.nFunctions)
+	function.int64Field(fieldFunctionName, b.stringId(name))
+	function.int64Field(fieldFunctionFilename, b.stringId(filename))
+	appendMessage(&b.functions, fieldProfileFunction, function.bytes)
+	return b.nFunctions
+}
+
+func (b *pprofBuilder) addLocation(address uint64, function uint64, line int64) uint64 {
+	b.nLocations++
+	var lineMessage protoBuffer
+	lineMessage.uint64Field(fieldLineFunctionId, function)
+	lineMessage.int64Field(fieldLineLine, line)
+	var location protoBuffer
+	location.uint64Field(fieldLocationId, b.nLocations)
+	location.uint64Field(fieldLocationAddress, address)
+	location.messageField(fieldLocationLine, lineMessage.bytes)
+	appendMessage(&b.locations, fieldProfileLocation, location.bytes)
+	return b.nLocations
+}
+
+func (b *pprofBuilder) addSample(locationIds []uint64, values ...int64) {
+	packedValues := make([]uint64, 0, len(values))
+	for _, value := range values {
+		packedValues = append(packedValues, uint64(value))
+	}
+	var sample protoBuffer
+	sample.packedUint64Field(fieldSampleLocationId, locationIds)
+	sample.packedUint64Field(fieldSampleValue, packedValues)
+	appendMessage(&b.samples, fieldProfileSample, sample.bytes)
+}
+
+func (b *pprofBuilder) encode() []byte {
+	var profile protoBuffer
+	for _, sampleType := range b.sampleTypes {
+		profile.messageField(fieldProfileSampleType, sampleType)
+	}
+	profile.bytes = append(profile.bytes, b.samples...)
+	profile.bytes = append(profile.bytes, b.locations...)
+	profile.bytes = append(profile.bytes, b.functions...)
+	for _, s := range b.strings {
+		profile.stringField(fieldProfileStringTable, s)
+	}
+	// Samples are taken once per step
+	profile.messageField(fieldProfilePeriodType, b.sampleTypes[0])
+	profile.int64Field(fieldProfilePeriod, 1)
+	// go tool pprof shows the last sample type by default, show the first one instead
+	profile.int64Field(fieldProfileDefaultSampleType, b.defaultSampleType)
+	return profile.bytes
+}
+
+func appendMessage(dest *[]byte, field int, message []byte) {
+	buffer := protoBuffer{bytes: *dest}
+	buffer.messageField(field, message)
+	*dest = buffer.bytes
+}
+
+// Minimal protobuf wire format encoder
+type protoBuffer struct {
+	bytes []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protoBuffer) varint(value uint64) {
+	for value >= 0x80 {
+		b.bytes = append(b.bytes, byte(value)|0x80)
+		value >>= 7
+	}
+	b.bytes = append(b.bytes, byte(value))
+}
+
+func (b *protoBuffer) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+// Zero values are omitted, as they are the default
+func (b *protoBuffer) uint64Field(field int, value uint64) {
+	if value == 0 {
+		return
+	}
+	b.key(field, wireVarint)
+	b.varint(value)
+}
+
+func (b *protoBuffer) int64Field(field int, value int64) {
+	b.uint64Field(field, uint64(value))
+}
+
+// Strings are always written, as the string table relies on their position
+func (b *protoBuffer) stringField(field int, value string) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(value)))
+	b.bytes = append(b.bytes, value...)
+}
+
+func (b *protoBuffer) messageField(field int, message []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(message)))
+	b.bytes = append(b.bytes, message...)
+}
+
+func (b *protoBuffer) packedUint64Field(field int, values []uint64) {
+	var packed protoBuffer
+	for _, value := range values {
+		packed.varint(value)
+	}
+	b.messageField(field, packed.bytes)
+}
@@ -0,0 +1,148 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Attributes the steps of a run, and the time spent running their hints, to the Cairo functions in
+// its call stack
+type Profiler struct {
+	// Call stacks seen during the run, keyed by their pcs
+	stacks map[string]*stack
+	// Keys of stacks, in the order they were first seen
+	order []string
+}
+
+// A call stack, given by the pcs of its frames starting with the current one, and the
+// number of steps run with it and the time spent in their hints
+type stack struct {
+	pcs   []uint
+	steps int64
+	hints time.Duration
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{stacks: make(map[string]*stack)}
+}
+
+// Records the call stack of the instruction about to run. Meant to be installed as a PreStep hook
+func (p *Profiler) Sample(v *vm.VirtualMachine) error {
+	p.sample(v)
+	return nil
+}
+
+// Returns a PreStep hook recording the call stack of the instruction about to run, then running
+// its hints through hints, which can be nil. The time spent in hints is attributed to the stack
+func (p *Profiler) WithHints(hints func(v *vm.VirtualMachine) error) func(v *vm.VirtualMachine) error {
+	if hints == nil {
+		return p.Sample
+	}
+	return func(v *vm.VirtualMachine) error {
+		s := p.sample(v)
+		start := time.Now()
+		err := hints(v)
+		s.hints += time.Since(start)
+		return err
+	}
+}
+
+func (p *Profiler) sample(v *vm.VirtualMachine) *stack {
+	frames := v.GetCallFrames()
+	pcs := make([]uint, 0, len(frames))
+	var key strings.Builder
+	for _, frame := range frames {
+		pcs = append(pcs, frame.Pc.Offset)
+		key.WriteString(strconv.FormatUint(uint64(frame.Pc.Offset), 10))
+		key.WriteByte(',')
+	}
+	s, ok := p.stacks[key.String()]
+	if !ok {
+		s = &stack{pcs: pcs}
+		p.stacks[key.String()] = s
+		p.order = append(p.order, key.String())
+	}
+	s.steps++
+	return s
+}
+
+// Returns the number of steps run within each function, not counting the functions it called
+func (p *Profiler) FunctionSteps(program *vm.Program) map[string]int64 {
+	steps := make(map[string]int64)
+	for _, s := range p.stacks {
+		steps[functionName(program, s.pcs[0])] += s.steps
+	}
+	return steps
+}
+
+// Returns the time spent in the hints run within each function, not counting the functions it called
+func (p *Profiler) FunctionHintTime(program *vm.Program) map[string]time.Duration {
+	hints := make(map[string]time.Duration)
+	for _, s := range p.stacks {
+		hints[functionName(program, s.pcs[0])] += s.hints
+	}
+	return hints
+}
+
+func functionName(program *vm.Program, pc uint) string {
+	if name, ok := program.GetFunctionAt(pc); ok {
+		return name
+	}
+	return fmt.Sprintf("pc %d", pc)
+}
+
+// Writes the profile in the gzipped protobuf format read by go tool pprof, with the steps and the
+// hint time as sample types. Each pc is a location, attributed to the function containing it and
+// to its source line if the program has debug info
+func (p *Profiler) WriteProfile(w io.Writer, program *vm.Program) error {
+	profile := newPprofBuilder()
+	profile.addSampleType("steps", "count")
+	profile.addSampleType("hint_time", "nanoseconds")
+
+	functionIds := make(map[string]uint64)
+	locationIds := make(map[uint]uint64)
+	locationIdOf := func(pc uint) uint64 {
+		if id, ok := locationIds[pc]; ok {
+			return id
+		}
+		name := functionName(program, pc)
+		location, hasLocation := program.GetLocation(pc)
+		functionId, ok := functionIds[name]
+		if !ok {
+			functionId = profile.addFunction(name, location.InputFile.Filename)
+			functionIds[name] = functionId
+		}
+		line := int64(0)
+		if hasLocation {
+			line = int64(location.StartLine)
+		}
+		id := profile.addLocation(uint64(pc), functionId, line)
+		locationIds[pc] = id
+		return id
+	}
+
+	// Sort the stacks so that the output is deterministic
+	keys := append([]string(nil), p.order...)
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := p.stacks[key]
+		ids := make([]uint64, 0, len(s.pcs))
+		for _, pc := range s.pcs {
+			ids = append(ids, locationIdOf(pc))
+		}
+		profile.addSample(ids, s.steps, s.hints.Nanoseconds())
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.encode()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
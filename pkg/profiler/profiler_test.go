@@ -0,0 +1,122 @@
+package profiler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/profiler"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// main calls f at pc 3, which writes 5 at ap and returns
+func profiledRun(t *testing.T) (*profiler.Profiler, *vm.Program) {
+	return profiledRunWithHints(t, nil)
+}
+
+func profiledRunWithHints(t *testing.T, hints func(v *vm.VirtualMachine) error) (*profiler.Profiler, *vm.Program) {
+	words := []string{
+		"0x1104800180018000", "0x3", // call rel 3
+		"0x208b7fff7fff7ffe",        // ret
+		"0x480680017fff8000", "0x5", // [ap] = 5; ap++
+		"0x208b7fff7fff7ffe", // ret
+	}
+	data := make([]memory.MaybeRelocatable, 0, len(words))
+	for _, word := range words {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex(word)))
+	}
+	program := vm.Program{Data: data, Identifiers: map[string]parser.Identifier{
+		"__main__.main": {Type: parser.IdentifierFunction, PC: 0},
+		"__main__.f":    {Type: parser.IdentifierFunction, PC: 3},
+	}}
+
+	runner, err := runners.NewCairoRunner(program, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	p := profiler.NewProfiler()
+	runner.Vm.Hooks.PreStep = p.WithHints(hints)
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC error in test: %s", err)
+	}
+	return p, &program
+}
+
+func TestFunctionSteps(t *testing.T) {
+	p, program := profiledRun(t)
+	expected := map[string]int64{"__main__.main": 2, "__main__.f": 2}
+	if steps := p.FunctionSteps(program); !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Wrong function steps. Expected %v, got %v", expected, steps)
+	}
+}
+
+func TestFunctionHintTime(t *testing.T) {
+	// f runs a hint taking a millisecond at pc 3
+	p, program := profiledRunWithHints(t, func(v *vm.VirtualMachine) error {
+		if v.RunContext.Pc.Offset == 3 {
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	})
+	hints := p.FunctionHintTime(program)
+	if hints["__main__.f"] < time.Millisecond || hints["__main__.main"] >= time.Millisecond {
+		t.Errorf("The hint time should be attributed to f, got %v", hints)
+	}
+	if steps := p.FunctionSteps(program); steps["__main__.f"] != 2 {
+		t.Errorf("Steps running hints should still be counted, got %v", steps)
+	}
+}
+
+func TestWriteProfile(t *testing.T) {
+	p, program := profiledRun(t)
+	var buffer bytes.Buffer
+	if err := p.WriteProfile(&buffer, program); err != nil {
+		t.Fatalf("WriteProfile error in test: %s", err)
+	}
+	reader, err := gzip.NewReader(&buffer)
+	if err != nil {
+		t.Fatalf("The profile should be gzipped: %s", err)
+	}
+	profile, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress the profile: %s", err)
+	}
+	// The string table holds the sample types and the function names
+	for _, s := range []string{"steps", "count", "hint_time", "nanoseconds", "__main__.main", "__main__.f"} {
+		if !bytes.Contains(profile, []byte(s)) {
+			t.Errorf("The profile should contain %q", s)
+		}
+	}
+}
+
+func TestCallGraph(t *testing.T) {
+	p, program := profiledRun(t)
+	graph := p.CallGraph(program)
+	expectedNodes := []profiler.CallGraphNode{{Name: "__main__.main", Self: 2, Total: 4}, {Name: "__main__.f", Self: 2, Total: 2}}
+	expectedEdges := []profiler.CallGraphEdge{{Caller: "__main__.main", Callee: "__main__.f", Steps: 2}}
+	if !reflect.DeepEqual(graph.Nodes, expectedNodes) || !reflect.DeepEqual(graph.Edges, expectedEdges) {
+		t.Errorf("Wrong call graph: %+v", graph)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	p, program := profiledRun(t)
+	var buffer bytes.Buffer
+	if err := p.CallGraph(program).WriteDOT(&buffer); err != nil {
+		t.Fatalf("WriteDOT error in test: %s", err)
+	}
+	if !bytes.HasPrefix(buffer.Bytes(), []byte("digraph calls {")) || !bytes.Contains(buffer.Bytes(), []byte(`"__main__.main" -> "__main__.f" [label="2"];`)) {
+		t.Errorf("Wrong DOT output:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,133 @@
+package prover
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+)
+
+// Names of the files written by WriteInputs
+const (
+	PublicInputFile  = "air_public_input.json"
+	PrivateInputFile = "air_private_input.json"
+	TraceFile        = "trace.bin"
+	MemoryFile       = "memory.bin"
+)
+
+// Offsets of the instructions are biased 16 bit values
+const rcBound = 1 << 16
+
+// Absolute paths of the Stone prover inputs written for a run
+type Inputs struct {
+	PublicInput  string
+	PrivateInput string
+	Trace        string
+	Memory       string
+}
+
+// Checks that a finished proof-mode run meets the constraints of the Stone prover
+func Validate(runner *runners.CairoRunner) error {
+	_, err := validate(runner)
+	return err
+}
+
+func validate(runner *runners.CairoRunner) (runners.AirPublicInput, error) {
+	if !runner.ProofMode {
+		return runners.AirPublicInput{}, errors.New("Only proof-mode runs can be proven")
+	}
+	if runner.Vm.RelocatedMemory == nil {
+		return runners.AirPublicInput{}, errors.New("memory not relocated")
+	}
+	publicInput, err := runner.GetAirPublicInput()
+	if err != nil {
+		return runners.AirPublicInput{}, err
+	}
+	if n := publicInput.NSteps; n == 0 || n&(n-1) != 0 {
+		return runners.AirPublicInput{}, fmt.Errorf("The number of steps should be a power of two, got %d", n)
+	}
+	if publicInput.RcMin < 0 || publicInput.RcMin > publicInput.RcMax || publicInput.RcMax >= rcBound {
+		return runners.AirPublicInput{}, fmt.Errorf("Invalid range check limits [%d, %d]", publicInput.RcMin, publicInput.RcMax)
+	}
+	if len(publicInput.PublicMemory) == 0 {
+		return runners.AirPublicInput{}, errors.New("The public memory is empty")
+	}
+	memorySize := uint64(len(runner.Vm.RelocatedMemory))
+	for _, name := range publicInput.SegmentNames() {
+		segment := publicInput.MemorySegments[name]
+		if segment.BeginAddr > segment.StopPtr || segment.StopPtr > memorySize {
+			return runners.AirPublicInput{}, fmt.Errorf("Segment %s spans [%d, %d], outside of a memory of size %d", name, segment.BeginAddr, segment.StopPtr, memorySize)
+		}
+	}
+	return publicInput, nil
+}
+
+// Validates a finished proof-mode run and writes the public input, private input, trace and
+// memory files the Stone prover takes into dir, creating it if needed. The private input
+// refers to the trace and memory files by their absolute paths.
+func WriteInputs(runner *runners.CairoRunner, dir string) (Inputs, error) {
+	publicInput, err := validate(runner)
+	if err != nil {
+		return Inputs{}, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return Inputs{}, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Inputs{}, err
+	}
+	inputs := Inputs{
+		PublicInput:  filepath.Join(dir, PublicInputFile),
+		PrivateInput: filepath.Join(dir, PrivateInputFile),
+		Trace:        filepath.Join(dir, TraceFile),
+		Memory:       filepath.Join(dir, MemoryFile),
+	}
+
+	if err := writeFile(inputs.Trace, runner.Vm.WriteEncodedTrace); err != nil {
+		return Inputs{}, fmt.Errorf("Failed to write trace: %w", err)
+	}
+	if err := writeFile(inputs.Memory, runner.Vm.WriteEncodedMemory); err != nil {
+		return Inputs{}, fmt.Errorf("Failed to write memory: %w", err)
+	}
+	if err := writeFile(inputs.PublicInput, writeJSON(publicInput)); err != nil {
+		return Inputs{}, fmt.Errorf("Failed to write AIR public input: %w", err)
+	}
+	privateInput := runner.GetAirPrivateInput(inputs.Trace, inputs.Memory)
+	if err := writeFile(inputs.PrivateInput, writeJSON(privateInput)); err != nil {
+		return Inputs{}, fmt.Errorf("Failed to write AIR private input: %w", err)
+	}
+	return inputs, nil
+}
+
+// Creates (or truncates) the file at path and writes to it using write
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if err := write(writer); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Returns a function writing value as indented JSON, to be used with writeFile
+func writeJSON(value any) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
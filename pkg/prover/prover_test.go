@@ -0,0 +1,127 @@
+package prover_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/prover"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Runs a program looping on jmp rel 0 from __start__, which is also __end__
+func runProgram(t *testing.T) *runners.CairoRunner {
+	program := vm.Program{
+		Data: []memory.MaybeRelocatable{
+			*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x10780017fff7fff")),
+			*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+		},
+		Identifiers: map[string]parser.Identifier{
+			"__main__.__start__": {Type: parser.IdentifierLabel, PC: 0},
+			"__main__.__end__":   {Type: parser.IdentifierLabel, PC: 0},
+		},
+	}
+	runner, err := cairo_run.CairoRunProgram(program, cairo_run.CairoRunConfig{ProofMode: true})
+	if err != nil {
+		t.Fatalf("CairoRunProgram error in test: %s", err)
+	}
+	return runner
+}
+
+func TestWriteInputs(t *testing.T) {
+	runner := runProgram(t)
+	dir := filepath.Join(t.TempDir(), "proof")
+	inputs, err := prover.WriteInputs(runner, dir)
+	if err != nil {
+		t.Fatalf("WriteInputs failed with error: %s", err)
+	}
+	if inputs.Trace != filepath.Join(dir, prover.TraceFile) || inputs.Memory != filepath.Join(dir, prover.MemoryFile) {
+		t.Errorf("Wrong input paths: %+v", inputs)
+	}
+
+	var privateInput runners.AirPrivateInput
+	data, err := os.ReadFile(inputs.PrivateInput)
+	if err != nil || json.Unmarshal(data, &privateInput) != nil {
+		t.Fatalf("Failed to read the private input: %v", err)
+	}
+	if privateInput.TracePath != inputs.Trace || privateInput.MemoryPath != inputs.Memory {
+		t.Errorf("The private input doesn't refer to the written files: %+v", privateInput)
+	}
+
+	var publicInput runners.AirPublicInput
+	data, err = os.ReadFile(inputs.PublicInput)
+	if err != nil || json.Unmarshal(data, &publicInput) != nil {
+		t.Fatalf("Failed to read the public input: %v", err)
+	}
+	traceFile, err := os.Open(inputs.Trace)
+	if err != nil {
+		t.Fatalf("Failed to open the trace: %s", err)
+	}
+	defer traceFile.Close()
+	trace, err := vm.DecodeTrace(traceFile)
+	if err != nil || uint(len(trace)) != publicInput.NSteps {
+		t.Errorf("The trace should have %d steps, got %d, err: %v", publicInput.NSteps, len(trace), err)
+	}
+	if _, err := os.Stat(inputs.Memory); err != nil {
+		t.Errorf("The memory wasn't written: %s", err)
+	}
+}
+
+func TestWriteInputsNotProofMode(t *testing.T) {
+	runner := runProgram(t)
+	runner.ProofMode = false
+	dir := filepath.Join(t.TempDir(), "proof")
+	if _, err := prover.WriteInputs(runner, dir); err == nil {
+		t.Errorf("WriteInputs should fail for a run not in proof mode")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Nothing should be written when the run is invalid")
+	}
+}
+
+func TestValidateStepsNotPowerOfTwo(t *testing.T) {
+	runner := runProgram(t)
+	entry, relocatedEntry := runner.Vm.Trace[0], runner.Vm.RelocatedTrace[0]
+	runner.Vm.Trace = append(runner.Vm.Trace, entry, entry)
+	runner.Vm.RelocatedTrace = append(runner.Vm.RelocatedTrace, relocatedEntry, relocatedEntry)
+	if err := prover.Validate(runner); err == nil {
+		t.Errorf("Validate should fail for a trace of 3 steps")
+	}
+}
+
+func TestValidateNotRelocated(t *testing.T) {
+	runner := runProgram(t)
+	runner.Vm.RelocatedMemory = nil
+	if err := prover.Validate(runner); err == nil {
+		t.Errorf("Validate should fail for a run whose memory wasn't relocated")
+	}
+}
+
+func TestWriteInputsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	var outputs [2]map[string][]byte
+	for i := range outputs {
+		inputs, err := prover.WriteInputs(runProgram(t), dir)
+		if err != nil {
+			t.Fatalf("WriteInputs failed with error: %s", err)
+		}
+		outputs[i] = make(map[string][]byte)
+		for _, path := range []string{inputs.PublicInput, inputs.PrivateInput, inputs.Trace, inputs.Memory} {
+			if outputs[i][path], err = os.ReadFile(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for path, data := range outputs[0] {
+		if !bytes.Equal(data, outputs[1][path]) {
+			t.Errorf("%s differs between two runs of the same program", filepath.Base(path))
+		}
+	}
+}
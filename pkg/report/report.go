@@ -0,0 +1,170 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/profiler"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+// Maximum number of cells of a segment shown in the heatmap
+const MaxHeatmapCells = 4096
+
+// Summary of a finished run: its memory layout, how often each memory cell was accessed and,
+// if the run was profiled, the call graph of its Cairo functions
+type Report struct {
+	Steps    int
+	Segments []Segment
+	// Nil if the run wasn't profiled
+	CallGraph *profiler.CallGraph
+}
+
+// A memory segment, as laid out after relocation
+type Segment struct {
+	Index uint
+	Name  string
+	// First relocated address of the segment
+	Base  uint
+	Size  uint
+	Holes uint
+	// The first MaxHeatmapCells cells of the segment
+	Cells     []Cell
+	Truncated bool
+}
+
+type Cell struct {
+	Address  uint
+	Offset   uint
+	Empty    bool
+	Accesses uint
+}
+
+// Builds the report of a relocated run. The profiler may be nil
+func NewReport(runner *runners.CairoRunner, p *profiler.Profiler) (*Report, error) {
+	v := &runner.Vm
+	if v.RelocatedMemory == nil {
+		return nil, errors.New("memory not relocated")
+	}
+	relocationTable, err := v.GetRelocationTable()
+	if err != nil {
+		return nil, err
+	}
+	accesses, err := vm.AccessCounts(v.RelocatedTrace, v.RelocatedMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[uint]string{0: "program", 1: "execution"}
+	for _, builtin := range v.BuiltinRunners {
+		names[uint(builtin.Base().SegmentIndex)] = builtin.Name()
+	}
+
+	report := &Report{Steps: len(v.RelocatedTrace)}
+	for i, base := range relocationTable {
+		segment := Segment{Index: uint(i), Name: names[uint(i)], Base: base, Size: v.Segments.GetSegmentSize(uint(i))}
+		for offset := uint(0); offset < segment.Size; offset++ {
+			address := base + offset
+			empty := address >= uint(len(v.RelocatedMemory)) || v.RelocatedMemory[address] == nil
+			if empty {
+				segment.Holes++
+			}
+			if offset < MaxHeatmapCells {
+				segment.Cells = append(segment.Cells, Cell{Address: address, Offset: offset, Empty: empty, Accesses: accesses[uint64(address)]})
+			}
+		}
+		segment.Truncated = segment.Size > MaxHeatmapCells
+		report.Segments = append(report.Segments, segment)
+	}
+	if p != nil {
+		graph := p.CallGraph(&runner.Program)
+		report.CallGraph = &graph
+	}
+	return report, nil
+}
+
+// Returns the highest number of accesses of a cell
+func (r *Report) maxAccesses() uint {
+	max := uint(0)
+	for _, segment := range r.Segments {
+		for _, cell := range segment.Cells {
+			if cell.Accesses > max {
+				max = cell.Accesses
+			}
+		}
+	}
+	return max
+}
+
+// Writes the report as a self-contained HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	maxAccesses := r.maxAccesses()
+	funcs := template.FuncMap{
+		// Background of a heatmap cell, on a logarithmic scale from white to red
+		"heat": func(cell Cell) template.CSS {
+			if cell.Empty {
+				return "background: #ccc"
+			}
+			if cell.Accesses == 0 || maxAccesses == 0 {
+				return "background: #fff"
+			}
+			intensity := math.Log1p(float64(cell.Accesses)) / math.Log1p(float64(maxAccesses))
+			shade := 255 - int(intensity*200)
+			return template.CSS(fmt.Sprintf("background: rgb(255, %d, %d)", shade, shade))
+		},
+	}
+	page, err := template.New("report").Funcs(funcs).Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	return page.Execute(w, r)
+}
+
+const reportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cairo run report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #999; padding: 0.2em 0.6em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.heatmap { display: flex; flex-wrap: wrap; max-width: 64em; margin-bottom: 1em; }
+.heatmap span { width: 0.9em; height: 0.9em; border: 1px solid #eee; }
+</style>
+</head>
+<body>
+<h1>Cairo run report</h1>
+<p>{{.Steps}} steps</p>
+
+<h2>Segments</h2>
+<table>
+<tr><th>Segment</th><th>Base</th><th>Size</th><th>Holes</th></tr>
+{{range .Segments}}<tr><td>{{.Index}} {{.Name}}</td><td>{{.Base}}</td><td>{{.Size}}</td><td>{{.Holes}}</td></tr>
+{{end}}</table>
+
+<h2>Memory accesses</h2>
+<p>Grey cells are holes, red cells are the most accessed ones.</p>
+{{range .Segments}}<h3>Segment {{.Index}} {{.Name}}</h3>
+<div class="heatmap">{{range .Cells}}<span style="{{heat .}}" title="{{.Address}} (offset {{.Offset}}): {{if .Empty}}hole{{else}}{{.Accesses}} accesses{{end}}"></span>{{end}}</div>
+{{if .Truncated}}<p>Only the first {{len .Cells}} cells are shown.</p>{{end}}
+{{end}}
+{{with .CallGraph}}<h2>Functions</h2>
+<table>
+<tr><th>Function</th><th>Self steps</th><th>Total steps</th></tr>
+{{range .Nodes}}<tr><td>{{.Name}}</td><td>{{.Self}}</td><td>{{.Total}}</td></tr>
+{{end}}</table>
+
+<h2>Calls</h2>
+<table>
+<tr><th>Caller</th><th>Callee</th><th>Steps</th></tr>
+{{range .Edges}}<tr><td>{{.Caller}}</td><td>{{.Callee}}</td><td>{{.Steps}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`
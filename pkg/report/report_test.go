@@ -0,0 +1,101 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/profiler"
+	"github.com/lambdaclass/cairo-vm.go/pkg/report"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// main calls f at pc 3, which writes 5 at ap and returns
+func reportedRun(t *testing.T) (*runners.CairoRunner, *profiler.Profiler) {
+	words := []string{
+		"0x1104800180018000", "0x3", // call rel 3
+		"0x208b7fff7fff7ffe",        // ret
+		"0x480680017fff8000", "0x5", // [ap] = 5; ap++
+		"0x208b7fff7fff7ffe", // ret
+	}
+	data := make([]memory.MaybeRelocatable, 0, len(words))
+	for _, word := range words {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex(word)))
+	}
+	program := vm.Program{Data: data, Identifiers: map[string]parser.Identifier{
+		"__main__.main": {Type: parser.IdentifierFunction, PC: 0},
+		"__main__.f":    {Type: parser.IdentifierFunction, PC: 3},
+	}}
+
+	runner, err := runners.NewCairoRunner(program, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	p := profiler.NewProfiler()
+	runner.Vm.Hooks.PreStep = p.Sample
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC error in test: %s", err)
+	}
+	if err := runner.Vm.Relocate(); err != nil {
+		t.Fatalf("Relocate error in test: %s", err)
+	}
+	return runner, p
+}
+
+func TestNewReport(t *testing.T) {
+	runner, p := reportedRun(t)
+	r, err := report.NewReport(runner, p)
+	if err != nil {
+		t.Fatalf("NewReport failed with error: %s", err)
+	}
+	if r.Steps != 4 || len(r.Segments) != 4 || r.CallGraph == nil {
+		t.Fatalf("Wrong report: %+v", r)
+	}
+	program, execution := r.Segments[0], r.Segments[1]
+	if program.Name != "program" || program.Base != 1 || program.Size != 6 || program.Holes != 0 {
+		t.Errorf("Wrong program segment: %+v", program)
+	}
+	if execution.Name != "execution" || execution.Base != 7 || len(execution.Cells) != int(execution.Size) {
+		t.Errorf("Wrong execution segment: %+v", execution)
+	}
+	// The immediate of the call is read once
+	if program.Cells[1].Accesses != 1 {
+		t.Errorf("Wrong access count for the call immediate: %d", program.Cells[1].Accesses)
+	}
+}
+
+func TestNewReportNotRelocated(t *testing.T) {
+	runner, err := runners.NewCairoRunner(vm.Program{}, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	if _, err := report.NewReport(runner, nil); err == nil {
+		t.Errorf("NewReport should fail before relocation")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	runner, p := reportedRun(t)
+	r, err := report.NewReport(runner, p)
+	if err != nil {
+		t.Fatalf("NewReport failed with error: %s", err)
+	}
+	var buffer bytes.Buffer
+	if err := r.WriteHTML(&buffer); err != nil {
+		t.Fatalf("WriteHTML failed with error: %s", err)
+	}
+	html := buffer.String()
+	for _, s := range []string{"<!DOCTYPE html>", "4 steps", "0 program", "1 execution", "__main__.f", "background: rgb(255,"} {
+		if !strings.Contains(html, s) {
+			t.Errorf("The report should contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package runners
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Public input of the Cairo AIR, in the format expected by the Stone prover.
+// MemorySegments is encoded with its keys sorted, SegmentNames goes over it in a fixed order
+type AirPublicInput struct {
+	Layout         string                            `json:"layout"`
+	RcMin          int                               `json:"rc_min"`
+	RcMax          int                               `json:"rc_max"`
+	NSteps         uint                              `json:"n_steps"`
+	MemorySegments map[string]MemorySegmentAddresses `json:"memory_segments"`
+	PublicMemory   []PublicMemoryEntry               `json:"public_memory"`
+}
+
+// Returns the names of the memory segments in the order they are laid out in the relocated memory,
+// ties being broken by name, so that going over them doesn't depend on the map's order
+func (p *AirPublicInput) SegmentNames() []string {
+	names := make([]string, 0, len(p.MemorySegments))
+	for name := range p.MemorySegments {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := p.MemorySegments[names[i]], p.MemorySegments[names[j]]
+		if a.BeginAddr != b.BeginAddr {
+			return a.BeginAddr < b.BeginAddr
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Relocated bounds of a memory segment used during the run
+type MemorySegmentAddresses struct {
+	BeginAddr uint64 `json:"begin_addr"`
+	StopPtr   uint64 `json:"stop_ptr"`
+}
+
+// A cell of the public memory. The value is hex encoded
+type PublicMemoryEntry struct {
+	Address uint   `json:"address"`
+	Value   string `json:"value"`
+	Page    uint   `json:"page"`
+}
+
+// Private input of the Cairo AIR: the location of the trace & memory files,
+// followed by the inputs of each builtin (there are none yet)
+type AirPrivateInput struct {
+	TracePath  string `json:"trace_path"`
+	MemoryPath string `json:"memory_path"`
+}
+
+// Builds the AIR public input of a finished proof-mode run, the memory must be relocated
+func (r *CairoRunner) GetAirPublicInput() (AirPublicInput, error) {
+	if !r.ProofMode {
+		return AirPublicInput{}, errors.New("The AIR public input can only be generated in proof mode")
+	}
+	relocationTable, err := r.Vm.GetRelocationTable()
+	if err != nil {
+		return AirPublicInput{}, err
+	}
+	publicMemoryAddresses, err := r.Vm.Segments.GetPublicMemoryAddresses(&relocationTable)
+	if err != nil {
+		return AirPublicInput{}, err
+	}
+	publicMemory := make([]PublicMemoryEntry, 0, len(publicMemoryAddresses))
+	for _, address := range publicMemoryAddresses {
+		if address.Address >= uint(len(r.Vm.RelocatedMemory)) || r.Vm.RelocatedMemory[address.Address] == nil {
+			return AirPublicInput{}, fmt.Errorf("Public memory address %d is empty", address.Address)
+		}
+		publicMemory = append(publicMemory, PublicMemoryEntry{
+			Address: address.Address,
+			Value:   r.Vm.RelocatedMemory[address.Address].ToHexString(),
+			Page:    address.Page,
+		})
+	}
+
+	// The program segment spans the pcs of the run, the execution segment its aps
+	programSegment, err := r.newMemorySegmentAddresses(r.initialPc, r.GetFinalPc())
+	if err != nil {
+		return AirPublicInput{}, err
+	}
+	executionSegment, err := r.newMemorySegmentAddresses(r.GetInitialAp(), r.Vm.RunContext.Ap)
+	if err != nil {
+		return AirPublicInput{}, err
+	}
+	memorySegments := map[string]MemorySegmentAddresses{"program": programSegment, "execution": executionSegment}
+	// Builtin segments span the cells used by the builtin
+	for i := range r.Vm.BuiltinRunners {
+		base := r.Vm.BuiltinRunners[i].Base()
+		stop := base
+		stop.Offset += r.Vm.Segments.GetSegmentSize(uint(base.SegmentIndex))
+		builtinSegment, err := r.newMemorySegmentAddresses(base, stop)
+		if err != nil {
+			return AirPublicInput{}, err
+		}
+		memorySegments[r.Vm.BuiltinRunners[i].Name()] = builtinSegment
+	}
+
+	rcMin, rcMax, _, err := r.GetPermRangeCheckLimits()
+	if err != nil {
+		return AirPublicInput{}, err
+	}
+
+	return AirPublicInput{
+		Layout:         r.Layout.Name,
+		RcMin:          rcMin,
+		RcMax:          rcMax,
+		NSteps:         r.Vm.TraceLen(),
+		MemorySegments: memorySegments,
+		PublicMemory:   publicMemory,
+	}, nil
+}
+
+func (r *CairoRunner) newMemorySegmentAddresses(begin memory.Relocatable, stop memory.Relocatable) (MemorySegmentAddresses, error) {
+	beginAddr, err := r.RelocateAddress(begin)
+	if err != nil {
+		return MemorySegmentAddresses{}, err
+	}
+	stopPtr, err := r.RelocateAddress(stop)
+	if err != nil {
+		return MemorySegmentAddresses{}, err
+	}
+	return MemorySegmentAddresses{BeginAddr: uint64(beginAddr), StopPtr: uint64(stopPtr)}, nil
+}
+
+// Builds the AIR private input of a proof-mode run, given the paths the trace and memory were written to
+func (r *CairoRunner) GetAirPrivateInput(tracePath string, memoryPath string) AirPrivateInput {
+	return AirPrivateInput{TracePath: tracePath, MemoryPath: memoryPath}
+}
@@ -0,0 +1,175 @@
+package runners_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func runProofModeProgram(t *testing.T) *runners.CairoRunner {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), true)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC error in test: %s", err)
+	}
+	if err := runner.EndRun(); err != nil {
+		t.Fatalf("EndRun error in test: %s", err)
+	}
+	if err := runner.FinalizeSegments(); err != nil {
+		t.Fatalf("FinalizeSegments error in test: %s", err)
+	}
+	if err := runner.Vm.Relocate(); err != nil {
+		t.Fatalf("Relocate error in test: %s", err)
+	}
+	return runner
+}
+
+func TestGetAirPublicInput(t *testing.T) {
+	runner := runProofModeProgram(t)
+	publicInput, err := runner.GetAirPublicInput()
+	if err != nil {
+		t.Fatalf("GetAirPublicInput error in test: %s", err)
+	}
+	// The program segment is relocated at 1 and the execution segment at 3
+	expected := runners.AirPublicInput{
+		Layout: "plain",
+		// jmp rel 0 has offsets -1, -1 and 1
+		RcMin:  1<<15 - 1,
+		RcMax:  1<<15 + 1,
+		NSteps: 1,
+		MemorySegments: map[string]runners.MemorySegmentAddresses{
+			"program":   {BeginAddr: 1, StopPtr: 1},
+			"execution": {BeginAddr: 5, StopPtr: 5},
+		},
+		PublicMemory: []runners.PublicMemoryEntry{
+			{Address: 1, Value: "0x10780017fff7fff", Page: 0},
+			{Address: 2, Value: "0x0", Page: 0},
+			{Address: 3, Value: "0x5", Page: 0},
+			{Address: 4, Value: "0x0", Page: 0},
+		},
+	}
+	if !reflect.DeepEqual(publicInput, expected) {
+		t.Errorf("Wrong AIR public input. Expected %+v, got %+v", expected, publicInput)
+	}
+}
+
+func TestGetAirPublicInputNotProofMode(t *testing.T) {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	if _, err := runner.GetAirPublicInput(); err == nil {
+		t.Errorf("GetAirPublicInput should fail outside of proof mode")
+	}
+}
+
+func TestGetAirPrivateInput(t *testing.T) {
+	runner := runProofModeProgram(t)
+	privateInput := runner.GetAirPrivateInput("/tmp/a.trace", "/tmp/a.memory")
+	if privateInput.TracePath != "/tmp/a.trace" || privateInput.MemoryPath != "/tmp/a.memory" {
+		t.Errorf("Wrong AIR private input, got %+v", privateInput)
+	}
+}
+
+func TestSegmentNames(t *testing.T) {
+	publicInput := runners.AirPublicInput{MemorySegments: map[string]runners.MemorySegmentAddresses{
+		"output":    {BeginAddr: 40, StopPtr: 42},
+		"execution": {BeginAddr: 10, StopPtr: 30},
+		"program":   {BeginAddr: 1, StopPtr: 5},
+		"pedersen":  {BeginAddr: 40, StopPtr: 40},
+	}}
+	expected := []string{"program", "execution", "output", "pedersen"}
+	if names := publicInput.SegmentNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Wrong segment names. Expected %v, got %v", expected, names)
+	}
+}
+
+// A proof mode program outputting 5 and 6, the second value on its own page
+func outputProgram(t *testing.T) vm.Program {
+	instructions := []vm.Instruction{
+		// [ap + 1] = 5
+		{Off0: 1, Off1: -1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm, ResLogic: vm.ResOp1, Opcode: vm.AssertEq},
+		// [ap + 1] = [[ap]]
+		{Off0: 1, Off1: 0, Off2: 0, DstReg: vm.AP, Op0Reg: vm.AP, Op1Addr: vm.Op1SrcOp0, ResLogic: vm.ResOp1, Opcode: vm.AssertEq},
+		// [ap + 2] = 6
+		{Off0: 2, Off1: -1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm, ResLogic: vm.ResOp1, Opcode: vm.AssertEq},
+		// [ap + 2] = [[ap] + 1]
+		{Off0: 2, Off1: 0, Off2: 1, DstReg: vm.AP, Op0Reg: vm.AP, Op1Addr: vm.Op1SrcOp0, ResLogic: vm.ResOp1, Opcode: vm.AssertEq},
+		// jmp rel 0
+		{Off0: -1, Off1: -1, Off2: 1, DstReg: vm.FP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm, ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateJumpRel, Opcode: vm.NOp},
+	}
+	immediates := map[int]uint64{0: 5, 2: 6, 4: 0}
+	data := make([]memory.MaybeRelocatable, 0)
+	for i := range instructions {
+		encoded, err := instructions[i].Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(encoded)))
+		if imm, ok := immediates[i]; ok {
+			data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(imm)))
+		}
+	}
+	identifiers := map[string]parser.Identifier{
+		"__main__.__start__": {Type: parser.IdentifierLabel, PC: 0},
+		"__main__.__end__":   {Type: parser.IdentifierLabel, PC: len(data) - 2},
+	}
+	return vm.Program{Data: data, Identifiers: identifiers, Builtins: []string{"output"}}
+}
+
+func TestGetAirPublicInputOutputPages(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(outputProgram(t), "small", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err == nil {
+		err = runner.EndRun()
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	output := runner.Vm.BuiltinRunners[0].(*builtins.OutputBuiltinRunner)
+	if err := output.AddPage(1, memory.NewRelocatable(output.Base().SegmentIndex, 1), 1); err != nil {
+		t.Fatalf("AddPage error in test: %s", err)
+	}
+	if err := runner.FinalizeSegments(); err != nil {
+		t.Fatalf("FinalizeSegments error in test: %s", err)
+	}
+	if err := runner.Vm.Relocate(); err != nil {
+		t.Fatalf("Relocate error in test: %s", err)
+	}
+	publicInput, err := runner.GetAirPublicInput()
+	if err != nil {
+		t.Fatalf("GetAirPublicInput error in test: %s", err)
+	}
+	if publicInput.Layout != "small" {
+		t.Errorf("Expected the small layout, got %s", publicInput.Layout)
+	}
+	// The output segment comes right after the execution segment, which holds the dummy frame,
+	// the output base and the two values output
+	outputBase := publicInput.MemorySegments["execution"].BeginAddr - 2 + 5
+	if segment := publicInput.MemorySegments["output"]; segment.BeginAddr != outputBase || segment.StopPtr != outputBase+2 {
+		t.Errorf("Wrong output segment %+v, expected it to start at %d", segment, outputBase)
+	}
+	expected := []runners.PublicMemoryEntry{{Address: uint(outputBase), Value: "0x5", Page: 0}, {Address: uint(outputBase) + 1, Value: "0x6", Page: 1}}
+	publicMemory := publicInput.PublicMemory
+	if len(publicMemory) < 2 || !reflect.DeepEqual(publicMemory[len(publicMemory)-2:], expected) {
+		t.Errorf("Expected the public memory to end with the output %+v, got %+v", expected, publicMemory)
+	}
+}
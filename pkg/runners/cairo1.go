@@ -0,0 +1,186 @@
+package runners
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Arguments of a Cairo 1 entry point, given by the runner on the stack
+type cairo1Entrypoint struct {
+	builtins   []string
+	initialGas uint64
+	calldata   []lambdaworks.Felt
+}
+
+// Builds a runner calling an entry point of a Cairo 1 contract class. Entry points take the
+// builtins they use, the gas available, the syscall pointer and their calldata as a span; this
+// is done by entry code prepended to the program's bytecode. Hints run at the same pcs as in the
+// bytecode, shifted by the size of the entry code.
+func NewCairo1Runner(program vm.Program, entryPoint parser.CasmEntryPoint, calldata []lambdaworks.Felt, initialGas uint64) (*CairoRunner, error) {
+	if entryPoint.Offset >= uint(len(program.Data)) {
+		return nil, fmt.Errorf("Entry point offset %d is out of the bytecode bounds", entryPoint.Offset)
+	}
+	entryCode, err := CreateEntryCode(entryPoint.Builtins, initialGas, entryPoint.Offset)
+	if err != nil {
+		return nil, err
+	}
+	entryCodeSize := uint(len(entryCode))
+	shifted := program
+	shifted.Data = append(entryCode, program.Data...)
+	shifted.Cairo1Hints = make(map[uint][]parser.CasmHint, len(program.Cairo1Hints))
+	for pc, hints := range program.Cairo1Hints {
+		shifted.Cairo1Hints[pc+entryCodeSize] = hints
+	}
+	shifted.MainEntrypoint = nil
+
+	runner, err := NewCairoRunner(shifted, false)
+	if err != nil {
+		return nil, err
+	}
+	runner.mainOffset = 0
+	runner.cairo1 = &cairo1Entrypoint{builtins: entryPoint.Builtins, initialGas: initialGas, calldata: calldata}
+	return runner, nil
+}
+
+// Returns the code calling the function at entryOffset, an offset of the code following the entry
+// code, then returning. The entry code runs with its arguments at the end of the caller's frame:
+// one pointer per builtin, the syscall pointer and the calldata start and end, and passes them
+// to the function with the initial gas after the builtins. Once the function returns, its
+// return values are the last values written before ap.
+func CreateEntryCode(builtins []string, initialGas uint64, entryOffset uint) ([]memory.MaybeRelocatable, error) {
+	// [fp - 3] holds the calldata end, [fp - 4] its start, [fp - 5] the syscall pointer and the builtins come before
+	firstArg := -(5 + len(builtins))
+	var code []memory.MaybeRelocatable
+	appendInstruction := func(instruction vm.Instruction, immediate *lambdaworks.Felt) error {
+		encoded, err := instruction.Encode()
+		if err != nil {
+			return err
+		}
+		code = append(code, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(encoded)))
+		if immediate != nil {
+			code = append(code, *memory.NewMaybeRelocatableFelt(*immediate))
+		}
+		return nil
+	}
+	// [ap + 0] = [fp + offset], ap++
+	pushFp := func(offset int) error {
+		return appendInstruction(vm.Instruction{Off0: 0, Off1: -1, Off2: offset, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP,
+			ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateRegular, ApUpdate: vm.ApUpdateAdd1, FpUpdate: vm.FpUpdateRegular, Opcode: vm.AssertEq}, nil)
+	}
+
+	for i := range builtins {
+		if err := pushFp(firstArg + i); err != nil {
+			return nil, err
+		}
+	}
+	// [ap + 0] = initialGas, ap++
+	gas := lambdaworks.FeltFromUint64(initialGas)
+	err := appendInstruction(vm.Instruction{Off0: 0, Off1: -1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateRegular, ApUpdate: vm.ApUpdateAdd1, FpUpdate: vm.FpUpdateRegular, Opcode: vm.AssertEq}, &gas)
+	if err != nil {
+		return nil, err
+	}
+	for _, offset := range []int{-5, -4, -3} {
+		if err := pushFp(offset); err != nil {
+			return nil, err
+		}
+	}
+	// call rel (entry code size + entryOffset - pc of the call), which is the call and ret sizes away
+	target := lambdaworks.FeltFromUint64(uint64(entryOffset + 3))
+	err = appendInstruction(vm.Instruction{Off0: 0, Off1: 1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.AP, Op1Addr: vm.Op1SrcImm,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateJumpRel, ApUpdate: vm.ApUpdateAdd2, FpUpdate: vm.FpUpdateAPPlus2, Opcode: vm.Call}, &target)
+	if err != nil {
+		return nil, err
+	}
+	// ret
+	err = appendInstruction(vm.Instruction{Off0: -2, Off1: -1, Off2: -1, DstReg: vm.FP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateJump, ApUpdate: vm.ApUpdateRegular, FpUpdate: vm.FpUpdateDst, Opcode: vm.Ret}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// Initializes memory & initial register values to run the entry code of a Cairo 1 entry point,
+// returns the end pointer
+func (r *CairoRunner) initializeCairo1Entrypoint() (memory.Relocatable, error) {
+	stack := make([]memory.MaybeRelocatable, 0, len(r.cairo1.builtins)+5)
+	// Builtins get plain segments. Contract entry points don't take the output builtin, the only one
+	// with a runner, so there are no builtin runners to validate their cells
+	for range r.cairo1.builtins {
+		stack = append(stack, *memory.NewMaybeRelocatableRelocatable(r.Vm.Segments.AddSegment()))
+	}
+	stack = append(stack, *memory.NewMaybeRelocatableRelocatable(r.Vm.Segments.AddSegment()))
+	calldata, err := r.Vm.Segments.GenArg(r.cairo1.calldata)
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	calldataStart, _ := calldata.GetRelocatable()
+	calldataEnd := memory.NewRelocatable(calldataStart.SegmentIndex, calldataStart.Offset+uint(len(r.cairo1.calldata)))
+	stack = append(stack, calldata, *memory.NewMaybeRelocatableRelocatable(calldataEnd))
+	return_fp := r.Vm.Segments.AddSegment()
+	return r.initializeFunctionEntrypoint(r.mainOffset, &stack, return_fp)
+}
+
+// The outcome of a Cairo 1 entry point
+type Cairo1Result struct {
+	// Gas left after the run
+	Gas uint64
+	// Whether the entry point panicked, in which case Retdata holds the panic reason
+	Failed  bool
+	Retdata []lambdaworks.Felt
+}
+
+// Reads the return values of a Cairo 1 entry point, once the runner reached its end: the gas left,
+// the syscall pointer, the failure flag and the retdata span
+func (r *CairoRunner) GetCairo1Result() (Cairo1Result, error) {
+	if r.cairo1 == nil {
+		return Cairo1Result{}, errors.New("The runner doesn't run a Cairo 1 entry point")
+	}
+	proxy := vm.NewVMProxy(&r.Vm)
+	returnValues, err := r.Vm.RunContext.Ap.SubUint(5)
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+	field := func(i uint) memory.Relocatable {
+		return memory.NewRelocatable(returnValues.SegmentIndex, returnValues.Offset+i)
+	}
+	gas, err := proxy.GetFeltFromVar(field(0))
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+	failureFlag, err := proxy.GetFeltFromVar(field(2))
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+	start, err := proxy.GetRelocatableFromVar(field(3))
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+	end, err := proxy.GetRelocatableFromVar(field(4))
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+	length, err := end.Sub(start)
+	if err != nil {
+		return Cairo1Result{}, err
+	}
+
+	result := Cairo1Result{Failed: !failureFlag.IsZero(), Retdata: make([]lambdaworks.Felt, 0, length)}
+	if result.Gas, err = gas.ToU64(); err != nil {
+		return Cairo1Result{}, fmt.Errorf("Invalid gas %s", gas.String())
+	}
+	for i := uint(0); i < length; i++ {
+		value, err := proxy.GetFeltFromVar(memory.NewRelocatable(start.SegmentIndex, start.Offset+i))
+		if err != nil {
+			return Cairo1Result{}, err
+		}
+		result.Retdata = append(result.Retdata, value)
+	}
+	return result, nil
+}
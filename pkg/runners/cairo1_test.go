@@ -0,0 +1,135 @@
+package runners_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Appends the encoding of [ap + 0] = [fp + offset], ap++ or, with op1Imm, [ap + 0] = immediate, ap++
+func appendPush(t *testing.T, data []memory.MaybeRelocatable, offset int, immediate *lambdaworks.Felt) []memory.MaybeRelocatable {
+	instruction := vm.Instruction{Off0: 0, Off1: -1, Off2: offset, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateRegular, ApUpdate: vm.ApUpdateAdd1, FpUpdate: vm.FpUpdateRegular, Opcode: vm.AssertEq}
+	if immediate != nil {
+		instruction.Off2 = 1
+		instruction.Op1Addr = vm.Op1SrcImm
+	}
+	encoded, err := instruction.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed with error: %s", err)
+	}
+	data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(encoded)))
+	if immediate != nil {
+		data = append(data, *memory.NewMaybeRelocatableFelt(*immediate))
+	}
+	return data
+}
+
+// A contract class whose entry point, at offset 1, returns its calldata with the gas and
+// syscall pointer it received, or panics with its calldata if failureFlag is 1
+func echoProgram(t *testing.T, failureFlag uint64) vm.Program {
+	// A filler instruction, so that the entry point doesn't start at offset 0
+	data := []memory.MaybeRelocatable{*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe))}
+	flag := lambdaworks.FeltFromUint64(failureFlag)
+	data = appendPush(t, data, -6, nil)
+	data = appendPush(t, data, -5, nil)
+	data = appendPush(t, data, 0, &flag)
+	data = appendPush(t, data, -4, nil)
+	data = appendPush(t, data, -3, nil)
+	data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe)))
+	return vm.Program{Data: data, Identifiers: map[string]parser.Identifier{}}
+}
+
+func runCairo1(t *testing.T, program vm.Program, calldata []lambdaworks.Felt) runners.Cairo1Result {
+	runner, err := runners.NewCairo1Runner(program, parser.CasmEntryPoint{Offset: 1}, calldata, 1000)
+	if err != nil {
+		t.Fatalf("NewCairo1Runner failed with error: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize failed with error: %s", err)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC failed with error: %s", err)
+	}
+	result, err := runner.GetCairo1Result()
+	if err != nil {
+		t.Fatalf("GetCairo1Result failed with error: %s", err)
+	}
+	return result
+}
+
+func TestCairo1RunnerReturnValues(t *testing.T) {
+	calldata := []lambdaworks.Felt{lambdaworks.FeltFromUint64(3), lambdaworks.FeltFromUint64(7)}
+	result := runCairo1(t, echoProgram(t, 0), calldata)
+	expected := runners.Cairo1Result{Gas: 1000, Retdata: calldata}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Wrong result. Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestCairo1RunnerPanic(t *testing.T) {
+	calldata := []lambdaworks.Felt{lambdaworks.FeltFromUint64(5)}
+	result := runCairo1(t, echoProgram(t, 1), calldata)
+	if !result.Failed || !reflect.DeepEqual(result.Retdata, calldata) {
+		t.Errorf("Expected a panic with the calldata as reason, got %+v", result)
+	}
+}
+
+func TestCairo1RunnerShiftsHints(t *testing.T) {
+	program := echoProgram(t, 0)
+	program.Cairo1Hints = map[uint][]parser.CasmHint{1: {{Name: "AllocSegment"}}}
+	runner, err := runners.NewCairo1Runner(program, parser.CasmEntryPoint{Offset: 1}, nil, 0)
+	if err != nil {
+		t.Fatalf("NewCairo1Runner failed with error: %s", err)
+	}
+	entryCodeSize := uint(len(runner.Program.Data) - len(program.Data))
+	if _, ok := runner.Program.Cairo1Hints[1+entryCodeSize]; !ok || len(runner.Program.Cairo1Hints) != 1 {
+		t.Errorf("Hints should be shifted by the entry code size %d, got %v", entryCodeSize, runner.Program.Cairo1Hints)
+	}
+}
+
+func TestCairo1RunnerInvalidOffset(t *testing.T) {
+	_, err := runners.NewCairo1Runner(echoProgram(t, 0), parser.CasmEntryPoint{Offset: 100}, nil, 0)
+	if err == nil {
+		t.Errorf("NewCairo1Runner should fail for an entry point out of the bytecode")
+	}
+}
+
+func TestGetCairo1ResultNotCairo1(t *testing.T) {
+	runner, err := runners.NewCairoRunner(echoProgram(t, 0), false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner failed with error: %s", err)
+	}
+	if _, err := runner.GetCairo1Result(); err == nil {
+		t.Errorf("GetCairo1Result should fail for a Cairo 0 run")
+	}
+}
+
+func TestCreateEntryCode(t *testing.T) {
+	code, err := runners.CreateEntryCode([]string{"range_check", "bitwise"}, 10, 4)
+	if err != nil {
+		t.Fatalf("CreateEntryCode failed with error: %s", err)
+	}
+	// One push per builtin, the gas push and its immediate, three pushes, the call and its offset, and ret
+	if len(code) != 10 {
+		t.Fatalf("Expected 10 words of entry code, got %d", len(code))
+	}
+	gas, _ := code[3].GetFelt()
+	target, _ := code[8].GetFelt()
+	if gas != lambdaworks.FeltFromUint64(10) || target != lambdaworks.FeltFromUint64(7) {
+		t.Errorf("Wrong immediates: gas %s, call offset %s", gas.String(), target.String())
+	}
+	// The call, at pc 7, jumps past the entry code to offset 4: 7 + 7 = 10 + 4
+	call, _ := code[7].GetFelt()
+	encoded, _ := call.ToU64()
+	instruction, err := vm.DecodeInstruction(encoded)
+	if err != nil || instruction.Opcode != vm.Call || instruction.PcUpdate != vm.PcUpdateJumpRel {
+		t.Errorf("Expected a relative call at pc 7, got %+v, err: %v", instruction, err)
+	}
+}
@@ -2,7 +2,10 @@ package runners
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 )
@@ -13,26 +16,51 @@ type CairoRunner struct {
 	ProgramBase   memory.Relocatable
 	executionBase memory.Relocatable
 	initialPc     memory.Relocatable
-	initialAp     memory.Relocatable
-	initialFp     memory.Relocatable
-	finalPc       memory.Relocatable
+	finalFp       memory.Relocatable
 	mainOffset    uint
+	// Layout the program runs on, its builtins were validated against it
+	Layout CairoLayout
+	// In proof mode the program runs from __start__ to __end__ and its trace is padded so it can be proven
+	ProofMode bool
+	// Offsets of the execution segment that belong to the public memory (proof mode only): the
+	// initial stack, and the builtin pointers main returns once read by ReadReturnValues
+	executionPublicMemory []uint
+	segmentsFinalized     bool
+	// Set when running a Cairo 1 entry point, see NewCairo1Runner
+	cairo1 *cairo1Entrypoint
+	// Cells reserved for the execution segment when it is created. It is only a hint, the
+	// segment still grows past it
+	ExecutionCapacity uint
 }
 
-func NewCairoRunner(program vm.Program) (*CairoRunner, error) {
-	mainIdentifier, ok := (*program.Identifiers)["__main__.main"]
+// Creates a runner for the program on the plain layout, see NewCairoRunnerWithLayout
+func NewCairoRunner(program vm.Program, proofMode bool) (*CairoRunner, error) {
+	return NewCairoRunnerWithLayout(program, "plain", proofMode)
+}
+
+// Creates a runner for the program on the given layout. Fails if the program's builtins aren't
+// declared in the canonical order or aren't all present in the layout
+func NewCairoRunnerWithLayout(program vm.Program, layoutName string, proofMode bool) (*CairoRunner, error) {
+	layout, err := GetLayout(layoutName)
+	if err != nil {
+		return nil, err
+	}
+	if err := layout.ValidateBuiltins(program.Builtins); err != nil {
+		return nil, err
+	}
 	main_offset := uint(0)
-	if ok {
-		main_offset = uint(mainIdentifier.PC)
+	if program.MainEntrypoint != nil {
+		main_offset = program.MainEntrypoint.PC
+	} else if main, err := program.GetEntrypoint("main"); err == nil {
+		main_offset = main.PC
 	}
-	runner := CairoRunner{Program: program, Vm: *vm.NewVirtualMachine(), mainOffset: main_offset}
+	runner := CairoRunner{Program: program, Vm: *vm.NewVirtualMachine(), mainOffset: main_offset, ProofMode: proofMode, Layout: layout}
 	for _, builtin_name := range program.Builtins {
 		switch builtin_name {
-		// Add a case for each builtin here, example:
-		// case "range_check":
-		// 	runner.Vm.BuiltinRunners = append(runner.Vm.BuiltinRunners, RangeCheckBuiltin{})
+		case "output":
+			runner.Vm.BuiltinRunners = append(runner.Vm.BuiltinRunners, builtins.NewOutputBuiltinRunner())
 		default:
-			return nil, errors.New("Invalid builtin")
+			return nil, fmt.Errorf("Builtin %s is not supported yet", builtin_name)
 		}
 	}
 
@@ -49,12 +77,49 @@ func (r *CairoRunner) Initialize() (memory.Relocatable, error) {
 	return end, err
 }
 
+// Performs the initialization step to run the given function with args, passed after the builtins
+// it takes. Args are converted to memory values by MemorySegmentManager.GenArg. Returns the end
+// pointer (pc upon which execution should stop)
+func (r *CairoRunner) InitializeFromEntrypoint(entrypoint vm.Entrypoint, args []any) (memory.Relocatable, error) {
+	if r.ProofMode || r.cairo1 != nil {
+		return memory.Relocatable{}, errors.New("Only plain Cairo 0 runs can start from an entrypoint")
+	}
+	r.initializeSegments()
+	stack := make([]memory.MaybeRelocatable, 0, len(entrypoint.Builtins)+len(args)+2)
+	for _, name := range entrypoint.Builtins {
+		found := false
+		for i := range r.Vm.BuiltinRunners {
+			if r.Vm.BuiltinRunners[i].Name() == name {
+				stack = append(stack, r.Vm.BuiltinRunners[i].InitialStack()...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return memory.Relocatable{}, fmt.Errorf("Builtin %s of %s is not used by the program", name, entrypoint.Name)
+		}
+	}
+	for i, arg := range args {
+		value, err := r.Vm.Segments.GenArg(arg)
+		if err != nil {
+			return memory.Relocatable{}, fmt.Errorf("Invalid argument %d: %w", i, err)
+		}
+		stack = append(stack, value)
+	}
+	return_fp := r.Vm.Segments.AddSegment()
+	end, err := r.initializeFunctionEntrypoint(entrypoint.PC, &stack, return_fp)
+	if err == nil {
+		err = r.initializeVM()
+	}
+	return end, err
+}
+
 // Creates program, execution and builtin segments
 func (r *CairoRunner) initializeSegments() {
-	// Program Segment
-	r.ProgramBase = r.Vm.Segments.AddSegment()
+	// Program Segment, its size is known beforehand
+	r.ProgramBase = r.Vm.Segments.AddSegmentWithCapacity(uint(len(r.Program.Data)))
 	// Execution Segment
-	r.executionBase = r.Vm.Segments.AddSegment()
+	r.executionBase = r.Vm.Segments.AddSegmentWithCapacity(r.ExecutionCapacity)
 	// Builtin Segments
 	for i := range r.Vm.BuiltinRunners {
 		r.Vm.BuiltinRunners[i].InitializeSegments(&r.Vm.Segments)
@@ -66,9 +131,12 @@ func (r *CairoRunner) initializeState(entrypoint uint, stack *[]memory.MaybeRelo
 	r.initialPc = r.ProgramBase
 	r.initialPc.Offset += entrypoint
 	// Load program data
-	_, err := r.Vm.Segments.LoadData(r.ProgramBase, &r.Program.Data)
+	_, err := r.Vm.Segments.LoadData(r.ProgramBase, r.Program.Data)
 	if err == nil {
-		_, err = r.Vm.Segments.LoadData(r.executionBase, stack)
+		err = r.Vm.LoadDecodedProgram(r.ProgramBase, vm.DecodeProgram(r.Program.Data))
+	}
+	if err == nil {
+		_, err = r.Vm.Segments.LoadData(r.executionBase, *stack)
 	}
 	// Mark data segment as accessed
 	return err
@@ -79,15 +147,20 @@ func (r *CairoRunner) initializeState(entrypoint uint, stack *[]memory.MaybeRelo
 func (r *CairoRunner) initializeFunctionEntrypoint(entrypoint uint, stack *[]memory.MaybeRelocatable, return_fp memory.Relocatable) (memory.Relocatable, error) {
 	end := r.Vm.Segments.AddSegment()
 	*stack = append(*stack, *memory.NewMaybeRelocatableRelocatable(return_fp), *memory.NewMaybeRelocatableRelocatable(end))
-	r.initialFp = r.executionBase
-	r.initialFp.Offset += uint(len(*stack))
-	r.initialAp = r.initialFp
-	r.finalPc = end
+	r.Vm.RunContext.InitialFp = r.executionBase
+	r.Vm.RunContext.InitialFp.Offset += uint(len(*stack))
+	r.Vm.RunContext.InitialAp = r.Vm.RunContext.InitialFp
+	r.Vm.RunContext.FinalPc = end
+	// The function returns to the frame of return_fp
+	r.finalFp = return_fp
 	return end, r.initializeState(entrypoint, stack)
 }
 
 // Initializes memory, initial register values & returns the end pointer (final pc) to run from the main entrypoint
 func (r *CairoRunner) initializeMainEntrypoint() (memory.Relocatable, error) {
+	if r.cairo1 != nil {
+		return r.initializeCairo1Entrypoint()
+	}
 	// When running from main entrypoint, only up to 11 values will be written (9 builtin bases + end + return_fp)
 	stack := make([]memory.MaybeRelocatable, 0, 11)
 	// Append builtins initial stack to stack
@@ -96,15 +169,55 @@ func (r *CairoRunner) initializeMainEntrypoint() (memory.Relocatable, error) {
 			stack = append(stack, val)
 		}
 	}
-	// Handle proof-mode specific behaviour
+	if r.ProofMode {
+		return r.initializeProofModeEntrypoint(stack)
+	}
 	return_fp := r.Vm.Segments.AddSegment()
 	return r.initializeFunctionEntrypoint(r.mainOffset, &stack, return_fp)
 }
 
+// Initializes memory & initial register values to run the program from __start__ in proof mode,
+// returns the pc of __end__, where the program loops forever
+func (r *CairoRunner) initializeProofModeEntrypoint(stack []memory.MaybeRelocatable) (memory.Relocatable, error) {
+	start, err := r.getLabel("__main__.__start__")
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	end, err := r.getLabel("__main__.__end__")
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	stackPrefix := r.Vm.RunContext.InitializeProofModeFrame(r.executionBase, stack)
+	r.executionPublicMemory = make([]uint, 0, len(stackPrefix))
+	for i := range stackPrefix {
+		r.executionPublicMemory = append(r.executionPublicMemory, uint(i))
+	}
+	if err := r.initializeState(start, &stackPrefix); err != nil {
+		return memory.Relocatable{}, err
+	}
+	r.Vm.RunContext.FinalPc = r.ProgramBase
+	r.Vm.RunContext.FinalPc.Offset += end
+	// __start__ returns from main to its own frame before reaching __end__
+	r.finalFp = r.Vm.RunContext.InitialFp
+	return r.Vm.RunContext.FinalPc, nil
+}
+
+// Returns the pc of the label with the given full name
+func (r *CairoRunner) getLabel(name string) (uint, error) {
+	identifier, err := r.Program.GetIdentifier(name)
+	if err != nil {
+		return 0, fmt.Errorf("Missing %s label, required to run in proof mode", name)
+	}
+	if identifier.Type != parser.IdentifierLabel {
+		return 0, fmt.Errorf("Identifier %s is not a label", name)
+	}
+	return uint(identifier.PC), nil
+}
+
 // Initializes the vm's run_context, adds builtin validation rules & validates memory
 func (r *CairoRunner) initializeVM() error {
-	r.Vm.RunContext.Ap = r.initialAp
-	r.Vm.RunContext.Fp = r.initialFp
+	r.Vm.RunContext.Ap = r.Vm.RunContext.InitialAp
+	r.Vm.RunContext.Fp = r.Vm.RunContext.InitialFp
 	r.Vm.RunContext.Pc = r.initialPc
 	// Add validation rules
 	for i := range r.Vm.BuiltinRunners {
@@ -114,6 +227,21 @@ func (r *CairoRunner) initializeVM() error {
 	return r.Vm.Segments.Memory.ValidateExistingMemory()
 }
 
+// Returns the ap the run started with
+func (r *CairoRunner) GetInitialAp() memory.Relocatable {
+	return r.Vm.RunContext.InitialAp
+}
+
+// Returns the fp the run started with
+func (r *CairoRunner) GetInitialFp() memory.Relocatable {
+	return r.Vm.RunContext.InitialFp
+}
+
+// Returns the pc the run ends at
+func (r *CairoRunner) GetFinalPc() memory.Relocatable {
+	return r.Vm.RunContext.FinalPc
+}
+
 func (r *CairoRunner) RunUntilPC(end memory.Relocatable) error {
 	for r.Vm.RunContext.Pc != end {
 		err := r.Vm.Step()
@@ -121,5 +249,165 @@ func (r *CairoRunner) RunUntilPC(end memory.Relocatable) error {
 			return err
 		}
 	}
+	// Report the overwrites recorded if running in relaxed write mode
+	return r.Vm.Segments.Memory.CheckWriteViolations()
+}
+
+// Runs the given number of steps, regardless of the pc
+func (r *CairoRunner) RunForSteps(steps uint) error {
+	for i := uint(0); i < steps; i++ {
+		if err := r.Vm.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs until the number of steps executed is a power of two
+func (r *CairoRunner) RunUntilNextPowerOf2() error {
+	for !isPowerOf2(r.Vm.CurrentStep) {
+		if err := r.Vm.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads the final pointers the program returned for its builtins, which lie right before
+// stackPtr in the order the builtins were received, and checks each of them against the cells its
+// builtin used. Returns the address of the first of them
+func (r *CairoRunner) GetBuiltinsFinalStack(stackPtr memory.Relocatable) (memory.Relocatable, error) {
+	var err error
+	for i := len(r.Vm.BuiltinRunners) - 1; i >= 0; i-- {
+		stackPtr, err = r.Vm.BuiltinRunners[i].FinalStack(&r.Vm.Segments, stackPtr)
+		if err != nil {
+			return memory.Relocatable{}, err
+		}
+	}
+	return stackPtr, nil
+}
+
+// Checks the final builtin pointers main returned, which lie right before ap once the run ended.
+// In proof mode, the cells holding them become public, so it must be called before FinalizeSegments
+func (r *CairoRunner) ReadReturnValues() error {
+	if r.segmentsFinalized {
+		return errors.New("Cannot read the return values once the segments are finalized")
+	}
+	pointer, err := r.GetBuiltinsFinalStack(r.Vm.RunContext.Ap)
+	if err != nil {
+		return err
+	}
+	if r.ProofMode {
+		for offset := pointer.Offset; offset < r.Vm.RunContext.Ap.Offset; offset++ {
+			r.executionPublicMemory = append(r.executionPublicMemory, offset-r.executionBase.Offset)
+		}
+	}
+	return nil
+}
+
+func isPowerOf2(n uint) bool {
+	return n != 0 && n&(n-1) == 0
+}
+
+// Finishes the run after the end pc was reached. Temporary segments are moved into their
+// destination first, see Memory.RelocateMemory. In proof mode, the trace is padded until its
+// length is a power of two and the layout's range check units suffice, as required by the prover.
+// Builtin ratios aren't modelled by the layouts yet, so the padding doesn't account for the cells
+// used by builtins.
+// Fails with an EndStateError if the registers aren't where the program should have ended
+func (r *CairoRunner) EndRun() error {
+	if err := r.Vm.Segments.Memory.RelocateMemory(); err != nil {
+		return err
+	}
+	if r.ProofMode {
+		if err := r.RunUntilNextPowerOf2(); err != nil {
+			return err
+		}
+		// Each step brings range check units, keep doubling the trace until there are enough
+		for r.CheckRangeCheckUsage() != nil {
+			if err := r.RunForSteps(1); err != nil {
+				return err
+			}
+			if err := r.RunUntilNextPowerOf2(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.verifyEndState(); err != nil {
+		return err
+	}
+	r.Vm.Segments.ComputeEffectiveSizes()
+	return nil
+}
+
+// A register holding an unexpected value once the run ended, e.g. because the run stopped before
+// reaching the end of the program. Ap can't be known beforehand, Expected is then the lowest
+// address it can end at
+type EndStateError struct {
+	Register string
+	Expected memory.Relocatable
+	Got      memory.Relocatable
+}
+
+func (e *EndStateError) Error() string {
+	if e.Register == "ap" {
+		return fmt.Sprintf("Run ended with ap at %d:%d, expected it at or past %d:%d",
+			e.Got.SegmentIndex, e.Got.Offset, e.Expected.SegmentIndex, e.Expected.Offset)
+	}
+	return fmt.Sprintf("Run ended with %s at %d:%d instead of %d:%d",
+		e.Register, e.Got.SegmentIndex, e.Got.Offset, e.Expected.SegmentIndex, e.Expected.Offset)
+}
+
+// Checks that pc is at the end of the program, that fp is back to the frame the program returns
+// to and that ap didn't move below where it started
+func (r *CairoRunner) verifyEndState() error {
+	context := r.Vm.RunContext
+	if context.Pc != context.FinalPc {
+		return &EndStateError{Register: "pc", Expected: context.FinalPc, Got: context.Pc}
+	}
+	if context.Fp != r.finalFp {
+		return &EndStateError{Register: "fp", Expected: r.finalFp, Got: context.Fp}
+	}
+	if context.Ap.SegmentIndex != context.InitialAp.SegmentIndex || context.Ap.Offset < context.InitialAp.Offset {
+		return &EndStateError{Register: "ap", Expected: context.InitialAp, Got: context.Ap}
+	}
+	return nil
+}
+
+// Declares the final size and public memory of the program and execution segments.
+// Only available in proof mode, must be called after EndRun and before relocating the memory
+func (r *CairoRunner) FinalizeSegments() error {
+	if r.segmentsFinalized {
+		return nil
+	}
+	if !r.ProofMode {
+		return errors.New("FinalizeSegments is only available in proof mode")
+	}
+	// The whole program is public
+	programSize := uint(len(r.Program.Data))
+	programPublicMemory := make([]memory.PublicMemoryOffset, 0, programSize)
+	for i := uint(0); i < programSize; i++ {
+		programPublicMemory = append(programPublicMemory, memory.PublicMemoryOffset{Offset: i, Page: 0})
+	}
+	r.Vm.Segments.Finalize(uint(r.ProgramBase.SegmentIndex), &programSize, programPublicMemory)
+	// Only the initial stack of the execution segment and the builtin pointers returned are public
+	executionPublicMemory := make([]memory.PublicMemoryOffset, 0, len(r.executionPublicMemory))
+	for _, offset := range r.executionPublicMemory {
+		executionPublicMemory = append(executionPublicMemory, memory.PublicMemoryOffset{Offset: offset, Page: 0})
+	}
+	r.Vm.Segments.Finalize(uint(r.executionBase.SegmentIndex), nil, executionPublicMemory)
+	// The output is public, split into its pages
+	for i := range r.Vm.BuiltinRunners {
+		output, ok := r.Vm.BuiltinRunners[i].(*builtins.OutputBuiltinRunner)
+		if !ok {
+			continue
+		}
+		outputPublicMemory, err := output.GetPublicMemory(&r.Vm.Segments)
+		if err != nil {
+			return err
+		}
+		r.Vm.Segments.Finalize(uint(output.Base().SegmentIndex), nil, outputPublicMemory)
+	}
+	r.segmentsFinalized = true
 	return nil
 }
@@ -1,6 +1,8 @@
 package runners_test
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
@@ -15,9 +17,9 @@ func TestNewCairoRunnerInvalidBuiltin(t *testing.T) {
 	program_data := make([]memory.MaybeRelocatable, 1)
 	empty_identifiers := make(map[string]parser.Identifier, 0)
 	program_data[0] = *memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne())
-	program := vm.Program{Data: program_data, Builtins: []string{"fake_builtin"}, Identifiers: &empty_identifiers}
+	program := vm.Program{Data: program_data, Builtins: []string{"fake_builtin"}, Identifiers: empty_identifiers}
 	// Create CairoRunner
-	_, err := runners.NewCairoRunner(program)
+	_, err := runners.NewCairoRunner(program, false)
 	if err == nil {
 		t.Errorf("Expected creating a CairoRunner with fake builtin to fail")
 	}
@@ -26,9 +28,9 @@ func TestInitializeRunnerNoBuiltinsNoProofModeEmptyProgram(t *testing.T) {
 	// Create a Program with empty data
 	program_data := make([]memory.MaybeRelocatable, 0)
 	empty_identifiers := make(map[string]parser.Identifier, 0)
-	program := vm.Program{Data: program_data, Identifiers: &empty_identifiers}
+	program := vm.Program{Data: program_data, Identifiers: empty_identifiers}
 	// Create CairoRunner
-	runner, err := runners.NewCairoRunner(program)
+	runner, err := runners.NewCairoRunner(program, false)
 	if err != nil {
 		t.Errorf("NewCairoRunner error in test: %s", err)
 	}
@@ -92,9 +94,9 @@ func TestInitializeRunnerNoBuiltinsNoProofModeNonEmptyProgram(t *testing.T) {
 	program_data := make([]memory.MaybeRelocatable, 1)
 	program_data[0] = *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1))
 	empty_identifiers := make(map[string]parser.Identifier, 0)
-	program := vm.Program{Data: program_data, Identifiers: &empty_identifiers}
+	program := vm.Program{Data: program_data, Identifiers: empty_identifiers}
 	// Create CairoRunner
-	runner, err := runners.NewCairoRunner(program)
+	runner, err := runners.NewCairoRunner(program, false)
 	if err != nil {
 		t.Errorf("NewCairoRunner error in test: %s", err)
 	}
@@ -156,3 +158,281 @@ func TestInitializeRunnerNoBuiltinsNoProofModeNonEmptyProgram(t *testing.T) {
 		t.Errorf("Wrong value for address 1:1: %d", rel)
 	}
 }
+
+// A program made of a single `jmp rel 0` instruction, labeled as both __start__ and __end__
+func proofModeProgram() vm.Program {
+	program_data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x10780017fff7fff")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+	}
+	identifiers := map[string]parser.Identifier{
+		"__main__.__start__": {Type: parser.IdentifierLabel, PC: 0},
+		"__main__.__end__":   {Type: parser.IdentifierLabel, PC: 0},
+	}
+	return vm.Program{Data: program_data, Identifiers: identifiers}
+}
+
+func TestInitializeRunnerProofMode(t *testing.T) {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), true)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end_ptr, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if end_ptr.SegmentIndex != 0 || end_ptr.Offset != 0 {
+		t.Errorf("Wrong end ptr value, got %+v", end_ptr)
+	}
+	// Ap and Fp point right after the dummy frame
+	if runner.Vm.RunContext.Ap != (memory.Relocatable{SegmentIndex: 1, Offset: 2}) || runner.Vm.RunContext.Fp != runner.Vm.RunContext.Ap {
+		t.Errorf("Wrong Ap/Fp values, got %+v/%+v", runner.Vm.RunContext.Ap, runner.Vm.RunContext.Fp)
+	}
+	returnFp, err := runner.Vm.Segments.Memory.GetRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 0})
+	if err != nil || returnFp != (memory.Relocatable{SegmentIndex: 1, Offset: 2}) {
+		t.Errorf("Wrong value for address 1:0: %+v, err: %v", returnFp, err)
+	}
+	returnPc, err := runner.Vm.Segments.Memory.GetFelt(memory.Relocatable{SegmentIndex: 1, Offset: 1})
+	if err != nil || !returnPc.IsZero() {
+		t.Errorf("Wrong value for address 1:1: %v, err: %v", returnPc, err)
+	}
+	if runner.GetInitialAp() != runner.Vm.RunContext.Ap || runner.GetInitialFp() != runner.Vm.RunContext.Fp || runner.GetFinalPc() != end_ptr {
+		t.Errorf("Wrong initial registers %+v/%+v or final pc %+v", runner.GetInitialAp(), runner.GetInitialFp(), runner.GetFinalPc())
+	}
+}
+
+func TestInitializeRunnerProofModeMissingLabels(t *testing.T) {
+	program := proofModeProgram()
+	delete(program.Identifiers, "__main__.__end__")
+	runner, err := runners.NewCairoRunner(program, true)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	if _, err := runner.Initialize(); err == nil {
+		t.Errorf("Initialize should fail without an __end__ label in proof mode")
+	}
+}
+
+func TestEndRunProofModePadsTrace(t *testing.T) {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), true)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC error in test: %s", err)
+	}
+	if err := runner.RunForSteps(3); err != nil {
+		t.Fatalf("RunForSteps error in test: %s", err)
+	}
+	if err := runner.EndRun(); err != nil {
+		t.Fatalf("EndRun error in test: %s", err)
+	}
+	if runner.Vm.CurrentStep != 4 || len(runner.Vm.Trace) != 4 {
+		t.Errorf("The trace should have been padded to 4 steps, got %d", runner.Vm.CurrentStep)
+	}
+}
+
+func TestEndRunBeforeTheEnd(t *testing.T) {
+	// [ap] = 5; ap++, ret
+	program_data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x480680017fff8000)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe)),
+	}
+	runner, err := runners.NewCairoRunner(vm.Program{Data: program_data, Identifiers: map[string]parser.Identifier{}}, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	if _, err := runner.Initialize(); err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.RunForSteps(1); err != nil {
+		t.Fatalf("RunForSteps error in test: %s", err)
+	}
+	var endState *runners.EndStateError
+	err = runner.EndRun()
+	if !errors.As(err, &endState) || endState.Register != "pc" || endState.Got != memory.NewRelocatable(0, 2) || endState.Expected != memory.NewRelocatable(3, 0) {
+		t.Fatalf("Expected EndRun to fail on pc, got %v", err)
+	}
+	if err.Error() != "Run ended with pc at 0:2 instead of 3:0" {
+		t.Errorf("Wrong error message: %s", err)
+	}
+}
+
+func TestEndRunUnexpectedRegisters(t *testing.T) {
+	runner := finishedRunner(t)
+	final := runner.Vm.RunContext
+
+	var endState *runners.EndStateError
+	runner.Vm.RunContext.Fp.Offset += 1
+	if err := runner.EndRun(); !errors.As(err, &endState) || endState.Register != "fp" || endState.Expected != final.Fp {
+		t.Errorf("Expected EndRun to fail on fp, got %v", err)
+	}
+	runner.Vm.RunContext = final
+	runner.Vm.RunContext.Ap = memory.NewRelocatable(1, 1)
+	err := runner.EndRun()
+	if !errors.As(err, &endState) || endState.Register != "ap" || endState.Expected != memory.NewRelocatable(1, 2) {
+		t.Fatalf("Expected EndRun to fail on ap, got %v", err)
+	}
+	if err.Error() != "Run ended with ap at 1:1, expected it at or past 1:2" {
+		t.Errorf("Wrong error message: %s", err)
+	}
+	runner.Vm.RunContext = final
+	if err := runner.EndRun(); err != nil {
+		t.Errorf("EndRun failed with error: %s", err)
+	}
+}
+
+func TestFinalizeSegmentsProofMode(t *testing.T) {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), true)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Fatalf("RunUntilPC error in test: %s", err)
+	}
+	if err := runner.EndRun(); err != nil {
+		t.Fatalf("EndRun error in test: %s", err)
+	}
+	if err := runner.FinalizeSegments(); err != nil {
+		t.Fatalf("FinalizeSegments error in test: %s", err)
+	}
+	if size := runner.Vm.Segments.GetSegmentSize(0); size != 2 {
+		t.Errorf("Wrong program segment size, got %d", size)
+	}
+	expected := []memory.PublicMemoryOffset{{Offset: 0, Page: 0}, {Offset: 1, Page: 0}}
+	if !reflect.DeepEqual(runner.Vm.Segments.PublicMemoryOffsets[0], expected) {
+		t.Errorf("Wrong program public memory, got %+v", runner.Vm.Segments.PublicMemoryOffsets[0])
+	}
+	if !reflect.DeepEqual(runner.Vm.Segments.PublicMemoryOffsets[1], expected) {
+		t.Errorf("Wrong execution public memory, got %+v", runner.Vm.Segments.PublicMemoryOffsets[1])
+	}
+}
+
+func TestFinalizeSegmentsNotProofMode(t *testing.T) {
+	runner, err := runners.NewCairoRunner(proofModeProgram(), false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	if err := runner.FinalizeSegments(); err == nil {
+		t.Errorf("FinalizeSegments should fail outside of proof mode")
+	}
+}
+
+func TestInitializeFromEntrypoint(t *testing.T) {
+	// [ap] = [fp - 4] + [fp - 3]; ap++, ret
+	program_data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x482a7ffd7ffc8000)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe)),
+	}
+	program := vm.Program{Data: program_data, Identifiers: map[string]parser.Identifier{}}
+	runner, err := runners.NewCairoRunner(program, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	args := []any{lambdaworks.FeltFromUint64(3), []lambdaworks.Felt{lambdaworks.FeltOne()}}
+	end, err := runner.InitializeFromEntrypoint(vm.Entrypoint{Name: "__main__.add"}, args)
+	if err != nil {
+		t.Fatalf("InitializeFromEntrypoint error in test: %s", err)
+	}
+	// The stack holds the two args, return_fp and end
+	if runner.Vm.RunContext.Fp.Offset != 4 || runner.Vm.RunContext.Ap != runner.Vm.RunContext.Fp {
+		t.Errorf("Wrong initial registers: %+v", runner.Vm.RunContext)
+	}
+	first, err := runner.Vm.Segments.Memory.Get(memory.NewRelocatable(1, 0))
+	if err != nil || *first != *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)) {
+		t.Errorf("The first argument should be at the start of the stack, got %v, err: %v", first, err)
+	}
+	if err := runner.RunUntilPC(end); err != nil {
+		t.Errorf("RunUntilPC error in test: %s", err)
+	}
+}
+
+func TestInitializeFromEntrypointErrors(t *testing.T) {
+	program := vm.Program{Data: []memory.MaybeRelocatable{}, Identifiers: map[string]parser.Identifier{}}
+	runner, _ := runners.NewCairoRunner(program, false)
+	if _, err := runner.InitializeFromEntrypoint(vm.Entrypoint{Name: "f", Builtins: []string{"output"}}, nil); err == nil {
+		t.Errorf("InitializeFromEntrypoint should fail for a builtin the program doesn't use")
+	}
+	runner, _ = runners.NewCairoRunner(program, true)
+	if _, err := runner.InitializeFromEntrypoint(vm.Entrypoint{Name: "f"}, nil); err == nil {
+		t.Errorf("InitializeFromEntrypoint should fail in proof mode")
+	}
+}
+
+func TestGetBuiltinsFinalStack(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(outputProgram(t), "small", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err == nil {
+		err = runner.EndRun()
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	// The program output two values, return the output pointer right after them
+	returnAddr, _ := runner.Vm.RunContext.Ap.AddUint(3)
+	stopPointer := memory.NewRelocatable(runner.Vm.BuiltinRunners[0].Base().SegmentIndex, 2)
+	if err := runner.Vm.Segments.Memory.Insert(returnAddr, memory.NewMaybeRelocatableRelocatable(stopPointer)); err != nil {
+		t.Fatal(err)
+	}
+	stackPtr, _ := returnAddr.AddUint(1)
+	if pointer, err := runner.GetBuiltinsFinalStack(stackPtr); err != nil || pointer != returnAddr {
+		t.Errorf("Expected the builtins final stack to start at %+v, got %+v, err: %v", returnAddr, pointer, err)
+	}
+	if _, err := runner.GetBuiltinsFinalStack(returnAddr); err == nil {
+		t.Errorf("Reading the output pointer from a cell holding a felt should fail")
+	}
+}
+
+func TestReadReturnValuesProofModePublicMemory(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(outputProgram(t), "small", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err == nil {
+		err = runner.EndRun()
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	// Return the output pointer right after the two values output, past the values written by
+	// the program
+	returnAddr, _ := runner.Vm.RunContext.Ap.AddUint(3)
+	stopPointer := memory.NewRelocatable(runner.Vm.BuiltinRunners[0].Base().SegmentIndex, 2)
+	if err := runner.Vm.Segments.Memory.Insert(returnAddr, memory.NewMaybeRelocatableRelocatable(stopPointer)); err != nil {
+		t.Fatal(err)
+	}
+	runner.Vm.RunContext.Ap, _ = returnAddr.AddUint(1)
+	if err := runner.ReadReturnValues(); err != nil {
+		t.Fatalf("ReadReturnValues error in test: %s", err)
+	}
+	if err := runner.FinalizeSegments(); err != nil {
+		t.Fatalf("FinalizeSegments error in test: %s", err)
+	}
+	// The dummy frame, the output base and the returned output pointer are public
+	expected := []memory.PublicMemoryOffset{{Offset: 0}, {Offset: 1}, {Offset: 2}, {Offset: returnAddr.Offset}}
+	if publicMemory := runner.Vm.Segments.PublicMemoryOffsets[uint(returnAddr.SegmentIndex)]; !reflect.DeepEqual(publicMemory, expected) {
+		t.Errorf("Wrong execution public memory, expected %+v, got %+v", expected, publicMemory)
+	}
+	if err := runner.ReadReturnValues(); err == nil {
+		t.Errorf("Reading the return values after finalizing the segments should fail")
+	}
+}
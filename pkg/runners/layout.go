@@ -0,0 +1,74 @@
+package runners
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+)
+
+// The builtins available in a layout, and the range check units it allocates per step. The cell
+// ratios and instance sizes used to size the builtin segments aren't modelled, as the builtins
+// themselves aren't implemented yet
+type CairoLayout struct {
+	Name     string
+	Builtins []string
+	RcUnits  uint
+}
+
+var layouts = map[string]CairoLayout{
+	"plain":                  {Name: "plain", Builtins: []string{}, RcUnits: 16},
+	"small":                  {Name: "small", Builtins: []string{"output", "pedersen", "range_check", "ecdsa"}, RcUnits: 16},
+	"dex":                    {Name: "dex", Builtins: []string{"output", "pedersen", "range_check", "ecdsa"}, RcUnits: 4},
+	"recursive":              {Name: "recursive", Builtins: []string{"output", "pedersen", "range_check", "bitwise"}, RcUnits: 4},
+	"starknet":               {Name: "starknet", Builtins: []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op", "poseidon"}, RcUnits: 4},
+	"starknet_with_keccak":   {Name: "starknet_with_keccak", Builtins: []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op", "keccak", "poseidon"}, RcUnits: 4},
+	"recursive_large_output": {Name: "recursive_large_output", Builtins: []string{"output", "pedersen", "range_check", "bitwise", "poseidon"}, RcUnits: 4},
+	"all_solidity":           {Name: "all_solidity", Builtins: []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op"}, RcUnits: 8},
+	"all_cairo":              {Name: "all_cairo", Builtins: []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op", "keccak", "poseidon"}, RcUnits: 4},
+	"dynamic":                {Name: "dynamic", Builtins: []string{"output", "pedersen", "range_check", "ecdsa", "bitwise", "ec_op", "keccak", "poseidon"}, RcUnits: 16},
+}
+
+// Returns the layout with the given name
+func GetLayout(name string) (CairoLayout, error) {
+	layout, ok := layouts[name]
+	if !ok {
+		return CairoLayout{}, fmt.Errorf("Invalid layout %s", name)
+	}
+	return layout, nil
+}
+
+// Checks that builtins are known, declared in the canonical order (without repetitions) and all
+// present in the layout
+func (l *CairoLayout) ValidateBuiltins(programBuiltins []string) error {
+	next := 0
+	for _, name := range programBuiltins {
+		position := indexOf(builtins.BuiltinNames, name)
+		if position < 0 {
+			return fmt.Errorf("Invalid builtin %s", name)
+		}
+		if position < next {
+			return fmt.Errorf("Given builtins are not in appropriate order, expected a subsequence of %v, got %v", builtins.BuiltinNames, programBuiltins)
+		}
+		next = position + 1
+	}
+	missing := make([]string, 0)
+	for _, name := range programBuiltins {
+		if indexOf(l.Builtins, name) < 0 {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Builtin %s not present in layout %s", strings.Join(missing, ", "), l.Name)
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i := range names {
+		if names[i] == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package runners_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+func TestNewCairoRunnerWithLayoutBuiltinErrors(t *testing.T) {
+	cases := []struct {
+		layout   string
+		builtins []string
+		err      string
+	}{
+		{"plain", []string{"output"}, "Builtin output not present in layout plain"},
+		{"small", []string{"output", "pedersen", "bitwise", "poseidon"}, "Builtin bitwise, poseidon not present in layout small"},
+		{"all_cairo", []string{"range_check", "output"}, "Given builtins are not in appropriate order, expected a subsequence of [output pedersen range_check ecdsa bitwise ec_op keccak poseidon range_check96 add_mod mul_mod], got [range_check output]"},
+		{"all_cairo", []string{"output", "output"}, "Given builtins are not in appropriate order, expected a subsequence of [output pedersen range_check ecdsa bitwise ec_op keccak poseidon range_check96 add_mod mul_mod], got [output output]"},
+		{"all_cairo", []string{"fake_builtin"}, "Invalid builtin fake_builtin"},
+		{"fake_layout", []string{}, "Invalid layout fake_layout"},
+	}
+	for _, c := range cases {
+		_, err := runners.NewCairoRunnerWithLayout(vm.Program{Builtins: c.builtins}, c.layout, false)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("Expected %v on layout %s to fail with %q, got %v", c.builtins, c.layout, c.err, err)
+		}
+	}
+}
+
+func TestNewCairoRunnerWithLayoutValidBuiltins(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(vm.Program{Builtins: []string{}}, "starknet", false)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	if runner.Layout.Name != "starknet" {
+		t.Errorf("Expected the runner to use the starknet layout, got %s", runner.Layout.Name)
+	}
+	// The builtins are valid for the layout, but can't run yet
+	_, err = runners.NewCairoRunnerWithLayout(vm.Program{Builtins: []string{"output", "range_check"}}, "small", false)
+	if err == nil || err.Error() != "Builtin range_check is not supported yet" {
+		t.Errorf("Expected unimplemented builtins to fail, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package runners
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// The range check units the layout allocates for the run can't fill the holes between rc_min and
+// rc_max, which the prover requires
+type InsufficientRangeCheckUnitsError struct {
+	Unused   uint
+	Required uint
+}
+
+func (e *InsufficientRangeCheckUnitsError) Error() string {
+	return fmt.Sprintf("There are only %d cells to fill the range checks holes, but potentially %d are required", e.Unused, e.Required)
+}
+
+// Returns rc_min and rc_max: the smallest and largest values the prover range checks, among the
+// biased offsets of the instructions run and the values of the builtins using range checks.
+// ok is false if nothing was range checked
+func (r *CairoRunner) GetRangeCheckLimits() (rcMin int, rcMax int, ok bool) {
+	rcMin, rcMax, ok = r.Vm.RangeCheckLimits()
+	return r.addBuiltinsRangeCheckUsage(rcMin, rcMax, ok)
+}
+
+// Same as GetRangeCheckLimits, but computes the limits of the instructions from the trace,
+// decoding the instruction each of its entries ran, as the public input of the prover does
+func (r *CairoRunner) GetPermRangeCheckLimits() (rcMin int, rcMax int, ok bool, err error) {
+	var stepErr error
+	err = r.Vm.RangeTrace(func(_ uint, entry vm.TraceEntry) bool {
+		var instruction vm.Instruction
+		instruction, stepErr = r.decodeInstructionAt(entry.Pc)
+		if stepErr != nil {
+			return false
+		}
+		for _, offset := range [3]int{instruction.Off0, instruction.Off1, instruction.Off2} {
+			offset += vm.OffsetBias
+			if !ok || offset < rcMin {
+				rcMin = offset
+			}
+			if !ok || offset > rcMax {
+				rcMax = offset
+			}
+			ok = true
+		}
+		return true
+	})
+	if err == nil {
+		err = stepErr
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	rcMin, rcMax, ok = r.addBuiltinsRangeCheckUsage(rcMin, rcMax, ok)
+	return rcMin, rcMax, ok, nil
+}
+
+func (r *CairoRunner) decodeInstructionAt(pc memory.Relocatable) (vm.Instruction, error) {
+	encodedInstruction, err := r.Vm.Segments.Memory.GetFelt(pc)
+	if err != nil {
+		return vm.Instruction{}, err
+	}
+	return vm.DecodeInstructionFelt(encodedInstruction)
+}
+
+// Widens the given limits to the values of the builtins using range checks
+func (r *CairoRunner) addBuiltinsRangeCheckUsage(rcMin int, rcMax int, ok bool) (int, int, bool) {
+	for _, builtin := range r.Vm.BuiltinRunners {
+		user, isUser := builtin.(builtins.RangeCheckUser)
+		if !isUser {
+			continue
+		}
+		builtinMin, builtinMax, used := user.GetRangeCheckUsage(&r.Vm.Segments.Memory)
+		if !used {
+			continue
+		}
+		if !ok || int(builtinMin) < rcMin {
+			rcMin = int(builtinMin)
+		}
+		if !ok || int(builtinMax) > rcMax {
+			rcMax = int(builtinMax)
+		}
+		ok = true
+	}
+	return rcMin, rcMax, ok
+}
+
+// Checks that the range check units of the layout, minus the ones holding the instructions'
+// offsets and the builtins' values, can fill every value between rc_min and rc_max.
+// Fails with an InsufficientRangeCheckUnitsError otherwise
+func (r *CairoRunner) CheckRangeCheckUsage() error {
+	rcMin, rcMax, ok := r.GetRangeCheckLimits()
+	if !ok {
+		return nil
+	}
+	usedByBuiltins := uint(0)
+	for _, builtin := range r.Vm.BuiltinRunners {
+		if user, isUser := builtin.(builtins.RangeCheckUser); isUser {
+			usedByBuiltins += user.GetUsedPermRangeCheckUnits(&r.Vm.Segments)
+		}
+	}
+	// Every step uses 3 units for the offsets of its instruction
+	unused := uint(0)
+	if allocated := (r.Layout.RcUnits - 3) * r.Vm.CurrentStep; allocated > usedByBuiltins {
+		unused = allocated - usedByBuiltins
+	}
+	if required := uint(rcMax - rcMin); unused < required {
+		return &InsufficientRangeCheckUnitsError{Unused: unused, Required: required}
+	}
+	return nil
+}
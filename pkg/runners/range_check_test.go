@@ -0,0 +1,101 @@
+package runners_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestGetRangeCheckLimits(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(outputProgram(t), "small", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	if _, _, ok := runner.GetRangeCheckLimits(); ok {
+		t.Errorf("Nothing should be range checked before the run")
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	// The offsets of the program range from -1 to 2
+	rcMin, rcMax, ok := runner.GetRangeCheckLimits()
+	if !ok || rcMin != vm.OffsetBias-1 || rcMax != vm.OffsetBias+2 {
+		t.Errorf("Wrong range check limits: %d, %d", rcMin, rcMax)
+	}
+}
+
+func TestGetPermRangeCheckLimits(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(wideOffsetsProgram(t), "dex", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	if _, _, ok, err := runner.GetPermRangeCheckLimits(); ok || err != nil {
+		t.Errorf("Expected no limits for an empty trace, got ok: %v, err: %v", ok, err)
+	}
+	if _, err := runner.Initialize(); err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.EndRun(); err != nil {
+		t.Fatalf("EndRun error in test: %s", err)
+	}
+	// The limits computed from the trace match the ones tracked during the run
+	rcMin, rcMax, ok, err := runner.GetPermRangeCheckLimits()
+	if err != nil || !ok || rcMin != vm.OffsetBias-1 || rcMax != vm.OffsetBias+300 {
+		t.Errorf("Wrong range check limits from the trace: %d, %d, err: %v", rcMin, rcMax, err)
+	}
+	if trackedMin, trackedMax, _ := runner.GetRangeCheckLimits(); trackedMin != rcMin || trackedMax != rcMax {
+		t.Errorf("Expected the tracked limits %d, %d to match the trace", trackedMin, trackedMax)
+	}
+}
+
+// jmp rel 0 with dst at [ap + 300], whose offsets range from -1 to 300
+func wideOffsetsProgram(t *testing.T) vm.Program {
+	instruction := vm.Instruction{Off0: 300, Off1: -1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateJumpRel, Opcode: vm.NOp}
+	encoded, err := instruction.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(encoded)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+	}
+	identifiers := map[string]parser.Identifier{
+		"__main__.__start__": {Type: parser.IdentifierLabel, PC: 0},
+		"__main__.__end__":   {Type: parser.IdentifierLabel, PC: 0},
+	}
+	return vm.Program{Data: data, Identifiers: identifiers, Builtins: []string{}}
+}
+
+func TestEndRunPadsUntilRangeCheckUnitsSuffice(t *testing.T) {
+	runner, err := runners.NewCairoRunnerWithLayout(wideOffsetsProgram(t), "dex", true)
+	if err != nil {
+		t.Fatalf("NewCairoRunnerWithLayout error in test: %s", err)
+	}
+	if _, err := runner.Initialize(); err != nil {
+		t.Fatalf("Initialize error in test: %s", err)
+	}
+	if err := runner.RunForSteps(1); err != nil {
+		t.Fatalf("RunForSteps error in test: %s", err)
+	}
+	// The dex layout has a single spare range check unit per step
+	var unitsErr *runners.InsufficientRangeCheckUnitsError
+	if err := runner.CheckRangeCheckUsage(); !errors.As(err, &unitsErr) || unitsErr.Unused != 1 || unitsErr.Required != 301 {
+		t.Errorf("Expected the range check units to be insufficient, got %v", err)
+	}
+	if err := runner.EndRun(); err != nil {
+		t.Fatalf("EndRun error in test: %s", err)
+	}
+	if runner.Vm.CurrentStep != 512 || runner.CheckRangeCheckUsage() != nil {
+		t.Errorf("Expected the trace to be padded to 512 steps, got %d", runner.Vm.CurrentStep)
+	}
+}
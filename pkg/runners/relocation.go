@@ -0,0 +1,53 @@
+package runners
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Returns the first relocated address of each segment, see VirtualMachine.GetRelocationTable.
+// It can be used once the run is finished, before or without relocating the whole memory
+func (r *CairoRunner) GetRelocationTable() ([]uint, error) {
+	return r.Vm.GetRelocationTable()
+}
+
+// Returns the address addr is relocated to
+func (r *CairoRunner) RelocateAddress(addr memory.Relocatable) (uint, error) {
+	relocationTable, err := r.Vm.GetRelocationTable()
+	if err != nil {
+		return 0, err
+	}
+	if addr.SegmentIndex < 0 || addr.SegmentIndex >= len(relocationTable) {
+		return 0, fmt.Errorf("No relocation found for segment %d", addr.SegmentIndex)
+	}
+	return addr.RelocateAddress(&relocationTable), nil
+}
+
+// Returns the felt a memory value is relocated to: felts are kept as they are and pointers are
+// turned into the address they are relocated to
+func (r *CairoRunner) RelocateValue(value memory.MaybeRelocatable) (lambdaworks.Felt, error) {
+	if felt, ok := value.GetFelt(); ok {
+		return felt, nil
+	}
+	if rel, ok := value.GetRelocatable(); ok {
+		address, err := r.RelocateAddress(rel)
+		if err != nil {
+			return lambdaworks.Felt{}, err
+		}
+		return lambdaworks.FeltFromUint64(uint64(address)), nil
+	}
+	return lambdaworks.Felt{}, errors.New("Can't relocate an empty value")
+}
+
+// Relocates the values of a single segment, such as the output, without relocating the whole
+// memory. See MemorySegmentManager.RelocateSegment
+func (r *CairoRunner) RelocateSegment(segmentIndex uint) ([]*lambdaworks.Felt, error) {
+	relocationTable, err := r.Vm.GetRelocationTable()
+	if err != nil {
+		return nil, err
+	}
+	return r.Vm.Segments.RelocateSegment(segmentIndex, &relocationTable)
+}
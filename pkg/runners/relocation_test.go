@@ -0,0 +1,81 @@
+package runners_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Runs [ap] = 5; ap++, ret, leaving the return fp, the end pointer and 5 in the execution segment
+func finishedRunner(t *testing.T) *runners.CairoRunner {
+	program_data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x480680017fff8000)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe)),
+	}
+	runner, err := runners.NewCairoRunner(vm.Program{Data: program_data, Identifiers: map[string]parser.Identifier{}}, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	return runner
+}
+
+func TestRelocateAddressAndValue(t *testing.T) {
+	runner := finishedRunner(t)
+	relocationTable, err := runner.GetRelocationTable()
+	if err != nil || len(relocationTable) != 4 || relocationTable[0] != 1 || relocationTable[1] != 4 {
+		t.Fatalf("Wrong relocation table %v, err: %v", relocationTable, err)
+	}
+	if address, err := runner.RelocateAddress(memory.NewRelocatable(1, 2)); err != nil || address != 6 {
+		t.Errorf("Expected (1, 2) to be relocated to 6, got %d, err: %v", address, err)
+	}
+	if _, err := runner.RelocateAddress(memory.NewRelocatable(4, 0)); err == nil {
+		t.Errorf("RelocateAddress should fail for a segment that doesn't exist")
+	}
+	pointer := *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 1))
+	if value, err := runner.RelocateValue(pointer); err != nil || value != lambdaworks.FeltFromUint64(5) {
+		t.Errorf("Expected (1, 1) to be relocated to 5, got %v, err: %v", value, err)
+	}
+	felt := *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(42))
+	if value, err := runner.RelocateValue(felt); err != nil || value != lambdaworks.FeltFromUint64(42) {
+		t.Errorf("Expected felts to be kept, got %v, err: %v", value, err)
+	}
+	if _, err := runner.RelocateValue(memory.MaybeRelocatable{}); err == nil {
+		t.Errorf("RelocateValue should fail for an empty value")
+	}
+}
+
+func TestRelocateSegmentMatchesFullRelocation(t *testing.T) {
+	runner := finishedRunner(t)
+	segment, err := runner.RelocateSegment(1)
+	if err != nil {
+		t.Fatalf("RelocateSegment failed with error: %s", err)
+	}
+	if err := runner.Vm.Relocate(); err != nil {
+		t.Fatalf("Relocate failed with error: %s", err)
+	}
+	relocationTable, _ := runner.GetRelocationTable()
+	if len(segment) != 3 {
+		t.Fatalf("Expected 3 relocated cells, got %d", len(segment))
+	}
+	for offset, value := range segment {
+		expected := runner.Vm.RelocatedMemory[relocationTable[1]+uint(offset)]
+		if value == nil || expected == nil || *value != *expected {
+			t.Errorf("Wrong value at offset %d. Expected %v, got %v", offset, expected, value)
+		}
+	}
+	if _, err := runner.RelocateSegment(4); err == nil {
+		t.Errorf("RelocateSegment should fail for a segment that doesn't exist")
+	}
+}
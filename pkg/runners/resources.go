@@ -0,0 +1,35 @@
+package runners
+
+import (
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Resources used by a run
+type ExecutionResources struct {
+	NSteps       uint `json:"n_steps"`
+	NMemoryCells uint `json:"n_memory_cells"`
+	// Cells within the segment sizes that were never written
+	NMemoryHoles uint `json:"n_memory_holes"`
+	// Sizes of the segments, indexed by segment index. Finalized sizes take precedence
+	SegmentSizes []uint `json:"segment_sizes"`
+}
+
+// Returns the resources used so far by the run
+func (r *CairoRunner) GetExecutionResources() ExecutionResources {
+	resources := ExecutionResources{NSteps: r.Vm.CurrentStep, SegmentSizes: r.Vm.Segments.GetSegmentSizes()}
+	r.Vm.Segments.Memory.RangeAll(func(address memory.Relocatable, _ memory.MaybeRelocatable) bool {
+		// Temporary segments aren't part of the segment sizes
+		if address.SegmentIndex >= 0 {
+			resources.NMemoryCells++
+		}
+		return true
+	})
+	totalSize := uint(0)
+	for _, size := range resources.SegmentSizes {
+		totalSize += size
+	}
+	if totalSize > resources.NMemoryCells {
+		resources.NMemoryHoles = totalSize - resources.NMemoryCells
+	}
+	return resources
+}
@@ -0,0 +1,39 @@
+package runners_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestGetExecutionResources(t *testing.T) {
+	// [ap] = 5; ap++, ret
+	program_data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x480680017fff8000)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0x208b7fff7fff7ffe)),
+	}
+	runner, err := runners.NewCairoRunner(vm.Program{Data: program_data, Identifiers: map[string]parser.Identifier{}}, false)
+	if err != nil {
+		t.Fatalf("NewCairoRunner error in test: %s", err)
+	}
+	end, err := runner.Initialize()
+	if err == nil {
+		err = runner.RunUntilPC(end)
+	}
+	if err != nil {
+		t.Fatalf("Run error in test: %s", err)
+	}
+	// Leaves holes at offsets 3 to 5 of the execution segment
+	runner.Vm.Segments.Memory.Insert(memory.NewRelocatable(1, 6), memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne()))
+
+	expected := runners.ExecutionResources{NSteps: 2, NMemoryCells: 7, NMemoryHoles: 3, SegmentSizes: []uint{3, 7, 0, 0}}
+	if got := runner.GetExecutionResources(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Wrong resources. Expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,97 @@
+package runners
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// A cell of the program segment whose value after the run differs from the loaded program, e.g.
+// because a hint wrote past the end of the program. Values without a value are empty cells
+type ProgramSegmentMismatchError struct {
+	Address  memory.Relocatable
+	Expected memory.MaybeRelocatable
+	Got      memory.MaybeRelocatable
+}
+
+func (e *ProgramSegmentMismatchError) Error() string {
+	return fmt.Sprintf("Program segment modified at %d:%d, expected %s, got %s",
+		e.Address.SegmentIndex, e.Address.Offset, e.Expected.String(), e.Got.String())
+}
+
+// A memory cell lying past the size of its segment, as declared when finalizing the segment
+type OutOfBoundsAccessError struct {
+	Address     memory.Relocatable
+	SegmentSize uint
+}
+
+func (e *OutOfBoundsAccessError) Error() string {
+	return fmt.Sprintf("Out of bounds access to segment %d at offset %d, the segment's size is %d",
+		e.Address.SegmentIndex, e.Address.Offset, e.SegmentSize)
+}
+
+// A memory cell pointing to a temporary segment, which should have been relocated into a real one
+type TemporarySegmentPointerError struct {
+	Address memory.Relocatable
+	Value   memory.Relocatable
+}
+
+func (e *TemporarySegmentPointerError) Error() string {
+	return fmt.Sprintf("Value at %d:%d points to temporary segment %d (offset %d), which wasn't relocated",
+		e.Address.SegmentIndex, e.Address.Offset, e.Value.SegmentIndex, e.Value.Offset)
+}
+
+// Checks that a finished run kept the guarantees cairo-lang's verify_secure_runner checks,
+// which hints could break:
+//   - The program segment holds exactly the program's data
+//   - Every memory cell lies within the size of its segment
+//   - No memory cell points to a temporary segment
+func (r *CairoRunner) VerifySecureRunner() error {
+	if err := r.verifyProgramSegment(); err != nil {
+		return err
+	}
+	return r.verifyMemoryAddresses()
+}
+
+// Goes over every memory cell, in address order, checking its address and value
+func (r *CairoRunner) verifyMemoryAddresses() error {
+	sizes := r.Vm.Segments.GetSegmentSizes()
+	var err error
+	r.Vm.Segments.Memory.RangeAll(func(address memory.Relocatable, value memory.MaybeRelocatable) bool {
+		if size := sizes[address.SegmentIndex]; address.Offset >= size {
+			err = &OutOfBoundsAccessError{Address: address, SegmentSize: size}
+			return false
+		}
+		if pointer, ok := value.GetRelocatable(); ok && pointer.SegmentIndex < 0 {
+			err = &TemporarySegmentPointerError{Address: address, Value: pointer}
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// Compares every cell of the program segment with the program's data, cells past the end of the
+// program having to be empty
+func (r *CairoRunner) verifyProgramSegment() error {
+	for offset, expected := range r.Program.Data {
+		address := memory.NewRelocatable(r.ProgramBase.SegmentIndex, r.ProgramBase.Offset+uint(offset))
+		got, err := r.Vm.Segments.Memory.TryGetValue(address)
+		if err != nil {
+			return err
+		}
+		if got != expected {
+			return &ProgramSegmentMismatchError{Address: address, Expected: expected, Got: got}
+		}
+	}
+	end := r.ProgramBase.Offset + uint(len(r.Program.Data))
+	var err error
+	r.Vm.Segments.Memory.Range(r.ProgramBase.SegmentIndex, func(address memory.Relocatable, value memory.MaybeRelocatable) bool {
+		if address.Offset < r.ProgramBase.Offset || address.Offset >= end {
+			err = &ProgramSegmentMismatchError{Address: address, Got: value}
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -0,0 +1,68 @@
+package runners_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestVerifySecureRunner(t *testing.T) {
+	runner := finishedRunner(t)
+	if err := runner.VerifySecureRunner(); err != nil {
+		t.Errorf("VerifySecureRunner failed with error: %s", err)
+	}
+}
+
+func TestVerifySecureRunnerWritePastProgram(t *testing.T) {
+	runner := finishedRunner(t)
+	runner.Vm.Segments.Memory.Insert(memory.NewRelocatable(0, 5), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)))
+	var mismatch *runners.ProgramSegmentMismatchError
+	err := runner.VerifySecureRunner()
+	if !errors.As(err, &mismatch) || mismatch.Address != memory.NewRelocatable(0, 5) || mismatch.Expected.HasValue() {
+		t.Fatalf("Expected a mismatch at 0:5, got %v", err)
+	}
+	if err.Error() != "Program segment modified at 0:5, expected no value, got 7" {
+		t.Errorf("Wrong error message: %s", err)
+	}
+}
+
+func TestVerifySecureRunnerModifiedInstruction(t *testing.T) {
+	runner := finishedRunner(t)
+	// The program segment no longer matches the data it was loaded from
+	runner.Program.Data = append(runner.Program.Data[:1:1], *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6)), runner.Program.Data[2])
+	var mismatch *runners.ProgramSegmentMismatchError
+	err := runner.VerifySecureRunner()
+	if !errors.As(err, &mismatch) || mismatch.Address != memory.NewRelocatable(0, 1) {
+		t.Fatalf("Expected a mismatch at 0:1, got %v", err)
+	}
+	if err.Error() != "Program segment modified at 0:1, expected 6, got 5" {
+		t.Errorf("Wrong error message: %s", err)
+	}
+}
+
+func TestVerifySecureRunnerOutOfBounds(t *testing.T) {
+	runner := finishedRunner(t)
+	size := uint(2)
+	runner.Vm.Segments.Finalize(1, &size, nil)
+	var outOfBounds *runners.OutOfBoundsAccessError
+	err := runner.VerifySecureRunner()
+	if !errors.As(err, &outOfBounds) || outOfBounds.Address != memory.NewRelocatable(1, 2) || outOfBounds.SegmentSize != 2 {
+		t.Fatalf("Expected an out of bounds access at 1:2, got %v", err)
+	}
+	if err.Error() != "Out of bounds access to segment 1 at offset 2, the segment's size is 2" {
+		t.Errorf("Wrong error message: %s", err)
+	}
+}
+
+func TestVerifySecureRunnerTemporarySegmentPointer(t *testing.T) {
+	runner := finishedRunner(t)
+	runner.Vm.Segments.Memory.Insert(memory.NewRelocatable(1, 5), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(-1, 3)))
+	var pointer *runners.TemporarySegmentPointerError
+	err := runner.VerifySecureRunner()
+	if !errors.As(err, &pointer) || pointer.Address != memory.NewRelocatable(1, 5) || pointer.Value != memory.NewRelocatable(-1, 3) {
+		t.Fatalf("Expected a pointer to a temporary segment at 1:5, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package starknet
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Names of the deprecated syscalls, as used by the syscall_handler hints of
+// starkware.starknet.common.syscalls, mapped to the name their selector is the encoding of
+var deprecatedSyscallNames = map[string]string{
+	"call_contract":           "CallContract",
+	"library_call":            "LibraryCall",
+	"library_call_l1_handler": "LibraryCallL1Handler",
+	"delegate_call":           "DelegateCall",
+	"delegate_l1_handler":     "DelegateL1Handler",
+	"deploy":                  "Deploy",
+	"get_caller_address":      "GetCallerAddress",
+	"get_sequencer_address":   "GetSequencerAddress",
+	"get_block_number":        "GetBlockNumber",
+	"get_block_timestamp":     "GetBlockTimestamp",
+	"get_contract_address":    "GetContractAddress",
+	"get_tx_signature":        "GetTxSignature",
+	"get_tx_info":             "GetTxInfo",
+	"storage_read":            "StorageRead",
+	"storage_write":           "StorageWrite",
+	"emit_event":              "EmitEvent",
+	"send_message_to_l1":      "SendMessageToL1",
+	"replace_class":           "ReplaceClass",
+}
+
+// Returns the selector of a syscall: its name encoded as a big endian short string
+func SyscallSelector(name string) lambdaworks.Felt {
+	return lambdaworks.FeltFromBigInt(new(big.Int).SetBytes([]byte(name)))
+}
+
+// Runs the syscalls of Cairo 0 contracts, which are requested through a
+// syscall_handler.<name>(segments=segments, syscall_ptr=ids.syscall_ptr) hint
+type DeprecatedSyscallHintProcessor struct {
+	Handler SyscallHandler
+	Program *vm.Program
+}
+
+func NewDeprecatedSyscallHintProcessor(handler SyscallHandler, program *vm.Program) *DeprecatedSyscallHintProcessor {
+	return &DeprecatedSyscallHintProcessor{Handler: handler, Program: program}
+}
+
+// Runs the syscall hints of the instruction about to run. Other hints are left alone.
+// Meant to be installed as a PreStep hook
+func (p *DeprecatedSyscallHintProcessor) PreStep(v *vm.VirtualMachine) error {
+	pc := v.RunContext.Pc
+	if pc.SegmentIndex != 0 {
+		return nil
+	}
+	for _, hint := range p.Program.Hints[pc.Offset] {
+		name, ok := deprecatedSyscallHintName(hint.Code)
+		if !ok {
+			continue
+		}
+		syscallPtr, err := hintIds{v: v, program: p.Program, hint: hint}.getRelocatable("syscall_ptr")
+		if err != nil {
+			return fmt.Errorf("%s syscall: %w", name, err)
+		}
+		proxy := vm.NewVMProxy(v)
+		selector, err := proxy.GetFeltFromVar(syscallPtr)
+		if err != nil {
+			return fmt.Errorf("%s syscall: %w", name, err)
+		}
+		if selector != SyscallSelector(deprecatedSyscallNames[name]) {
+			return fmt.Errorf("%s syscall: wrong selector %s", name, selector.ToHexString())
+		}
+		if err := p.ExecuteSyscall(proxy, syscallPtr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the name of the syscall a hint requests, if it is a syscall hint
+func deprecatedSyscallHintName(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, "syscall_handler.") || !strings.HasSuffix(code, "(segments=segments, syscall_ptr=ids.syscall_ptr)") {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(code, "syscall_handler."), "(segments=segments, syscall_ptr=ids.syscall_ptr)")
+	_, ok := deprecatedSyscallNames[name]
+	return name, ok
+}
+
+// Runs the syscall whose request starts at syscallPtr, writing its response after the request
+func (p *DeprecatedSyscallHintProcessor) ExecuteSyscall(proxy *vm.VMProxy, syscallPtr memory.Relocatable) error {
+	request := syscallRequest{proxy: proxy, ptr: syscallPtr}
+	selector, err := request.felt(0)
+	if err != nil {
+		return err
+	}
+	switch selector {
+	case SyscallSelector("CallContract"):
+		return p.call(request, CallKindCallContract)
+	case SyscallSelector("LibraryCall"):
+		return p.call(request, CallKindLibraryCall)
+	case SyscallSelector("LibraryCallL1Handler"):
+		return p.call(request, CallKindLibraryCallL1Handler)
+	case SyscallSelector("DelegateCall"):
+		return p.call(request, CallKindDelegateCall)
+	case SyscallSelector("DelegateL1Handler"):
+		return p.call(request, CallKindDelegateL1Handler)
+	case SyscallSelector("Deploy"):
+		return p.deploy(request)
+	case SyscallSelector("GetCallerAddress"):
+		return request.respond(1, p.Handler.ExecutionInfo().CallerAddress)
+	case SyscallSelector("GetSequencerAddress"):
+		return request.respond(1, p.Handler.ExecutionInfo().SequencerAddress)
+	case SyscallSelector("GetBlockNumber"):
+		return request.respond(1, p.Handler.ExecutionInfo().BlockNumber)
+	case SyscallSelector("GetBlockTimestamp"):
+		return request.respond(1, p.Handler.ExecutionInfo().BlockTimestamp)
+	case SyscallSelector("GetContractAddress"):
+		return request.respond(1, p.Handler.ExecutionInfo().ContractAddress)
+	case SyscallSelector("GetTxSignature"):
+		signature := p.Handler.ExecutionInfo().TxInfo.Signature
+		return request.respond(1, lambdaworks.FeltFromUint64(uint64(len(signature))), signature)
+	case SyscallSelector("GetTxInfo"):
+		txInfo := p.Handler.ExecutionInfo().TxInfo
+		signature, err := proxy.GenArg(txInfo.Signature)
+		if err != nil {
+			return err
+		}
+		fields := []memory.MaybeRelocatable{
+			*memory.NewMaybeRelocatableFelt(txInfo.Version),
+			*memory.NewMaybeRelocatableFelt(txInfo.AccountContractAddress),
+			*memory.NewMaybeRelocatableFelt(txInfo.MaxFee),
+			*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(len(txInfo.Signature)))),
+			signature,
+			*memory.NewMaybeRelocatableFelt(txInfo.TransactionHash),
+			*memory.NewMaybeRelocatableFelt(txInfo.ChainId),
+			*memory.NewMaybeRelocatableFelt(txInfo.Nonce),
+		}
+		return request.respond(1, fields)
+	case SyscallSelector("StorageRead"):
+		address, err := request.felt(1)
+		if err != nil {
+			return err
+		}
+		value, err := p.Handler.StorageRead(address)
+		if err != nil {
+			return err
+		}
+		return request.respond(2, value)
+	case SyscallSelector("StorageWrite"):
+		address, err := request.felt(1)
+		if err != nil {
+			return err
+		}
+		value, err := request.felt(2)
+		if err != nil {
+			return err
+		}
+		return p.Handler.StorageWrite(address, value)
+	case SyscallSelector("EmitEvent"):
+		keys, err := request.felts(1, 2)
+		if err != nil {
+			return err
+		}
+		data, err := request.felts(3, 4)
+		if err != nil {
+			return err
+		}
+		return p.Handler.EmitEvent(Event{Keys: keys, Data: data})
+	case SyscallSelector("SendMessageToL1"):
+		toAddress, err := request.felt(1)
+		if err != nil {
+			return err
+		}
+		payload, err := request.felts(2, 3)
+		if err != nil {
+			return err
+		}
+		return p.Handler.SendMessageToL1(MessageToL1{ToAddress: toAddress, Payload: payload})
+	case SyscallSelector("ReplaceClass"):
+		classHash, err := request.felt(1)
+		if err != nil {
+			return err
+		}
+		return p.Handler.ReplaceClass(classHash)
+	}
+	return fmt.Errorf("unknown syscall selector %s", selector.ToHexString())
+}
+
+// Runs a call syscall: [selector, target, function_selector, calldata_size, calldata] -> [retdata_size, retdata]
+func (p *DeprecatedSyscallHintProcessor) call(request syscallRequest, kind CallKind) error {
+	target, err := request.felt(1)
+	if err != nil {
+		return err
+	}
+	selector, err := request.felt(2)
+	if err != nil {
+		return err
+	}
+	calldata, err := request.felts(3, 4)
+	if err != nil {
+		return err
+	}
+	retdata, err := p.Handler.CallContract(ContractCall{Kind: kind, Target: target, Selector: selector, Calldata: calldata})
+	if err != nil {
+		return err
+	}
+	return request.respond(5, lambdaworks.FeltFromUint64(uint64(len(retdata))), retdata)
+}
+
+// Runs a deploy syscall: [selector, class_hash, contract_address_salt, constructor_calldata_size,
+// constructor_calldata, deploy_from_zero] -> [contract_address, constructor_retdata_size, constructor_retdata]
+func (p *DeprecatedSyscallHintProcessor) deploy(request syscallRequest) error {
+	classHash, err := request.felt(1)
+	if err != nil {
+		return err
+	}
+	salt, err := request.felt(2)
+	if err != nil {
+		return err
+	}
+	calldata, err := request.felts(3, 4)
+	if err != nil {
+		return err
+	}
+	deployFromZero, err := request.felt(5)
+	if err != nil {
+		return err
+	}
+	address, retdata, err := p.Handler.Deploy(DeployRequest{ClassHash: classHash, Salt: salt, ConstructorCalldata: calldata, DeployFromZero: !deployFromZero.IsZero()})
+	if err != nil {
+		return err
+	}
+	return request.respond(6, address, lambdaworks.FeltFromUint64(uint64(len(retdata))), retdata)
+}
@@ -0,0 +1,221 @@
+package starknet_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func felt(value uint64) lambdaworks.Felt {
+	return lambdaworks.FeltFromUint64(value)
+}
+
+// An array passed as its start and end pointers, as done by Cairo 1 syscalls
+type span []lambdaworks.Felt
+
+// Returns a vm with the given request written at the start of a new segment, and the request's address
+func syscallVM(t *testing.T, request ...any) (*vm.VirtualMachine, memory.Relocatable) {
+	virtualMachine := vm.NewVirtualMachine()
+	ptr := virtualMachine.Segments.AddSegment()
+	var fields []memory.MaybeRelocatable
+	for _, field := range request {
+		if felts, ok := field.(span); ok {
+			start, err := virtualMachine.Segments.GenArg([]lambdaworks.Felt(felts))
+			if err != nil {
+				t.Fatalf("GenArg error in test: %s", err)
+			}
+			base, _ := start.GetRelocatable()
+			end := memory.NewRelocatable(base.SegmentIndex, base.Offset+uint(len(felts)))
+			fields = append(fields, start, *memory.NewMaybeRelocatableRelocatable(end))
+			continue
+		}
+		value, err := virtualMachine.Segments.GenArg(field)
+		if err != nil {
+			t.Fatalf("GenArg error in test: %s", err)
+		}
+		fields = append(fields, value)
+	}
+	if _, err := virtualMachine.Segments.LoadData(ptr, fields); err != nil {
+		t.Fatalf("LoadData error in test: %s", err)
+	}
+	return virtualMachine, ptr
+}
+
+func readFelt(t *testing.T, virtualMachine *vm.VirtualMachine, segment int, offset uint) lambdaworks.Felt {
+	value, err := virtualMachine.Segments.Memory.GetFelt(memory.NewRelocatable(segment, offset))
+	if err != nil {
+		t.Fatalf("Failed to read %d:%d: %s", segment, offset, err)
+	}
+	return value
+}
+
+func TestDeprecatedStorageRead(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageRead"), felt(5))
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if value := readFelt(t, virtualMachine, 0, 2); value != felt(105) {
+		t.Errorf("Wrong storage value: %s", value.String())
+	}
+}
+
+func TestDeprecatedStorageWrite(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageWrite"), felt(5), felt(9))
+	handler := starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})
+	processor := starknet.NewDeprecatedSyscallHintProcessor(handler, &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if handler.StorageWrites[felt(5)] != felt(9) {
+		t.Errorf("The write should have been recorded: %v", handler.StorageWrites)
+	}
+}
+
+func TestDeprecatedEmitEvent(t *testing.T) {
+	keys := []lambdaworks.Felt{felt(1)}
+	data := []lambdaworks.Felt{felt(2), felt(3)}
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("EmitEvent"), felt(1), keys, felt(2), data)
+	handler := starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})
+	processor := starknet.NewDeprecatedSyscallHintProcessor(handler, &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	expected := []starknet.Event{{Keys: keys, Data: data}}
+	if !reflect.DeepEqual(handler.Events, expected) {
+		t.Errorf("Wrong events. Expected %v, got %v", expected, handler.Events)
+	}
+}
+
+func TestDeprecatedEmitEventHugeArray(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("EmitEvent"), felt(1<<63), []lambdaworks.Felt{felt(1)}, felt(0), []lambdaworks.Felt{})
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err == nil {
+		t.Errorf("ExecuteSyscall should fail on an array larger than its segment")
+	}
+}
+
+func TestDeprecatedGetTxInfo(t *testing.T) {
+	info := starknet.ExecutionInfo{TxInfo: starknet.TxInfo{Version: felt(1), Signature: []lambdaworks.Felt{felt(7), felt(8)}, Nonce: felt(3)}}
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("GetTxInfo"))
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, info), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	txInfo, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(0, 1))
+	if err != nil {
+		t.Fatalf("The response should be a pointer to the tx info: %s", err)
+	}
+	if readFelt(t, virtualMachine, txInfo.SegmentIndex, 0) != felt(1) || readFelt(t, virtualMachine, txInfo.SegmentIndex, 3) != felt(2) || readFelt(t, virtualMachine, txInfo.SegmentIndex, 7) != felt(3) {
+		t.Errorf("Wrong tx info fields")
+	}
+	signature, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(txInfo.SegmentIndex, 4))
+	if err != nil || readFelt(t, virtualMachine, signature.SegmentIndex, 1) != felt(8) {
+		t.Errorf("Wrong signature, err: %v", err)
+	}
+}
+
+// Returns the calldata of every call, reversed
+type echoHandler struct {
+	*starknet.BaseSyscallHandler
+	calls []starknet.ContractCall
+}
+
+func (h *echoHandler) CallContract(call starknet.ContractCall) ([]lambdaworks.Felt, error) {
+	h.calls = append(h.calls, call)
+	retdata := make([]lambdaworks.Felt, 0, len(call.Calldata))
+	for i := len(call.Calldata) - 1; i >= 0; i-- {
+		retdata = append(retdata, call.Calldata[i])
+	}
+	return retdata, nil
+}
+
+func TestDeprecatedLibraryCall(t *testing.T) {
+	calldata := []lambdaworks.Felt{felt(1), felt(2)}
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("LibraryCall"), felt(10), felt(20), felt(2), calldata)
+	handler := &echoHandler{BaseSyscallHandler: starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})}
+	processor := starknet.NewDeprecatedSyscallHintProcessor(handler, &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	expectedCall := starknet.ContractCall{Kind: starknet.CallKindLibraryCall, Target: felt(10), Selector: felt(20), Calldata: calldata}
+	if len(handler.calls) != 1 || !reflect.DeepEqual(handler.calls[0], expectedCall) {
+		t.Errorf("Wrong calls: %+v", handler.calls)
+	}
+	if readFelt(t, virtualMachine, 0, 5) != felt(2) {
+		t.Errorf("Wrong retdata size")
+	}
+	retdata, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(0, 6))
+	if err != nil || readFelt(t, virtualMachine, retdata.SegmentIndex, 0) != felt(2) {
+		t.Errorf("Wrong retdata, err: %v", err)
+	}
+}
+
+func TestDeprecatedUnknownSelector(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("Unknown"))
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err == nil {
+		t.Errorf("ExecuteSyscall should fail with an unknown selector")
+	}
+}
+
+// Returns a vm about to run a storage_read hint at pc 0:0, whose syscall_ptr is stored at [fp - 3].
+// The request is written to segment 0, standing in for the program segment hints run from
+func syscallHintVM(t *testing.T, selector lambdaworks.Felt) (*vm.VirtualMachine, *vm.Program) {
+	virtualMachine, ptr := syscallVM(t, selector, felt(5))
+	virtualMachine.Segments.AddSegment()
+	execution := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableRelocatable(ptr))
+	virtualMachine.RunContext.Pc = memory.NewRelocatable(0, 0)
+	virtualMachine.RunContext.Fp = memory.NewRelocatable(execution.SegmentIndex, 3)
+	virtualMachine.RunContext.Ap = virtualMachine.RunContext.Fp
+
+	reference, err := parser.NewHintReference(parser.Reference{Value: "[cast(fp + (-3), felt*)]"})
+	if err != nil {
+		t.Fatalf("NewHintReference error in test: %s", err)
+	}
+	program := &vm.Program{
+		Hints: map[uint][]parser.HintParams{0: {{
+			Code:             "syscall_handler.storage_read(segments=segments, syscall_ptr=ids.syscall_ptr)",
+			FlowTrackingData: parser.FlowTrackingData{ReferenceIDS: map[string]int{"starkware.starknet.common.syscalls.storage_read.syscall_ptr": 0}},
+		}}},
+		References: []parser.HintReference{reference},
+	}
+	return virtualMachine, program
+}
+
+func TestDeprecatedSyscallHint(t *testing.T) {
+	virtualMachine, program := syscallHintVM(t, starknet.SyscallSelector("StorageRead"))
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), program)
+	if err := processor.PreStep(virtualMachine); err != nil {
+		t.Fatalf("PreStep failed with error: %s", err)
+	}
+	if value := readFelt(t, virtualMachine, 0, 2); value != felt(105) {
+		t.Errorf("Wrong storage value: %s", value.String())
+	}
+}
+
+func TestDeprecatedSyscallHintWrongSelector(t *testing.T) {
+	virtualMachine, program := syscallHintVM(t, starknet.SyscallSelector("StorageWrite"))
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), program)
+	if err := processor.PreStep(virtualMachine); err == nil {
+		t.Errorf("PreStep should fail when the selector doesn't match the hint")
+	}
+}
+
+func TestDeprecatedSyscallHintOtherSegment(t *testing.T) {
+	virtualMachine, program := syscallHintVM(t, starknet.SyscallSelector("StorageRead"))
+	virtualMachine.RunContext.Pc = memory.NewRelocatable(1, 0)
+	processor := starknet.NewDeprecatedSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), program)
+	if err := processor.PreStep(virtualMachine); err != nil {
+		t.Fatalf("PreStep failed with error: %s", err)
+	}
+	if _, err := virtualMachine.Segments.Memory.GetFelt(memory.NewRelocatable(0, 2)); err == nil {
+		t.Errorf("Hints should only run at pcs of the program segment")
+	}
+}
@@ -0,0 +1,140 @@
+package starknet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// The ids of a Cairo 0 hint: the references accessible from it, resolved at the current state of the vm
+type hintIds struct {
+	v       *vm.VirtualMachine
+	program *vm.Program
+	hint    parser.HintParams
+}
+
+// Returns the reference of ids.name, whose full name is scoped, e.g. __main__.main.name
+func (ids hintIds) reference(name string) (parser.HintReference, error) {
+	for fullName, id := range ids.hint.FlowTrackingData.ReferenceIDS {
+		if fullName != name && !strings.HasSuffix(fullName, "."+name) {
+			continue
+		}
+		if id < 0 || id >= len(ids.program.References) {
+			return parser.HintReference{}, fmt.Errorf("unknown reference id %d", id)
+		}
+		return ids.program.References[id], nil
+	}
+	return parser.HintReference{}, fmt.Errorf("ids.%s is not accessible from the hint", name)
+}
+
+// Returns the value of ids.name. Struct members are accessed with dots, e.g. ids.x.low, see addressOf
+func (ids hintIds) get(name string) (memory.MaybeRelocatable, error) {
+	if strings.Contains(name, ".") {
+		addr, err := ids.addressOf(name)
+		if err != nil {
+			return memory.MaybeRelocatable{}, err
+		}
+		value, err := ids.v.Segments.Memory.Get(addr)
+		if err != nil {
+			return memory.MaybeRelocatable{}, fmt.Errorf("ids.%s: %w", name, err)
+		}
+		return *value, nil
+	}
+	reference, err := ids.reference(name)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return ids.v.GetReferenceValue(reference, ids.hint.FlowTrackingData.APTracking)
+}
+
+func (ids hintIds) getRelocatable(name string) (memory.Relocatable, error) {
+	value, err := ids.get(name)
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	ptr, ok := value.GetRelocatable()
+	if !ok {
+		return memory.Relocatable{}, fmt.Errorf("ids.%s is not a pointer", name)
+	}
+	return ptr, nil
+}
+
+// Writes ids.name, which must be stored in memory
+func (ids hintIds) set(name string, value memory.MaybeRelocatable) error {
+	addr, err := ids.addressOf(name)
+	if err != nil {
+		return err
+	}
+	return ids.v.Segments.Memory.Insert(addr, &value)
+}
+
+// Returns the address of ids.name, which must be stored in memory. Members of structs are
+// accessed with dots, e.g. ids.x.low, either on structs or on pointers to structs, and can be
+// nested. Member offsets are taken from the struct identifiers of the program
+func (ids hintIds) addressOf(name string) (memory.Relocatable, error) {
+	path := strings.Split(name, ".")
+	reference, err := ids.reference(path[0])
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	addr, err := ids.v.GetReferenceAddress(reference, ids.hint.FlowTrackingData.APTracking)
+	if len(path) == 1 {
+		if err != nil {
+			return memory.Relocatable{}, fmt.Errorf("ids.%s: %w", name, err)
+		}
+		return addr, nil
+	}
+	// References stored in memory are casts of their address, whose type has an extra *
+	cairoType := reference.CairoType
+	if reference.Dereference {
+		cairoType = strings.TrimSuffix(cairoType, "*")
+	}
+	// Pointers are followed, the members of structs lie right after their address
+	if strings.HasSuffix(cairoType, "*") {
+		addr, err = ids.getRelocatable(path[0])
+	}
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	for i, member := range path[1:] {
+		if i > 0 && strings.HasSuffix(cairoType, "*") {
+			if addr, err = ids.v.Segments.Memory.GetRelocatable(addr); err != nil {
+				return memory.Relocatable{}, fmt.Errorf("ids.%s: %w", strings.Join(path[:i+1], "."), err)
+			}
+		}
+		structType := strings.TrimSuffix(cairoType, "*")
+		members, err := ids.program.GetStructMembers(structType)
+		if err != nil {
+			return memory.Relocatable{}, err
+		}
+		offset, ok := members[member]
+		if !ok {
+			return memory.Relocatable{}, fmt.Errorf("%s has no member %s", structType, member)
+		}
+		if addr, err = addr.AddUint(uint(offset.Offset)); err != nil {
+			return memory.Relocatable{}, err
+		}
+		cairoType = offset.CairoType
+	}
+	return addr, nil
+}
+
+// Returns the offset of a member of a struct accessible from the hint, e.g. ids.ProgramHeader.builtin_list
+func (ids hintIds) memberOffset(structName string, member string) (uint, error) {
+	// Inner scopes shadow outer ones
+	for i := len(ids.hint.AccessibleScopes) - 1; i >= 0; i-- {
+		members, err := ids.program.GetStructMembers(ids.hint.AccessibleScopes[i] + "." + structName)
+		if err != nil {
+			continue
+		}
+		offset, ok := members[member]
+		if !ok {
+			return 0, fmt.Errorf("%s has no member %s", structName, member)
+		}
+		return uint(offset.Offset), nil
+	}
+	return 0, fmt.Errorf("struct %s is not accessible from the hint", structName)
+}
@@ -0,0 +1,102 @@
+package starknet
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// ids.x is a Uint256 stored at fp, ids.p a pointer at fp + 2 to a Pair stored in segment 2,
+// whose member a holds a Uint256 and member b points back to ids.x
+func structIds(t *testing.T) hintIds {
+	references := []parser.HintReference{}
+	for _, value := range []string{"[cast(fp, __main__.Uint256*)]", "[cast(fp + 2, __main__.Pair**)]"} {
+		reference, err := parser.NewHintReference(parser.Reference{Value: value})
+		if err != nil {
+			t.Fatalf("NewHintReference error in test: %s", err)
+		}
+		references = append(references, reference)
+	}
+	program := vm.Program{
+		References: references,
+		Identifiers: map[string]parser.Identifier{
+			"__main__.Uint256": {Type: parser.IdentifierStruct, Members: map[string]parser.Member{
+				"low": {CairoType: "felt", Offset: 0}, "high": {CairoType: "felt", Offset: 1},
+			}},
+			"__main__.Pair": {Type: parser.IdentifierStruct, Members: map[string]parser.Member{
+				"a": {CairoType: "__main__.Uint256", Offset: 0}, "b": {CairoType: "__main__.Uint256*", Offset: 2},
+			}},
+		},
+	}
+	hint := parser.HintParams{FlowTrackingData: parser.FlowTrackingData{ReferenceIDS: map[string]int{"__main__.x": 0, "__main__.p": 1}}}
+
+	v := vm.NewVirtualMachine()
+	for i := 0; i < 3; i++ {
+		v.Segments.AddSegment()
+	}
+	v.RunContext.Fp = memory.NewRelocatable(1, 0)
+	cells := map[memory.Relocatable]memory.MaybeRelocatable{
+		memory.NewRelocatable(1, 0): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)),
+		memory.NewRelocatable(1, 1): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)),
+		memory.NewRelocatable(1, 2): *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(2, 0)),
+		memory.NewRelocatable(2, 1): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(4)),
+		memory.NewRelocatable(2, 2): *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 0)),
+	}
+	for addr, value := range cells {
+		value := value
+		if err := v.Segments.Memory.Insert(addr, &value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hintIds{v: v, program: &program, hint: hint}
+}
+
+func TestHintIdsAddressOf(t *testing.T) {
+	ids := structIds(t)
+	expected := map[string]memory.Relocatable{
+		"x":        memory.NewRelocatable(1, 0),
+		"x.high":   memory.NewRelocatable(1, 1),
+		"p":        memory.NewRelocatable(1, 2),
+		"p.a":      memory.NewRelocatable(2, 0),
+		"p.a.high": memory.NewRelocatable(2, 1),
+		"p.b":      memory.NewRelocatable(2, 2),
+		"p.b.high": memory.NewRelocatable(1, 1),
+	}
+	for name, addr := range expected {
+		if got, err := ids.addressOf(name); err != nil || got != addr {
+			t.Errorf("Expected ids.%s at %+v, got %+v, err: %v", name, addr, got, err)
+		}
+	}
+}
+
+func TestHintIdsMemberAccess(t *testing.T) {
+	ids := structIds(t)
+	expected := map[string]uint64{"x.low": 1, "x.high": 2, "p.a.high": 4, "p.b.low": 1}
+	for name, value := range expected {
+		if got, err := ids.get(name); err != nil || got != *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(value)) {
+			t.Errorf("Expected ids.%s to be %d, got %s, err: %v", name, value, got, err)
+		}
+	}
+	if err := ids.set("p.a.low", *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3))); err != nil {
+		t.Fatalf("set error in test: %s", err)
+	}
+	if got, err := ids.v.Segments.Memory.GetFelt(memory.NewRelocatable(2, 0)); err != nil || got != lambdaworks.FeltFromUint64(3) {
+		t.Errorf("Expected ids.p.a.low to be written at 2:0, got %s, err: %v", got, err)
+	}
+}
+
+func TestHintIdsMemberAccessErrors(t *testing.T) {
+	ids := structIds(t)
+	if _, err := ids.get("x.mid"); err == nil || err.Error() != "__main__.Uint256 has no member mid" {
+		t.Errorf("Accessing a missing member should fail, got %v", err)
+	}
+	if _, err := ids.addressOf("x.low.value"); err == nil {
+		t.Errorf("Accessing a member of a felt should fail")
+	}
+	if _, err := ids.get("y.low"); err == nil || err.Error() != "ids.y is not accessible from the hint" {
+		t.Errorf("Accessing a member of an unknown reference should fail, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package starknet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Hints of the Starknet OS and of the simple bootloader it is run by, as found in compiled programs
+const (
+	loadOsInputHint = `from starkware.starknet.core.os.os_input import StarknetOsInput
+
+os_input = StarknetOsInput.load(data=program_input)
+
+ids.initial_carried_outputs.messages_to_l1 = segments.add_temp_segment()
+ids.initial_carried_outputs.messages_to_l2 = segments.add_temp_segment()`
+	loadBootloaderInputHint = `from starkware.cairo.bootloaders.simple_bootloader.objects import SimpleBootloaderInput
+simple_bootloader_input = SimpleBootloaderInput.Schema().load(program_input)`
+	nTasksHint         = `memory[ap] = to_felt_or_relocatable(len(simple_bootloader_input.tasks))`
+	setCurrentTaskHint = `from starkware.cairo.bootloaders.simple_bootloader.objects import Task
+
+# Pass current task to execute_task.
+task_id = len(simple_bootloader_input.tasks) - ids.n_tasks
+task = simple_bootloader_input.tasks[task_id].load_task()`
+	allocateProgramDataHint = `ids.program_data_ptr = program_data_base = segments.add()`
+	loadProgramHint         = `from starkware.cairo.bootloaders.simple_bootloader.utils import load_program
+
+# Call load_program to load the program header and code to memory.
+program_address, program_data_size = load_program(
+    task=task, memory=memory, program_header=ids.program_header,
+    builtins_offset=ids.ProgramHeader.builtin_list)
+segments.finalize(program_data_base.segment_index, program_data_size)`
+	assertProgramAddressHint = `# Sanity check.
+assert ids.program_address == program_address`
+)
+
+// Version of the bootloader written into the headers of the programs it loads
+const bootloaderVersion = 0
+
+var osHints = map[string]func(p *OsHintProcessor, ids hintIds) error{
+	loadOsInputHint:          (*OsHintProcessor).loadOsInput,
+	loadBootloaderInputHint:  (*OsHintProcessor).loadBootloaderInput,
+	nTasksHint:               (*OsHintProcessor).nTasks,
+	setCurrentTaskHint:       (*OsHintProcessor).setCurrentTask,
+	allocateProgramDataHint:  (*OsHintProcessor).allocateProgramData,
+	loadProgramHint:          (*OsHintProcessor).loadProgram,
+	assertProgramAddressHint: (*OsHintProcessor).assertProgramAddress,
+}
+
+// Input of the Starknet OS, the program_input of its runs. Its parts are kept in their JSON form
+// until hints reading them are implemented
+type OsInput struct {
+	ContractStateCommitmentInfo  json.RawMessage            `json:"contract_state_commitment_info"`
+	ContractClassCommitmentInfo  json.RawMessage            `json:"contract_class_commitment_info"`
+	DeprecatedCompiledClasses    map[string]json.RawMessage `json:"deprecated_compiled_classes"`
+	CompiledClasses              map[string]json.RawMessage `json:"compiled_classes"`
+	Contracts                    map[string]json.RawMessage `json:"contracts"`
+	ClassHashToCompiledClassHash map[string]json.RawMessage `json:"class_hash_to_compiled_class_hash"`
+	GeneralConfig                json.RawMessage            `json:"general_config"`
+	Transactions                 []json.RawMessage          `json:"transactions"`
+	BlockHash                    json.RawMessage            `json:"block_hash"`
+}
+
+// Input of the simple bootloader: the tasks it runs, one after the other
+type SimpleBootloaderInput struct {
+	Tasks              []BootloaderTask `json:"tasks"`
+	FactTopologiesPath string           `json:"fact_topologies_path"`
+	SinglePage         bool             `json:"single_page"`
+}
+
+// A task of the simple bootloader. Only RunProgramTask tasks, running a compiled program with
+// its own program_input, are supported
+type BootloaderTask struct {
+	Type         string          `json:"type"`
+	Program      json.RawMessage `json:"program"`
+	ProgramInput json.RawMessage `json:"program_input"`
+	UsePoseidon  bool            `json:"use_poseidon"`
+}
+
+// Runs the hints of the Starknet OS and of the simple bootloader loading it as a task: loading
+// the OS input, and loading the bootloader's tasks and their programs. The hints of a task's
+// program run with the task's program_input once it is loaded.
+// Hints over the output builtin, like the bootloader's add_page and its fact topology attributes,
+// aren't supported yet: the output builtin runner can store pages and attributes, but nothing adds them.
+// Other hints are left alone. Meant to be installed as a PreStep hook, it is opt-in as these
+// hints are only found in the OS and bootloader programs
+type OsHintProcessor struct {
+	Program *vm.Program
+	// Input of the run, an OsInput for the OS or a SimpleBootloaderInput for the bootloader
+	ProgramInput json.RawMessage
+	// Loaded by the hints as they run
+	OsInput         *OsInput
+	BootloaderInput *SimpleBootloaderInput
+	// The task being run and the address its program was loaded at
+	task            *BootloaderTask
+	taskProgram     *vm.Program
+	programDataBase memory.Relocatable
+	programAddress  memory.Relocatable
+}
+
+func NewOsHintProcessor(program *vm.Program, programInput json.RawMessage) *OsHintProcessor {
+	return &OsHintProcessor{Program: program, ProgramInput: programInput}
+}
+
+// Runs the OS and bootloader hints of the instruction about to run
+func (p *OsHintProcessor) PreStep(v *vm.VirtualMachine) error {
+	program, offset, ok := p.programAt(v.RunContext.Pc)
+	if !ok {
+		return nil
+	}
+	for _, hint := range program.Hints[offset] {
+		run, ok := osHints[strings.TrimSpace(hint.Code)]
+		if !ok {
+			continue
+		}
+		if err := run(p, hintIds{v: v, program: program, hint: hint}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the program pc belongs to, either the main program or the program of the current task,
+// and the offset of pc within it
+func (p *OsHintProcessor) programAt(pc memory.Relocatable) (*vm.Program, uint, bool) {
+	if pc.SegmentIndex == 0 {
+		return p.Program, pc.Offset, true
+	}
+	if p.taskProgram != nil && pc.SegmentIndex == p.programAddress.SegmentIndex && pc.Offset >= p.programAddress.Offset {
+		return p.taskProgram, pc.Offset - p.programAddress.Offset, true
+	}
+	return nil, 0, false
+}
+
+// The program_input of the program being run: the task's if one is loaded
+func (p *OsHintProcessor) programInput() json.RawMessage {
+	if p.task != nil {
+		return p.task.ProgramInput
+	}
+	return p.ProgramInput
+}
+
+// Loads the OS input and allocates the segments the OS accumulates its messages in. The OS asks for
+// temporary segments, later moved into the output with relocation rules, but the hint adding those
+// rules isn't supported, so the messages get real segments: temporary ones would never be relocated
+func (p *OsHintProcessor) loadOsInput(ids hintIds) error {
+	var osInput OsInput
+	if err := json.Unmarshal(p.programInput(), &osInput); err != nil {
+		return fmt.Errorf("invalid OS input: %w", err)
+	}
+	p.OsInput = &osInput
+	for _, member := range []string{"messages_to_l1", "messages_to_l2"} {
+		addr, err := ids.addressOf("initial_carried_outputs." + member)
+		if err != nil {
+			return err
+		}
+		segment := ids.v.Segments.AddSegment()
+		if err := ids.v.Segments.Memory.Insert(addr, memory.NewMaybeRelocatableRelocatable(segment)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *OsHintProcessor) loadBootloaderInput(ids hintIds) error {
+	var bootloaderInput SimpleBootloaderInput
+	if err := json.Unmarshal(p.programInput(), &bootloaderInput); err != nil {
+		return fmt.Errorf("invalid bootloader input: %w", err)
+	}
+	p.BootloaderInput = &bootloaderInput
+	return nil
+}
+
+// Writes the number of tasks at ap
+func (p *OsHintProcessor) nTasks(ids hintIds) error {
+	if p.BootloaderInput == nil {
+		return errors.New("the bootloader input wasn't loaded")
+	}
+	nTasks := lambdaworks.FeltFromUint64(uint64(len(p.BootloaderInput.Tasks)))
+	return ids.v.Segments.Memory.Insert(ids.v.RunContext.Ap, memory.NewMaybeRelocatableFelt(nTasks))
+}
+
+// Picks the next task to run, ids.n_tasks being the number of tasks left
+func (p *OsHintProcessor) setCurrentTask(ids hintIds) error {
+	if p.BootloaderInput == nil {
+		return errors.New("the bootloader input wasn't loaded")
+	}
+	value, err := ids.get("n_tasks")
+	if err != nil {
+		return err
+	}
+	nTasks, ok := value.GetFelt()
+	if !ok {
+		return errors.New("ids.n_tasks is not a felt")
+	}
+	left, err := nTasks.ToU64()
+	if err != nil || left == 0 || left > uint64(len(p.BootloaderInput.Tasks)) {
+		return fmt.Errorf("invalid number of tasks left %s", nTasks.String())
+	}
+	task := p.BootloaderInput.Tasks[uint64(len(p.BootloaderInput.Tasks))-left]
+	if task.Type != "RunProgramTask" {
+		return fmt.Errorf("unsupported task type %s", task.Type)
+	}
+	program, err := vm.ParseProgramStream(bytes.NewReader(task.Program))
+	if err != nil {
+		return fmt.Errorf("invalid task program: %w", err)
+	}
+	p.task, p.taskProgram = &task, &program
+	return nil
+}
+
+func (p *OsHintProcessor) allocateProgramData(ids hintIds) error {
+	p.programDataBase = ids.v.Segments.AddSegment()
+	return ids.set("program_data_ptr", *memory.NewMaybeRelocatableRelocatable(p.programDataBase))
+}
+
+// Writes the header of the task's program at ids.program_header, followed by its code:
+// [data_length, bootloader_version, program_main, n_builtins, builtins..., code...], where
+// data_length is the number of cells after it and builtins are encoded as short strings
+func (p *OsHintProcessor) loadProgram(ids hintIds) error {
+	if p.taskProgram == nil {
+		return errors.New("no task was loaded")
+	}
+	header, err := ids.getRelocatable("program_header")
+	if err != nil {
+		return err
+	}
+	builtinsOffset, err := ids.memberOffset("ProgramHeader", "builtin_list")
+	if err != nil {
+		return err
+	}
+	if p.taskProgram.MainEntrypoint == nil {
+		return errors.New("the task's program has no main function")
+	}
+	builtins := p.taskProgram.Builtins
+	headerSize := builtinsOffset + uint(len(builtins))
+	dataSize := headerSize + uint(len(p.taskProgram.Data))
+
+	fields := []uint64{uint64(dataSize - 1), bootloaderVersion, uint64(p.taskProgram.MainEntrypoint.PC), uint64(len(builtins))}
+	if builtinsOffset != uint(len(fields)) {
+		return fmt.Errorf("expected the builtins at offset %d of the program header, got %d", len(fields), builtinsOffset)
+	}
+	data := make([]memory.MaybeRelocatable, 0, dataSize)
+	for _, value := range fields {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(value)))
+	}
+	for _, builtin := range builtins {
+		encoded := lambdaworks.FeltFromBigInt(new(big.Int).SetBytes([]byte(builtin)))
+		data = append(data, *memory.NewMaybeRelocatableFelt(encoded))
+	}
+	programAddress, err := ids.v.Segments.LoadData(header, data)
+	if err != nil {
+		return err
+	}
+	if _, err := ids.v.Segments.LoadData(programAddress, p.taskProgram.Data); err != nil {
+		return err
+	}
+	p.programAddress = programAddress
+	ids.v.Segments.Finalize(uint(p.programDataBase.SegmentIndex), &dataSize, nil)
+	return nil
+}
+
+func (p *OsHintProcessor) assertProgramAddress(ids hintIds) error {
+	programAddress, err := ids.getRelocatable("program_address")
+	if err != nil {
+		return err
+	}
+	if programAddress != p.programAddress {
+		return fmt.Errorf("the program was loaded at %v, but ids.program_address is %v", p.programAddress, programAddress)
+	}
+	return nil
+}
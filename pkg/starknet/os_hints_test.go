@@ -0,0 +1,203 @@
+package starknet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+const loadOsInputHint = `from starkware.starknet.core.os.os_input import StarknetOsInput
+
+os_input = StarknetOsInput.load(data=program_input)
+
+ids.initial_carried_outputs.messages_to_l1 = segments.add_temp_segment()
+ids.initial_carried_outputs.messages_to_l2 = segments.add_temp_segment()`
+
+// The hints of the simple bootloader, run one per pc
+var bootloaderHints = []string{
+	`from starkware.cairo.bootloaders.simple_bootloader.objects import SimpleBootloaderInput
+simple_bootloader_input = SimpleBootloaderInput.Schema().load(program_input)`,
+	`memory[ap] = to_felt_or_relocatable(len(simple_bootloader_input.tasks))`,
+	`from starkware.cairo.bootloaders.simple_bootloader.objects import Task
+
+# Pass current task to execute_task.
+task_id = len(simple_bootloader_input.tasks) - ids.n_tasks
+task = simple_bootloader_input.tasks[task_id].load_task()`,
+	`ids.program_data_ptr = program_data_base = segments.add()`,
+	`from starkware.cairo.bootloaders.simple_bootloader.utils import load_program
+
+# Call load_program to load the program header and code to memory.
+program_address, program_data_size = load_program(
+    task=task, memory=memory, program_header=ids.program_header,
+    builtins_offset=ids.ProgramHeader.builtin_list)
+segments.finalize(program_data_base.segment_index, program_data_size)`,
+	`# Sanity check.
+assert ids.program_address == program_address`,
+}
+
+// A compiled program whose main function runs the hint loading the OS input, with
+// initial_carried_outputs stored at [fp]
+func osProgram(t *testing.T) []byte {
+	program := map[string]any{
+		"data":     []string{"0x208b7fff7fff7ffe"},
+		"builtins": []string{"output"},
+		"identifiers": map[string]any{
+			"__main__.main": map[string]any{"type": "function", "pc": 0, "decorators": []string{}},
+			"__main__.OsCarriedOutputs": map[string]any{"type": "struct", "size": 2, "members": map[string]any{
+				"messages_to_l1": map[string]any{"cairo_type": "felt*", "offset": 0},
+				"messages_to_l2": map[string]any{"cairo_type": "felt*", "offset": 1},
+			}},
+		},
+		"hints": map[string]any{"0": []any{map[string]any{
+			"code":               loadOsInputHint,
+			"accessible_scopes":  []string{"__main__", "__main__.main"},
+			"flow_tracking_data": map[string]any{"ap_tracking": map[string]int{"group": 0, "offset": 0}, "reference_ids": map[string]int{"__main__.main.initial_carried_outputs": 0}},
+		}}},
+		"reference_manager": map[string]any{"references": []any{
+			map[string]any{"ap_tracking_data": map[string]int{"group": 0, "offset": 0}, "pc": 0, "value": "[cast(fp, __main__.OsCarriedOutputs**)]"},
+		}},
+	}
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("Marshal error in test: %s", err)
+	}
+	return data
+}
+
+func TestLoadOsInput(t *testing.T) {
+	program, err := vm.ParseProgramStream(bytes.NewReader(osProgram(t)))
+	if err != nil {
+		t.Fatalf("ParseProgramStream error in test: %s", err)
+	}
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	execution := virtualMachine.Segments.AddSegment()
+	carriedOutputs := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableRelocatable(carriedOutputs))
+	virtualMachine.RunContext.Fp = execution
+
+	processor := starknet.NewOsHintProcessor(&program, json.RawMessage(`{"transactions": [{}, {}], "block_hash": 7}`))
+	if err := processor.PreStep(virtualMachine); err != nil {
+		t.Fatalf("PreStep failed with error: %s", err)
+	}
+	if processor.OsInput == nil || len(processor.OsInput.Transactions) != 2 {
+		t.Errorf("The OS input wasn't loaded: %+v", processor.OsInput)
+	}
+	for offset := uint(0); offset < 2; offset++ {
+		segment, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(carriedOutputs.SegmentIndex, offset))
+		if err != nil || segment.Offset != 0 || segment.SegmentIndex <= carriedOutputs.SegmentIndex {
+			t.Errorf("Expected a new segment at offset %d of the carried outputs, got %v, err: %v", offset, segment, err)
+		}
+	}
+}
+
+func TestBootloaderLoadsTask(t *testing.T) {
+	// n_tasks is at [fp], program_data_ptr and program_header at [fp + 1], program_address at [fp + 2]
+	references := []parser.HintReference{}
+	for _, value := range []string{"[cast(fp, felt*)]", "[cast(fp + 1, felt**)]", "[cast(fp + 1, __main__.ProgramHeader**)]", "[cast(fp + 2, felt**)]"} {
+		reference, err := parser.NewHintReference(parser.Reference{Value: value})
+		if err != nil {
+			t.Fatalf("NewHintReference error in test: %s", err)
+		}
+		references = append(references, reference)
+	}
+	referenceIds := map[string]int{"__main__.n_tasks": 0, "__main__.program_data_ptr": 1, "__main__.program_header": 2, "__main__.program_address": 3}
+	program := vm.Program{
+		Hints:      map[uint][]parser.HintParams{},
+		References: references,
+		Identifiers: map[string]parser.Identifier{
+			"__main__.ProgramHeader": {Type: parser.IdentifierStruct, Members: map[string]parser.Member{
+				"data_length": {Offset: 0}, "bootloader_version": {Offset: 1}, "program_main": {Offset: 2}, "n_builtins": {Offset: 3}, "builtin_list": {Offset: 4},
+			}},
+		},
+	}
+	for pc, code := range bootloaderHints {
+		program.Hints[uint(pc)] = []parser.HintParams{{Code: code, AccessibleScopes: []string{"__main__"}, FlowTrackingData: parser.FlowTrackingData{ReferenceIDS: referenceIds}}}
+	}
+	task, err := json.Marshal(map[string]any{"tasks": []any{map[string]any{
+		"type": "RunProgramTask", "program": json.RawMessage(osProgram(t)), "program_input": map[string]any{"transactions": []any{}},
+	}}})
+	if err != nil {
+		t.Fatalf("Marshal error in test: %s", err)
+	}
+
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	execution := virtualMachine.Segments.AddSegment()
+	virtualMachine.RunContext.Fp = execution
+	virtualMachine.RunContext.Ap = memory.NewRelocatable(execution.SegmentIndex, 3)
+	processor := starknet.NewOsHintProcessor(&program, task)
+	runHint := func(pc uint) {
+		virtualMachine.RunContext.Pc = memory.NewRelocatable(0, pc)
+		if err := processor.PreStep(virtualMachine); err != nil {
+			t.Fatalf("Hint at pc %d failed with error: %s", pc, err)
+		}
+	}
+
+	runHint(0)
+	runHint(1)
+	if nTasks := readFelt(t, virtualMachine, execution.SegmentIndex, 3); nTasks != felt(1) {
+		t.Fatalf("Expected 1 task, got %s", nTasks.String())
+	}
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableFelt(felt(1)))
+	runHint(2)
+	runHint(3)
+	runHint(4)
+
+	// The header is followed by the program's code: its single ret instruction
+	header, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(execution.SegmentIndex, 1))
+	if err != nil {
+		t.Fatalf("program_data_ptr wasn't written: %s", err)
+	}
+	expected := []lambdaworks.Felt{felt(5), felt(0), felt(0), felt(1), starknet.SyscallSelector("output"), felt(0x208b7fff7fff7ffe)}
+	for i, value := range expected {
+		if got := readFelt(t, virtualMachine, header.SegmentIndex, uint(i)); got != value {
+			t.Errorf("Wrong program data at offset %d: expected %s, got %s", i, value.String(), got.String())
+		}
+	}
+	if size := virtualMachine.Segments.FinalizedSizes[uint(header.SegmentIndex)]; size != 6 {
+		t.Errorf("Expected the program data segment to be finalized with size 6, got %d", size)
+	}
+
+	programAddress := memory.NewRelocatable(header.SegmentIndex, 5)
+	virtualMachine.Segments.Memory.Insert(memory.NewRelocatable(execution.SegmentIndex, 2), memory.NewMaybeRelocatableRelocatable(programAddress))
+	runHint(5)
+
+	// The hints of the task's program run with the task's input
+	carriedOutputs := virtualMachine.Segments.AddSegment()
+	frame := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(frame, memory.NewMaybeRelocatableRelocatable(carriedOutputs))
+	virtualMachine.RunContext.Fp = frame
+	virtualMachine.RunContext.Pc = programAddress
+	if err := processor.PreStep(virtualMachine); err != nil {
+		t.Fatalf("The task's hint failed with error: %s", err)
+	}
+	if processor.OsInput == nil || processor.OsInput.Transactions == nil {
+		t.Errorf("The task's hint should have loaded the OS input from the task's program_input")
+	}
+}
+
+func TestBootloaderWrongProgramAddress(t *testing.T) {
+	reference, _ := parser.NewHintReference(parser.Reference{Value: "[cast(fp, felt**)]"})
+	program := vm.Program{
+		References: []parser.HintReference{reference},
+		Hints: map[uint][]parser.HintParams{0: {{
+			Code:             bootloaderHints[5],
+			FlowTrackingData: parser.FlowTrackingData{ReferenceIDS: map[string]int{"__main__.program_address": 0}},
+		}}},
+	}
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	execution := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(4, 2)))
+	virtualMachine.RunContext.Fp = execution
+	if err := starknet.NewOsHintProcessor(&program, nil).PreStep(virtualMachine); err == nil {
+		t.Errorf("The sanity check should fail when no program was loaded at ids.program_address")
+	}
+}
@@ -0,0 +1,169 @@
+package starknet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+)
+
+// Read access to the Starknet state a contract runs against
+type StateReader interface {
+	// Returns the value stored at key in the storage of the given contract, zero if it was never written
+	GetStorageAt(contractAddress lambdaworks.Felt, key lambdaworks.Felt) (lambdaworks.Felt, error)
+}
+
+// A state where nothing was ever written, to run a contract on its own
+type EmptyState struct{}
+
+func (EmptyState) GetStorageAt(lambdaworks.Felt, lambdaworks.Felt) (lambdaworks.Felt, error) {
+	return lambdaworks.FeltZero(), nil
+}
+
+// Runs the effects of the syscalls of a contract. Syscall hint processors read the requests from
+// the VM's memory, call the handler, and write its responses back
+type SyscallHandler interface {
+	StorageRead(address lambdaworks.Felt) (lambdaworks.Felt, error)
+	StorageWrite(address lambdaworks.Felt, value lambdaworks.Felt) error
+	EmitEvent(event Event) error
+	SendMessageToL1(message MessageToL1) error
+	// Runs a call to another contract or class, returning its retdata
+	CallContract(call ContractCall) ([]lambdaworks.Felt, error)
+	// Deploys a contract, returning its address and the retdata of its constructor
+	Deploy(request DeployRequest) (lambdaworks.Felt, []lambdaworks.Felt, error)
+	ReplaceClass(classHash lambdaworks.Felt) error
+	ExecutionInfo() ExecutionInfo
+}
+
+// Implemented by handlers that can serve the get_block_hash syscall of Cairo 1 contracts
+type BlockHashReader interface {
+	GetBlockHash(blockNumber uint64) (lambdaworks.Felt, error)
+}
+
+// Returned by handlers when a syscall fails in a way the contract can recover from, e.g. when a
+// called contract reverts. Cairo 1 contracts get the reason as the syscall's failure response,
+// other errors abort the run
+type SyscallFailure struct {
+	Reason []lambdaworks.Felt
+}
+
+func (e *SyscallFailure) Error() string {
+	return fmt.Sprintf("syscall failed with reason %v", e.Reason)
+}
+
+type Event struct {
+	Keys []lambdaworks.Felt
+	Data []lambdaworks.Felt
+}
+
+type MessageToL1 struct {
+	ToAddress lambdaworks.Felt
+	Payload   []lambdaworks.Felt
+}
+
+type CallKind uint
+
+const (
+	// Calls a deployed contract
+	CallKindCallContract CallKind = iota
+	// Runs a function of a class in the context of the caller
+	CallKindLibraryCall
+	CallKindLibraryCallL1Handler
+	// Runs a function of a deployed contract in the context of the caller
+	CallKindDelegateCall
+	CallKindDelegateL1Handler
+)
+
+type ContractCall struct {
+	Kind CallKind
+	// Address of the called contract, or class hash for library calls
+	Target   lambdaworks.Felt
+	Selector lambdaworks.Felt
+	Calldata []lambdaworks.Felt
+}
+
+type DeployRequest struct {
+	ClassHash           lambdaworks.Felt
+	Salt                lambdaworks.Felt
+	ConstructorCalldata []lambdaworks.Felt
+	DeployFromZero      bool
+}
+
+// The context a contract runs in
+type ExecutionInfo struct {
+	CallerAddress    lambdaworks.Felt
+	ContractAddress  lambdaworks.Felt
+	SequencerAddress lambdaworks.Felt
+	BlockNumber      lambdaworks.Felt
+	BlockTimestamp   lambdaworks.Felt
+	// Selector of the entry point being run, only available to Cairo 1 contracts
+	EntryPointSelector lambdaworks.Felt
+	TxInfo             TxInfo
+}
+
+type TxInfo struct {
+	Version                lambdaworks.Felt
+	AccountContractAddress lambdaworks.Felt
+	MaxFee                 lambdaworks.Felt
+	Signature              []lambdaworks.Felt
+	TransactionHash        lambdaworks.Felt
+	ChainId                lambdaworks.Felt
+	Nonce                  lambdaworks.Felt
+}
+
+// A SyscallHandler running a single contract over a StateReader: storage writes, events and
+// messages are recorded, and reads see the writes made before them. Calls to other contracts
+// and deploys aren't supported, types embedding it can provide them.
+type BaseSyscallHandler struct {
+	State StateReader
+	Info  ExecutionInfo
+	// Storage written during the run, by address
+	StorageWrites map[lambdaworks.Felt]lambdaworks.Felt
+	Events        []Event
+	Messages      []MessageToL1
+	// Class the contract was replaced with, nil if it wasn't
+	ReplacedClass *lambdaworks.Felt
+}
+
+func NewBaseSyscallHandler(state StateReader, info ExecutionInfo) *BaseSyscallHandler {
+	return &BaseSyscallHandler{State: state, Info: info, StorageWrites: make(map[lambdaworks.Felt]lambdaworks.Felt)}
+}
+
+func (h *BaseSyscallHandler) StorageRead(address lambdaworks.Felt) (lambdaworks.Felt, error) {
+	if value, ok := h.StorageWrites[address]; ok {
+		return value, nil
+	}
+	return h.State.GetStorageAt(h.Info.ContractAddress, address)
+}
+
+func (h *BaseSyscallHandler) StorageWrite(address lambdaworks.Felt, value lambdaworks.Felt) error {
+	h.StorageWrites[address] = value
+	return nil
+}
+
+func (h *BaseSyscallHandler) EmitEvent(event Event) error {
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *BaseSyscallHandler) SendMessageToL1(message MessageToL1) error {
+	h.Messages = append(h.Messages, message)
+	return nil
+}
+
+func (h *BaseSyscallHandler) CallContract(call ContractCall) ([]lambdaworks.Felt, error) {
+	return nil, errors.New("contract calls are not supported")
+}
+
+func (h *BaseSyscallHandler) Deploy(request DeployRequest) (lambdaworks.Felt, []lambdaworks.Felt, error) {
+	return lambdaworks.FeltZero(), nil, errors.New("deploys are not supported")
+}
+
+func (h *BaseSyscallHandler) ReplaceClass(classHash lambdaworks.Felt) error {
+	h.ReplacedClass = &classHash
+	return nil
+}
+
+func (h *BaseSyscallHandler) ExecutionInfo() ExecutionInfo {
+	return h.Info
+}
@@ -0,0 +1,40 @@
+package starknet_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+)
+
+// A state with the storage of every contract set to key + 100
+type testState struct{}
+
+func (testState) GetStorageAt(contractAddress lambdaworks.Felt, key lambdaworks.Felt) (lambdaworks.Felt, error) {
+	return key.Add(lambdaworks.FeltFromUint64(100)), nil
+}
+
+func TestBaseSyscallHandlerStorage(t *testing.T) {
+	handler := starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})
+	value, err := handler.StorageRead(lambdaworks.FeltFromUint64(1))
+	if err != nil || value != lambdaworks.FeltFromUint64(101) {
+		t.Errorf("StorageRead should read from the state, got %s, err: %v", value.String(), err)
+	}
+	if err := handler.StorageWrite(lambdaworks.FeltFromUint64(1), lambdaworks.FeltFromUint64(7)); err != nil {
+		t.Fatalf("StorageWrite failed with error: %s", err)
+	}
+	value, err = handler.StorageRead(lambdaworks.FeltFromUint64(1))
+	if err != nil || value != lambdaworks.FeltFromUint64(7) {
+		t.Errorf("StorageRead should see the written value, got %s, err: %v", value.String(), err)
+	}
+}
+
+func TestBaseSyscallHandlerUnsupportedCalls(t *testing.T) {
+	handler := starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})
+	if _, err := handler.CallContract(starknet.ContractCall{}); err == nil {
+		t.Errorf("CallContract should fail")
+	}
+	if _, _, err := handler.Deploy(starknet.DeployRequest{}); err == nil {
+		t.Errorf("Deploy should fail")
+	}
+}
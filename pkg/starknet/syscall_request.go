@@ -0,0 +1,123 @@
+package starknet
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// A syscall request, read from the memory starting at ptr
+type syscallRequest struct {
+	proxy *vm.VMProxy
+	ptr   memory.Relocatable
+}
+
+func (r syscallRequest) address(field uint) memory.Relocatable {
+	return memory.NewRelocatable(r.ptr.SegmentIndex, r.ptr.Offset+field)
+}
+
+func (r syscallRequest) felt(field uint) (lambdaworks.Felt, error) {
+	return r.proxy.GetFeltFromVar(r.address(field))
+}
+
+// Reads an array given by its size and pointer fields
+func (r syscallRequest) felts(sizeField uint, ptrField uint) ([]lambdaworks.Felt, error) {
+	size, err := r.felt(sizeField)
+	if err != nil {
+		return nil, err
+	}
+	length, err := size.ToU64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid array size %s", size.String())
+	}
+	base, err := r.proxy.GetRelocatableFromVar(r.address(ptrField))
+	if err != nil {
+		return nil, err
+	}
+	return r.readArray(base, length)
+}
+
+// Reads length felts starting at base. The length comes from the contract, so it's checked
+// against the size of the segment before reading anything
+func (r syscallRequest) readArray(base memory.Relocatable, length uint64) ([]lambdaworks.Felt, error) {
+	// Temporary segments have no size yet, reading them fails at their first empty cell
+	if base.SegmentIndex >= 0 {
+		used := r.proxy.GetSegmentUsedSize(uint(base.SegmentIndex))
+		if base.Offset > used || length > uint64(used-base.Offset) {
+			return nil, fmt.Errorf("array of size %d at %d:%d runs past the end of its segment", length, base.SegmentIndex, base.Offset)
+		}
+	}
+	felts := make([]lambdaworks.Felt, 0)
+	for i := uint(0); i < uint(length); i++ {
+		felt, err := r.proxy.GetFeltFromVar(memory.NewRelocatable(base.SegmentIndex, base.Offset+i))
+		if err != nil {
+			return nil, err
+		}
+		felts = append(felts, felt)
+	}
+	return felts, nil
+}
+
+// Writes the response fields starting at the given field. Slices are written into new segments
+func (r syscallRequest) respond(field uint, values ...any) error {
+	for i, value := range values {
+		arg, err := r.proxy.GenArg(value)
+		if err != nil {
+			return err
+		}
+		if err := r.proxy.Insert(r.address(field+uint(i)), &arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads the storage key of a storage syscall, given by its address domain and key fields. Only
+// the domain 0 is supported, other domains fail the syscall
+func (r syscallRequest) storageKey() (lambdaworks.Felt, error) {
+	domain, err := r.felt(0)
+	if err != nil {
+		return lambdaworks.Felt{}, err
+	}
+	if !domain.IsZero() {
+		return lambdaworks.Felt{}, &SyscallFailure{Reason: []lambdaworks.Felt{SyscallSelector("Unsupported address domain")}}
+	}
+	return r.felt(1)
+}
+
+// Reads an array given by its start and end pointer fields
+func (r syscallRequest) feltRange(startField uint) ([]lambdaworks.Felt, error) {
+	start, err := r.proxy.GetRelocatableFromVar(r.address(startField))
+	if err != nil {
+		return nil, err
+	}
+	end, err := r.proxy.GetRelocatableFromVar(r.address(startField + 1))
+	if err != nil {
+		return nil, err
+	}
+	if end.SegmentIndex != start.SegmentIndex || end.Offset < start.Offset {
+		return nil, fmt.Errorf("invalid array range %d:%d to %d:%d", start.SegmentIndex, start.Offset, end.SegmentIndex, end.Offset)
+	}
+	return r.readArray(start, uint64(end.Offset-start.Offset))
+}
+
+// Writes felts into a new segment, returning its start and end pointers
+func (r syscallRequest) writeRange(felts []lambdaworks.Felt) ([]any, error) {
+	arg, err := r.proxy.GenArg(felts)
+	if err != nil {
+		return nil, err
+	}
+	start, _ := arg.GetRelocatable()
+	return []any{start, memory.NewRelocatable(start.SegmentIndex, start.Offset+uint(len(felts)))}, nil
+}
+
+// Writes the response of a failed syscall: [gas, 1, reason_start, reason_end]
+func (r syscallRequest) respondFailure(field uint, gas uint64, reason []lambdaworks.Felt) error {
+	reasonRange, err := r.writeRange(reason)
+	if err != nil {
+		return err
+	}
+	return r.respond(field, append([]any{lambdaworks.FeltFromUint64(gas), lambdaworks.FeltOne()}, reasonRange...)...)
+}
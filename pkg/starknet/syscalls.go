@@ -0,0 +1,291 @@
+package starknet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Gas charged for the syscalls of Cairo 1 contracts, as charged by the Starknet sequencer
+const (
+	StepGasCost             = 100
+	SyscallBaseGasCost      = 100 * StepGasCost
+	EntryPointGasCost       = 100*StepGasCost + 500*StepGasCost
+	CallContractGasCost     = SyscallBaseGasCost + 10*StepGasCost + EntryPointGasCost
+	DeployGasCost           = SyscallBaseGasCost + 200*StepGasCost + EntryPointGasCost
+	EmitEventGasCost        = SyscallBaseGasCost + 10*StepGasCost
+	GetBlockHashGasCost     = SyscallBaseGasCost + 50*StepGasCost
+	GetExecutionInfoGasCost = SyscallBaseGasCost + 10*StepGasCost
+	LibraryCallGasCost      = CallContractGasCost
+	ReplaceClassGasCost     = SyscallBaseGasCost + 50*StepGasCost
+	SendMessageToL1GasCost  = SyscallBaseGasCost + 50*StepGasCost
+	StorageReadGasCost      = SyscallBaseGasCost + 50*StepGasCost
+	StorageWriteGasCost     = SyscallBaseGasCost + 50*StepGasCost
+)
+
+// A Cairo 1 syscall: the number of fields of its request (after the selector and gas fields),
+// its gas cost and its implementation, which returns the fields of its response
+type syscall struct {
+	requestSize uint
+	gasCost     uint64
+	run         func(p *SyscallHintProcessor, request syscallRequest) ([]any, error)
+}
+
+var syscalls = map[lambdaworks.Felt]syscall{
+	SyscallSelector("CallContract"):     {4, CallContractGasCost, func(p *SyscallHintProcessor, r syscallRequest) ([]any, error) { return p.call(r, CallKindCallContract) }},
+	SyscallSelector("LibraryCall"):      {4, LibraryCallGasCost, func(p *SyscallHintProcessor, r syscallRequest) ([]any, error) { return p.call(r, CallKindLibraryCall) }},
+	SyscallSelector("Deploy"):           {5, DeployGasCost, (*SyscallHintProcessor).deploy},
+	SyscallSelector("EmitEvent"):        {4, EmitEventGasCost, (*SyscallHintProcessor).emitEvent},
+	SyscallSelector("GetBlockHash"):     {1, GetBlockHashGasCost, (*SyscallHintProcessor).getBlockHash},
+	SyscallSelector("GetExecutionInfo"): {0, GetExecutionInfoGasCost, (*SyscallHintProcessor).getExecutionInfo},
+	SyscallSelector("ReplaceClass"):     {1, ReplaceClassGasCost, (*SyscallHintProcessor).replaceClass},
+	SyscallSelector("SendMessageToL1"):  {3, SendMessageToL1GasCost, (*SyscallHintProcessor).sendMessageToL1},
+	SyscallSelector("StorageRead"):      {2, StorageReadGasCost, (*SyscallHintProcessor).storageRead},
+	SyscallSelector("StorageWrite"):     {3, StorageWriteGasCost, (*SyscallHintProcessor).storageWrite},
+}
+
+// Runs the syscalls of Cairo 1 contracts, which are requested through SystemCall hints. A request
+// is made of its selector, the gas left and its fields, and is followed by the response: the gas
+// left after the syscall, a failure flag and either the response fields or a revert reason
+type SyscallHintProcessor struct {
+	Handler SyscallHandler
+	Program *vm.Program
+}
+
+func NewSyscallHintProcessor(handler SyscallHandler, program *vm.Program) *SyscallHintProcessor {
+	return &SyscallHintProcessor{Handler: handler, Program: program}
+}
+
+// Runs the SystemCall hints of the instruction about to run. Other hints are left alone.
+// Meant to be installed as a PreStep hook
+func (p *SyscallHintProcessor) PreStep(v *vm.VirtualMachine) error {
+	pc := v.RunContext.Pc
+	if pc.SegmentIndex != 0 {
+		return nil
+	}
+	for _, hint := range p.Program.Cairo1Hints[pc.Offset] {
+		if hint.Name != "SystemCall" {
+			continue
+		}
+		var args struct {
+			System parser.ResOperand `json:"system"`
+		}
+		if err := json.Unmarshal(hint.Args, &args); err != nil {
+			return fmt.Errorf("invalid SystemCall hint: %w", err)
+		}
+		value, err := v.GetResOperandValue(args.System)
+		if err != nil {
+			return fmt.Errorf("SystemCall: %w", err)
+		}
+		syscallPtr, ok := value.GetRelocatable()
+		if !ok {
+			return errors.New("SystemCall: system is not a pointer")
+		}
+		if err := p.ExecuteSyscall(vm.NewVMProxy(v), syscallPtr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs the syscall whose request starts at syscallPtr, writing its response after the request.
+// Running out of gas and SyscallFailure errors are reported to the contract as failures
+func (p *SyscallHintProcessor) ExecuteSyscall(proxy *vm.VMProxy, syscallPtr memory.Relocatable) error {
+	header := syscallRequest{proxy: proxy, ptr: syscallPtr}
+	selector, err := header.felt(0)
+	if err != nil {
+		return err
+	}
+	gasFelt, err := header.felt(1)
+	if err != nil {
+		return err
+	}
+	gas, err := gasFelt.ToU64()
+	if err != nil {
+		return fmt.Errorf("invalid gas %s", gasFelt.String())
+	}
+	s, ok := syscalls[selector]
+	if !ok {
+		return fmt.Errorf("unknown syscall selector %s", selector.ToHexString())
+	}
+	request := syscallRequest{proxy: proxy, ptr: header.address(2)}
+	response := 2 + s.requestSize
+
+	if gas < s.gasCost {
+		return header.respondFailure(response, gas, []lambdaworks.Felt{SyscallSelector("Out of gas")})
+	}
+	gas -= s.gasCost
+	body, err := s.run(p, request)
+	var failure *SyscallFailure
+	if errors.As(err, &failure) {
+		return header.respondFailure(response, gas, failure.Reason)
+	}
+	if err != nil {
+		return err
+	}
+	return header.respond(response, append([]any{lambdaworks.FeltFromUint64(gas), lambdaworks.FeltZero()}, body...)...)
+}
+
+// Runs a call syscall: [target, selector, calldata_start, calldata_end] -> [retdata_start, retdata_end]
+func (p *SyscallHintProcessor) call(request syscallRequest, kind CallKind) ([]any, error) {
+	target, err := request.felt(0)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := request.felt(1)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := request.feltRange(2)
+	if err != nil {
+		return nil, err
+	}
+	retdata, err := p.Handler.CallContract(ContractCall{Kind: kind, Target: target, Selector: selector, Calldata: calldata})
+	if err != nil {
+		return nil, err
+	}
+	return request.writeRange(retdata)
+}
+
+// Runs a deploy syscall: [class_hash, salt, calldata_start, calldata_end, deploy_from_zero] ->
+// [contract_address, retdata_start, retdata_end]
+func (p *SyscallHintProcessor) deploy(request syscallRequest) ([]any, error) {
+	classHash, err := request.felt(0)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := request.felt(1)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := request.feltRange(2)
+	if err != nil {
+		return nil, err
+	}
+	deployFromZero, err := request.felt(4)
+	if err != nil {
+		return nil, err
+	}
+	address, retdata, err := p.Handler.Deploy(DeployRequest{ClassHash: classHash, Salt: salt, ConstructorCalldata: calldata, DeployFromZero: !deployFromZero.IsZero()})
+	if err != nil {
+		return nil, err
+	}
+	retdataRange, err := request.writeRange(retdata)
+	if err != nil {
+		return nil, err
+	}
+	return append([]any{address}, retdataRange...), nil
+}
+
+// [keys_start, keys_end, data_start, data_end] -> []
+func (p *SyscallHintProcessor) emitEvent(request syscallRequest) ([]any, error) {
+	keys, err := request.feltRange(0)
+	if err != nil {
+		return nil, err
+	}
+	data, err := request.feltRange(2)
+	if err != nil {
+		return nil, err
+	}
+	return nil, p.Handler.EmitEvent(Event{Keys: keys, Data: data})
+}
+
+// [block_number] -> [block_hash]
+func (p *SyscallHintProcessor) getBlockHash(request syscallRequest) ([]any, error) {
+	reader, ok := p.Handler.(BlockHashReader)
+	if !ok {
+		return nil, errors.New("the syscall handler doesn't support get_block_hash")
+	}
+	blockNumber, err := request.felt(0)
+	if err != nil {
+		return nil, err
+	}
+	number, err := blockNumber.ToU64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number %s", blockNumber.String())
+	}
+	hash, err := reader.GetBlockHash(number)
+	if err != nil {
+		return nil, err
+	}
+	return []any{hash}, nil
+}
+
+// [] -> [execution_info], a pointer to an ExecutionInfo struct:
+// [block_info, tx_info, caller_address, contract_address, entry_point_selector], where
+// block_info points to [block_number, block_timestamp, sequencer_address] and tx_info to
+// [version, account_contract_address, max_fee, signature_start, signature_end, transaction_hash, chain_id, nonce]
+func (p *SyscallHintProcessor) getExecutionInfo(request syscallRequest) ([]any, error) {
+	info := p.Handler.ExecutionInfo()
+	signature, err := request.writeRange(info.TxInfo.Signature)
+	if err != nil {
+		return nil, err
+	}
+	txInfo := append([]any{info.TxInfo.Version, info.TxInfo.AccountContractAddress, info.TxInfo.MaxFee}, signature...)
+	txInfo = append(txInfo, info.TxInfo.TransactionHash, info.TxInfo.ChainId, info.TxInfo.Nonce)
+	executionInfo := []any{
+		[]any{info.BlockNumber, info.BlockTimestamp, info.SequencerAddress},
+		txInfo,
+		info.CallerAddress,
+		info.ContractAddress,
+		info.EntryPointSelector,
+	}
+	ptr, err := request.proxy.GenArg(executionInfo)
+	if err != nil {
+		return nil, err
+	}
+	return []any{ptr}, nil
+}
+
+// [class_hash] -> []
+func (p *SyscallHintProcessor) replaceClass(request syscallRequest) ([]any, error) {
+	classHash, err := request.felt(0)
+	if err != nil {
+		return nil, err
+	}
+	return nil, p.Handler.ReplaceClass(classHash)
+}
+
+// [to_address, payload_start, payload_end] -> []
+func (p *SyscallHintProcessor) sendMessageToL1(request syscallRequest) ([]any, error) {
+	toAddress, err := request.felt(0)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := request.feltRange(1)
+	if err != nil {
+		return nil, err
+	}
+	return nil, p.Handler.SendMessageToL1(MessageToL1{ToAddress: toAddress, Payload: payload})
+}
+
+// [address_domain, key] -> [value]
+func (p *SyscallHintProcessor) storageRead(request syscallRequest) ([]any, error) {
+	key, err := request.storageKey()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.Handler.StorageRead(key)
+	if err != nil {
+		return nil, err
+	}
+	return []any{value}, nil
+}
+
+// [address_domain, key, value] -> []
+func (p *SyscallHintProcessor) storageWrite(request syscallRequest) ([]any, error) {
+	key, err := request.storageKey()
+	if err != nil {
+		return nil, err
+	}
+	value, err := request.felt(2)
+	if err != nil {
+		return nil, err
+	}
+	return nil, p.Handler.StorageWrite(key, value)
+}
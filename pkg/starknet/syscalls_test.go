@@ -0,0 +1,172 @@
+package starknet_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/starknet"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func readRange(t *testing.T, virtualMachine *vm.VirtualMachine, segment int, offset uint) []lambdaworks.Felt {
+	start, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(segment, offset))
+	if err != nil {
+		t.Fatalf("Failed to read the range start: %s", err)
+	}
+	end, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(segment, offset+1))
+	if err != nil {
+		t.Fatalf("Failed to read the range end: %s", err)
+	}
+	felts := []lambdaworks.Felt{}
+	for i := start.Offset; i < end.Offset; i++ {
+		felts = append(felts, readFelt(t, virtualMachine, start.SegmentIndex, i))
+	}
+	return felts
+}
+
+func TestStorageRead(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageRead"), felt(20000), felt(0), felt(5))
+	processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if gas := readFelt(t, virtualMachine, 0, 4); gas != felt(20000-starknet.StorageReadGasCost) {
+		t.Errorf("Wrong gas left: %s", gas.String())
+	}
+	if readFelt(t, virtualMachine, 0, 5) != felt(0) || readFelt(t, virtualMachine, 0, 6) != felt(105) {
+		t.Errorf("Wrong response")
+	}
+}
+
+func TestStorageReadUnsupportedDomain(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageRead"), felt(20000), felt(1), felt(5))
+	processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if readFelt(t, virtualMachine, 0, 5) != felt(1) {
+		t.Errorf("The syscall should have failed")
+	}
+	if reason := readRange(t, virtualMachine, 0, 6); !reflect.DeepEqual(reason, []lambdaworks.Felt{starknet.SyscallSelector("Unsupported address domain")}) {
+		t.Errorf("Wrong failure reason: %v", reason)
+	}
+}
+
+func TestSyscallOutOfGas(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageWrite"), felt(100), felt(0), felt(5), felt(9))
+	handler := starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})
+	processor := starknet.NewSyscallHintProcessor(handler, &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if readFelt(t, virtualMachine, 0, 5) != felt(100) || readFelt(t, virtualMachine, 0, 6) != felt(1) {
+		t.Errorf("The syscall should have failed without charging gas")
+	}
+	if reason := readRange(t, virtualMachine, 0, 7); !reflect.DeepEqual(reason, []lambdaworks.Felt{starknet.SyscallSelector("Out of gas")}) {
+		t.Errorf("Wrong failure reason: %v", reason)
+	}
+	if len(handler.StorageWrites) != 0 {
+		t.Errorf("The write shouldn't have run")
+	}
+}
+
+// Reverts every call with its calldata as the reason
+type revertingHandler struct {
+	*starknet.BaseSyscallHandler
+}
+
+func (h *revertingHandler) CallContract(call starknet.ContractCall) ([]lambdaworks.Felt, error) {
+	return nil, &starknet.SyscallFailure{Reason: call.Calldata}
+}
+
+func TestCallContractRevert(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("CallContract"), felt(100000), felt(10), felt(20), span{felt(7)})
+
+	processor := starknet.NewSyscallHintProcessor(&revertingHandler{starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{})}, &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	if readFelt(t, virtualMachine, 0, 6) != felt(100000-starknet.CallContractGasCost) || readFelt(t, virtualMachine, 0, 7) != felt(1) {
+		t.Errorf("The call should have failed after charging gas")
+	}
+	if reason := readRange(t, virtualMachine, 0, 8); !reflect.DeepEqual(reason, []lambdaworks.Felt{felt(7)}) {
+		t.Errorf("Wrong revert reason: %v", reason)
+	}
+}
+
+func TestEmitEventInvalidRange(t *testing.T) {
+	// The keys are read from the request segment itself, which holds 6 cells
+	for _, keys := range [][2]uint{{5, 3}, {0, 1 << 63}} {
+		virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("EmitEvent"), felt(100000), memory.NewRelocatable(0, keys[0]), memory.NewRelocatable(0, keys[1]), span{})
+		processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+		if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err == nil {
+			t.Errorf("ExecuteSyscall should fail for keys from 0:%d to 0:%d", keys[0], keys[1])
+		}
+	}
+}
+
+func TestGetExecutionInfo(t *testing.T) {
+	info := starknet.ExecutionInfo{
+		BlockNumber:        felt(3),
+		ContractAddress:    felt(4),
+		EntryPointSelector: felt(5),
+		TxInfo:             starknet.TxInfo{Signature: []lambdaworks.Felt{felt(6)}, Nonce: felt(7)},
+	}
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("GetExecutionInfo"), felt(20000))
+	processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, info), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err != nil {
+		t.Fatalf("ExecuteSyscall failed with error: %s", err)
+	}
+	executionInfo, err := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(0, 4))
+	if err != nil {
+		t.Fatalf("The response should be a pointer to the execution info: %s", err)
+	}
+	blockInfo, _ := virtualMachine.Segments.Memory.GetRelocatable(executionInfo)
+	txInfo, _ := virtualMachine.Segments.Memory.GetRelocatable(memory.NewRelocatable(executionInfo.SegmentIndex, 1))
+	if readFelt(t, virtualMachine, blockInfo.SegmentIndex, 0) != felt(3) {
+		t.Errorf("Wrong block number")
+	}
+	if readFelt(t, virtualMachine, executionInfo.SegmentIndex, 3) != felt(4) || readFelt(t, virtualMachine, executionInfo.SegmentIndex, 4) != felt(5) {
+		t.Errorf("Wrong contract address or entry point selector")
+	}
+	if signature := readRange(t, virtualMachine, txInfo.SegmentIndex, 3); !reflect.DeepEqual(signature, []lambdaworks.Felt{felt(6)}) {
+		t.Errorf("Wrong signature: %v", signature)
+	}
+	if readFelt(t, virtualMachine, txInfo.SegmentIndex, 7) != felt(7) {
+		t.Errorf("Wrong nonce")
+	}
+}
+
+func TestGetBlockHashUnsupported(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("GetBlockHash"), felt(20000), felt(1))
+	processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), &vm.Program{})
+	if err := processor.ExecuteSyscall(vm.NewVMProxy(virtualMachine), ptr); err == nil {
+		t.Errorf("ExecuteSyscall should fail when the handler can't read block hashes")
+	}
+}
+
+func TestSystemCallHint(t *testing.T) {
+	virtualMachine, ptr := syscallVM(t, starknet.SyscallSelector("StorageRead"), felt(20000), felt(0), felt(5))
+	execution := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableRelocatable(ptr))
+	virtualMachine.RunContext.Pc = memory.NewRelocatable(0, 0)
+	virtualMachine.RunContext.Fp = memory.NewRelocatable(execution.SegmentIndex, 1)
+	virtualMachine.RunContext.Ap = virtualMachine.RunContext.Fp
+
+	var hint parser.CasmHint
+	if err := json.Unmarshal([]byte(`{"SystemCall": {"system": {"Deref": {"register": "FP", "offset": -1}}}}`), &hint); err != nil {
+		t.Fatalf("Unmarshal error in test: %s", err)
+	}
+	program := &vm.Program{Cairo1Hints: map[uint][]parser.CasmHint{0: {hint}}}
+	processor := starknet.NewSyscallHintProcessor(starknet.NewBaseSyscallHandler(testState{}, starknet.ExecutionInfo{}), program)
+	if err := processor.PreStep(virtualMachine); err != nil {
+		t.Fatalf("PreStep failed with error: %s", err)
+	}
+	if readFelt(t, virtualMachine, 0, 6) != felt(105) {
+		t.Errorf("Wrong storage value")
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Represents a cairo Uint256 struct: a 256 bit unsigned integer split into two 128 bit felts.
+type Uint256 struct {
+	Low  lambdaworks.Felt
+	High lambdaworks.Felt
+}
+
+var two128 = new(big.Int).Lsh(big.NewInt(1), 128)
+var mask128 = new(big.Int).Sub(two128, big.NewInt(1))
+var two256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// Splits the canonical value of a felt into its low and high 128 bits.
+func Uint256FromFelt(felt lambdaworks.Felt) Uint256 {
+	return Uint256FromBigInt(felt.ToBigInt())
+}
+
+// Builds a Uint256 from a big.Int, reducing it modulo 2^256.
+func Uint256FromBigInt(n *big.Int) Uint256 {
+	value := new(big.Int).Mod(n, two256)
+	return Uint256{
+		Low:  lambdaworks.FeltFromBigInt(new(big.Int).And(value, mask128)),
+		High: lambdaworks.FeltFromBigInt(new(big.Int).Rsh(value, 128)),
+	}
+}
+
+// Returns High * 2^128 + Low.
+func (u Uint256) ToBigInt() *big.Int {
+	result := new(big.Int).Lsh(u.High.ToBigInt(), 128)
+	return result.Add(result, u.Low.ToBigInt())
+}
+
+// Returns u + other modulo 2^256, and whether the addition overflowed.
+func (u Uint256) Add(other Uint256) (Uint256, bool) {
+	sum := new(big.Int).Add(u.ToBigInt(), other.ToBigInt())
+	return Uint256FromBigInt(sum), sum.Cmp(two256) >= 0
+}
+
+// Returns the 512 bit product u * other, split into its low and high 256 bits.
+func (u Uint256) Mul(other Uint256) (Uint256, Uint256) {
+	product := new(big.Int).Mul(u.ToBigInt(), other.ToBigInt())
+	return Uint256FromBigInt(product), Uint256FromBigInt(new(big.Int).Rsh(product, 256))
+}
+
+// Returns the quotient and remainder of u / div, or an error if div is zero.
+func (u Uint256) DivRem(div Uint256) (Uint256, Uint256, error) {
+	divisor := div.ToBigInt()
+	if divisor.Sign() == 0 {
+		return Uint256{}, Uint256{}, errors.New("Uint256 division by zero")
+	}
+	quotient, remainder := new(big.Int).QuoRem(u.ToBigInt(), divisor, new(big.Int))
+	return Uint256FromBigInt(quotient), Uint256FromBigInt(remainder), nil
+}
+
+// Reads a Uint256 stored as two consecutive felts (low, high) starting at addr.
+func Uint256FromMemory(mem *memory.Memory, addr memory.Relocatable) (Uint256, error) {
+	low, err := mem.GetFelt(addr)
+	if err != nil {
+		return Uint256{}, fmt.Errorf("Failed to read Uint256 low at %d:%d: %w", addr.SegmentIndex, addr.Offset, err)
+	}
+	highAddr, err := addr.AddUint(1)
+	if err != nil {
+		return Uint256{}, err
+	}
+	high, err := mem.GetFelt(highAddr)
+	if err != nil {
+		return Uint256{}, fmt.Errorf("Failed to read Uint256 high at %d:%d: %w", highAddr.SegmentIndex, highAddr.Offset, err)
+	}
+	return Uint256{Low: low, High: high}, nil
+}
+
+// Writes the Uint256 as two consecutive felts (low, high) starting at addr.
+func (u Uint256) WriteToMemory(mem *memory.Memory, addr memory.Relocatable) error {
+	if err := mem.Insert(addr, memory.NewMaybeRelocatableFelt(u.Low)); err != nil {
+		return err
+	}
+	highAddr, err := addr.AddUint(1)
+	if err != nil {
+		return err
+	}
+	return mem.Insert(highAddr, memory.NewMaybeRelocatableFelt(u.High))
+}
@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/utils"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestUint256FromFelt(t *testing.T) {
+	felt := lambdaworks.FeltFromHex("0x1234000000000000000000000000000000ab")
+	expected := utils.Uint256{Low: lambdaworks.FeltFromUint64(0xab), High: lambdaworks.FeltFromUint64(0x1234)}
+	if got := utils.Uint256FromFelt(felt); got != expected {
+		t.Errorf("TestUint256FromFelt failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestUint256ToBigInt(t *testing.T) {
+	u := utils.Uint256{Low: lambdaworks.FeltFromUint64(1), High: lambdaworks.FeltFromUint64(1)}
+	expected := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	if got := u.ToBigInt(); got.Cmp(expected) != 0 {
+		t.Errorf("TestUint256ToBigInt failed. Expected %v, Got: %v", expected, got)
+	}
+}
+
+func TestUint256AddWithCarry(t *testing.T) {
+	max := utils.Uint256FromBigInt(big.NewInt(-1))
+	one := utils.Uint256{Low: lambdaworks.FeltOne(), High: lambdaworks.FeltZero()}
+	sum, carry := max.Add(one)
+	if !carry || sum != (utils.Uint256{Low: lambdaworks.FeltZero(), High: lambdaworks.FeltZero()}) {
+		t.Errorf("TestUint256AddWithCarry failed. Got: %v, carry: %v", sum, carry)
+	}
+}
+
+func TestUint256Add(t *testing.T) {
+	a := utils.Uint256FromBigInt(big.NewInt(20))
+	b := utils.Uint256FromBigInt(big.NewInt(6))
+	sum, carry := a.Add(b)
+	if carry || sum != utils.Uint256FromBigInt(big.NewInt(26)) {
+		t.Errorf("TestUint256Add failed. Got: %v, carry: %v", sum, carry)
+	}
+}
+
+func TestUint256Mul(t *testing.T) {
+	// (2^256 - 1)^2 = 2^512 - 2^257 + 1
+	max := utils.Uint256FromBigInt(big.NewInt(-1))
+	low, high := max.Mul(max)
+	if low != utils.Uint256FromBigInt(big.NewInt(1)) || high != utils.Uint256FromBigInt(big.NewInt(-2)) {
+		t.Errorf("TestUint256Mul failed. Got: low %v, high %v", low, high)
+	}
+}
+
+func TestUint256DivRem(t *testing.T) {
+	quotient, remainder, err := utils.Uint256FromBigInt(big.NewInt(26)).DivRem(utils.Uint256FromBigInt(big.NewInt(7)))
+	if err != nil || quotient != utils.Uint256FromBigInt(big.NewInt(3)) || remainder != utils.Uint256FromBigInt(big.NewInt(5)) {
+		t.Errorf("TestUint256DivRem failed. Got: %v, %v, err: %v", quotient, remainder, err)
+	}
+}
+
+func TestUint256DivRemByZero(t *testing.T) {
+	if _, _, err := utils.Uint256FromBigInt(big.NewInt(26)).DivRem(utils.Uint256{}); err == nil {
+		t.Errorf("TestUint256DivRemByZero should fail")
+	}
+}
+
+func TestUint256MemoryRoundTrip(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	base := mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	u := utils.Uint256{Low: lambdaworks.FeltFromUint64(26), High: lambdaworks.FeltFromUint64(7)}
+
+	if err := u.WriteToMemory(mem, base); err != nil {
+		t.Errorf("WriteToMemory failed with error: %s", err)
+	}
+	got, err := utils.Uint256FromMemory(mem, base)
+	if err != nil || !reflect.DeepEqual(got, u) {
+		t.Errorf("TestUint256MemoryRoundTrip failed. Expected %v, Got: %v, err: %v", u, got, err)
+	}
+}
+
+func TestUint256FromMemoryMissingHigh(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	base := mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(base, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(26)))
+
+	if _, err := utils.Uint256FromMemory(mem, base); err == nil {
+		t.Errorf("Uint256FromMemory should fail when the high part is missing")
+	}
+}
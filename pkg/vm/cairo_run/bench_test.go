@@ -0,0 +1,57 @@
+package cairo_run_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+// Runs a compiled program b.N times, from the runner's initialization to the relocation of its
+// trace and memory. The program is parsed once, outside of the timer. Skips programs that
+// aren't compiled, which make bench does, and programs using builtins the VM can't run yet
+func benchmarkProgram(b *testing.B, path string) {
+	file, err := os.Open(filepath.Join(corpusDir, path))
+	if os.IsNotExist(err) {
+		b.Skipf("%s is not compiled, run make bench", path)
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+	program, err := vm.ParseProgramStream(file)
+	file.Close()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := runners.NewCairoRunner(program, false); err != nil {
+		b.Skipf("%s can't run yet: %s", path, err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cairo_run.CairoRunProgram(program, cairo_run.CairoRunConfig{}); err != nil {
+			b.Fatalf("Program execution failed with error: %s", err)
+		}
+	}
+}
+
+func BenchmarkFibonacci(b *testing.B) {
+	benchmarkProgram(b, "fibonacci.json")
+}
+
+func BenchmarkFactorial(b *testing.B) {
+	benchmarkProgram(b, "factorial.json")
+}
+
+// Uses the range_check and bitwise builtins
+func BenchmarkKeccakHeavy(b *testing.B) {
+	benchmarkProgram(b, "benchmarks/keccak_heavy.json")
+}
+
+func BenchmarkBigMemory(b *testing.B) {
+	benchmarkProgram(b, "benchmarks/big_memory.json")
+}
@@ -1,14 +1,13 @@
 package cairo_run
 
 import (
-	"encoding/binary"
-	"errors"
-	"fmt"
+	"bufio"
 	"io"
-	"sort"
+	"os"
+	"time"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
-	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/metrics"
 	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
 )
@@ -20,113 +19,110 @@ type RunResources struct {
 type CairoRunConfig struct {
 	TraceFile  *string
 	MemoryFile *string
+	ProofMode  bool
+	// Layout the program runs on, plain if empty
+	Layout string
+	// Installed on the vm before running the program
+	Hooks vm.Hooks
+	// Recorded once the run is finished, can be nil
+	Metrics *metrics.Metrics
+	// Spill the trace to a temporary file instead of keeping it in memory, for runs whose trace
+	// doesn't fit in RAM. The caller must close the runner's Vm.TraceSpill to remove the file
+	SpillTrace bool
+	// Check that hints didn't break the guarantees of the run once it ends, see VerifySecureRunner
+	SecureRun bool
+	// Fail the run if hints write to code, see Memory.SetForbidCodeWrites
+	ForbidCodeWrites bool
 }
 
-func CairoRun(programPath string) (*runners.CairoRunner, error) {
-	compiledProgram := parser.Parse(programPath)
-	programJson := vm.DeserializeProgramJson(compiledProgram)
-
-	cairoRunner, err := runners.NewCairoRunner(programJson)
+func CairoRun(programPath string, config CairoRunConfig) (*runners.CairoRunner, error) {
+	programFile, err := os.Open(programPath)
 	if err != nil {
 		return nil, err
 	}
-	end, err := cairoRunner.Initialize()
+	defer programFile.Close()
+	programJson, err := vm.ParseProgramStream(bufio.NewReader(programFile))
 	if err != nil {
 		return nil, err
 	}
-	err = cairoRunner.RunUntilPC(end)
+	return CairoRunProgram(programJson, config)
+}
+
+// Runs an already parsed program from its main entrypoint (or __start__ in proof mode) and relocates the run
+func CairoRunProgram(program vm.Program, config CairoRunConfig) (*runners.CairoRunner, error) {
+	layout := config.Layout
+	if layout == "" {
+		layout = "plain"
+	}
+	cairoRunner, err := runners.NewCairoRunnerWithLayout(program, layout, config.ProofMode)
+	if err != nil {
+		return nil, err
+	}
+	cairoRunner.Vm.Hooks = config.Hooks
+	cairoRunner.Vm.Segments.Memory.SetForbidCodeWrites(config.ForbidCodeWrites)
+	if config.SpillTrace {
+		spill, err := vm.NewTraceSpill("")
+		if err != nil {
+			return nil, err
+		}
+		cairoRunner.Vm.TraceSpill = spill
+	}
+	err = run(cairoRunner, config)
+	if err != nil && cairoRunner.Vm.TraceSpill != nil {
+		cairoRunner.Vm.TraceSpill.Close()
+	}
 	if err != nil {
 		return nil, err
 	}
+	config.Metrics.RecordRun(&cairoRunner.Vm)
+	relocationStart := time.Now()
 	err = cairoRunner.Vm.Relocate()
+	config.Metrics.ObserveRelocation(time.Since(relocationStart))
 	return cairoRunner, err
 }
 
-// Writes the trace binary representation.
-//
-// Bincode encodes to little endian by default and each trace entry is composed of
-// 3 usize values that are padded to always reach 64 bit size.
-func WriteEncodedTrace(relocatedTrace []vm.RelocatedTraceEntry, dest io.Writer) error {
-	for i, entry := range relocatedTrace {
-		ap_buffer := make([]byte, 8)
-		ap, err := entry.Ap.ToU64()
-		if err != nil {
-			return err
-		}
-		binary.LittleEndian.PutUint64(ap_buffer, ap)
-		_, err = dest.Write(ap_buffer)
-		if err != nil {
-			return encodeTraceError(i, err)
-		}
-
-		fp_buffer := make([]byte, 8)
-		fp, err := entry.Fp.ToU64()
-		if err != nil {
-			return err
-		}
-		binary.LittleEndian.PutUint64(fp_buffer, fp)
-		_, err = dest.Write(fp_buffer)
-		if err != nil {
-			return encodeTraceError(i, err)
-		}
-
-		pc_buffer := make([]byte, 8)
-		pc, err := entry.Pc.ToU64()
-		if err != nil {
+func run(cairoRunner *runners.CairoRunner, config CairoRunConfig) error {
+	end, err := cairoRunner.Initialize()
+	if err != nil {
+		return err
+	}
+	err = cairoRunner.RunUntilPC(end)
+	if err != nil {
+		return err
+	}
+	err = cairoRunner.EndRun()
+	if err != nil {
+		return err
+	}
+	// main returns the final pointers of its builtins
+	if err := cairoRunner.ReadReturnValues(); err != nil {
+		return err
+	}
+	if config.ProofMode {
+		if err := cairoRunner.FinalizeSegments(); err != nil {
 			return err
 		}
-		binary.LittleEndian.PutUint64(pc_buffer, pc)
-		_, err = dest.Write(pc_buffer)
-		if err != nil {
-			return encodeTraceError(i, err)
-		}
 	}
-
+	if config.SecureRun {
+		return cairoRunner.VerifySecureRunner()
+	}
 	return nil
 }
 
-func encodeTraceError(i int, err error) error {
-	return errors.New(fmt.Sprintf("failed to encode trace at position %d, serialize error: %s", i, err))
+// Writes the trace binary representation.
+//
+// Bincode encodes to little endian by default and each trace entry is composed of
+// 3 usize values that are padded to always reach 64 bit size.
+func WriteEncodedTrace(relocatedTrace []vm.RelocatedTraceEntry, dest io.Writer) error {
+	return vm.EncodeTrace(relocatedTrace, dest)
 }
 
 // Writes a binary representation of the relocated memory.
 //
-// The memory pairs (address, value) are encoded and concatenated:
+// The memory pairs (address, value) are encoded and concatenated in address order,
+// skipping memory holes:
 // * address -> 8-byte encoded
 // * value -> 32-byte encoded
-func WriteEncodedMemory(relocatedMemory map[uint]lambdaworks.Felt, dest io.Writer) error {
-	// create a slice to store keys of the relocatedMemory map
-	keysMap := make([]uint, 0, len(relocatedMemory))
-	for k := range relocatedMemory {
-		keysMap = append(keysMap, k)
-	}
-
-	// sort the keys
-	sort.Slice(keysMap, func(i, j int) bool { return keysMap[i] < keysMap[j] })
-
-	// iterate over the `relocatedMemory` map in sorted key order
-	for _, k := range keysMap {
-
-		// write the key
-		keyArray := make([]byte, 8)
-		binary.LittleEndian.PutUint64(keyArray, uint64(k))
-		_, err := dest.Write(keyArray)
-		if err != nil {
-			return encodeMemoryError(k, err)
-		}
-
-		// write the value
-		valueArray := relocatedMemory[k].ToLeBytes()
-
-		_, err = dest.Write(valueArray[:])
-		if err != nil {
-			return encodeMemoryError(k, err)
-		}
-	}
-
-	return nil
-}
-
-func encodeMemoryError(i uint, err error) error {
-	return fmt.Errorf("failed to encode trace at position %d, serialize error: %s", i, err)
+func WriteEncodedMemory(relocatedMemory []*lambdaworks.Felt, dest io.Writer) error {
+	return vm.EncodeMemory(relocatedMemory, dest)
 }
@@ -1,9 +1,14 @@
 package cairo_run_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"runtime"
 	"testing"
 
+	"github.com/lambdaclass/cairo-vm.go/pkg/metrics"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
 )
 
@@ -13,9 +18,105 @@ import (
 // - Asserting expected trace values
 // - Asserting memory_holes
 func TestFibonacci(t *testing.T) {
-	_, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json")
+	_, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{})
 	if err != nil {
 		t.Errorf("Program execution failed with error: %s", err)
 	}
 	fmt.Println(err)
 }
+
+type counter struct{ total float64 }
+
+func (c *counter) Add(delta float64) { c.total += delta }
+func (c *counter) Observe(x float64) { c.total += x }
+
+func TestFibonacciMetrics(t *testing.T) {
+	var steps, relocation counter
+	config := cairo_run.CairoRunConfig{Metrics: &metrics.Metrics{StepsExecuted: &steps, RelocationSeconds: &relocation}}
+	runner, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", config)
+	if err != nil {
+		t.Fatalf("Program execution failed with error: %s", err)
+	}
+	if steps.total != float64(runner.Vm.CurrentStep) || relocation.total <= 0 {
+		t.Errorf("Wrong metrics: steps %v, relocation time %v", steps.total, relocation.total)
+	}
+}
+
+func TestFibonacciVerifyTrace(t *testing.T) {
+	runner, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{})
+	if err != nil {
+		t.Fatalf("Program execution failed with error: %s", err)
+	}
+	if err := vm.VerifyTrace(runner.Program, runner.Vm.RelocatedTrace, runner.Vm.RelocatedMemory); err != nil {
+		t.Errorf("VerifyTrace failed with error: %s", err)
+	}
+}
+
+func TestFibonacciSpilledTrace(t *testing.T) {
+	runner, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{})
+	if err != nil {
+		t.Fatalf("Program execution failed with error: %s", err)
+	}
+	spilled, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{SpillTrace: true})
+	if err != nil {
+		t.Fatalf("Program execution failed with error: %s", err)
+	}
+	defer spilled.Vm.TraceSpill.Close()
+	if len(spilled.Vm.Trace) != 0 || spilled.Vm.TraceLen() != runner.Vm.TraceLen() {
+		t.Errorf("Expected the %d entries of the trace to be spilled, got %d in memory and %d spilled", runner.Vm.TraceLen(), len(spilled.Vm.Trace), spilled.Vm.TraceLen())
+	}
+
+	var expected, got bytes.Buffer
+	if err := runner.Vm.WriteEncodedTrace(&expected); err != nil {
+		t.Fatalf("WriteEncodedTrace failed with error: %s", err)
+	}
+	if err := spilled.Vm.WriteEncodedTrace(&got); err != nil {
+		t.Fatalf("WriteEncodedTrace failed with error: %s", err)
+	}
+	if !bytes.Equal(expected.Bytes(), got.Bytes()) {
+		t.Errorf("The spilled trace doesn't match the trace kept in memory")
+	}
+
+	first, last, err := spilled.Vm.GetRelocatedTraceEnds()
+	trace := runner.Vm.RelocatedTrace
+	if err != nil || first != trace[0] || last != trace[len(trace)-1] {
+		t.Errorf("Wrong trace ends %v and %v, err: %v", first, last, err)
+	}
+	if _, err := spilled.Vm.GetRelocatedTrace(); err == nil {
+		t.Errorf("GetRelocatedTrace should fail for a spilled trace")
+	}
+}
+
+// Runs fibonacci twice, relocating in parallel, and compares every artifact of the runs byte by byte
+func TestFibonacciDeterministic(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	var artifacts [2][]byte
+	for i := range artifacts {
+		runner, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{})
+		if err != nil {
+			t.Fatalf("Program execution failed with error: %s", err)
+		}
+		var buffer bytes.Buffer
+		if err := runner.Vm.WriteEncodedTrace(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		if err := runner.Vm.WriteEncodedMemory(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		resources, err := json.Marshal(runner.GetExecutionResources())
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifacts[i] = append(buffer.Bytes(), resources...)
+	}
+	if !bytes.Equal(artifacts[0], artifacts[1]) {
+		t.Errorf("The artifacts differ between two runs of the same program")
+	}
+}
+
+func TestFibonacciSecureRun(t *testing.T) {
+	_, err := cairo_run.CairoRun("../../../cairo_programs/fibonacci.json", cairo_run.CairoRunConfig{SecureRun: true})
+	if err != nil {
+		t.Errorf("Program execution failed with error: %s", err)
+	}
+}
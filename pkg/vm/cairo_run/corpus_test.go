@@ -0,0 +1,100 @@
+package cairo_run_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/runners"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
+)
+
+const corpusDir = "../../../cairo_programs"
+
+var update = flag.Bool("update", false, "rewrite the expected outputs of the corpus programs")
+
+// Returns the compiled program of a corpus source, compiling it with cairo-compile if it isn't
+// compiled or is older than its source. Returns false if it can't be compiled
+func compiledProgram(t *testing.T, source string) (string, bool) {
+	compiled := strings.TrimSuffix(source, ".cairo") + ".json"
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiledInfo, err := os.Stat(compiled)
+	upToDate := err == nil && !compiledInfo.ModTime().Before(sourceInfo.ModTime())
+	compiler, lookErr := exec.LookPath("cairo-compile")
+	if upToDate || (err == nil && lookErr != nil) {
+		return compiled, true
+	}
+	if lookErr != nil {
+		return "", false
+	}
+	compiled = filepath.Join(t.TempDir(), filepath.Base(compiled))
+	if output, err := exec.Command(compiler, "--cairo_path", corpusDir, source, "--output", compiled).CombinedOutput(); err != nil {
+		t.Fatalf("cairo-compile failed: %s\n%s", err, output)
+	}
+	return compiled, true
+}
+
+// Runs every program of the corpus, checking that it completes and that it uses the resources
+// stored in expected/PROGRAM.json. Programs that aren't compiled are skipped when cairo-compile
+// isn't available. Run with -update to write the expected outputs
+func TestCorpus(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join(corpusDir, "*.cairo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		source := source
+		name := strings.TrimSuffix(filepath.Base(source), ".cairo")
+		t.Run(name, func(t *testing.T) {
+			program, ok := compiledProgram(t, source)
+			if !ok {
+				t.Skipf("%s is not compiled and cairo-compile is not available", source)
+			}
+			runner, err := cairo_run.CairoRun(program, cairo_run.CairoRunConfig{})
+			if err != nil {
+				t.Fatalf("Program execution failed with error: %s", err)
+			}
+			resources := runner.GetExecutionResources()
+
+			expectedPath := filepath.Join(corpusDir, "expected", name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(expectedPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				data, err := json.MarshalIndent(resources, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(expectedPath, append(data, '\n'), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			data, err := os.ReadFile(expectedPath)
+			if os.IsNotExist(err) {
+				t.Logf("%s has no expected output, run with -update to record it", name)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected runners.ExecutionResources
+			if err := json.Unmarshal(data, &expected); err != nil {
+				t.Fatalf("Invalid expected output %s: %s", expectedPath, err)
+			}
+			if !reflect.DeepEqual(resources, expected) {
+				t.Errorf("Wrong execution resources. Expected %+v, got %+v", expected, resources)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package vm
+
+import "github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+
+// A function call in progress
+type CallFrame struct {
+	Pc memory.Relocatable
+	Fp memory.Relocatable
+}
+
+// Returns the frames of the calls in progress, starting with the current one. Each call saves
+// the caller's fp at [fp - 2] and the return pc at [fp - 1]; the walk stops when these aren't
+// valid pointers (e.g. at the frame of the entrypoint, whose return pc points to the end segment)
+func (v *VirtualMachine) GetCallFrames() []CallFrame {
+	frames := []CallFrame{{Pc: v.RunContext.Pc, Fp: v.RunContext.Fp}}
+	// Bound the walk by the number of steps, as each frame was created by a call
+	for i := uint(0); i < v.CurrentStep; i++ {
+		frame := frames[len(frames)-1]
+		if frame.Fp.Offset < 2 {
+			break
+		}
+		savedFp, err := v.Segments.Memory.GetRelocatable(memory.NewRelocatable(frame.Fp.SegmentIndex, frame.Fp.Offset-2))
+		if err != nil || savedFp.SegmentIndex != frame.Fp.SegmentIndex || savedFp.Offset > frame.Fp.Offset-2 {
+			break
+		}
+		returnPc, err := v.Segments.Memory.GetRelocatable(memory.NewRelocatable(frame.Fp.SegmentIndex, frame.Fp.Offset-1))
+		if err != nil || returnPc.SegmentIndex != v.RunContext.Pc.SegmentIndex {
+			break
+		}
+		frames = append(frames, CallFrame{Pc: returnPc, Fp: savedFp})
+	}
+	return frames
+}
@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"errors"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Returns the address of a cell of a Cairo 1 hint operand
+func (v *VirtualMachine) GetCellRefAddress(cell parser.CellRef) (memory.Relocatable, error) {
+	base := v.RunContext.Ap
+	if cell.Register == parser.FP {
+		base = v.RunContext.Fp
+	}
+	return base.AddFelt(feltFromInt(cell.Offset))
+}
+
+// Returns the value of a Cairo 1 hint operand
+func (v *VirtualMachine) GetResOperandValue(operand parser.ResOperand) (memory.MaybeRelocatable, error) {
+	if operand.Kind == parser.ResOperandImmediate {
+		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromBigInt(operand.Immediate)), nil
+	}
+	a, err := v.getCellRefValue(operand.Cell)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	switch operand.Kind {
+	case parser.ResOperandDoubleDeref:
+		base, ok := a.GetRelocatable()
+		if !ok {
+			return memory.MaybeRelocatable{}, errors.New("DoubleDeref operand doesn't point to a memory address")
+		}
+		addr, err := base.AddFelt(feltFromInt(operand.Offset))
+		if err != nil {
+			return memory.MaybeRelocatable{}, err
+		}
+		cell, err := v.Segments.Memory.Get(addr)
+		if err != nil {
+			return memory.MaybeRelocatable{}, err
+		}
+		return *cell, nil
+	case parser.ResOperandBinOp:
+		var b memory.MaybeRelocatable
+		if operand.B != nil {
+			if b, err = v.getCellRefValue(*operand.B); err != nil {
+				return memory.MaybeRelocatable{}, err
+			}
+		} else {
+			b = *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromBigInt(operand.Immediate))
+		}
+		if operand.Op == "Add" {
+			return a.Add(b)
+		}
+		return a.Mul(b)
+	}
+	return a, nil
+}
+
+func (v *VirtualMachine) getCellRefValue(cell parser.CellRef) (memory.MaybeRelocatable, error) {
+	addr, err := v.GetCellRefAddress(cell)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	value, err := v.Segments.Memory.Get(addr)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return *value, nil
+}
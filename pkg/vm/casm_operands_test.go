@@ -0,0 +1,57 @@
+package vm_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestGetCellRefAddress(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	addr, err := virtualMachine.GetCellRefAddress(parser.CellRef{Register: parser.AP, Offset: -1})
+	if err != nil || addr != memory.NewRelocatable(1, 3) {
+		t.Errorf("Wrong address: %+v, err: %v", addr, err)
+	}
+}
+
+func TestGetResOperandValue(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	felt := func(value uint64) *memory.MaybeRelocatable {
+		return memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(value))
+	}
+	cases := []struct {
+		operand  parser.ResOperand
+		expected *memory.MaybeRelocatable
+	}{
+		{parser.ResOperand{Kind: parser.ResOperandDeref, Cell: parser.CellRef{Register: parser.FP, Offset: -2}}, felt(10)},
+		{parser.ResOperand{Kind: parser.ResOperandDoubleDeref, Cell: parser.CellRef{Register: parser.FP, Offset: -1}}, felt(20)},
+		{parser.ResOperand{Kind: parser.ResOperandImmediate, Immediate: big.NewInt(7)}, felt(7)},
+		{parser.ResOperand{Kind: parser.ResOperandBinOp, Op: "Add", Cell: parser.CellRef{Register: parser.FP, Offset: -1}, Immediate: big.NewInt(1)},
+			memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 4))},
+		{parser.ResOperand{Kind: parser.ResOperandBinOp, Op: "Mul", Cell: parser.CellRef{Register: parser.FP, Offset: -2}, B: &parser.CellRef{Register: parser.FP, Offset: 1}},
+			felt(200)},
+	}
+	for _, c := range cases {
+		value, err := virtualMachine.GetResOperandValue(c.operand)
+		if err != nil || !value.IsEqual(c.expected) {
+			t.Errorf("Wrong value for %+v. Expected %+v, got %+v, err: %v", c.operand, c.expected, value, err)
+		}
+	}
+}
+
+func TestGetResOperandValueErrors(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	invalid := []parser.ResOperand{
+		{Kind: parser.ResOperandDeref, Cell: parser.CellRef{Register: parser.AP}},
+		{Kind: parser.ResOperandDoubleDeref, Cell: parser.CellRef{Register: parser.FP, Offset: -2}},
+		{Kind: parser.ResOperandBinOp, Op: "Mul", Cell: parser.CellRef{Register: parser.FP, Offset: -1}, Immediate: big.NewInt(2)},
+	}
+	for _, operand := range invalid {
+		if _, err := virtualMachine.GetResOperandValue(operand); err == nil {
+			t.Errorf("GetResOperandValue should fail for %+v", operand)
+		}
+	}
+}
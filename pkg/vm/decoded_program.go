@@ -0,0 +1,45 @@
+package vm
+
+import "github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+
+// The instructions of a program, decoded and validated ahead of time and indexed by their offset
+// in the program. The program mixes instructions with immediates and constants, so every word is
+// decoded, words that aren't valid instructions having no entry. Some immediates decode to valid
+// instructions, which is harmless as only the words the pc reaches are looked up
+type DecodedProgram struct {
+	instructions []Instruction
+	valid        []bool
+}
+
+// Decodes every word of a program's data. Never fails, as invalid words are only an error if the
+// pc reaches them, in which case the step decoding them from memory reports it
+func DecodeProgram(data []memory.MaybeRelocatable) DecodedProgram {
+	program := DecodedProgram{instructions: make([]Instruction, len(data)), valid: make([]bool, len(data))}
+	for i := range data {
+		felt, ok := data[i].GetFelt()
+		if !ok {
+			continue
+		}
+		instruction, err := DecodeInstructionFelt(felt)
+		if err != nil || instruction.Validate() != nil {
+			continue
+		}
+		program.instructions[i] = instruction
+		program.valid[i] = true
+	}
+	return program
+}
+
+// Returns the number of words of the program
+func (p *DecodedProgram) Len() uint {
+	return uint(len(p.instructions))
+}
+
+// Returns the instruction at offset, false if the word there isn't a valid instruction or is
+// past the end of the program
+func (p *DecodedProgram) Get(offset uint) (*Instruction, bool) {
+	if offset >= uint(len(p.instructions)) || !p.valid[offset] {
+		return nil, false
+	}
+	return &p.instructions[offset], true
+}
@@ -0,0 +1,37 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestDecodeProgram(t *testing.T) {
+	data := []memory.MaybeRelocatable{
+		// [ap] = 5; ap++
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x480680017fff8000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 0)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x8000000000000000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x10000000000000000")),
+	}
+	program := vm.DecodeProgram(data)
+	if program.Len() != 5 {
+		t.Fatalf("Expected 5 words, got %d", program.Len())
+	}
+	expected, _ := vm.DecodeInstruction(0x480680017fff8000)
+	if instruction, ok := program.Get(0); !ok || *instruction != expected {
+		t.Errorf("Expected %+v at offset 0, got %+v", expected, instruction)
+	}
+	// Immediates that happen to be valid instructions are decoded too
+	if _, ok := program.Get(1); !ok {
+		t.Errorf("Expected the immediate 5 to decode to an instruction")
+	}
+	for _, offset := range []uint{2, 3, 4, 5} {
+		if instruction, ok := program.Get(offset); ok {
+			t.Errorf("Expected no instruction at offset %d, got %+v", offset, instruction)
+		}
+	}
+}
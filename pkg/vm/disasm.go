@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+)
+
+type WordKind uint
+
+const (
+	// The first word of an instruction
+	WordInstruction WordKind = iota
+	// The immediate value following an instruction with an immediate op1
+	WordImmediate
+	// A word that can't be decoded as an instruction
+	WordData
+)
+
+// A word of the program data, along with how it was decoded
+type DisassembledWord struct {
+	PC    uint
+	Kind  WordKind
+	Value lambdaworks.Felt
+	// Only set for WordInstruction words
+	Instruction Instruction
+	// Only set for WordData words: the reason the word isn't an instruction
+	Err error
+}
+
+// Decodes every word of the program data, in pc order. Immediate values are
+// attributed to the instruction preceding them, words that can't be decoded are
+// returned as data words rather than failing the whole disassembly.
+func Disassemble(program Program) []DisassembledWord {
+	words := make([]DisassembledWord, 0, len(program.Data))
+	for pc := uint(0); pc < uint(len(program.Data)); pc++ {
+		value, ok := program.Data[pc].GetFelt()
+		if !ok {
+			words = append(words, DisassembledWord{PC: pc, Kind: WordData, Err: errors.New("not a felt")})
+			continue
+		}
+		instruction, err := decodeFelt(value)
+		if err != nil {
+			words = append(words, DisassembledWord{PC: pc, Kind: WordData, Value: value, Err: err})
+			continue
+		}
+		words = append(words, DisassembledWord{PC: pc, Kind: WordInstruction, Value: value, Instruction: instruction})
+		if instruction.Size() == 2 && pc+1 < uint(len(program.Data)) {
+			pc++
+			immediate, _ := program.Data[pc].GetFelt()
+			words = append(words, DisassembledWord{PC: pc, Kind: WordImmediate, Value: immediate})
+		}
+	}
+	return words
+}
+
+func decodeFelt(value lambdaworks.Felt) (Instruction, error) {
+	return DecodeInstructionFelt(value)
+}
+
+// Formats the word as a line of a disassembly listing
+func (w DisassembledWord) String() string {
+	prefix := fmt.Sprintf("%6d: %-20s", w.PC, w.Value.ToHexString())
+	switch w.Kind {
+	case WordInstruction:
+		i := w.Instruction
+		line := fmt.Sprintf("%s %-9s dst=[%s%+d] op0=[%s%+d] op1=%s%+d res=%s pc_update=%s ap_update=%s",
+			prefix, i.Opcode, i.DstReg, i.Off0, i.Op0Reg, i.Off1, i.Op1Addr, i.Off2, i.ResLogic, i.PcUpdate, i.ApUpdate)
+		if i.OpcodeExtension != OpcodeExtensionStone {
+			line += fmt.Sprintf(" extension=%s", i.OpcodeExtension)
+		}
+		return line
+	case WordImmediate:
+		return fmt.Sprintf("%s immediate %s", prefix, w.Value.ToSignedDecString())
+	default:
+		return fmt.Sprintf("%s data      (%s)", prefix, w.Err)
+	}
+}
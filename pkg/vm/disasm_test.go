@@ -0,0 +1,62 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func programFromHex(words ...string) vm.Program {
+	data := make([]memory.MaybeRelocatable, 0, len(words))
+	for _, word := range words {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex(word)))
+	}
+	return vm.Program{Data: data}
+}
+
+func TestDisassemble(t *testing.T) {
+	// [ap] = 5; ap++ / ret / a word with a bit past the opcode extension set
+	program := programFromHex("0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe", "0x40000000000000000")
+	words := vm.Disassemble(program)
+	if len(words) != 4 {
+		t.Fatalf("Expected 4 words, got %d", len(words))
+	}
+	expectedKinds := []vm.WordKind{vm.WordInstruction, vm.WordImmediate, vm.WordInstruction, vm.WordData}
+	for i, word := range words {
+		if word.PC != uint(i) || word.Kind != expectedKinds[i] {
+			t.Errorf("Wrong word at %d: %+v", i, word)
+		}
+	}
+	if words[0].Instruction.Opcode != vm.AssertEq || words[0].Instruction.Op1Addr != vm.Op1SrcImm || words[0].Instruction.ApUpdate != vm.ApUpdateAdd1 {
+		t.Errorf("Wrong first instruction: %+v", words[0].Instruction)
+	}
+	if words[2].Instruction.Opcode != vm.Ret {
+		t.Errorf("Wrong third instruction: %+v", words[2].Instruction)
+	}
+	if words[3].Err != vm.ErrNonZeroHighBitError {
+		t.Errorf("Wrong error for the data word: %v", words[3].Err)
+	}
+}
+
+func TestDisassembledWordString(t *testing.T) {
+	words := vm.Disassemble(programFromHex("0x480680017fff8000", "0x5", "0x40000000000000000"))
+	expected := []string{
+		"     0: 0x480680017fff8000   assert_eq dst=[ap+0] op0=[fp-1] op1=imm+1 res=op1 pc_update=regular ap_update=add1",
+		"     1: 0x5                  immediate 5",
+		"     2: 0x40000000000000000  data      (Instruction high bit was not set to zero)",
+	}
+	for i, word := range words {
+		if word.String() != expected[i] {
+			t.Errorf("Wrong listing line. Expected %q, got %q", expected[i], word.String())
+		}
+	}
+}
+
+func TestDisassembleTrailingImmediateMissing(t *testing.T) {
+	words := vm.Disassemble(programFromHex("0x480680017fff8000"))
+	if len(words) != 1 || words[0].Kind != vm.WordInstruction {
+		t.Errorf("Wrong disassembly: %+v", words)
+	}
+}
@@ -2,6 +2,10 @@ package vm
 
 import (
 	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
 )
 
 //  Structure of the 63-bit that form the first word of each instruction.
@@ -19,6 +23,10 @@ import (
 // │  0  │  1  │ 2 │ 3 │ 4 │ 5 │ 6 │ 7 │ 8 │ 9 │ 10 │ 11 │ 12 │ 13 │ 14 │ 15 │
 // └─────┴─────┴───┴───┴───┴───┴───┴───┴───┴───┴────┴────┴────┴────┴────┴────┘
 
+// Newer Cairo versions extend the word past 63 bits: bits 63 to 65 hold the opcode extension,
+// which selects additional opcodes such as blake2s and QM31 arithmetic. Instructions without an
+// extension fit in a uint64.
+
 // Instruction is the representation of the first word of each Cairo instruction.
 // Some instructions spread over two words when they use an immediate value, so
 // representing the first one with this struct is enougth.
@@ -34,6 +42,8 @@ type Instruction struct {
 	ApUpdate ApUpdate
 	FpUpdate FpUpdate
 	Opcode   Opcode
+	// OpcodeExtensionStone, the zero value, for instructions of the original instruction set
+	OpcodeExtension OpcodeExtension
 }
 
 // x-----------------------------x
@@ -100,13 +110,52 @@ const (
 	Ret      Opcode = 4
 )
 
+type OpcodeExtension uint
+
+const (
+	OpcodeExtensionStone         OpcodeExtension = 0
+	OpcodeExtensionBlake         OpcodeExtension = 1
+	OpcodeExtensionBlakeFinalize OpcodeExtension = 2
+	OpcodeExtensionQM31Operation OpcodeExtension = 3
+)
+
 var ErrNonZeroHighBitError = errors.New("Instruction high bit was not set to zero")
 var ErrInvalidOp1RegError = errors.New("Instruction had invalid Op1 Register")
 var ErrInvalidPcUpdateError = errors.New("Instruction had invalid Pc update")
 var ErrInvalidResError = errors.New("Instruction had an invalid res")
 var ErrInvalidOpcodeError = errors.New("Instruction had an invalid opcode")
 var ErrInvalidApUpdateError = errors.New("Instruction had an invalid Ap Update")
+var ErrOffsetOutOfRangeError = errors.New("Instruction offset out of range")
+var ErrInvalidRegisterError = errors.New("Instruction had an invalid register")
+var ErrInvalidOpcodeExtensionError = errors.New("Instruction had an invalid opcode extension")
+var ErrOpcodeExtensionFlagsError = errors.New("Instruction flags can't be used with its opcode extension")
+
+// Offsets are encoded as biased 16-bit values, so they must lie within [-2^15, 2^15)
+const (
+	MinOffset  = -(1 << 15)
+	MaxOffset  = (1 << 15) - 1
+	OffsetBias = 1 << 15
+)
+
+// InstructionFieldError is returned when a field of an instruction holds a value
+// outside of the range allowed by the spec. Field names the offending field
+// (off0, off1, off2, dst_reg, op0_reg, opcode_extension) and Err holds the kind of failure.
+type InstructionFieldError struct {
+	Field string
+	Value int
+	Err   error
+}
 
+func (e *InstructionFieldError) Error() string {
+	return fmt.Sprintf("%s: %s = %d", e.Err, e.Field, e.Value)
+}
+
+func (e *InstructionFieldError) Unwrap() error {
+	return e.Err
+}
+
+// Decodes an instruction of the original instruction set, which fits in 63 bits
+// Use DecodeInstructionFelt to decode instructions that may have an opcode extension
 func DecodeInstruction(encodedInstruction uint64) (Instruction, error) {
 	const HighBit uint64 = 1 << 63
 	const DstRegMask uint64 = 0x0001
@@ -241,7 +290,7 @@ func DecodeInstruction(encodedInstruction uint64) (Instruction, error) {
 		fpUpdate = FpUpdateRegular
 	}
 
-	return Instruction{
+	instruction := Instruction{
 		Off0:     offset0,
 		Off1:     offset1,
 		Off2:     offset2,
@@ -253,7 +302,168 @@ func DecodeInstruction(encodedInstruction uint64) (Instruction, error) {
 		ApUpdate: apUpdate,
 		FpUpdate: fpUpdate,
 		Opcode:   opcode,
-	}, nil
+	}
+
+	if err := instruction.Validate(); err != nil {
+		return Instruction{}, err
+	}
+	return instruction, nil
+}
+
+// Decodes an instruction from a memory word, including its opcode extension. Fails on unknown
+// extensions and on flags the extension doesn't allow
+func DecodeInstructionFelt(encodedInstruction lambdaworks.Felt) (Instruction, error) {
+	high, low, err := encodedInstruction.ToU128()
+	if err != nil || high>>2 != 0 {
+		return Instruction{}, ErrNonZeroHighBitError
+	}
+	instruction, err := DecodeInstruction(low &^ (1 << 63))
+	if err != nil {
+		return Instruction{}, err
+	}
+	instruction.OpcodeExtension = OpcodeExtension(high<<1 | low>>63)
+	if err := instruction.validateOpcodeExtension(); err != nil {
+		return Instruction{}, err
+	}
+	return instruction, nil
+}
+
+// Checks that the offsets and register selections of the instruction lie within the
+// ranges allowed by the spec, and that its flags are allowed by its opcode extension,
+// returning an InstructionFieldError naming the first offending field otherwise
+func (i *Instruction) Validate() error {
+	offsets := []struct {
+		name  string
+		value int
+	}{{"off0", i.Off0}, {"off1", i.Off1}, {"off2", i.Off2}}
+	for _, off := range offsets {
+		if off.value < MinOffset || off.value > MaxOffset {
+			return &InstructionFieldError{Field: off.name, Value: off.value, Err: ErrOffsetOutOfRangeError}
+		}
+	}
+	if i.DstReg != AP && i.DstReg != FP {
+		return &InstructionFieldError{Field: "dst_reg", Value: int(i.DstReg), Err: ErrInvalidRegisterError}
+	}
+	if i.Op0Reg != AP && i.Op0Reg != FP {
+		return &InstructionFieldError{Field: "op0_reg", Value: int(i.Op0Reg), Err: ErrInvalidRegisterError}
+	}
+	return i.validateOpcodeExtension()
+}
+
+// Blake opcodes hash the state and message op0 and op1 point to into dst, QM31 operations add or
+// multiply QM31 elements
+func (i *Instruction) validateOpcodeExtension() error {
+	var valid bool
+	switch i.OpcodeExtension {
+	case OpcodeExtensionStone:
+		valid = true
+	case OpcodeExtensionBlake, OpcodeExtensionBlakeFinalize:
+		valid = i.Opcode == NOp && (i.Op1Addr == Op1SrcFP || i.Op1Addr == Op1SrcAP) && i.ResLogic == ResOp1 &&
+			i.PcUpdate == PcUpdateRegular && (i.ApUpdate == ApUpdateRegular || i.ApUpdate == ApUpdateAdd1)
+	case OpcodeExtensionQM31Operation:
+		valid = i.Opcode == AssertEq && (i.ResLogic == ResAdd || i.ResLogic == ResMul)
+	default:
+		return &InstructionFieldError{Field: "opcode_extension", Value: int(i.OpcodeExtension), Err: ErrInvalidOpcodeExtensionError}
+	}
+	if !valid {
+		return &InstructionFieldError{Field: "opcode_extension", Value: int(i.OpcodeExtension), Err: ErrOpcodeExtensionFlagsError}
+	}
+	return nil
+}
+
+// Encodes the instruction into its first word, the inverse of DecodeInstruction. Fails if the
+// instruction can't be represented, e.g. an ap update of add2 outside of a call, or if it has an
+// opcode extension, see EncodeFelt
+func (i *Instruction) Encode() (uint64, error) {
+	if err := i.Validate(); err != nil {
+		return 0, err
+	}
+	if i.OpcodeExtension != OpcodeExtensionStone {
+		return 0, &InstructionFieldError{Field: "opcode_extension", Value: int(i.OpcodeExtension), Err: ErrInvalidOpcodeExtensionError}
+	}
+	var flags uint64
+	if i.DstReg == FP {
+		flags |= 1
+	}
+	if i.Op0Reg == FP {
+		flags |= 1 << 1
+	}
+	switch i.Op1Addr {
+	case Op1SrcImm:
+		flags |= 1 << 2
+	case Op1SrcFP:
+		flags |= 2 << 2
+	case Op1SrcAP:
+		flags |= 4 << 2
+	case Op1SrcOp0:
+	default:
+		return 0, ErrInvalidOp1RegError
+	}
+	switch i.ResLogic {
+	case ResOp1:
+	case ResAdd:
+		flags |= 1 << 5
+	case ResMul:
+		flags |= 2 << 5
+	case ResUnconstrained:
+		if i.PcUpdate != PcUpdateJnz {
+			return 0, ErrInvalidResError
+		}
+	default:
+		return 0, ErrInvalidResError
+	}
+	switch i.PcUpdate {
+	case PcUpdateRegular:
+	case PcUpdateJump:
+		flags |= 1 << 7
+	case PcUpdateJumpRel:
+		flags |= 2 << 7
+	case PcUpdateJnz:
+		flags |= 4 << 7
+	default:
+		return 0, ErrInvalidPcUpdateError
+	}
+	switch {
+	case i.ApUpdate == ApUpdateRegular && i.Opcode != Call, i.ApUpdate == ApUpdateAdd2 && i.Opcode == Call:
+	case i.ApUpdate == ApUpdateAdd:
+		flags |= 1 << 10
+	case i.ApUpdate == ApUpdateAdd1:
+		flags |= 2 << 10
+	default:
+		return 0, ErrInvalidApUpdateError
+	}
+	switch i.Opcode {
+	case NOp:
+	case Call:
+		flags |= 1 << 12
+	case Ret:
+		flags |= 2 << 12
+	case AssertEq:
+		flags |= 4 << 12
+	default:
+		return 0, ErrInvalidOpcodeError
+	}
+	return flags<<48 | toBiasedRepresentation(i.Off2)<<32 | toBiasedRepresentation(i.Off1)<<16 | toBiasedRepresentation(i.Off0), nil
+}
+
+// Encodes the instruction into its first word along with its opcode extension, the inverse of
+// DecodeInstructionFelt
+func (i *Instruction) EncodeFelt() (lambdaworks.Felt, error) {
+	stone := *i
+	stone.OpcodeExtension = OpcodeExtensionStone
+	if err := i.validateOpcodeExtension(); err != nil {
+		return lambdaworks.FeltZero(), err
+	}
+	encoded, err := stone.Encode()
+	if err != nil {
+		return lambdaworks.FeltZero(), err
+	}
+	word := new(big.Int).Lsh(new(big.Int).SetUint64(uint64(i.OpcodeExtension)), 63)
+	return lambdaworks.FeltFromBigInt(word.Or(word, new(big.Int).SetUint64(encoded))), nil
+}
+
+func toBiasedRepresentation(offset int) uint64 {
+	return uint64(uint16(int16(offset)) + 1<<15)
 }
 
 func fromBiasedRepresentation(offset uint64) int {
@@ -261,9 +471,106 @@ func fromBiasedRepresentation(offset uint64) int {
 	return int(int16(uint16(offset) - bias))
 }
 
+// Returns the number of words the instruction spans: 2 when op1 is an immediate, stored in the
+// word right after the instruction, 1 otherwise. Pc updates, call return addresses and the
+// disassembler all rely on it
 func (i *Instruction) Size() uint {
 	if i.Op1Addr == Op1SrcImm {
 		return 2
 	}
 	return 1
 }
+
+func (r Register) String() string {
+	switch r {
+	case AP:
+		return "ap"
+	case FP:
+		return "fp"
+	}
+	return fmt.Sprintf("Register(%d)", uint(r))
+}
+
+func (o Op1Src) String() string {
+	switch o {
+	case Op1SrcImm:
+		return "imm"
+	case Op1SrcAP:
+		return "ap"
+	case Op1SrcFP:
+		return "fp"
+	case Op1SrcOp0:
+		return "op0"
+	}
+	return fmt.Sprintf("Op1Src(%d)", uint(o))
+}
+
+func (r ResLogic) String() string {
+	switch r {
+	case ResOp1:
+		return "op1"
+	case ResAdd:
+		return "add"
+	case ResMul:
+		return "mul"
+	case ResUnconstrained:
+		return "unconstrained"
+	}
+	return fmt.Sprintf("ResLogic(%d)", uint(r))
+}
+
+func (p PcUpdate) String() string {
+	switch p {
+	case PcUpdateRegular:
+		return "regular"
+	case PcUpdateJump:
+		return "jump"
+	case PcUpdateJumpRel:
+		return "jump_rel"
+	case PcUpdateJnz:
+		return "jnz"
+	}
+	return fmt.Sprintf("PcUpdate(%d)", uint(p))
+}
+
+func (a ApUpdate) String() string {
+	switch a {
+	case ApUpdateRegular:
+		return "regular"
+	case ApUpdateAdd:
+		return "add"
+	case ApUpdateAdd1:
+		return "add1"
+	case ApUpdateAdd2:
+		return "add2"
+	}
+	return fmt.Sprintf("ApUpdate(%d)", uint(a))
+}
+
+func (o Opcode) String() string {
+	switch o {
+	case NOp:
+		return "nop"
+	case AssertEq:
+		return "assert_eq"
+	case Call:
+		return "call"
+	case Ret:
+		return "ret"
+	}
+	return fmt.Sprintf("Opcode(%d)", uint(o))
+}
+
+func (o OpcodeExtension) String() string {
+	switch o {
+	case OpcodeExtensionStone:
+		return "stone"
+	case OpcodeExtensionBlake:
+		return "blake"
+	case OpcodeExtensionBlakeFinalize:
+		return "blake_finalize"
+	case OpcodeExtensionQM31Operation:
+		return "qm31_operation"
+	}
+	return fmt.Sprintf("OpcodeExtension(%d)", uint(o))
+}
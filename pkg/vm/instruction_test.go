@@ -1,8 +1,10 @@
 package vm_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
 )
 
@@ -266,3 +268,166 @@ func TestDecodeOffsetNegative(t *testing.T) {
 		t.Error("Wrong Instruction Offset destination")
 	}
 }
+
+func TestValidateInstructionOk(t *testing.T) {
+	instruction := vm.Instruction{Off0: vm.MinOffset, Off1: 0, Off2: vm.MaxOffset, DstReg: vm.FP, Op0Reg: vm.AP}
+	if err := instruction.Validate(); err != nil {
+		t.Errorf("Instruction validation failed with error %s", err)
+	}
+}
+
+func TestValidateInstructionOffsetOutOfRange(t *testing.T) {
+	instruction := vm.Instruction{Off0: 0, Off1: vm.MaxOffset + 1}
+	err := instruction.Validate()
+	fieldErr, ok := err.(*vm.InstructionFieldError)
+	if !ok {
+		t.Fatalf("Validation should fail with InstructionFieldError, got %v", err)
+	}
+	if fieldErr.Field != "off1" || fieldErr.Value != vm.MaxOffset+1 {
+		t.Errorf("Wrong offending field reported: %s = %d", fieldErr.Field, fieldErr.Value)
+	}
+	if !errors.Is(err, vm.ErrOffsetOutOfRangeError) {
+		t.Error("Validation error should wrap ErrOffsetOutOfRangeError")
+	}
+}
+
+func TestValidateInstructionNegativeOffsetOutOfRange(t *testing.T) {
+	instruction := vm.Instruction{Off2: vm.MinOffset - 1}
+	err := instruction.Validate()
+	fieldErr, ok := err.(*vm.InstructionFieldError)
+	if !ok || fieldErr.Field != "off2" {
+		t.Errorf("Validation should fail on off2, got %v", err)
+	}
+}
+
+func TestValidateInstructionInvalidRegister(t *testing.T) {
+	instruction := vm.Instruction{DstReg: vm.AP, Op0Reg: vm.Register(2)}
+	err := instruction.Validate()
+	fieldErr, ok := err.(*vm.InstructionFieldError)
+	if !ok || fieldErr.Field != "op0_reg" || !errors.Is(err, vm.ErrInvalidRegisterError) {
+		t.Errorf("Validation should fail on op0_reg, got %v", err)
+	}
+}
+
+func TestEncodeInstructionRoundTrip(t *testing.T) {
+	encodings := []uint64{0x1104800180018000, 0x208b7fff7fff7ffe, 0x480680017fff8000, 0x10780017fff7fff, 0x4200800080008000, 0x14A7800080008000}
+	for _, encoded := range encodings {
+		instruction, err := vm.DecodeInstruction(encoded)
+		if err != nil {
+			t.Fatalf("DecodeInstruction error in test: %s", err)
+		}
+		got, err := instruction.Encode()
+		if err != nil || got != encoded {
+			t.Errorf("Wrong encoding. Expected %#x, got %#x, err: %v", encoded, got, err)
+		}
+	}
+}
+
+// Goes over every flags word: the ones that decode must encode back to the same word, and span
+// two words exactly when their op1 source is an immediate
+func TestInstructionSizeAllFlags(t *testing.T) {
+	// off0 = -1, off1 = 2, off2 = 1
+	const offsets uint64 = 0x8001<<32 | 0x8002<<16 | 0x7fff
+	decoded := 0
+	for flags := uint64(0); flags < 1<<15; flags++ {
+		encoded := flags<<48 | offsets
+		instruction, err := vm.DecodeInstruction(encoded)
+		if err != nil {
+			continue
+		}
+		decoded++
+		if got, err := instruction.Encode(); err != nil || got != encoded {
+			t.Fatalf("Wrong encoding. Expected %#x, got %#x, err: %v", encoded, got, err)
+		}
+		expectedSize := uint(1)
+		if (flags>>2)&7 == 1 {
+			expectedSize = 2
+		}
+		if instruction.Size() != expectedSize || (instruction.Op1Addr == vm.Op1SrcImm) != (expectedSize == 2) {
+			t.Fatalf("Expected %#x to span %d words, got %d (op1 source %s)", encoded, expectedSize, instruction.Size(), instruction.Op1Addr)
+		}
+	}
+	// 2 registers * 2 registers * 4 op1 sources * 3 res logics * 4 pc updates * 3 ap updates * 4 opcodes
+	if decoded != 2*2*4*3*4*3*4 {
+		t.Errorf("Expected every valid combination of flags to decode, %d did", decoded)
+	}
+}
+
+func TestEncodeInstructionInvalid(t *testing.T) {
+	invalid := []vm.Instruction{
+		{ApUpdate: vm.ApUpdateAdd2, Opcode: vm.AssertEq},
+		{ResLogic: vm.ResUnconstrained, PcUpdate: vm.PcUpdateJump},
+		{Off0: vm.MaxOffset + 1},
+	}
+	for _, instruction := range invalid {
+		if _, err := instruction.Encode(); err == nil {
+			t.Errorf("Encode should fail for %+v", instruction)
+		}
+	}
+}
+
+// Any word either fails to decode or decodes into an instruction encoding back to it
+func FuzzDecodeInstruction(f *testing.F) {
+	for _, seed := range []uint64{0x480680017fff8000, 0x208b7fff7fff7ffe, 0x1104800180018000, 0x10780017fff7fff, 0x482a7ffd7ffc8000, 0x94A7800080008000, 0} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, encoded uint64) {
+		instruction, err := vm.DecodeInstruction(encoded)
+		if err != nil {
+			return
+		}
+		reencoded, err := instruction.Encode()
+		if err != nil {
+			t.Fatalf("Decoded %#x into %+v, which fails to encode: %s", encoded, instruction, err)
+		}
+		if reencoded != encoded {
+			t.Fatalf("Decoded %#x into %+v, which encodes to %#x", encoded, instruction, reencoded)
+		}
+	})
+}
+
+func TestDecodeInstructionFeltOpcodeExtensions(t *testing.T) {
+	// [ap] = [fp - 3] + [fp - 4] as a QM31 addition, and a blake opcode with op1 at fp
+	qm31 := vm.Instruction{Off0: 0, Off1: -3, Off2: -4, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP, ResLogic: vm.ResAdd,
+		FpUpdate: vm.FpUpdateRegular, Opcode: vm.AssertEq, OpcodeExtension: vm.OpcodeExtensionQM31Operation}
+	blake := vm.Instruction{Off0: 0, Off1: -3, Off2: -4, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP, ResLogic: vm.ResOp1,
+		ApUpdate: vm.ApUpdateAdd1, Opcode: vm.NOp, OpcodeExtension: vm.OpcodeExtensionBlakeFinalize}
+	for _, instruction := range []vm.Instruction{qm31, blake} {
+		encoded, err := instruction.EncodeFelt()
+		if err != nil {
+			t.Fatalf("EncodeFelt error in test: %s", err)
+		}
+		decoded, err := vm.DecodeInstructionFelt(encoded)
+		if err != nil || decoded != instruction {
+			t.Errorf("Expected %s to decode into %+v, got %+v, err: %v", encoded.ToHexString(), instruction, decoded, err)
+		}
+	}
+	if _, err := qm31.Encode(); !errors.Is(err, vm.ErrInvalidOpcodeExtensionError) {
+		t.Errorf("Encoding an instruction with an opcode extension into 63 bits should fail, got %v", err)
+	}
+	// Instructions without an extension decode the same way from a uint64 and from a felt
+	instruction, err := vm.DecodeInstructionFelt(lambdaworks.FeltFromUint64(0x480680017fff8000))
+	if err != nil || instruction.OpcodeExtension != vm.OpcodeExtensionStone || instruction.Opcode != vm.AssertEq {
+		t.Errorf("Wrong decoding of a stone instruction: %+v, err: %v", instruction, err)
+	}
+}
+
+func TestDecodeInstructionFeltInvalidOpcodeExtensions(t *testing.T) {
+	cases := []struct {
+		encoded string
+		err     error
+	}{
+		// Extension 4
+		{"0x2480680017fff8000", vm.ErrInvalidOpcodeExtensionError},
+		// A blake opcode with an assert_eq opcode
+		{"0xc80680017fff8000", vm.ErrOpcodeExtensionFlagsError},
+		// Bit 66 set
+		{"0x4480680017fff8000", vm.ErrNonZeroHighBitError},
+	}
+	for _, c := range cases {
+		_, err := vm.DecodeInstructionFelt(lambdaworks.FeltFromHex(c.encoded))
+		if !errors.Is(err, c.err) {
+			t.Errorf("Expected %s to fail with %v, got %v", c.encoded, c.err, err)
+		}
+	}
+}
@@ -2,105 +2,521 @@ package memory
 
 import (
 	"errors"
-)
+	"fmt"
 
-// A Set to store Relocatable values
-type AddressSet map[Relocatable]bool
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+)
 
-func NewAddressSet() AddressSet {
-	return make(map[Relocatable]bool)
-}
+// A set of validated offsets within a single segment, stored as a bitset
+type offsetSet []uint64
 
-func (set AddressSet) Add(element Relocatable) {
-	set[element] = true
+func (set *offsetSet) Add(offset uint) {
+	for uint(len(*set))*64 <= offset {
+		*set = append(*set, 0)
+	}
+	(*set)[offset/64] |= 1 << (offset % 64)
 }
 
-func (set AddressSet) Contains(element Relocatable) bool {
-	return set[element]
+func (set offsetSet) Contains(offset uint) bool {
+	if offset/64 >= uint(len(set)) {
+		return false
+	}
+	return set[offset/64]&(1<<(offset%64)) != 0
 }
 
 // A function that validates a memory address and returns a list of validated addresses
 type ValidationRule func(*Memory, Relocatable) ([]Relocatable, error)
 
+// A single memory segment, stored as a growable slice of cells.
+// As memory can have holes, an occupancy bitmap keeps track of which cells hold a value.
+type segment struct {
+	cells    []MaybeRelocatable
+	occupied []uint64
+	// Set once the segment holds code the pc runs, see Memory.MarkExecutable
+	executable bool
+}
+
+// Returns true if the cell at offset holds a value
+func (s *segment) isOccupied(offset uint) bool {
+	if offset >= uint(len(s.cells)) {
+		return false
+	}
+	return s.occupied[offset/64]&(1<<(offset%64)) != 0
+}
+
+// Stores val at offset, growing the segment if needed
+func (s *segment) set(offset uint, val MaybeRelocatable) {
+	if offset >= uint(len(s.cells)) {
+		// Rely on append's amortized growth to avoid reallocating on every insertion
+		s.cells = append(s.cells, make([]MaybeRelocatable, offset+1-uint(len(s.cells)))...)
+		for uint(len(s.occupied))*64 < uint(len(s.cells)) {
+			s.occupied = append(s.occupied, 0)
+		}
+	}
+	s.cells[offset] = val
+	s.occupied[offset/64] |= 1 << (offset % 64)
+}
+
+// Pre-sizes the segment's storage to hold at least capacity cells without reallocating
+func (s *segment) reserve(capacity uint) {
+	if uint(cap(s.cells)) < capacity {
+		cells := make([]MaybeRelocatable, len(s.cells), capacity)
+		copy(cells, s.cells)
+		s.cells = cells
+	}
+	words := (capacity + 63) / 64
+	if uint(cap(s.occupied)) < words {
+		occupied := make([]uint64, len(s.occupied), words)
+		copy(occupied, s.occupied)
+		s.occupied = occupied
+	}
+}
+
+// Returns the size of the segment, aka the highest occupied offset + 1
+func (s *segment) size() uint {
+	return uint(len(s.cells))
+}
+
 // Memory represents the Cairo VM's memory.
 type Memory struct {
-	data                map[Relocatable]MaybeRelocatable
-	num_segments        uint
-	validation_rules    map[uint]ValidationRule
-	validated_addresses AddressSet
+	data             []segment
+	num_segments     uint
+	validation_rules map[uint]ValidationRule
+	// Temporary segments, the one with index -i is stored at temp_data[i - 1]
+	temp_data         []segment
+	num_temp_segments uint
+	// Destination of each temporary segment, indexed by its (negative) index, see AddRelocationRule
+	relocation_rules map[int]Relocatable
+	// Validated offsets, only tracked for segments that have a validation rule
+	validated_addresses map[uint]*offsetSet
+	// When set, write-once violations are recorded instead of failing the insertion
+	relaxed_writes   bool
+	write_violations []WriteViolation
+	// When set, writes to the empty cells of executable segments fail
+	forbid_code_writes bool
+}
+
+// An insertion into a memory cell that already holds a different value
+type MemoryOverwriteError struct {
+	Address  Relocatable
+	OldValue MaybeRelocatable
+	NewValue MaybeRelocatable
+}
+
+func (e *MemoryOverwriteError) Error() string {
+	return fmt.Sprintf("Memory is write-once, cannot overwrite memory value at %d:%d (old value: %s, new value: %s)",
+		e.Address.SegmentIndex, e.Address.Offset, e.OldValue, e.NewValue)
+}
+
+// A write-once violation recorded while running in relaxed write mode
+type WriteViolation struct {
+	Address  Relocatable
+	OldValue MaybeRelocatable
+	NewValue MaybeRelocatable
 }
 
 func NewMemory() *Memory {
 	return &Memory{
-		data:                make(map[Relocatable]MaybeRelocatable),
-		validated_addresses: NewAddressSet(),
+		data:                make([]segment, 0),
+		validated_addresses: make(map[uint]*offsetSet),
 		validation_rules:    make(map[uint]ValidationRule),
+		relocation_rules:    make(map[int]Relocatable),
+	}
+}
+
+// Returns the segment with the given index, allocating its storage if it hasn't been used yet
+// The caller must ensure that index < num_segments
+func (m *Memory) segment(index int) *segment {
+	for len(m.data) <= index {
+		m.data = append(m.data, segment{})
 	}
+	return &m.data[index]
+}
+
+// Same as segment, for the temporary segment with the given (negative) index
+// The caller must ensure that -index <= num_temp_segments
+func (m *Memory) tempSegment(index int) *segment {
+	for len(m.temp_data) < -index {
+		m.temp_data = append(m.temp_data, segment{})
+	}
+	return &m.temp_data[-index-1]
 }
 
 func (m *Memory) NumSegments() uint {
 	return m.num_segments
 }
 
+func (m *Memory) NumTempSegments() uint {
+	return m.num_temp_segments
+}
+
 // Inserts a value in some memory address, given by a Relocatable value.
 func (m *Memory) Insert(addr Relocatable, val *MaybeRelocatable) error {
-	// FIXME: There should be a special handling if the key
-	// segment index is negative. This is an edge
-	// case, so for now let's raise an error.
-	if addr.SegmentIndex < 0 {
-		return errors.New("Segment index of key is negative - unimplemented")
-	}
-
 	// Check that insertions are preformed within the memory bounds
-	if addr.SegmentIndex >= int(m.num_segments) {
-		return errors.New("Error: Inserting into a non allocated segment")
+	var segment *segment
+	if addr.SegmentIndex < 0 {
+		if -addr.SegmentIndex > int(m.num_temp_segments) {
+			return errors.New("Error: Inserting into a non allocated temporary segment")
+		}
+		segment = m.tempSegment(addr.SegmentIndex)
+	} else {
+		if addr.SegmentIndex >= int(m.num_segments) {
+			return errors.New("Error: Inserting into a non allocated segment")
+		}
+		segment = m.segment(addr.SegmentIndex)
 	}
 
 	// Check for possible overwrites
-	prev_elem, ok := m.data[addr]
-	if ok && prev_elem != *val {
-		return errors.New("Memory is write-once, cannot overwrite memory value")
+	if segment.isOccupied(addr.Offset) && segment.cells[addr.Offset] != *val {
+		if !m.relaxed_writes {
+			return &MemoryOverwriteError{Address: addr, OldValue: segment.cells[addr.Offset], NewValue: *val}
+		}
+		// Keep the first value written so that the run stays consistent with it
+		m.write_violations = append(m.write_violations, WriteViolation{addr, segment.cells[addr.Offset], *val})
+		return nil
 	}
-	m.data[addr] = *val
+	if m.forbid_code_writes && segment.executable && !segment.isOccupied(addr.Offset) {
+		return fmt.Errorf("Segment %d holds code, writing to it at offset %d is forbidden", addr.SegmentIndex, addr.Offset)
+	}
+	segment.set(addr.Offset, *val)
 	return m.validateAddress(addr)
 }
 
+// Enables or disables relaxed write mode.
+// In relaxed write mode, attempts to overwrite a memory cell with a different value don't fail,
+// the original value is kept and the violation is recorded so that it can be reported at the end
+// of the run. Meant to be used as a debugging aid to locate the first divergent hint.
+func (m *Memory) SetRelaxedWrites(relaxed bool) {
+	m.relaxed_writes = relaxed
+}
+
+// Returns the write-once violations recorded in relaxed write mode, in the order they happened
+func (m *Memory) WriteViolations() []WriteViolation {
+	return m.write_violations
+}
+
+// Returns an error describing the first write-once violation recorded in relaxed write mode, if any
+func (m *Memory) CheckWriteViolations() error {
+	if len(m.write_violations) == 0 {
+		return nil
+	}
+	first := m.write_violations[0]
+	return fmt.Errorf("Memory is write-once, %d overwrite(s) recorded, first one at %d:%d (old value: %s, new value: %s)",
+		len(m.write_violations), first.Address.SegmentIndex, first.Address.Offset, first.OldValue, first.NewValue)
+}
+
+// Marks a segment as holding code the pc runs. Writing to its empty cells afterwards is a code
+// write, which fails if code writes are forbidden, see SetForbidCodeWrites
+func (m *Memory) MarkExecutable(segmentIndex int) {
+	if segmentIndex >= 0 && segmentIndex < int(m.num_segments) {
+		m.segment(segmentIndex).executable = true
+	}
+}
+
+// Makes writes to executable segments fail, for runs where hints mustn't modify code
+func (m *Memory) SetForbidCodeWrites(forbid bool) {
+	m.forbid_code_writes = forbid
+}
+
 // Gets some value stored in the memory address `addr`.
+// Fails if the cell is empty, use TryGet to tell empty cells apart from errors
 func (m *Memory) Get(addr Relocatable) (*MaybeRelocatable, error) {
-	// FIXME: There should be a special handling if the key
-	// segment index is negative. This is an edge
-	// case, so for now let's raise an error.
+	value, err := m.TryGet(addr)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("Memory Get: Value not found")
+	}
+	return value, nil
+}
+
+// Gets some value stored in the memory address `addr`.
+// Returns a nil value and no error if the cell is empty, errors are reserved for
+// invalid accesses
+func (m *Memory) TryGet(addr Relocatable) (*MaybeRelocatable, error) {
+	value, err := m.TryGetValue(addr)
+	if err != nil || !value.HasValue() {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// Same as TryGet, but returns the value itself, which holds no value if the cell is empty.
+// Unlike TryGet it doesn't allocate, which matters in the vm's main loop
+func (m *Memory) TryGetValue(addr Relocatable) (MaybeRelocatable, error) {
 	if addr.SegmentIndex < 0 {
-		return nil, errors.New("Segment index of key is negative - unimplemented")
+		if -addr.SegmentIndex > int(m.num_temp_segments) {
+			return MaybeRelocatable{}, fmt.Errorf("Temporary segment %d doesn't exist", addr.SegmentIndex)
+		}
+		if -addr.SegmentIndex > len(m.temp_data) || !m.temp_data[-addr.SegmentIndex-1].isOccupied(addr.Offset) {
+			return MaybeRelocatable{}, nil
+		}
+		return m.temp_data[-addr.SegmentIndex-1].cells[addr.Offset], nil
 	}
+	if addr.SegmentIndex >= len(m.data) || !m.data[addr.SegmentIndex].isOccupied(addr.Offset) {
+		return MaybeRelocatable{}, nil
+	}
+	return m.data[addr.SegmentIndex].cells[addr.Offset], nil
+}
 
-	// FIXME: We should create a function for this value,
-	// `relocate_value()` in the future. This function should
-	// check if the value is a `Relocatable` with a negative
-	// segment index. Again, these are edge cases so not important
-	// right now. See cairo-vm code for details.
-	value, ok := m.data[addr]
+// Returns the value stored at addr, failing if the cell is empty
+func (m *Memory) getValue(addr Relocatable) (MaybeRelocatable, error) {
+	value, err := m.TryGetValue(addr)
+	if err == nil && !value.HasValue() {
+		err = errors.New("Memory Get: Value not found")
+	}
+	return value, err
+}
 
+// Gets the Felt value stored in the memory address `addr`.
+// Fails if the cell is empty or if it holds a Relocatable value
+func (m *Memory) GetFelt(addr Relocatable) (lambdaworks.Felt, error) {
+	val, err := m.getValue(addr)
+	if err != nil {
+		return lambdaworks.FeltZero(), err
+	}
+	felt, ok := val.GetFelt()
 	if !ok {
-		return nil, errors.New("Memory Get: Value not found")
+		return lambdaworks.FeltZero(), fmt.Errorf("expected integer at %d:%d, got relocatable", addr.SegmentIndex, addr.Offset)
 	}
+	return felt, nil
+}
 
-	return &value, nil
+// Gets the Relocatable value stored in the memory address `addr`.
+// Fails if the cell is empty or if it holds a Felt value
+func (m *Memory) GetRelocatable(addr Relocatable) (Relocatable, error) {
+	val, err := m.getValue(addr)
+	if err != nil {
+		return Relocatable{}, err
+	}
+	rel, ok := val.GetRelocatable()
+	if !ok {
+		return Relocatable{}, fmt.Errorf("expected relocatable at %d:%d, got integer", addr.SegmentIndex, addr.Offset)
+	}
+	return rel, nil
+}
+
+// Declares that the temporary segment starting at src will be moved to dst by RelocateMemory
+// dst may lie in another temporary segment, as long as the rules don't form a cycle
+func (m *Memory) AddRelocationRule(src Relocatable, dst Relocatable) error {
+	if src.SegmentIndex >= 0 {
+		return fmt.Errorf("Relocation rules can only move temporary segments, got segment %d", src.SegmentIndex)
+	}
+	if src.Offset != 0 {
+		return fmt.Errorf("Relocation rules must start at the beginning of a temporary segment, got %d:%d", src.SegmentIndex, src.Offset)
+	}
+	if -src.SegmentIndex > int(m.num_temp_segments) {
+		return fmt.Errorf("Temporary segment %d doesn't exist", src.SegmentIndex)
+	}
+	if _, ok := m.relocation_rules[src.SegmentIndex]; ok {
+		return fmt.Errorf("Temporary segment %d already has a relocation rule", src.SegmentIndex)
+	}
+	m.relocation_rules[src.SegmentIndex] = dst
+	return nil
+}
+
+// Applies the relocation rules: every pointer into a relocated temporary segment is made to point
+// to its destination, and the segment's values are moved there. Temporary segments without a
+// rule are left as they are. Fails if the rules form a cycle or if a moved value conflicts with
+// the destination's
+func (m *Memory) RelocateMemory() error {
+	if len(m.relocation_rules) == 0 {
+		return nil
+	}
+	rules := make(map[int]Relocatable, len(m.relocation_rules))
+	for src := range m.relocation_rules {
+		dst, err := m.resolveRelocationRule(src)
+		if err != nil {
+			return err
+		}
+		rules[src] = dst
+	}
+	relocateCells := func(s *segment) {
+		for offset := uint(0); offset < s.size(); offset++ {
+			rel, ok := s.cells[offset].GetRelocatable()
+			if !ok || !s.isOccupied(offset) {
+				continue
+			}
+			if dst, ok := rules[rel.SegmentIndex]; ok {
+				s.cells[offset] = *NewMaybeRelocatableRelocatable(NewRelocatable(dst.SegmentIndex, dst.Offset+rel.Offset))
+			}
+		}
+	}
+	for i := range m.data {
+		relocateCells(&m.data[i])
+	}
+	for i := range m.temp_data {
+		relocateCells(&m.temp_data[i])
+	}
+	for i := range m.temp_data {
+		dst, ok := rules[-i-1]
+		if !ok {
+			continue
+		}
+		moved := m.temp_data[i]
+		m.temp_data[i] = segment{}
+		for offset := uint(0); offset < moved.size(); offset++ {
+			if !moved.isOccupied(offset) {
+				continue
+			}
+			addr := NewRelocatable(dst.SegmentIndex, dst.Offset+offset)
+			if err := m.Insert(addr, &moved.cells[offset]); err != nil {
+				return fmt.Errorf("Failed to relocate temporary segment %d to %d:%d: %w", -i-1, dst.SegmentIndex, dst.Offset, err)
+			}
+		}
+	}
+	m.relocation_rules = make(map[int]Relocatable)
+	return nil
+}
+
+// Follows the relocation rules from the temporary segment src until reaching a segment without a
+// rule, and returns where src ends up
+func (m *Memory) resolveRelocationRule(src int) (Relocatable, error) {
+	visited := map[int]bool{src: true}
+	dst := m.relocation_rules[src]
+	for {
+		next, ok := m.relocation_rules[dst.SegmentIndex]
+		if !ok {
+			return dst, nil
+		}
+		if visited[dst.SegmentIndex] {
+			return Relocatable{}, fmt.Errorf("Relocation rules form a cycle through temporary segment %d", dst.SegmentIndex)
+		}
+		visited[dst.SegmentIndex] = true
+		dst = NewRelocatable(next.SegmentIndex, next.Offset+dst.Offset)
+	}
+}
+
+// Calls fn for every value stored in the given segment, in address order
+// Iteration stops early if fn returns false
+func (m *Memory) Range(segmentIndex int, fn func(addr Relocatable, val MaybeRelocatable) bool) {
+	if segmentIndex < 0 || segmentIndex >= len(m.data) {
+		return
+	}
+	segment := &m.data[segmentIndex]
+	for offset := uint(0); offset < segment.size(); offset++ {
+		if !segment.isOccupied(offset) {
+			continue
+		}
+		if !fn(NewRelocatable(segmentIndex, offset), segment.cells[offset]) {
+			return
+		}
+	}
+}
+
+// Calls fn for every value stored in memory, in address order
+// Iteration stops early if fn returns false
+func (m *Memory) RangeAll(fn func(addr Relocatable, val MaybeRelocatable) bool) {
+	keepGoing := true
+	for i := 0; i < len(m.data) && keepGoing; i++ {
+		m.Range(i, func(addr Relocatable, val MaybeRelocatable) bool {
+			keepGoing = fn(addr, val)
+			return keepGoing
+		})
+	}
+}
+
+// Compares the memory ranges [lhs, lhs + len) and [rhs, rhs + len) cell by cell.
+// Returns the ordering of the first differing pair of cells (-1, 0 or 1) and the index at which they
+// differ, or (0, len) if both ranges are equal. Missing cells (gaps) are considered smaller than any
+// value, relocatable values are considered smaller than felts, felts are compared by their canonical
+// value and relocatables by segment index and then offset.
+// If either segment doesn't exist, only the existence of the segments is compared.
+func (m *Memory) MemCmp(lhs Relocatable, rhs Relocatable, len uint) (int, uint) {
+	lhsExists := lhs.SegmentIndex >= 0 && lhs.SegmentIndex < int(m.num_segments)
+	rhsExists := rhs.SegmentIndex >= 0 && rhs.SegmentIndex < int(m.num_segments)
+	switch {
+	case !lhsExists && !rhsExists:
+		return 0, 0
+	case !rhsExists:
+		return 1, 0
+	case !lhsExists:
+		return -1, 0
+	}
+	for i := uint(0); i < len; i++ {
+		lhsCell, _ := m.TryGet(NewRelocatable(lhs.SegmentIndex, lhs.Offset+i))
+		rhsCell, _ := m.TryGet(NewRelocatable(rhs.SegmentIndex, rhs.Offset+i))
+		if ord := compareCells(lhsCell, rhsCell); ord != 0 {
+			return ord, i
+		}
+	}
+	return 0, len
+}
+
+// Returns true if the memory ranges [lhs, lhs + len) and [rhs, rhs + len) hold the same values,
+// including gaps in the same positions
+func (m *Memory) MemEq(lhs Relocatable, rhs Relocatable, len uint) bool {
+	if lhs == rhs {
+		return true
+	}
+	ord, _ := m.MemCmp(lhs, rhs, len)
+	return ord == 0
+}
+
+// Orders two memory cells, see MemCmp
+func compareCells(a *MaybeRelocatable, b *MaybeRelocatable) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	aRel, aIsRel := a.GetRelocatable()
+	bRel, bIsRel := b.GetRelocatable()
+	switch {
+	case aIsRel && bIsRel:
+		if aRel.SegmentIndex != bRel.SegmentIndex {
+			return compareInts(aRel.SegmentIndex, bRel.SegmentIndex)
+		}
+		return compareInts(int(aRel.Offset), int(bRel.Offset))
+	case aIsRel:
+		return -1
+	case bIsRel:
+		return 1
+	}
+	aFelt, _ := a.GetFelt()
+	bFelt, _ := b.GetFelt()
+	return aFelt.Cmp(bFelt)
+}
+
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
 }
 
 // Adds a validation rule for a given segment
 func (m *Memory) AddValidationRule(segment_index uint, rule ValidationRule) {
 	m.validation_rules[segment_index] = rule
+	if _, ok := m.validated_addresses[segment_index]; !ok {
+		m.validated_addresses[segment_index] = &offsetSet{}
+	}
+}
+
+// Returns true if addr has been validated by its segment's validation rule
+func (m *Memory) IsValidated(addr Relocatable) bool {
+	if addr.SegmentIndex < 0 {
+		return false
+	}
+	validated, ok := m.validated_addresses[uint(addr.SegmentIndex)]
+	return ok && validated.Contains(addr.Offset)
 }
 
 // Applies the validation rule for the addr's segment if any
 // Skips validation if the address is temporary or if it has been previously validated
 func (m *Memory) validateAddress(addr Relocatable) error {
-	if addr.SegmentIndex < 0 || m.validated_addresses.Contains(addr) {
+	if addr.SegmentIndex < 0 {
 		return nil
 	}
 	rule, ok := m.validation_rules[uint(addr.SegmentIndex)]
-	if !ok {
+	if !ok || m.IsValidated(addr) {
 		return nil
 	}
 	validated_addresses, error := rule(m, addr)
@@ -108,7 +524,13 @@ func (m *Memory) validateAddress(addr Relocatable) error {
 		return error
 	}
 	for _, validated_address := range validated_addresses {
-		m.validated_addresses.Add(validated_address)
+		// Addresses in segments without validation rules are never looked up
+		if validated_address.SegmentIndex < 0 {
+			continue
+		}
+		if validated, ok := m.validated_addresses[uint(validated_address.SegmentIndex)]; ok {
+			validated.Add(validated_address.Offset)
+		}
 	}
 	return nil
 }
@@ -116,11 +538,10 @@ func (m *Memory) validateAddress(addr Relocatable) error {
 // Applies validation_rules to every memory address, if applicatble
 // Skips validation if the address is temporary or if it has been previously validated
 func (m *Memory) ValidateExistingMemory() error {
-	for addr := range m.data {
-		err := m.validateAddress(addr)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	var err error
+	m.RangeAll(func(addr Relocatable, _ MaybeRelocatable) bool {
+		err = m.validateAddress(addr)
+		return err == nil
+	})
+	return err
 }
@@ -167,6 +167,21 @@ func TestMemoryInsertOverWriteValue(t *testing.T) {
 	if err2 == nil {
 		t.Errorf("Overwritting memory value should fail")
 	}
+	var overwriteErr *memory.MemoryOverwriteError
+	if !errors.As(err2, &overwriteErr) || overwriteErr.Address != key || overwriteErr.OldValue != *val || overwriteErr.NewValue != *val2 {
+		t.Errorf("Expected a MemoryOverwriteError with both values, got %v", err2)
+	}
+}
+
+func TestMemoryOverwriteErrorMessage(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.Memory.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(2, 3)))
+	err := mem_manager.Memory.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	expected := "Memory is write-once, cannot overwrite memory value at 0:1 (old value: 2:3, new value: 5)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
 }
 
 func TestMemoryInsertUnallocatedSegment(t *testing.T) {
@@ -192,12 +207,26 @@ func TestMemorySegmentsLoadDataUnallocatedSegment(t *testing.T) {
 	data := []memory.MaybeRelocatable{*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
 
 	// Load Data
-	_, err := mem_manager.LoadData(ptr, &data)
+	_, err := mem_manager.LoadData(ptr, data)
 	if err == nil {
 		t.Errorf("Insertion on unallocated segment should fail")
 	}
 }
 
+func TestMemorySegmentsLoadDataEmpty(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+
+	ptr := memory.NewRelocatable(0, 3)
+	end_ptr, err := mem_manager.LoadData(ptr, []memory.MaybeRelocatable{})
+	if err != nil {
+		t.Errorf("LoadData error in test: %s", err)
+	}
+	if end_ptr != ptr {
+		t.Errorf("LoadData with empty data should return the base ptr")
+	}
+}
+
 func TestMemorySegmentsLoadDataOneElement(t *testing.T) {
 	mem_manager := memory.NewMemorySegmentManager()
 	mem_manager.AddSegment()
@@ -207,7 +236,7 @@ func TestMemorySegmentsLoadDataOneElement(t *testing.T) {
 	data := []memory.MaybeRelocatable{*val}
 
 	// Load Data
-	end_ptr, err := mem_manager.LoadData(ptr, &data)
+	end_ptr, err := mem_manager.LoadData(ptr, data)
 	if err != nil {
 		t.Errorf("LoadData error in test: %s", err)
 	}
@@ -240,7 +269,7 @@ func TestMemorySegmentsLoadDataTwoElements(t *testing.T) {
 	data := []memory.MaybeRelocatable{*val, *val2}
 
 	// Load Data
-	end_ptr, err := mem_manager.LoadData(ptr, &data)
+	end_ptr, err := mem_manager.LoadData(ptr, data)
 	if err != nil {
 		t.Errorf("LoadData error in test: %s", err)
 	}
@@ -320,3 +349,526 @@ func TestMemoryValidateExistingMemoryErr(t *testing.T) {
 		t.Errorf("ValidateExistingMemory should have failed")
 	}
 }
+
+func TestMemoryGetFelt(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+
+	felt, err := mem.GetFelt(memory.NewRelocatable(0, 0))
+	if err != nil {
+		t.Errorf("GetFelt error in test: %s", err)
+	}
+	if felt != lambdaworks.FeltFromUint64(5) {
+		t.Errorf("GetFelt returned wrong value")
+	}
+}
+
+func TestMemoryGetFeltRelocatable(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(1, 5), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0)))
+
+	_, err := mem.GetFelt(memory.NewRelocatable(1, 5))
+	if err == nil || err.Error() != "expected integer at 1:5, got relocatable" {
+		t.Errorf("GetFelt should fail with a descriptive error, got: %v", err)
+	}
+}
+
+func TestMemoryGetFeltEmptyCell(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	_, err := mem_manager.Memory.GetFelt(memory.NewRelocatable(0, 0))
+	if err == nil {
+		t.Errorf("GetFelt should fail on an empty cell")
+	}
+}
+
+func TestMemoryGetRelocatable(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 3)))
+
+	rel, err := mem.GetRelocatable(memory.NewRelocatable(0, 0))
+	if err != nil {
+		t.Errorf("GetRelocatable error in test: %s", err)
+	}
+	if rel != memory.NewRelocatable(0, 3) {
+		t.Errorf("GetRelocatable returned wrong value")
+	}
+}
+
+func TestMemoryGetRelocatableFelt(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+
+	_, err := mem.GetRelocatable(memory.NewRelocatable(0, 2))
+	if err == nil || err.Error() != "expected relocatable at 0:2, got integer" {
+		t.Errorf("GetRelocatable should fail with a descriptive error, got: %v", err)
+	}
+}
+
+func TestMemoryGetHoleInSegment(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	// Insert past the first bitmap word so the segment grows over several words
+	mem.Insert(memory.NewRelocatable(0, 130), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	mem.Insert(memory.NewRelocatable(0, 64), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6)))
+
+	if _, err := mem.Get(memory.NewRelocatable(0, 65)); err == nil {
+		t.Errorf("Get should fail on a memory hole")
+	}
+	if _, err := mem.Get(memory.NewRelocatable(0, 131)); err == nil {
+		t.Errorf("Get should fail past the end of the segment")
+	}
+	val, err := mem.Get(memory.NewRelocatable(0, 64))
+	if err != nil || !reflect.DeepEqual(val, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6))) {
+		t.Errorf("Get returned wrong value after growing the segment")
+	}
+	val, err = mem.Get(memory.NewRelocatable(0, 130))
+	if err != nil || !reflect.DeepEqual(val, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))) {
+		t.Errorf("Get returned wrong value after growing the segment")
+	}
+}
+
+func TestMemoryGetUnusedSegment(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	if _, err := mem_manager.Memory.Get(memory.NewRelocatable(1, 0)); err == nil {
+		t.Errorf("Get should fail on an unused segment")
+	}
+}
+
+func TestMemoryInsertZeroValueIsOccupied(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()))
+	err := mem.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne()))
+	if err == nil {
+		t.Errorf("Overwriting a cell holding zero should fail")
+	}
+}
+
+func TestMemoryTryGetEmptyCell(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	val, err := mem_manager.Memory.TryGet(memory.NewRelocatable(0, 3))
+	if val != nil || err != nil {
+		t.Errorf("TryGet on an empty cell should return a nil value and no error")
+	}
+}
+
+func TestMemoryTryGetNegativeSegment(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	_, err := mem_manager.Memory.TryGet(memory.NewRelocatable(-1, 0))
+	if err == nil {
+		t.Errorf("TryGet on a negative segment should fail")
+	}
+}
+
+func TestMemoryTryGetOk(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	val := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))
+	mem_manager.Memory.Insert(memory.NewRelocatable(0, 0), val)
+	res, err := mem_manager.Memory.TryGet(memory.NewRelocatable(0, 0))
+	if err != nil || !reflect.DeepEqual(res, val) {
+		t.Errorf("TryGet returned wrong value")
+	}
+}
+
+func TestMemoryValidatedAddressesPerSegment(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.AddValidationRule(1, rule_always_ok)
+
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	mem.Insert(memory.NewRelocatable(1, 70), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+
+	if mem.IsValidated(memory.NewRelocatable(0, 0)) {
+		t.Errorf("Addresses in segments without validation rules should not be tracked")
+	}
+	if !mem.IsValidated(memory.NewRelocatable(1, 70)) {
+		t.Errorf("Address 1:70 should have been validated")
+	}
+	if mem.IsValidated(memory.NewRelocatable(1, 6)) {
+		t.Errorf("Address 1:6 should not have been validated")
+	}
+}
+
+func TestMemoryValidateExistingMemory(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	mem.AddValidationRule(0, rule_always_ok)
+
+	if mem.IsValidated(memory.NewRelocatable(0, 2)) {
+		t.Errorf("Address 0:2 should not be validated before calling ValidateExistingMemory")
+	}
+	if err := mem.ValidateExistingMemory(); err != nil {
+		t.Errorf("ValidateExistingMemory error in test: %s", err)
+	}
+	if !mem.IsValidated(memory.NewRelocatable(0, 2)) {
+		t.Errorf("Address 0:2 should have been validated")
+	}
+}
+
+func TestMemoryRangeSegmentInOrder(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(1, 4), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)))
+	mem.Insert(memory.NewRelocatable(1, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)))
+	mem.Insert(memory.NewRelocatable(1, 2), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0)))
+
+	addrs := make([]memory.Relocatable, 0)
+	mem.Range(1, func(addr memory.Relocatable, val memory.MaybeRelocatable) bool {
+		addrs = append(addrs, addr)
+		return true
+	})
+	expected := []memory.Relocatable{memory.NewRelocatable(1, 0), memory.NewRelocatable(1, 2), memory.NewRelocatable(1, 4)}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("Range yielded wrong addresses: %v", addrs)
+	}
+}
+
+func TestMemoryRangeAllStopsEarly(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	mem.Insert(memory.NewRelocatable(1, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)))
+	mem.Insert(memory.NewRelocatable(1, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)))
+
+	values := make([]memory.MaybeRelocatable, 0)
+	mem.RangeAll(func(addr memory.Relocatable, val memory.MaybeRelocatable) bool {
+		values = append(values, val)
+		return len(values) < 2
+	})
+	expected := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)),
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("RangeAll yielded wrong values: %v", values)
+	}
+}
+
+func TestMemCmpAndMemEq(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)),
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)),
+	}
+	mem_manager.LoadData(memory.NewRelocatable(0, 0), data)
+	mem_manager.LoadData(memory.NewRelocatable(1, 0), data[:2])
+	mem.Insert(memory.NewRelocatable(1, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+
+	ord, idx := mem.MemCmp(memory.NewRelocatable(0, 0), memory.NewRelocatable(1, 0), 3)
+	if ord != -1 || idx != 2 {
+		t.Errorf("MemCmp returned (%d, %d), expected (-1, 2)", ord, idx)
+	}
+	ord, idx = mem.MemCmp(memory.NewRelocatable(0, 0), memory.NewRelocatable(1, 0), 2)
+	if ord != 0 || idx != 2 {
+		t.Errorf("MemCmp returned (%d, %d), expected (0, 2)", ord, idx)
+	}
+	if !mem.MemEq(memory.NewRelocatable(0, 0), memory.NewRelocatable(1, 0), 2) {
+		t.Errorf("MemEq should be true for equal ranges")
+	}
+	if mem.MemEq(memory.NewRelocatable(0, 0), memory.NewRelocatable(1, 0), 3) {
+		t.Errorf("MemEq should be false for different ranges")
+	}
+}
+
+func TestMemCmpGapsAndTypes(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	mem.Insert(memory.NewRelocatable(0, 5), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0)))
+
+	// A value is greater than a gap
+	ord, idx := mem.MemCmp(memory.NewRelocatable(0, 0), memory.NewRelocatable(0, 1), 1)
+	if ord != 1 || idx != 0 {
+		t.Errorf("MemCmp returned (%d, %d), expected (1, 0)", ord, idx)
+	}
+	// Gaps compare equal
+	if !mem.MemEq(memory.NewRelocatable(0, 1), memory.NewRelocatable(0, 2), 3) {
+		t.Errorf("MemEq should be true for ranges of gaps")
+	}
+	// Relocatables are smaller than felts
+	ord, _ = mem.MemCmp(memory.NewRelocatable(0, 5), memory.NewRelocatable(0, 0), 1)
+	if ord != -1 {
+		t.Errorf("MemCmp should order relocatables before felts")
+	}
+	// Non-existing segments
+	ord, idx = mem.MemCmp(memory.NewRelocatable(0, 0), memory.NewRelocatable(3, 0), 1)
+	if ord != 1 || idx != 0 {
+		t.Errorf("MemCmp returned (%d, %d), expected (1, 0)", ord, idx)
+	}
+}
+
+func TestMemoryRelaxedWritesRecordsViolations(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.SetRelaxedWrites(true)
+
+	addr := memory.NewRelocatable(0, 1)
+	old := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1))
+	new := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))
+	mem.Insert(addr, old)
+	if err := mem.Insert(addr, new); err != nil {
+		t.Errorf("Overwrite should not fail in relaxed write mode: %s", err)
+	}
+
+	val, _ := mem.Get(addr)
+	if !reflect.DeepEqual(val, old) {
+		t.Errorf("The original value should be kept")
+	}
+	expected := []memory.WriteViolation{{Address: addr, OldValue: *old, NewValue: *new}}
+	if !reflect.DeepEqual(mem.WriteViolations(), expected) {
+		t.Errorf("Wrong write violations recorded: %v", mem.WriteViolations())
+	}
+	if err := mem.CheckWriteViolations(); err == nil {
+		t.Errorf("CheckWriteViolations should report the recorded violation")
+	}
+}
+
+func TestMemoryCodeWrites(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	value := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1))
+	mem.Insert(memory.NewRelocatable(0, 0), value)
+	mem.MarkExecutable(0)
+
+	if err := mem.Insert(memory.NewRelocatable(0, 2), value); err != nil {
+		t.Errorf("Code writes should be allowed unless forbidden, got %v", err)
+	}
+
+	mem.SetForbidCodeWrites(true)
+	// Writing the value a cell already holds doesn't modify the code
+	if err := mem.Insert(memory.NewRelocatable(0, 0), value); err != nil {
+		t.Errorf("Rewriting the value of a code cell should succeed, got %v", err)
+	}
+	if err := mem.Insert(memory.NewRelocatable(1, 0), value); err != nil {
+		t.Errorf("Writes to non executable segments should succeed, got %v", err)
+	}
+	if err := mem.Insert(memory.NewRelocatable(0, 3), value); err == nil {
+		t.Errorf("Writing to an executable segment should fail when code writes are forbidden")
+	}
+	if _, err := mem.Get(memory.NewRelocatable(0, 3)); err == nil {
+		t.Errorf("The forbidden write shouldn't be stored")
+	}
+}
+
+func TestMemoryStrictWritesNoViolations(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem := &mem_manager.Memory
+	mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	if err := mem.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))); err == nil {
+		t.Errorf("Overwrite should fail outside of relaxed write mode")
+	}
+	if len(mem.WriteViolations()) != 0 || mem.CheckWriteViolations() != nil {
+		t.Errorf("No violations should be recorded outside of relaxed write mode")
+	}
+}
+
+// Runs sequences of segment allocations, inserts and gets decoded from the input, checking the
+// memory against a map of the values written, then relocates it and checks every relocated cell
+func FuzzMemory(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 0, 1, 0, 3, 7, 1, 0, 3, 8, 2, 0, 3, 0})
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0x81, 1, 0, 2, 0x80, 2, 1, 0, 0})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		segments := memory.NewMemorySegmentManager()
+		written := make(map[memory.Relocatable]memory.MaybeRelocatable)
+		for i := 0; i+4 <= len(ops); i += 4 {
+			op, segment, offset, value := ops[i]%3, int(ops[i+1]%8), uint(ops[i+2]), ops[i+3]
+			addr := memory.NewRelocatable(segment, offset)
+			switch op {
+			case 0:
+				segments.AddSegment()
+			case 1:
+				// Values with the high bit set are pointers to the segment given by the low bits
+				val := *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(value)))
+				if value >= 0x80 {
+					val = *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(int(value%8), uint(value)))
+				}
+				err := segments.Memory.Insert(addr, &val)
+				previous, exists := written[addr]
+				switch {
+				case uint(segment) >= segments.Memory.NumSegments():
+					if err == nil {
+						t.Fatalf("Inserting into unallocated segment %d should fail", segment)
+					}
+				case exists && previous != val:
+					if err == nil {
+						t.Fatalf("Overwriting %v with a different value should fail", addr)
+					}
+				case err != nil:
+					t.Fatalf("Insert at %v failed with error: %s", addr, err)
+				default:
+					written[addr] = val
+				}
+			case 2:
+				got, err := segments.Memory.TryGet(addr)
+				expected, exists := written[addr]
+				if err != nil || (got == nil) == exists || (exists && *got != expected) {
+					t.Fatalf("Wrong value at %v: expected %v, got %v, err: %v", addr, expected, got, err)
+				}
+			}
+		}
+
+		segments.ComputeEffectiveSizes()
+		table, ok := segments.RelocateSegments()
+		if !ok {
+			t.Fatalf("RelocateSegments failed")
+		}
+		relocated, err := segments.RelocateMemory(&table)
+		for _, val := range written {
+			if rel, isRel := val.GetRelocatable(); isRel && uint(rel.SegmentIndex) >= segments.Memory.NumSegments() {
+				if err == nil {
+					t.Fatalf("Relocating a pointer to unallocated segment %d should fail", rel.SegmentIndex)
+				}
+				return
+			}
+		}
+		if err != nil {
+			t.Fatalf("RelocateMemory failed with error: %s", err)
+		}
+		for addr, val := range written {
+			expected, isFelt := val.GetFelt()
+			if rel, isRel := val.GetRelocatable(); isRel {
+				expected = lambdaworks.FeltFromUint64(uint64(rel.RelocateAddress(&table)))
+			} else if !isFelt {
+				t.Fatalf("Unexpected value %v", val)
+			}
+			got := relocated[addr.RelocateAddress(&table)]
+			if got == nil || *got != expected {
+				t.Fatalf("Wrong relocated value for %v: expected %s, got %v", addr, expected.String(), got)
+			}
+		}
+	})
+}
+
+func TestMemoryTempSegments(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	temp := mem_manager.AddTempSegment()
+	if temp != memory.NewRelocatable(-1, 0) || mem_manager.Memory.NumTempSegments() != 1 {
+		t.Fatalf("Expected the first temporary segment to be -1, got %+v", temp)
+	}
+	val := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
+	if err := mem_manager.Memory.Insert(memory.NewRelocatable(-1, 2), val); err != nil {
+		t.Fatalf("Insert error in test: %s", err)
+	}
+	if got, err := mem_manager.Memory.TryGet(memory.NewRelocatable(-1, 2)); err != nil || got == nil || *got != *val {
+		t.Errorf("Expected %v at -1:2, got %v, err: %v", val, got, err)
+	}
+	if err := mem_manager.Memory.Insert(memory.NewRelocatable(-2, 0), val); err == nil {
+		t.Errorf("Insertion on unallocated temporary segment should fail")
+	}
+}
+
+func TestMemoryRelocateMemory(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	mem_manager.AddSegment()
+	first := mem_manager.AddTempSegment()
+	second := mem_manager.AddTempSegment()
+	mem := &mem_manager.Memory
+	cells := map[memory.Relocatable]memory.MaybeRelocatable{
+		memory.NewRelocatable(0, 0):  *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(-1, 1)),
+		memory.NewRelocatable(1, 0):  *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)),
+		memory.NewRelocatable(-1, 0): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)),
+		memory.NewRelocatable(-1, 1): *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(-2, 0)),
+		memory.NewRelocatable(-2, 0): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)),
+	}
+	for addr, value := range cells {
+		value := value
+		if err := mem.Insert(addr, &value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// -1 is moved after the value in segment 1, and -2 right after -1, through -1
+	if err := mem.AddRelocationRule(first, memory.NewRelocatable(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.AddRelocationRule(second, memory.NewRelocatable(-1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.RelocateMemory(); err != nil {
+		t.Fatalf("RelocateMemory error in test: %s", err)
+	}
+	expected := map[memory.Relocatable]memory.MaybeRelocatable{
+		memory.NewRelocatable(0, 0): *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2)),
+		memory.NewRelocatable(1, 1): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)),
+		memory.NewRelocatable(1, 2): *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 3)),
+		memory.NewRelocatable(1, 3): *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)),
+	}
+	for addr, value := range expected {
+		if got, err := mem.TryGetValue(addr); err != nil || got != value {
+			t.Errorf("Expected %s at %+v, got %s, err: %v", value, addr, got, err)
+		}
+	}
+	if got, err := mem.TryGet(memory.NewRelocatable(-1, 0)); err != nil || got != nil {
+		t.Errorf("Expected the temporary segment to be emptied, got %v, err: %v", got, err)
+	}
+}
+
+func TestMemoryRelocateMemoryCycle(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	first := mem_manager.AddTempSegment()
+	second := mem_manager.AddTempSegment()
+	mem := &mem_manager.Memory
+	mem.AddRelocationRule(first, memory.NewRelocatable(-2, 0))
+	mem.AddRelocationRule(second, memory.NewRelocatable(-1, 3))
+	if err := mem.RelocateMemory(); err == nil || err.Error() != "Relocation rules form a cycle through temporary segment -2" && err.Error() != "Relocation rules form a cycle through temporary segment -1" {
+		t.Errorf("Cyclic relocation rules should fail, got %v", err)
+	}
+}
+
+func TestMemoryAddRelocationRuleErrors(t *testing.T) {
+	mem_manager := memory.NewMemorySegmentManager()
+	mem_manager.AddSegment()
+	temp := mem_manager.AddTempSegment()
+	mem := &mem_manager.Memory
+	dst := memory.NewRelocatable(0, 0)
+	if err := mem.AddRelocationRule(memory.NewRelocatable(0, 0), dst); err == nil {
+		t.Errorf("Relocating a real segment should fail")
+	}
+	if err := mem.AddRelocationRule(memory.NewRelocatable(-1, 1), dst); err == nil {
+		t.Errorf("Relocating from the middle of a temporary segment should fail")
+	}
+	if err := mem.AddRelocationRule(memory.NewRelocatable(-2, 0), dst); err == nil {
+		t.Errorf("Relocating an unallocated temporary segment should fail")
+	}
+	if err := mem.AddRelocationRule(temp, dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.AddRelocationRule(temp, dst); err == nil || err.Error() != "Temporary segment -1 already has a relocation rule" {
+		t.Errorf("Adding a second rule for a segment should fail, got %v", err)
+	}
+}
@@ -90,108 +90,132 @@ func (relocatable *Relocatable) AddUint(other uint) (Relocatable, error) {
 	return NewRelocatable(relocatable.SegmentIndex, new_offset), nil
 }
 
-// MaybeRelocatable is the type of the memory cells in the Cairo
-// VM. For now, `inner` will hold any type but it should be
-// instantiated only with `Relocatable` or `Int` types.
-// We should analyze better alternatives to this.
+// MaybeRelocatable is the type of the memory cells in the Cairo VM, a tagged union holding
+// either a Felt or a Relocatable. The zero MaybeRelocatable holds neither, it stands for unknown
+// values, such as empty memory cells or operands yet to be deduced.
+// Only the field selected by kind is set, so values can be compared with ==
 type MaybeRelocatable struct {
-	inner any
+	kind        maybeRelocatableKind
+	felt        lambdaworks.Felt
+	relocatable Relocatable
 }
 
+type maybeRelocatableKind uint8
+
+const (
+	noValue maybeRelocatableKind = iota
+	feltValue
+	relocatableValue
+)
+
 // Creates a new MaybeRelocatable with an Int inner value
 func NewMaybeRelocatableFelt(felt lambdaworks.Felt) *MaybeRelocatable {
-	return &MaybeRelocatable{inner: felt}
+	return &MaybeRelocatable{kind: feltValue, felt: felt}
 }
 
 // Creates a new MaybeRelocatable with a Relocatable inner value
 func NewMaybeRelocatableRelocatable(relocatable Relocatable) *MaybeRelocatable {
-	return &MaybeRelocatable{inner: relocatable}
+	return &MaybeRelocatable{kind: relocatableValue, relocatable: relocatable}
+}
+
+// Returns false for the zero MaybeRelocatable, which holds neither a Felt nor a Relocatable.
+// It stands for unknown values, such as empty memory cells or operands yet to be deduced
+func (m *MaybeRelocatable) HasValue() bool {
+	return m.kind != noValue
+}
+
+func (m *MaybeRelocatable) IsFelt() bool {
+	return m.kind == feltValue
+}
+
+func (m *MaybeRelocatable) IsRelocatable() bool {
+	return m.kind == relocatableValue
 }
 
 // If m is Felt, returns the inner value + true, if not, returns zero + false
 func (m *MaybeRelocatable) GetFelt() (lambdaworks.Felt, bool) {
-	felt, is_type := m.inner.(lambdaworks.Felt)
-	return felt, is_type
+	return m.felt, m.kind == feltValue
 }
 
 // If m is Relocatable, returns the inner value + true, if not, returns zero + false
 func (m *MaybeRelocatable) GetRelocatable() (Relocatable, bool) {
-	rel, is_type := m.inner.(Relocatable)
-	return rel, is_type
+	return m.relocatable, m.kind == relocatableValue
 }
 
-func (m *MaybeRelocatable) IsZero() bool {
-	felt, is_int := m.GetFelt()
-	return is_int && felt.IsZero()
+// Returns the inner Felt, fails if m holds a Relocatable or no value
+func (m *MaybeRelocatable) Felt() (lambdaworks.Felt, error) {
+	if m.kind != feltValue {
+		return lambdaworks.Felt{}, fmt.Errorf("Expected a felt, got %s", m)
+	}
+	return m.felt, nil
 }
 
-// Turns a MaybeRelocatable into a Felt252 value.
-// If the inner value is an Int, it will extract the Felt252 value from it.
-// If the inner value is a Relocatable, it will relocate it according to the relocation_table
-// TODO: Return value should be of type (felt, error)
-func (m *MaybeRelocatable) RelocateValue(relocationTable *[]uint) (lambdaworks.Felt, error) {
-	inner_felt, ok := m.GetFelt()
-	if ok {
-		return inner_felt, nil
+// Returns the inner Relocatable, fails if m holds a Felt or no value
+func (m *MaybeRelocatable) Relocatable() (Relocatable, error) {
+	if m.kind != relocatableValue {
+		return Relocatable{}, fmt.Errorf("Expected a relocatable, got %s", m)
 	}
+	return m.relocatable, nil
+}
 
-	inner_relocatable, ok := m.GetRelocatable()
-	if ok {
-		return lambdaworks.FeltFromUint64(uint64(inner_relocatable.RelocateAddress(relocationTable))), nil
+// Formats felts in decimal, relocatables as segment:offset and the zero MaybeRelocatable as "no value"
+func (m MaybeRelocatable) String() string {
+	switch m.kind {
+	case feltValue:
+		return m.felt.String()
+	case relocatableValue:
+		return fmt.Sprintf("%d:%d", m.relocatable.SegmentIndex, m.relocatable.Offset)
 	}
-
-	return lambdaworks.FeltZero(), errors.New(fmt.Sprintf("Unexpected type %T", m.inner))
+	return "no value"
 }
 
-func (m *MaybeRelocatable) IsEqual(m1 *MaybeRelocatable) bool {
-	a, a_type := m.GetFelt()
-	b, b_type := m1.GetFelt()
-	if a_type == b_type {
-		if a_type {
-			return a == b
-		} else {
-			a, _ := m.GetRelocatable()
-			b, _ := m1.GetRelocatable()
-			return a.IsEqual(&b)
-		}
-	} else {
-		return false
+// Converts the inner felt into a u64, failing if the value is a relocatable or doesn't fit in 64 bits
+func (m *MaybeRelocatable) ToU64() (uint64, error) {
+	felt, ok := m.GetFelt()
+	if !ok {
+		return 0, errors.New("Cannot convert relocatable to u64")
 	}
+	return felt.ToU64()
 }
 
-func (m MaybeRelocatable) Add(other MaybeRelocatable) (MaybeRelocatable, error) {
-	// check if they are felt
-	m_int, m_is_int := m.GetFelt()
-	other_int, other_is_int := other.GetFelt()
+func (m *MaybeRelocatable) IsZero() bool {
+	return m.kind == feltValue && m.felt.IsZero()
+}
 
-	if m_is_int && other_is_int {
-		result := NewMaybeRelocatableFelt(m_int.Add(other_int))
-		return *result, nil
+// Turns a MaybeRelocatable into a Felt252 value.
+// If the inner value is an Int, it will extract the Felt252 value from it.
+// If the inner value is a Relocatable, it will relocate it according to the relocation_table
+func (m *MaybeRelocatable) RelocateValue(relocationTable *[]uint) (lambdaworks.Felt, error) {
+	switch m.kind {
+	case feltValue:
+		return m.felt, nil
+	case relocatableValue:
+		return lambdaworks.FeltFromUint64(uint64(m.relocatable.RelocateAddress(relocationTable))), nil
 	}
+	return lambdaworks.FeltZero(), errors.New("Can't relocate an empty value")
+}
 
-	// check if one is relocatable and the other int
-	m_rel, is_rel_m := m.GetRelocatable()
-	other_rel, is_rel_other := other.GetRelocatable()
-
-	if is_rel_m && !is_rel_other {
-		other_felt, _ := other.GetFelt()
-		relocatable, err := m_rel.AddFelt(other_felt)
-		if err != nil {
-			return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
-		}
-		return *NewMaybeRelocatableRelocatable(relocatable), nil
-
-	} else if !is_rel_m && is_rel_other {
+func (m *MaybeRelocatable) IsEqual(m1 *MaybeRelocatable) bool {
+	return *m == *m1
+}
 
-		m_felt, _ := m.GetFelt()
-		relocatable, err := other_rel.AddFelt(m_felt)
-		if err != nil {
-			return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
-		}
-		return *NewMaybeRelocatableRelocatable(relocatable), nil
-	} else {
-		return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), errors.New("RelocatableAdd")
+// Adds two MaybeRelocatable values
+// Behaves as follows:
+// Felt + Felt : Performs felt addition
+// Relocatable + Felt, Felt + Relocatable : Adds the Felt value to the Relocatable's offset, fails if the new offset exceeds the size of a uint
+// Relocatable + Relocatable : Always fails, this is not supported
+func (m MaybeRelocatable) Add(other MaybeRelocatable) (MaybeRelocatable, error) {
+	switch {
+	case m.kind == feltValue && other.kind == feltValue:
+		return *NewMaybeRelocatableFelt(m.felt.Add(other.felt)), nil
+	case m.kind == relocatableValue && other.kind == feltValue:
+		return m.AddFelt(other.felt)
+	case m.kind == feltValue && other.kind == relocatableValue:
+		return other.AddFelt(m.felt)
+	case m.kind == relocatableValue && other.kind == relocatableValue:
+		return MaybeRelocatable{}, errors.New("RelocatableAdd")
 	}
+	return MaybeRelocatable{}, fmt.Errorf("Can't add %s and %s", m, other)
 }
 
 // Subtracts two MaybeRelocatable values
@@ -201,33 +225,71 @@ func (m MaybeRelocatable) Add(other MaybeRelocatable) (MaybeRelocatable, error)
 // Relocatable - Relocatable : Returns the difference between the two offsets, fails if the difference is negative or if the segment indexes of the two relocatables don't match
 // Felt - Relocatable : Always fails, this is not supported
 func (m MaybeRelocatable) Sub(other MaybeRelocatable) (MaybeRelocatable, error) {
-	// check if they are felt
-	m_int, m_is_int := m.GetFelt()
-	other_felt, other_is_felt := other.GetFelt()
+	switch {
+	case m.kind == feltValue && other.kind == feltValue:
+		return *NewMaybeRelocatableFelt(m.felt.Sub(other.felt)), nil
+	case m.kind == relocatableValue && other.kind == feltValue:
+		return m.SubFelt(other.felt)
+	case m.kind == relocatableValue && other.kind == relocatableValue:
+		offset_diff, err := m.relocatable.Sub(other.relocatable)
+		if err != nil {
+			return MaybeRelocatable{}, err
+		}
+		return *NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(offset_diff))), nil
+	case m.kind == feltValue && other.kind == relocatableValue:
+		return MaybeRelocatable{}, errors.New("Cant sub Relocatable from Felt")
+	}
+	return MaybeRelocatable{}, fmt.Errorf("Can't subtract %s from %s", other, m)
+}
 
-	if m_is_int && other_is_felt {
-		result := NewMaybeRelocatableFelt(m_int.Sub(other_felt))
-		return *result, nil
+// Multiplies two Felt values, fails if either of them isn't a Felt
+func (m MaybeRelocatable) Mul(other MaybeRelocatable) (MaybeRelocatable, error) {
+	if m.kind != feltValue || other.kind != feltValue {
+		return MaybeRelocatable{}, fmt.Errorf("Can't multiply %s and %s, only felts can be multiplied", m, other)
 	}
+	return *NewMaybeRelocatableFelt(m.felt.Mul(other.felt)), nil
+}
 
-	// check if one is relocatable and the other int
-	m_rel, is_rel_m := m.GetRelocatable()
-	other_rel, is_rel_other := other.GetRelocatable()
+// Adds a Felt to the value, to the offset if it is a Relocatable
+// Fails if the new offset exceeds the size of a uint, or if m holds no value
+func (m MaybeRelocatable) AddFelt(felt lambdaworks.Felt) (MaybeRelocatable, error) {
+	switch m.kind {
+	case feltValue:
+		return *NewMaybeRelocatableFelt(m.felt.Add(felt)), nil
+	case relocatableValue:
+		relocatable, err := m.relocatable.AddFelt(felt)
+		if err != nil {
+			return MaybeRelocatable{}, err
+		}
+		return *NewMaybeRelocatableRelocatable(relocatable), nil
+	}
+	return MaybeRelocatable{}, errors.New("Can't add a felt to an empty value")
+}
 
-	if is_rel_m && !is_rel_other {
-		relocatable, err := m_rel.SubFelt(other_felt)
+// Subtracts a Felt from the value, from the offset if it is a Relocatable
+// Fails if the new offset is negative or exceeds the size of a uint, or if m holds no value
+func (m MaybeRelocatable) SubFelt(felt lambdaworks.Felt) (MaybeRelocatable, error) {
+	switch m.kind {
+	case feltValue:
+		return *NewMaybeRelocatableFelt(m.felt.Sub(felt)), nil
+	case relocatableValue:
+		relocatable, err := m.relocatable.SubFelt(felt)
 		if err != nil {
-			return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
+			return MaybeRelocatable{}, err
 		}
 		return *NewMaybeRelocatableRelocatable(relocatable), nil
+	}
+	return MaybeRelocatable{}, errors.New("Can't subtract a felt from an empty value")
+}
 
-	} else if is_rel_m && is_rel_other {
-		offset_diff, err := m_rel.Sub(other_rel)
+// Adds an unsigned integer to the value, to the offset if it is a Relocatable
+func (m MaybeRelocatable) AddUint(other uint) (MaybeRelocatable, error) {
+	if m.kind == relocatableValue {
+		relocatable, err := m.relocatable.AddUint(other)
 		if err != nil {
-			return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
+			return MaybeRelocatable{}, err
 		}
-		return *NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(offset_diff))), nil
-	} else {
-		return *NewMaybeRelocatableFelt(lambdaworks.FeltZero()), errors.New("Cant sub Relocatable from Felt")
+		return *NewMaybeRelocatableRelocatable(relocatable), nil
 	}
+	return m.AddFelt(lambdaworks.FeltFromUint64(uint64(other)))
 }
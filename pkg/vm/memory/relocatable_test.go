@@ -159,3 +159,105 @@ func TestMaybeRelocatableSubRelFromFelt(t *testing.T) {
 		t.Errorf("Subtraction of relocatable from felt should fail")
 	}
 }
+
+func TestMaybeRelocatableToU64(t *testing.T) {
+	value := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(26))
+	got, err := value.ToU64()
+	if err != nil || got != 26 {
+		t.Errorf("MaybeRelocatable(26).ToU64() failed. Got: %d, err: %v", got, err)
+	}
+}
+
+func TestMaybeRelocatableToU64Relocatable(t *testing.T) {
+	value := memory.NewMaybeRelocatableRelocatable(memory.Relocatable{1, 1})
+	if _, err := value.ToU64(); err == nil {
+		t.Errorf("MaybeRelocatable(1:1).ToU64() should fail")
+	}
+}
+
+func TestMaybeRelocatableToU64TooBig(t *testing.T) {
+	value := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromDecString("-1"))
+	if _, err := value.ToU64(); err == nil {
+		t.Errorf("MaybeRelocatable(-1).ToU64() should fail")
+	}
+}
+
+func TestMaybeRelocatableAccessors(t *testing.T) {
+	felt := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3))
+	rel := memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2))
+	empty := memory.MaybeRelocatable{}
+	if !felt.IsFelt() || felt.IsRelocatable() || !rel.IsRelocatable() || rel.IsFelt() || empty.IsFelt() || empty.IsRelocatable() || empty.HasValue() {
+		t.Errorf("Wrong kinds for %s, %s and %s", felt, rel, &empty)
+	}
+	if value, err := felt.Felt(); err != nil || value != lambdaworks.FeltFromUint64(3) {
+		t.Errorf("Felt() failed. Got: %v, err: %v", value, err)
+	}
+	if value, err := rel.Relocatable(); err != nil || value != memory.NewRelocatable(1, 2) {
+		t.Errorf("Relocatable() failed. Got: %v, err: %v", value, err)
+	}
+	if _, err := rel.Felt(); err == nil || err.Error() != "Expected a felt, got 1:2" {
+		t.Errorf("Felt() on a relocatable should fail, got %v", err)
+	}
+	if _, err := felt.Relocatable(); err == nil || err.Error() != "Expected a relocatable, got 3" {
+		t.Errorf("Relocatable() on a felt should fail, got %v", err)
+	}
+	if _, err := empty.Felt(); err == nil || err.Error() != "Expected a felt, got no value" {
+		t.Errorf("Felt() on an empty value should fail, got %v", err)
+	}
+}
+
+func TestMaybeRelocatableEquality(t *testing.T) {
+	// A relocatable 0:0 and the felt 0 must differ from each other and from the empty value
+	values := []memory.MaybeRelocatable{
+		{},
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0)),
+	}
+	for i := range values {
+		for j := range values {
+			if (values[i] == values[j]) != (i == j) || values[i].IsEqual(&values[j]) != (i == j) {
+				t.Errorf("Wrong equality between %s and %s", values[i], values[j])
+			}
+		}
+	}
+}
+
+func TestMaybeRelocatableMul(t *testing.T) {
+	a := *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6))
+	b := *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
+	res, err := a.Mul(b)
+	if err != nil || res != *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(42)) {
+		t.Errorf("6 * 7 failed. Got: %s, err: %v", res, err)
+	}
+	rel := *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2))
+	if _, err := a.Mul(rel); err == nil {
+		t.Errorf("Multiplying a relocatable should fail")
+	}
+}
+
+func TestMaybeRelocatableAddUint(t *testing.T) {
+	rel := *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2))
+	res, err := rel.AddUint(3)
+	if err != nil || res != *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 5)) {
+		t.Errorf("1:2 + 3 failed. Got: %s, err: %v", res, err)
+	}
+	felt := *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))
+	res, err = felt.AddUint(3)
+	if err != nil || res != *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)) {
+		t.Errorf("2 + 3 failed. Got: %s, err: %v", res, err)
+	}
+}
+
+func TestMaybeRelocatableArithmeticOnEmptyValue(t *testing.T) {
+	empty := memory.MaybeRelocatable{}
+	rel := *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2))
+	if _, err := empty.Add(rel); err == nil {
+		t.Errorf("Adding an empty value should fail")
+	}
+	if _, err := rel.Sub(empty); err == nil {
+		t.Errorf("Subtracting an empty value should fail")
+	}
+	if _, err := empty.AddUint(1); err == nil {
+		t.Errorf("Adding to an empty value should fail")
+	}
+}
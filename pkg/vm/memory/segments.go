@@ -1,37 +1,87 @@
 package memory
 
-import "github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parallel"
+)
 
 // MemorySegmentManager manages the list of memory segments.
 // Also holds metadata useful for the relocation process of
 // the memory at the end of the VM run.
 type MemorySegmentManager struct {
+	// Sizes computed from the memory contents by ComputeEffectiveSizes
 	SegmentSizes map[uint]uint
-	Memory       Memory
+	// Sizes declared via Finalize, these take precedence over the computed ones
+	FinalizedSizes map[uint]uint
+	// Offsets of each segment that belong to the public memory, declared via Finalize
+	PublicMemoryOffsets map[uint][]PublicMemoryOffset
+	Memory              Memory
+}
+
+// An offset within a segment that belongs to the public memory, along with its page id
+type PublicMemoryOffset struct {
+	Offset uint
+	Page   uint
 }
 
 func NewMemorySegmentManager() MemorySegmentManager {
 	memory := NewMemory()
-	return MemorySegmentManager{make(map[uint]uint), *memory}
+	return MemorySegmentManager{
+		SegmentSizes:        make(map[uint]uint),
+		FinalizedSizes:      make(map[uint]uint),
+		PublicMemoryOffsets: make(map[uint][]PublicMemoryOffset),
+		Memory:              *memory,
+	}
 }
 
 // Adds a memory segment and returns the first address of the new segment
+// This is the only way to allocate segments, the memory's segment count is kept by it
 func (m *MemorySegmentManager) AddSegment() Relocatable {
 	ptr := Relocatable{int(m.Memory.num_segments), 0}
 	m.Memory.num_segments += 1
 	return ptr
 }
 
+// Returns the number of segments allocated so far, temporary segments excluded
+func (m *MemorySegmentManager) NumSegments() uint {
+	return m.Memory.NumSegments()
+}
+
+// Returns the number of cells used by a segment, aka its highest occupied offset + 1, as stored
+// in memory right now. Unused and non allocated segments have size 0
+func (m *MemorySegmentManager) SegmentUsedSize(segmentIndex uint) uint {
+	if segmentIndex >= uint(len(m.Memory.data)) {
+		return 0
+	}
+	return m.Memory.data[segmentIndex].size()
+}
+
+// Adds a temporary segment and returns its first address. Temporary segments have negative
+// indexes and must be moved into real segments with Memory.AddRelocationRule before the memory is
+// relocated
+func (m *MemorySegmentManager) AddTempSegment() Relocatable {
+	m.Memory.num_temp_segments += 1
+	return Relocatable{-int(m.Memory.num_temp_segments), 0}
+}
+
+// Adds a memory segment with storage pre-sized to hold capacity cells and returns the first
+// address of the new segment. The capacity is only a hint, the segment can still grow past it
+func (m *MemorySegmentManager) AddSegmentWithCapacity(capacity uint) Relocatable {
+	ptr := m.AddSegment()
+	m.Memory.segment(ptr.SegmentIndex).reserve(capacity)
+	return ptr
+}
+
 // Calculates the size of each memory segment.
+// The sizes are only computed once, at the end of the run, segments added afterwards report their
+// used size
 func (m *MemorySegmentManager) ComputeEffectiveSizes() map[uint]uint {
 	if len(m.SegmentSizes) == 0 {
-
-		for ptr := range m.Memory.data {
-			segmentIndex := uint(ptr.SegmentIndex)
-			segmentMaxSize := m.SegmentSizes[segmentIndex]
-			segmentSize := ptr.Offset + 1
-			if segmentSize > segmentMaxSize {
-				m.SegmentSizes[segmentIndex] = segmentSize
+		for i := uint(0); i < m.NumSegments(); i++ {
+			if segmentSize := m.SegmentUsedSize(i); segmentSize > 0 {
+				m.SegmentSizes[i] = segmentSize
 			}
 		}
 	}
@@ -39,6 +89,71 @@ func (m *MemorySegmentManager) ComputeEffectiveSizes() map[uint]uint {
 	return m.SegmentSizes
 }
 
+// Declares the final size of a segment and the offsets within it that belong to the public memory
+// A nil size keeps the size computed by ComputeEffectiveSizes, a nil publicMemoryOffsets
+// declares that no offset of the segment is public
+func (m *MemorySegmentManager) Finalize(segmentIndex uint, size *uint, publicMemoryOffsets []PublicMemoryOffset) {
+	if size != nil {
+		m.FinalizedSizes[segmentIndex] = *size
+	}
+	if publicMemoryOffsets == nil {
+		publicMemoryOffsets = make([]PublicMemoryOffset, 0)
+	}
+	m.PublicMemoryOffsets[segmentIndex] = publicMemoryOffsets
+}
+
+// Marks offsets of a segment as belonging to the public memory, keeping any previously marked ones
+func (m *MemorySegmentManager) AddPublicMemoryOffsets(segmentIndex uint, offsets ...PublicMemoryOffset) {
+	m.PublicMemoryOffsets[segmentIndex] = append(m.PublicMemoryOffsets[segmentIndex], offsets...)
+}
+
+// A relocated public memory address, along with its page id
+type PublicMemoryAddress struct {
+	Address uint
+	Page    uint
+}
+
+// Returns the relocated addresses of every public memory offset, in segment order
+// Fails if the relocation table doesn't cover every segment
+func (m *MemorySegmentManager) GetPublicMemoryAddresses(relocationTable *[]uint) ([]PublicMemoryAddress, error) {
+	addresses := make([]PublicMemoryAddress, 0)
+	for i := uint(0); i < m.NumSegments(); i++ {
+		offsets := m.PublicMemoryOffsets[i]
+		if len(offsets) == 0 {
+			continue
+		}
+		if i >= uint(len(*relocationTable)) {
+			return nil, fmt.Errorf("Malformed public memory: no relocation found for segment %d", i)
+		}
+		segmentStart := (*relocationTable)[i]
+		for _, offset := range offsets {
+			addresses = append(addresses, PublicMemoryAddress{Address: segmentStart + offset.Offset, Page: offset.Page})
+		}
+	}
+	return addresses, nil
+}
+
+// Returns the size of a segment, using the finalized size if there is one, the size computed by
+// ComputeEffectiveSizes if it was computed and its used size otherwise
+func (m *MemorySegmentManager) GetSegmentSize(segmentIndex uint) uint {
+	if size, ok := m.FinalizedSizes[segmentIndex]; ok {
+		return size
+	}
+	if size, ok := m.SegmentSizes[segmentIndex]; ok {
+		return size
+	}
+	return m.SegmentUsedSize(segmentIndex)
+}
+
+// Returns the size of every segment, indexed by segment index, see GetSegmentSize
+func (m *MemorySegmentManager) GetSegmentSizes() []uint {
+	sizes := make([]uint, m.NumSegments())
+	for i := range sizes {
+		sizes[i] = m.GetSegmentSize(uint(i))
+	}
+	return sizes
+}
+
 // Returns a vector containing the first relocated address of each memory segment
 func (m *MemorySegmentManager) RelocateSegments() ([]uint, bool) {
 	if m.SegmentSizes == nil {
@@ -48,8 +163,8 @@ func (m *MemorySegmentManager) RelocateSegments() ([]uint, bool) {
 	first_addr := uint(1)
 	relocation_table := []uint{first_addr}
 
-	for i := uint(0); i < m.Memory.NumSegments(); i++ {
-		new_addr := relocation_table[i] + m.SegmentSizes[i]
+	for i := uint(0); i < m.NumSegments(); i++ {
+		new_addr := relocation_table[i] + m.GetSegmentSize(i)
 		relocation_table = append(relocation_table, new_addr)
 	}
 	relocation_table = relocation_table[:len(relocation_table)-1]
@@ -57,39 +172,180 @@ func (m *MemorySegmentManager) RelocateSegments() ([]uint, bool) {
 	return relocation_table, true
 }
 
+// Number of cells relocated by each worker at a time
+const relocationChunkSize = 1 << 16
+
 // Relocates the VM's memory, turning bidimensional indexes into contiguous numbers, and values
 // into Felt252s. Uses the relocation_table to asign each index a number according to the value
 // on its segment number.
-func (s *MemorySegmentManager) RelocateMemory(relocationTable *[]uint) (map[uint]lambdaworks.Felt, error) {
-	relocatedMemory := make(map[uint]lambdaworks.Felt, 0)
-
-	for i := uint(0); i < s.Memory.NumSegments(); i++ {
-		for j := uint(0); j < s.SegmentSizes[i]; j++ {
-			ptr := NewRelocatable(int(i), j)
-			cell, err := s.Memory.Get(ptr)
-			if err == nil {
-				relocatedAddr := ptr.RelocateAddress(relocationTable)
-				value, err := cell.RelocateValue(relocationTable)
-				if err != nil {
-					return nil, err
-				}
-				relocatedMemory[relocatedAddr] = value
+// The relocated memory is returned as a dense slice indexed by relocated address, holes (and
+// address 0, which is never used) are represented by nil values.
+// Segments are relocated in parallel, in chunks of relocationChunkSize cells. Fails if a segment
+// holds data past its size, e.g. its finalized size.
+func (s *MemorySegmentManager) RelocateMemory(relocationTable *[]uint) ([]*lambdaworks.Felt, error) {
+	size := uint(1)
+	if numSegments := len(*relocationTable); numSegments > 0 {
+		size = (*relocationTable)[numSegments-1] + s.GetSegmentSize(uint(numSegments-1))
+	}
+	relocatedMemory := make([]*lambdaworks.Felt, size)
+	// Back all the relocated values with a single allocation
+	values := make([]lambdaworks.Felt, size)
+
+	// Big segments are split so that a single execution segment still spreads over all CPUs
+	type chunk struct {
+		segment    int
+		start, end uint
+	}
+	var chunks []chunk
+	for i := 0; i < int(s.NumSegments()); i++ {
+		segmentSize, err := s.relocatedSegmentSize(uint(i))
+		if err != nil {
+			return nil, err
+		}
+		for start := uint(0); start < segmentSize; start += relocationChunkSize {
+			end := start + relocationChunkSize
+			if end > segmentSize {
+				end = segmentSize
 			}
+			chunks = append(chunks, chunk{i, start, end})
+		}
+	}
+	// Chunks write to disjoint parts of the relocated memory
+	errs := make([]error, len(chunks))
+	parallel.For(len(chunks), func(k int) {
+		c := chunks[k]
+		base := (*relocationTable)[c.segment] + c.start
+		errs[k] = s.relocateCells(c.segment, c.start, c.end, relocationTable, relocatedMemory[base:], values[base:])
+	})
+	// Report the error of the lowest address, as a sequential relocation would
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	return relocatedMemory, nil
 }
 
-// Writes data into the memory from address ptr and returns the first address after the data.
+// Relocates the values of a single segment, such as the output, without relocating the rest of
+// the memory. The values are indexed by offset, holes being nil, so that the value at offset i
+// lies at the relocated address relocationTable[segmentIndex] + i
+func (s *MemorySegmentManager) RelocateSegment(segmentIndex uint, relocationTable *[]uint) ([]*lambdaworks.Felt, error) {
+	if segmentIndex >= s.NumSegments() {
+		return nil, fmt.Errorf("Segment %d doesn't exist", segmentIndex)
+	}
+	size, err := s.relocatedSegmentSize(segmentIndex)
+	if err != nil {
+		return nil, err
+	}
+	relocatedSegment := make([]*lambdaworks.Felt, size)
+	values := make([]lambdaworks.Felt, size)
+	if err := s.relocateCells(int(segmentIndex), 0, size, relocationTable, relocatedSegment, values); err != nil {
+		return nil, err
+	}
+	return relocatedSegment, nil
+}
+
+// Returns the size of a segment, see GetSegmentSize, failing if the segment holds data past it, as
+// that data would be lost by the relocation
+func (s *MemorySegmentManager) relocatedSegmentSize(segmentIndex uint) (uint, error) {
+	size := s.GetSegmentSize(segmentIndex)
+	if used := s.SegmentUsedSize(segmentIndex); used > size {
+		return 0, fmt.Errorf("Segment %d has data at offset %d, past its size %d", segmentIndex, used-1, size)
+	}
+	return size, nil
+}
+
+// Relocates the cells of a segment in [start, end), writing the value at offset start + i to
+// values[i] and pointing dst[i] to it. Holes are left nil
+func (s *MemorySegmentManager) relocateCells(segmentIndex int, start uint, end uint, relocationTable *[]uint, dst []*lambdaworks.Felt, values []lambdaworks.Felt) error {
+	for j := start; j < end; j++ {
+		ptr := NewRelocatable(segmentIndex, j)
+		cell, err := s.Memory.TryGetValue(ptr)
+		if err != nil {
+			return err
+		}
+		if !cell.HasValue() {
+			continue
+		}
+		if rel, ok := cell.GetRelocatable(); ok && (rel.SegmentIndex < 0 || rel.SegmentIndex >= len(*relocationTable)) {
+			return fmt.Errorf("Value at %v points to segment %d, which wasn't relocated", ptr, rel.SegmentIndex)
+		}
+		if values[j-start], err = cell.RelocateValue(relocationTable); err != nil {
+			return err
+		}
+		dst[j-start] = &values[j-start]
+	}
+	return nil
+}
+
+// Writes data contiguously into the memory starting from address ptr and returns the first address after the data.
 // If any insertion fails, returns (0,0) and the memory insertion error
-func (m *MemorySegmentManager) LoadData(ptr Relocatable, data *[]MaybeRelocatable) (Relocatable, error) {
-	for _, val := range *data {
-		err := m.Memory.Insert(ptr, &val)
+func (m *MemorySegmentManager) LoadData(ptr Relocatable, data []MaybeRelocatable) (Relocatable, error) {
+	for i := range data {
+		err := m.Memory.Insert(ptr, &data[i])
 		if err != nil {
-			return Relocatable{0, 0}, err
+			return Relocatable{0, 0}, fmt.Errorf("Failed to load data at %+v: %w", ptr, err)
 		}
 		ptr.Offset += 1
 	}
 	return ptr, nil
 }
+
+// Converts a Cairo argument into a MaybeRelocatable value, writing it into memory if needed
+// Felts, Relocatables and MaybeRelocatables are returned as they are
+// Slices are written into a newly allocated segment and the base of said segment is returned,
+// nested slices ([]any) are converted recursively, each inner slice getting its own segment
+func (m *MemorySegmentManager) GenArg(arg any) (MaybeRelocatable, error) {
+	switch arg := arg.(type) {
+	case MaybeRelocatable:
+		return arg, nil
+	case *MaybeRelocatable:
+		return *arg, nil
+	case lambdaworks.Felt:
+		return *NewMaybeRelocatableFelt(arg), nil
+	case Relocatable:
+		return *NewMaybeRelocatableRelocatable(arg), nil
+	case []MaybeRelocatable:
+		return m.genSliceArg(arg)
+	case []lambdaworks.Felt:
+		data := make([]MaybeRelocatable, 0, len(arg))
+		for _, felt := range arg {
+			data = append(data, *NewMaybeRelocatableFelt(felt))
+		}
+		return m.genSliceArg(data)
+	case []Relocatable:
+		data := make([]MaybeRelocatable, 0, len(arg))
+		for _, rel := range arg {
+			data = append(data, *NewMaybeRelocatableRelocatable(rel))
+		}
+		return m.genSliceArg(data)
+	case []any:
+		base := m.AddSegment()
+		data := make([]MaybeRelocatable, 0, len(arg))
+		for _, inner := range arg {
+			val, err := m.GenArg(inner)
+			if err != nil {
+				return MaybeRelocatable{}, err
+			}
+			data = append(data, val)
+		}
+		_, err := m.LoadData(base, data)
+		if err != nil {
+			return MaybeRelocatable{}, err
+		}
+		return *NewMaybeRelocatableRelocatable(base), nil
+	default:
+		return MaybeRelocatable{}, fmt.Errorf("GenArg: unsupported argument type %T", arg)
+	}
+}
+
+// Writes data into a new segment and returns its base
+func (m *MemorySegmentManager) genSliceArg(data []MaybeRelocatable) (MaybeRelocatable, error) {
+	base := m.AddSegment()
+	_, err := m.LoadData(base, data)
+	if err != nil {
+		return MaybeRelocatable{}, err
+	}
+	return *NewMaybeRelocatableRelocatable(base), nil
+}
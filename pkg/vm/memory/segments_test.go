@@ -2,6 +2,8 @@ package memory_test
 
 import (
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
@@ -215,10 +217,342 @@ func TestRelocateMemory(t *testing.T) {
 		5: lambdaworks.FeltFromUint64(10),
 		9: lambdaworks.FeltFromUint64(5),
 	}
-	for i, v := range expectedMemory {
-		actual := relocatedMemory[i]
-		if actual != v {
-			t.Errorf("Expected relocated memory at index %d to be %d but it's %d", i, v, actual)
+	if len(relocatedMemory) != 10 {
+		t.Errorf("Expected relocated memory to have 10 cells, got %d", len(relocatedMemory))
+	}
+	for i, actual := range relocatedMemory {
+		v, ok := expectedMemory[uint(i)]
+		if !ok {
+			if actual != nil {
+				t.Errorf("Expected relocated memory at index %d to be a hole but it's %v", i, *actual)
+			}
+			continue
+		}
+		if actual == nil || *actual != v {
+			t.Errorf("Expected relocated memory at index %d to be %v but it's %v", i, v, actual)
+		}
+	}
+}
+
+func TestRelocateMemoryPointerToUnallocatedSegment(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(4, 0)))
+	segments.ComputeEffectiveSizes()
+	relocationTable, _ := segments.RelocateSegments()
+
+	if _, err := segments.RelocateMemory(&relocationTable); err == nil {
+		t.Errorf("RelocateMemory should fail for a pointer to an unallocated segment")
+	}
+}
+
+func TestRelocateMemoryDataPastFinalizedSize(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	size := uint(2)
+	segments.Finalize(0, &size, nil)
+	segments.ComputeEffectiveSizes()
+	relocationTable, _ := segments.RelocateSegments()
+
+	if _, err := segments.RelocateMemory(&relocationTable); err == nil {
+		t.Errorf("RelocateMemory should fail for data past the finalized size of its segment")
+	}
+	if _, err := segments.RelocateSegment(0, &relocationTable); err == nil {
+		t.Errorf("RelocateSegment should fail for data past the finalized size of its segment")
+	}
+}
+
+func TestRelocateMemorySpanningManyChunks(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	// Segment 0 holds its own offsets, segment 1 points back into segment 0
+	const size = 200000
+	for i := uint(0); i < size; i++ {
+		segments.Memory.Insert(memory.NewRelocatable(0, i), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(uint64(i))))
+		if i%3 == 0 {
+			segments.Memory.Insert(memory.NewRelocatable(1, i), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, i)))
+		}
+	}
+	segments.ComputeEffectiveSizes()
+	relocationTable, _ := segments.RelocateSegments()
+
+	relocatedMemory, err := segments.RelocateMemory(&relocationTable)
+	if err != nil {
+		t.Fatalf("Test failed with error: %s", err)
+	}
+	if uint(len(relocatedMemory)) != 2*size {
+		t.Fatalf("Expected relocated memory to have %d cells, got %d", 2*size, len(relocatedMemory))
+	}
+	for i := uint(0); i < size; i++ {
+		if value := relocatedMemory[1+i]; value == nil || *value != lambdaworks.FeltFromUint64(uint64(i)) {
+			t.Fatalf("Wrong relocated value at %d: %v", 1+i, value)
+		}
+		if 1+size+i >= uint(len(relocatedMemory)) {
+			continue
+		}
+		value := relocatedMemory[1+size+i]
+		if i%3 != 0 && value != nil {
+			t.Fatalf("Expected a hole at %d, got %v", 1+size+i, *value)
+		}
+		if i%3 == 0 && (value == nil || *value != lambdaworks.FeltFromUint64(uint64(1+i))) {
+			t.Fatalf("Wrong relocated pointer at %d: %v", 1+size+i, value)
 		}
 	}
 }
+
+func TestRelocateMemoryReportsLowestInvalidPointer(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 150000), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(7, 0)))
+	segments.Memory.Insert(memory.NewRelocatable(0, 10), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(5, 0)))
+	segments.ComputeEffectiveSizes()
+	relocationTable, _ := segments.RelocateSegments()
+
+	_, err := segments.RelocateMemory(&relocationTable)
+	if err == nil || !strings.Contains(err.Error(), "segment 5") {
+		t.Errorf("RelocateMemory should fail for the pointer at the lowest address, got: %v", err)
+	}
+}
+
+func TestRelocateSegmentWithHoles(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 2), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 1)))
+	segments.ComputeEffectiveSizes()
+	relocationTable, _ := segments.RelocateSegments()
+
+	relocated, err := segments.RelocateSegment(1, &relocationTable)
+	if err != nil {
+		t.Fatalf("Test failed with error: %s", err)
+	}
+	if len(relocated) != 3 || relocated[1] != nil {
+		t.Fatalf("Expected 3 cells with a hole at offset 1, got %v", relocated)
+	}
+	if *relocated[0] != lambdaworks.FeltFromUint64(3) || *relocated[2] != lambdaworks.FeltFromUint64(2) {
+		t.Errorf("Wrong relocated values %v and %v", *relocated[0], *relocated[2])
+	}
+}
+
+func TestGenArgFelt(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	arg, err := segments.GenArg(lambdaworks.FeltFromUint64(3))
+	if err != nil {
+		t.Errorf("GenArg failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(arg, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3))) {
+		t.Errorf("GenArg returned wrong value: %v", arg)
+	}
+	if segments.Memory.NumSegments() != 0 {
+		t.Errorf("GenArg should not allocate segments for felt values")
+	}
+}
+
+func TestGenArgRelocatable(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	arg, err := segments.GenArg(memory.NewRelocatable(1, 2))
+	if err != nil {
+		t.Errorf("GenArg failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(arg, *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2))) {
+		t.Errorf("GenArg returned wrong value: %v", arg)
+	}
+}
+
+func TestGenArgSlice(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)),
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 1)),
+	}
+	arg, err := segments.GenArg(data)
+	if err != nil {
+		t.Errorf("GenArg failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(arg, *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0))) {
+		t.Errorf("GenArg returned wrong value: %v", arg)
+	}
+	val, err := segments.Memory.Get(memory.NewRelocatable(0, 1))
+	if err != nil || !reflect.DeepEqual(*val, data[1]) {
+		t.Errorf("GenArg didn't write the slice into memory")
+	}
+}
+
+func TestGenArgNestedSlices(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	arg, err := segments.GenArg([]any{
+		[]lambdaworks.Felt{lambdaworks.FeltFromUint64(1), lambdaworks.FeltFromUint64(2)},
+		lambdaworks.FeltFromUint64(3),
+	})
+	if err != nil {
+		t.Errorf("GenArg failed with error: %s", err)
+	}
+	// The outer slice is allocated first
+	if !reflect.DeepEqual(arg, *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 0))) {
+		t.Errorf("GenArg returned wrong value: %v", arg)
+	}
+	inner, err := segments.Memory.Get(memory.NewRelocatable(0, 0))
+	if err != nil || !reflect.DeepEqual(*inner, *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 0))) {
+		t.Errorf("GenArg didn't write the inner slice pointer")
+	}
+	val, err := segments.Memory.Get(memory.NewRelocatable(1, 1))
+	if err != nil || !reflect.DeepEqual(*val, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))) {
+		t.Errorf("GenArg didn't write the inner slice values")
+	}
+}
+
+func TestGenArgUnsupportedType(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	_, err := segments.GenArg("not an arg")
+	if err == nil {
+		t.Errorf("GenArg should fail with an unsupported type")
+	}
+}
+
+func TestFinalizeSegmentSize(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.ComputeEffectiveSizes()
+
+	size := uint(5)
+	segments.Finalize(0, &size, nil)
+
+	if segments.GetSegmentSize(0) != 5 {
+		t.Errorf("Finalized size should take precedence, got %d", segments.GetSegmentSize(0))
+	}
+	if segments.GetSegmentSize(1) != 1 {
+		t.Errorf("Non-finalized segment should keep its computed size, got %d", segments.GetSegmentSize(1))
+	}
+	relocationTable, _ := segments.RelocateSegments()
+	if !reflect.DeepEqual(relocationTable, []uint{1, 6}) {
+		t.Errorf("Relocation table should use the finalized size, got %v", relocationTable)
+	}
+	if offsets, ok := segments.PublicMemoryOffsets[0]; !ok || len(offsets) != 0 {
+		t.Errorf("Finalize without public memory should register an empty offset list")
+	}
+}
+
+func TestFinalizeSegmentPublicMemoryOnly(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.ComputeEffectiveSizes()
+
+	publicMemory := []memory.PublicMemoryOffset{{Offset: 0, Page: 0}, {Offset: 2, Page: 1}}
+	segments.Finalize(0, nil, publicMemory)
+
+	if segments.GetSegmentSize(0) != 3 {
+		t.Errorf("Finalize without size should keep the computed size, got %d", segments.GetSegmentSize(0))
+	}
+	if !reflect.DeepEqual(segments.PublicMemoryOffsets[0], publicMemory) {
+		t.Errorf("Wrong public memory offsets: %v", segments.PublicMemoryOffsets[0])
+	}
+}
+
+func TestGetSegmentSizes(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 3), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	size := uint(7)
+	segments.Finalize(1, &size, nil)
+
+	if !reflect.DeepEqual(segments.GetSegmentSizes(), []uint{4, 7, 0}) {
+		t.Errorf("Wrong segment sizes: %v", segments.GetSegmentSizes())
+	}
+}
+
+func TestGetPublicMemoryAddresses(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	for i := 0; i < 3; i++ {
+		segments.AddSegment()
+	}
+	segments.Finalize(0, nil, []memory.PublicMemoryOffset{{Offset: 0, Page: 0}, {Offset: 1, Page: 0}})
+	segments.AddPublicMemoryOffsets(2, memory.PublicMemoryOffset{Offset: 2, Page: 1})
+	segments.AddPublicMemoryOffsets(2, memory.PublicMemoryOffset{Offset: 4, Page: 1})
+
+	relocationTable := []uint{1, 4, 9}
+	addresses, err := segments.GetPublicMemoryAddresses(&relocationTable)
+	if err != nil {
+		t.Errorf("GetPublicMemoryAddresses failed with error: %s", err)
+	}
+	expected := []memory.PublicMemoryAddress{{Address: 1, Page: 0}, {Address: 2, Page: 0}, {Address: 11, Page: 1}, {Address: 13, Page: 1}}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("Wrong public memory addresses: %v", addresses)
+	}
+}
+
+func TestGetPublicMemoryAddressesMissingRelocation(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.AddPublicMemoryOffsets(1, memory.PublicMemoryOffset{Offset: 0, Page: 0})
+
+	relocationTable := []uint{1}
+	_, err := segments.GetPublicMemoryAddresses(&relocationTable)
+	if err == nil {
+		t.Errorf("GetPublicMemoryAddresses should fail with an incomplete relocation table")
+	}
+}
+
+func TestAddSegmentWithCapacity(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	base := segments.AddSegmentWithCapacity(100)
+	if base != memory.NewRelocatable(1, 0) {
+		t.Errorf("Wrong segment base: %+v", base)
+	}
+	// Preallocation doesn't change the segment's size
+	if !reflect.DeepEqual(segments.GetSegmentSizes(), []uint{0, 0}) {
+		t.Errorf("Wrong segment sizes: %v", segments.GetSegmentSizes())
+	}
+	// The segment can still grow past its capacity
+	segments.Memory.Insert(memory.NewRelocatable(1, 150), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 3), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2)))
+	val, err := segments.Memory.GetFelt(memory.NewRelocatable(1, 3))
+	if err != nil || val != lambdaworks.FeltFromUint64(2) {
+		t.Errorf("Wrong value stored in preallocated segment")
+	}
+	if _, err := segments.Memory.Get(memory.NewRelocatable(1, 4)); err == nil {
+		t.Errorf("Preallocated cells should be empty")
+	}
+}
+
+func TestSegmentUsedSize(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(1, 4), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	if segments.NumSegments() != 2 || segments.NumSegments() != segments.Memory.NumSegments() {
+		t.Errorf("Expected 2 segments, got %d", segments.NumSegments())
+	}
+	for i, expected := range []uint{0, 5, 0} {
+		if size := segments.SegmentUsedSize(uint(i)); size != expected {
+			t.Errorf("Expected segment %d to use %d cells, got %d", i, expected, size)
+		}
+	}
+}
+
+func TestGetSegmentSizesAfterComputingSizes(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	segments.ComputeEffectiveSizes()
+	// Segments added after the sizes were computed report their used size
+	base := segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(base.SegmentIndex, 2), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)))
+	if sizes := segments.GetSegmentSizes(); !reflect.DeepEqual(sizes, []uint{2, 3}) || segments.GetSegmentSize(1) != 3 {
+		t.Errorf("Wrong segment sizes: %v", sizes)
+	}
+}
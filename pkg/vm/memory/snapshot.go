@@ -0,0 +1,320 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+)
+
+// Binary snapshot of the segment state, used to checkpoint a VM to disk and resume it later.
+//
+// All integers are encoded in little endian:
+// * header -> "CVMS" magic + 1-byte version
+// * number of segments -> 8 bytes
+// * for each segment: number of stored cells (8 bytes), followed by each cell as
+//   offset (8 bytes) + tag (1 byte) + value, where the value is either a 32-byte felt (tag 0)
+//   or a relocatable encoded as segment index (8 bytes, signed) + offset (8 bytes) (tag 1)
+// * number of temporary segments -> 8 bytes, followed by the cells of the segments -1, -2, ...
+//   encoded as those of the segments
+// * relocation rules -> count (8 bytes) + for each rule: temporary segment index (8 bytes,
+//   signed) + destination segment index (8 bytes, signed) + destination offset (8 bytes)
+// * computed segment sizes, finalized segment sizes -> count (8 bytes) + (segment, size) pairs
+// * public memory offsets -> count (8 bytes) + for each segment: segment index, number of
+//   offsets and (offset, page) pairs
+//
+// Validation rules are functions and can't be serialized, they have to be added again after
+// loading a snapshot (builtin runners do this when initializing the vm).
+
+var snapshotMagic = [4]byte{'C', 'V', 'M', 'S'}
+
+const snapshotVersion uint8 = 2
+
+const (
+	snapshotTagFelt        uint8 = 0
+	snapshotTagRelocatable uint8 = 1
+)
+
+// Smallest encodings of a segment (its number of cells), a cell (an offset, a tag and a
+// relocatable) and a pair of integers, used to bound the counts read from a snapshot by its size
+const (
+	snapshotMinSegmentSize = 8
+	snapshotMinCellSize    = 8 + 1 + 16
+	snapshotPairSize       = 16
+	snapshotRuleSize       = 24
+)
+
+// Segments are stored densely, so a cell at a given offset allocates every cell before it. Offsets
+// are capped so that a corrupted or hostile snapshot can't allocate more than this many cells for
+// a segment, which is far more than any run fitting in memory uses
+const snapshotMaxSegmentSize = 1 << 28
+
+// Writes a binary snapshot of the segments and their memory into w
+func (m *MemorySegmentManager) WriteSnapshot(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	enc := snapshotEncoder{w: buf}
+
+	enc.write(snapshotMagic)
+	enc.write(snapshotVersion)
+	enc.writeUint(m.NumSegments())
+	for i := 0; i < int(m.NumSegments()); i++ {
+		var cells *segment
+		if i < len(m.Memory.data) {
+			cells = &m.Memory.data[i]
+		}
+		enc.writeCells(cells)
+	}
+	enc.writeUint(m.Memory.NumTempSegments())
+	for i := 0; i < int(m.Memory.NumTempSegments()); i++ {
+		var cells *segment
+		if i < len(m.Memory.temp_data) {
+			cells = &m.Memory.temp_data[i]
+		}
+		enc.writeCells(cells)
+	}
+	rules := make([]int, 0, len(m.Memory.relocation_rules))
+	for src := range m.Memory.relocation_rules {
+		rules = append(rules, src)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(rules)))
+	enc.writeUint(uint(len(rules)))
+	for _, src := range rules {
+		dst := m.Memory.relocation_rules[src]
+		enc.write(int64(src))
+		enc.write(int64(dst.SegmentIndex))
+		enc.writeUint(dst.Offset)
+	}
+	enc.writeSizes(m.SegmentSizes)
+	enc.writeSizes(m.FinalizedSizes)
+
+	segments := sortedKeys(m.PublicMemoryOffsets)
+	enc.writeUint(uint(len(segments)))
+	for _, segment := range segments {
+		offsets := m.PublicMemoryOffsets[segment]
+		enc.writeUint(segment)
+		enc.writeUint(uint(len(offsets)))
+		for _, offset := range offsets {
+			enc.writeUint(offset.Offset)
+			enc.writeUint(offset.Page)
+		}
+	}
+
+	if enc.err != nil {
+		return fmt.Errorf("failed to write memory snapshot: %w", enc.err)
+	}
+	return buf.Flush()
+}
+
+// Reads a binary snapshot written by WriteSnapshot, returning a new MemorySegmentManager
+// holding the same segments and memory. Counts read from the snapshot are checked against the
+// remaining input before anything is allocated for them
+func ReadSnapshot(r io.Reader) (MemorySegmentManager, error) {
+	segments := NewMemorySegmentManager()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return segments, fmt.Errorf("failed to read memory snapshot: %w", err)
+	}
+	dec := snapshotDecoder{r: bytes.NewReader(data)}
+
+	var magic [4]byte
+	var version uint8
+	dec.read(&magic)
+	dec.read(&version)
+	if dec.err != nil {
+		return segments, fmt.Errorf("failed to read memory snapshot: %w", dec.err)
+	}
+	if magic != snapshotMagic {
+		return segments, errors.New("failed to read memory snapshot: invalid header")
+	}
+	if version != snapshotVersion {
+		return segments, fmt.Errorf("failed to read memory snapshot: unsupported version %d", version)
+	}
+
+	numSegments := dec.readCount(snapshotMinSegmentSize)
+	for i := uint(0); i < numSegments && dec.err == nil; i++ {
+		segments.AddSegment()
+	}
+	for i := uint(0); i < numSegments && dec.err == nil; i++ {
+		if err := dec.readCells(&segments, int(i)); err != nil {
+			return segments, err
+		}
+	}
+	numTempSegments := dec.readCount(snapshotMinSegmentSize)
+	for i := uint(0); i < numTempSegments && dec.err == nil; i++ {
+		segments.AddTempSegment()
+	}
+	for i := uint(0); i < numTempSegments && dec.err == nil; i++ {
+		if err := dec.readCells(&segments, -int(i)-1); err != nil {
+			return segments, err
+		}
+	}
+	rules := dec.readCount(snapshotRuleSize)
+	for i := uint(0); i < rules && dec.err == nil; i++ {
+		var src, dstSegment int64
+		dec.read(&src)
+		dec.read(&dstSegment)
+		dst := NewRelocatable(int(dstSegment), dec.readUint())
+		if dec.err == nil {
+			if err := segments.Memory.AddRelocationRule(NewRelocatable(int(src), 0), dst); err != nil {
+				return segments, fmt.Errorf("failed to read memory snapshot: %w", err)
+			}
+		}
+	}
+	dec.readSizes(segments.SegmentSizes)
+	dec.readSizes(segments.FinalizedSizes)
+
+	count := dec.readCount(snapshotPairSize)
+	for i := uint(0); i < count && dec.err == nil; i++ {
+		segment := dec.readUint()
+		n := dec.readCount(snapshotPairSize)
+		offsets := make([]PublicMemoryOffset, 0)
+		for j := uint(0); j < n && dec.err == nil; j++ {
+			offsets = append(offsets, PublicMemoryOffset{Offset: dec.readUint(), Page: dec.readUint()})
+		}
+		segments.PublicMemoryOffsets[segment] = offsets
+	}
+
+	if dec.err != nil {
+		return segments, fmt.Errorf("failed to read memory snapshot: %w", dec.err)
+	}
+	return segments, nil
+}
+
+// Accumulates the first write error so that encoding code doesn't need to check each write
+type snapshotEncoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *snapshotEncoder) write(data any) {
+	if e.err == nil {
+		e.err = binary.Write(e.w, binary.LittleEndian, data)
+	}
+}
+
+func (e *snapshotEncoder) writeUint(value uint) {
+	e.write(uint64(value))
+}
+
+// Writes the number of cells stored in a segment, which can be nil if it was never written to,
+// followed by the cells
+func (e *snapshotEncoder) writeCells(s *segment) {
+	if s == nil {
+		e.writeUint(0)
+		return
+	}
+	cells := uint(0)
+	for offset := uint(0); offset < s.size(); offset++ {
+		if s.isOccupied(offset) {
+			cells++
+		}
+	}
+	e.writeUint(cells)
+	for offset := uint(0); offset < s.size() && e.err == nil; offset++ {
+		if !s.isOccupied(offset) {
+			continue
+		}
+		e.writeUint(offset)
+		val := s.cells[offset]
+		if felt, ok := val.GetFelt(); ok {
+			e.write(snapshotTagFelt)
+			e.write(felt.ToLeBytes())
+		} else {
+			rel, _ := val.GetRelocatable()
+			e.write(snapshotTagRelocatable)
+			e.write(int64(rel.SegmentIndex))
+			e.writeUint(rel.Offset)
+		}
+	}
+}
+
+func (e *snapshotEncoder) writeSizes(sizes map[uint]uint) {
+	keys := sortedKeys(sizes)
+	e.writeUint(uint(len(keys)))
+	for _, key := range keys {
+		e.writeUint(key)
+		e.writeUint(sizes[key])
+	}
+}
+
+// Accumulates the first read error so that decoding code doesn't need to check each read
+type snapshotDecoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *snapshotDecoder) read(data any) {
+	if d.err == nil {
+		d.err = binary.Read(d.r, binary.LittleEndian, data)
+	}
+}
+
+func (d *snapshotDecoder) readUint() uint {
+	var value uint64
+	d.read(&value)
+	return uint(value)
+}
+
+// Reads the number of items that follow, failing if the rest of the input can't hold that many
+// items of at least itemSize bytes
+func (d *snapshotDecoder) readCount(itemSize uint) uint {
+	count := d.readUint()
+	if d.err == nil && count > uint(d.r.Len())/itemSize {
+		d.err = fmt.Errorf("count %d exceeds the %d remaining bytes", count, d.r.Len())
+	}
+	return count
+}
+
+// Reads the cells of a segment written by writeCells, inserting them into segmentIndex
+func (d *snapshotDecoder) readCells(segments *MemorySegmentManager, segmentIndex int) error {
+	cells := d.readCount(snapshotMinCellSize)
+	for j := uint(0); j < cells && d.err == nil; j++ {
+		offset := d.readUint()
+		if d.err == nil && offset >= snapshotMaxSegmentSize {
+			return fmt.Errorf("failed to read memory snapshot: offset %d of segment %d exceeds the maximum segment size %d", offset, segmentIndex, snapshotMaxSegmentSize)
+		}
+		var tag uint8
+		d.read(&tag)
+		var val *MaybeRelocatable
+		switch tag {
+		case snapshotTagFelt:
+			var bytes [32]byte
+			d.read(&bytes)
+			val = NewMaybeRelocatableFelt(lambdaworks.FeltFromLeBytes(&bytes))
+		case snapshotTagRelocatable:
+			var index int64
+			d.read(&index)
+			val = NewMaybeRelocatableRelocatable(NewRelocatable(int(index), d.readUint()))
+		default:
+			return fmt.Errorf("failed to read memory snapshot: invalid value tag %d at %d:%d", tag, segmentIndex, offset)
+		}
+		if d.err == nil {
+			if err := segments.Memory.Insert(NewRelocatable(segmentIndex, offset), val); err != nil {
+				return fmt.Errorf("failed to read memory snapshot: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *snapshotDecoder) readSizes(sizes map[uint]uint) {
+	count := d.readCount(snapshotPairSize)
+	for i := uint(0); i < count && d.err == nil; i++ {
+		key := d.readUint()
+		sizes[key] = d.readUint()
+	}
+}
+
+func sortedKeys[V any](m map[uint]V) []uint {
+	keys := make([]uint, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
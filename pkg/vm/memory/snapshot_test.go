@@ -0,0 +1,150 @@
+package memory_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	for i := 0; i < 3; i++ {
+		segments.AddSegment()
+	}
+	segments.Memory.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromDecString("-1")))
+	segments.Memory.Insert(memory.NewRelocatable(0, 3), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)))
+	segments.Memory.Insert(memory.NewRelocatable(1, 1), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(2, 5)))
+	segments.ComputeEffectiveSizes()
+	size := uint(8)
+	segments.Finalize(1, &size, []memory.PublicMemoryOffset{{Offset: 1, Page: 2}})
+
+	var buffer bytes.Buffer
+	if err := segments.WriteSnapshot(&buffer); err != nil {
+		t.Fatalf("WriteSnapshot failed with error: %s", err)
+	}
+	loaded, err := memory.ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed with error: %s", err)
+	}
+
+	if loaded.Memory.NumSegments() != 3 {
+		t.Errorf("Wrong number of segments: %d", loaded.Memory.NumSegments())
+	}
+	if !loaded.Memory.MemEq(memory.NewRelocatable(0, 0), memory.NewRelocatable(0, 0), 4) {
+		t.Errorf("Loaded memory differs")
+	}
+	for _, addr := range []memory.Relocatable{memory.NewRelocatable(0, 0), memory.NewRelocatable(0, 3), memory.NewRelocatable(1, 1)} {
+		expected, _ := segments.Memory.Get(addr)
+		actual, err := loaded.Memory.Get(addr)
+		if err != nil || !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Loaded memory differs at %+v", addr)
+		}
+	}
+	if _, err := loaded.Memory.Get(memory.NewRelocatable(0, 1)); err == nil {
+		t.Errorf("Memory holes should be preserved")
+	}
+	if !reflect.DeepEqual(loaded.SegmentSizes, segments.SegmentSizes) || !reflect.DeepEqual(loaded.FinalizedSizes, segments.FinalizedSizes) {
+		t.Errorf("Segment sizes differ")
+	}
+	if !reflect.DeepEqual(loaded.PublicMemoryOffsets, segments.PublicMemoryOffsets) {
+		t.Errorf("Public memory offsets differ")
+	}
+}
+
+func TestReadSnapshotInvalidHeader(t *testing.T) {
+	_, err := memory.ReadSnapshot(bytes.NewReader([]byte("nope, not a snapshot")))
+	if err == nil {
+		t.Errorf("ReadSnapshot should fail with an invalid header")
+	}
+}
+
+func TestReadSnapshotTruncated(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	segments.Memory.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)))
+	var buffer bytes.Buffer
+	segments.WriteSnapshot(&buffer)
+
+	_, err := memory.ReadSnapshot(bytes.NewReader(buffer.Bytes()[:buffer.Len()-10]))
+	if err == nil {
+		t.Errorf("ReadSnapshot should fail with a truncated snapshot")
+	}
+}
+
+func TestSnapshotRoundTripSparseSegment(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	segments.AddSegment()
+	value := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
+	segments.Memory.Insert(memory.NewRelocatable(0, 100), value)
+	var buffer bytes.Buffer
+	if err := segments.WriteSnapshot(&buffer); err != nil {
+		t.Fatalf("WriteSnapshot failed with error: %s", err)
+	}
+	read, err := memory.ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed with error: %s", err)
+	}
+	if got, err := read.Memory.Get(memory.NewRelocatable(0, 100)); err != nil || *got != *value {
+		t.Errorf("Expected %v at 0:100, got %v, err: %v", value, got, err)
+	}
+}
+
+func TestSnapshotRoundTripTempSegments(t *testing.T) {
+	segments := memory.NewMemorySegmentManager()
+	base := segments.AddSegment()
+	temp := segments.AddTempSegment()
+	segments.AddTempSegment()
+	value := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
+	segments.Memory.Insert(memory.NewRelocatable(temp.SegmentIndex, 1), value)
+	segments.Memory.Insert(base, memory.NewMaybeRelocatableRelocatable(temp))
+	if err := segments.Memory.AddRelocationRule(temp, memory.NewRelocatable(base.SegmentIndex, 5)); err != nil {
+		t.Fatalf("AddRelocationRule error in test: %s", err)
+	}
+	var buffer bytes.Buffer
+	if err := segments.WriteSnapshot(&buffer); err != nil {
+		t.Fatalf("WriteSnapshot failed with error: %s", err)
+	}
+	read, err := memory.ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed with error: %s", err)
+	}
+	if read.Memory.NumTempSegments() != 2 {
+		t.Errorf("Expected 2 temporary segments, got %d", read.Memory.NumTempSegments())
+	}
+	if got, err := read.Memory.Get(memory.NewRelocatable(temp.SegmentIndex, 1)); err != nil || *got != *value {
+		t.Errorf("Expected %v at -1:1, got %v, err: %v", value, got, err)
+	}
+	// The relocation rule was read back: relocating moves the value to 0:6
+	if err := read.Memory.RelocateMemory(); err != nil {
+		t.Fatalf("RelocateMemory failed with error: %s", err)
+	}
+	if got, err := read.Memory.Get(memory.NewRelocatable(base.SegmentIndex, 6)); err != nil || *got != *value {
+		t.Errorf("Expected %v at 0:6, got %v, err: %v", value, got, err)
+	}
+}
+
+func TestReadSnapshotHugeCounts(t *testing.T) {
+	header := []byte{'C', 'V', 'M', 'S', 2}
+	le := func(values ...uint64) []byte {
+		encoded := make([]byte, 8*len(values))
+		for i, value := range values {
+			binary.LittleEndian.PutUint64(encoded[8*i:], value)
+		}
+		return encoded
+	}
+	felt := append([]byte{0}, make([]byte, 32)...)
+	snapshots := map[string][]byte{
+		"segment count": append(header, le(1<<40)...),
+		"cell count":    append(header, le(1, 1<<40)...),
+		"offset":        append(append(header, le(1, 1, 1<<40)...), felt...),
+	}
+	for name, snapshot := range snapshots {
+		if _, err := memory.ReadSnapshot(bytes.NewReader(snapshot)); err == nil {
+			t.Errorf("ReadSnapshot should fail with a huge %s", name)
+		}
+	}
+}
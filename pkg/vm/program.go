@@ -1,6 +1,14 @@
 package vm
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
 	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
@@ -9,19 +17,309 @@ import (
 type Program struct {
 	Data        []memory.MaybeRelocatable
 	Builtins    []string
-	Identifiers *map[string]parser.Identifier
+	Identifiers map[string]parser.Identifier
+	// Hints indexed by the pc they run at, in execution order
+	Hints            map[uint][]parser.HintParams
+	ReferenceManager parser.ReferenceManager
+	// Parsed references, indexed by the reference ids of the hints' flow tracking data
+	References []parser.HintReference
+	Attributes []parser.Attribute
+	DebugInfo  *parser.DebugInfo
+	// Source locations of the instructions, indexed by pc. Empty if the program has no debug info
+	InstructionLocations map[uint]parser.InstructionLocation
+	MainScope            string
+	CompilerVersion      string
+	Prime                string
+	// Entrypoint of the __main__.main function, nil if the program has none
+	MainEntrypoint *Entrypoint
+	// Cairo 1 programs only: structured hints indexed by pc and the contract's entry points
+	Cairo1Hints       map[uint][]parser.CasmHint
+	EntryPointsByType *parser.CasmEntryPointsByType
 }
 
-func DeserializeProgramJson(compiledProgram parser.CompiledJson) Program {
+func DeserializeProgramJson(compiledProgram parser.CompiledJson) (Program, error) {
 	var program Program
 
 	hexData := compiledProgram.Data
-	for _, hexVal := range hexData {
+	for i, hexVal := range hexData {
+		if err := parser.CheckHexFelt(hexVal); err != nil {
+			return Program{}, &parser.ParseError{Path: fmt.Sprintf("data[%d]", i), Err: err}
+		}
 		felt := lambdaworks.FeltFromHex(hexVal)
 		program.Data = append(program.Data, *memory.NewMaybeRelocatableFelt(felt))
 	}
 	program.Builtins = compiledProgram.Builtins
-	program.Identifiers = &compiledProgram.Identifiers
+	program.Identifiers = compiledProgram.Identifiers
+
+	program.Hints = make(map[uint][]parser.HintParams, len(compiledProgram.Hints))
+	for pcStr, hints := range compiledProgram.Hints {
+		pc, err := strconv.ParseUint(pcStr, 10, 0)
+		if err != nil {
+			return Program{}, &parser.ParseError{Path: fmt.Sprintf("hints[%q]", pcStr), Err: fmt.Errorf("invalid pc: %w", err)}
+		}
+		program.Hints[uint(pc)] = hints
+	}
+	program.ReferenceManager = compiledProgram.ReferenceManager
+	program.References = make([]parser.HintReference, 0, len(compiledProgram.ReferenceManager.References))
+	for i, reference := range compiledProgram.ReferenceManager.References {
+		hintReference, err := parser.NewHintReference(reference)
+		if err != nil {
+			return Program{}, &parser.ParseError{Path: fmt.Sprintf("reference_manager.references[%d].value", i), Err: err}
+		}
+		program.References = append(program.References, hintReference)
+	}
+	program.Attributes = compiledProgram.Attributes
+	program.DebugInfo = compiledProgram.DebugInfo
+	program.InstructionLocations = make(map[uint]parser.InstructionLocation)
+	if compiledProgram.DebugInfo != nil {
+		for pcStr, location := range compiledProgram.DebugInfo.InstructionLocation {
+			pc, err := strconv.ParseUint(pcStr, 10, 0)
+			if err != nil {
+				return Program{}, &parser.ParseError{Path: fmt.Sprintf("debug_info.instruction_locations[%q]", pcStr), Err: fmt.Errorf("invalid pc: %w", err)}
+			}
+			program.InstructionLocations[uint(pc)] = location
+		}
+	}
+	program.MainScope = compiledProgram.MainScope
+	program.CompilerVersion = compiledProgram.CompilerVersion
+	program.Prime = compiledProgram.Prime
+	if main, err := program.GetEntrypoint("main"); err == nil {
+		program.MainEntrypoint = &main
+	}
+
+	return program, nil
+}
+
+// Parses a compiled program read from r, converting its data into felts as it is decoded,
+// so that the hex strings of the data array are never held in memory all at once.
+func ParseProgramStream(r io.Reader) (Program, error) {
+	var data []memory.MaybeRelocatable
+	compiled, err := parser.ParseStream(r, func(_ int, value string) error {
+		data = append(data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex(value)))
+		return nil
+	})
+	if err != nil {
+		return Program{}, err
+	}
+	program, err := DeserializeProgramJson(compiled)
+	if err != nil {
+		return Program{}, err
+	}
+	program.Data = data
+	return program, nil
+}
+
+// Converts a Program back into its cairo-lang compatible JSON representation
+func SerializeProgramJson(program Program) (parser.CompiledJson, error) {
+	compiled := parser.CompiledJson{
+		Attributes:       program.Attributes,
+		Builtins:         program.Builtins,
+		CompilerVersion:  program.CompilerVersion,
+		Data:             make([]string, 0, len(program.Data)),
+		DebugInfo:        program.DebugInfo,
+		Hints:            make(map[string][]parser.HintParams, len(program.Hints)),
+		Identifiers:      program.Identifiers,
+		MainScope:        program.MainScope,
+		Prime:            program.Prime,
+		ReferenceManager: program.ReferenceManager,
+	}
+	for i, value := range program.Data {
+		felt, ok := value.GetFelt()
+		if !ok {
+			return parser.CompiledJson{}, fmt.Errorf("Program data at offset %d is not a felt", i)
+		}
+		compiled.Data = append(compiled.Data, felt.ToHexString())
+	}
+	for pc, hints := range program.Hints {
+		compiled.Hints[strconv.FormatUint(uint64(pc), 10)] = hints
+	}
+	// cairo-lang expects empty collections rather than null values
+	if compiled.Attributes == nil {
+		compiled.Attributes = []parser.Attribute{}
+	}
+	if compiled.Builtins == nil {
+		compiled.Builtins = []string{}
+	}
+	if compiled.Identifiers == nil {
+		compiled.Identifiers = map[string]parser.Identifier{}
+	}
+	if compiled.ReferenceManager.References == nil {
+		compiled.ReferenceManager.References = []parser.Reference{}
+	}
+	return compiled, nil
+}
+
+// Writes the program to w as cairo-lang compatible JSON
+func (p *Program) WriteJSON(w io.Writer) error {
+	compiled, err := SerializeProgramJson(*p)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(compiled)
+}
+
+// Returns the source location of the instruction at pc, if the program has debug info for it
+func (p *Program) GetLocation(pc uint) (parser.Location, bool) {
+	location, ok := p.InstructionLocations[pc]
+	return location.Inst, ok
+}
+
+// Returns the index of the first hint at each pc holding hints. Hints are indexed by their
+// position among all the hints of the program, ordered by pc and then by the order they run in,
+// so indexes only depend on the program and are the same across runs
+func (p *Program) HintIndexes() map[uint]uint {
+	pcs := make([]uint, 0, len(p.Hints))
+	for pc := range p.Hints {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	indexes := make(map[uint]uint, len(pcs))
+	next := uint(0)
+	for _, pc := range pcs {
+		indexes[pc] = next
+		next += uint(len(p.Hints[pc]))
+	}
+	return indexes
+}
+
+// Builds a Program out of a Cairo 1 casm contract class. The entry point to run is chosen
+// from EntryPointsByType when initializing the runner.
+func DeserializeCasmContractClass(casm parser.CasmContractClass) (Program, error) {
+	var program Program
 
-	return program
+	for i, hexVal := range casm.Bytecode {
+		if err := parser.CheckHexFelt(hexVal); err != nil {
+			return Program{}, &parser.ParseError{Path: fmt.Sprintf("bytecode[%d]", i), Err: err}
+		}
+		felt := lambdaworks.FeltFromHex(hexVal)
+		program.Data = append(program.Data, *memory.NewMaybeRelocatableFelt(felt))
+	}
+	program.Identifiers = map[string]parser.Identifier{}
+	program.Cairo1Hints = make(map[uint][]parser.CasmHint, len(casm.Hints))
+	for _, hints := range casm.Hints {
+		if hints.Offset >= uint(len(program.Data)) {
+			return Program{}, &parser.ParseError{Path: "hints", Err: fmt.Errorf("offset %d is out of the bytecode bounds", hints.Offset)}
+		}
+		program.Cairo1Hints[hints.Offset] = append(program.Cairo1Hints[hints.Offset], hints.Hints...)
+	}
+	entryPoints := casm.EntryPointsByType
+	program.EntryPointsByType = &entryPoints
+	program.CompilerVersion = casm.CompilerVersion
+	program.Prime = casm.Prime
+
+	return program, nil
+}
+
+// Returns the identifier with the given full name, following aliases to their destination
+func (p *Program) GetIdentifier(name string) (parser.Identifier, error) {
+	// Bound the number of aliases followed, so that alias cycles can't loop forever
+	for i := 0; i <= len(p.Identifiers); i++ {
+		identifier, ok := p.Identifiers[name]
+		if !ok {
+			return parser.Identifier{}, fmt.Errorf("Identifier %s not found", name)
+		}
+		if identifier.Type != parser.IdentifierAlias {
+			return identifier, nil
+		}
+		name = identifier.Destination
+	}
+	return parser.Identifier{}, fmt.Errorf("Alias cycle found resolving identifier %s", name)
+}
+
+// Returns the value of the constant with the given full name
+func (p *Program) GetConstant(name string) (lambdaworks.Felt, error) {
+	identifier, err := p.GetIdentifier(name)
+	if err != nil {
+		return lambdaworks.FeltZero(), err
+	}
+	if identifier.Type != parser.IdentifierConst || identifier.Value == nil {
+		return lambdaworks.FeltZero(), fmt.Errorf("Identifier %s is not a constant", name)
+	}
+	return lambdaworks.FeltFromBigInt(identifier.Value), nil
+}
+
+// Returns the members of the struct with the given full name, indexed by member name
+func (p *Program) GetStructMembers(name string) (map[string]parser.Member, error) {
+	identifier, err := p.GetIdentifier(name)
+	if err != nil {
+		return nil, err
+	}
+	if identifier.Type != parser.IdentifierStruct {
+		return nil, fmt.Errorf("Identifier %s is not a struct", name)
+	}
+	return identifier.Members, nil
+}
+
+// A function that can be used as an entrypoint to run the program
+type Entrypoint struct {
+	// Full name of the function
+	Name       string
+	PC         uint
+	Decorators []string
+	// Builtins taken as implicit arguments, in the order they are received
+	Builtins []string
+}
+
+// Returns true if the builtin can be received as an implicit argument: any builtin a program can
+// declare, and Cairo 1's segment_arena. Other *_ptr arguments, e.g. syscall_ptr, are plain arguments
+func isImplicitArgBuiltin(name string) bool {
+	if name == "segment_arena" {
+		return true
+	}
+	for _, builtin := range builtins.BuiltinNames {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the entrypoint metadata of the function with the given name. Names without a
+// module path (e.g. main) are looked up in the __main__ module.
+func (p *Program) GetEntrypoint(name string) (Entrypoint, error) {
+	fullName := name
+	if !strings.Contains(name, ".") {
+		fullName = "__main__." + name
+	}
+	identifier, err := p.GetIdentifier(fullName)
+	if err != nil {
+		return Entrypoint{}, err
+	}
+	if identifier.Type != parser.IdentifierFunction {
+		return Entrypoint{}, fmt.Errorf("Identifier %s is not a function", fullName)
+	}
+	entrypoint := Entrypoint{Name: fullName, PC: uint(identifier.PC), Decorators: identifier.Decorators}
+
+	// Builtins are received as implicit arguments named after them, e.g. range_check_ptr
+	implicitArgs, err := p.GetStructMembers(fullName + ".ImplicitArgs")
+	if err == nil {
+		names := make([]string, 0, len(implicitArgs))
+		for name := range implicitArgs {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return implicitArgs[names[i]].Offset < implicitArgs[names[j]].Offset })
+		for _, name := range names {
+			builtin := strings.TrimSuffix(name, "_ptr")
+			if builtin != name && isImplicitArgBuiltin(builtin) {
+				entrypoint.Builtins = append(entrypoint.Builtins, builtin)
+			}
+		}
+	}
+	return entrypoint, nil
+}
+
+// Returns the full name of the function containing pc: the function with the highest pc not past it
+func (p *Program) GetFunctionAt(pc uint) (string, bool) {
+	name, start := "", -1
+	for identifierName, identifier := range p.Identifiers {
+		if identifier.Type != parser.IdentifierFunction || identifier.PC > int(pc) || identifier.PC < start {
+			continue
+		}
+		// Pick deterministically between functions sharing a pc
+		if identifier.PC == start && identifierName > name {
+			continue
+		}
+		name, start = identifierName, identifier.PC
+	}
+	return name, start >= 0
 }
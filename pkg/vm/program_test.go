@@ -1,9 +1,399 @@
 package vm
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 )
 
 func TestNewProgram(t *testing.T) {
 
 }
+
+func TestDeserializeProgramJsonHints(t *testing.T) {
+	hint := parser.HintParams{Code: "memory[ap] = segments.add()", AccessibleScopes: []string{"__main__"}}
+	compiled := parser.CompiledJson{
+		Data:            []string{"0x1", "0x2"},
+		Hints:           map[string][]parser.HintParams{"1": {hint}},
+		MainScope:       "__main__",
+		CompilerVersion: "0.11.0",
+	}
+	program, err := DeserializeProgramJson(compiled)
+	if err != nil {
+		t.Fatalf("DeserializeProgramJson failed with error: %s", err)
+	}
+	if len(program.Data) != 2 {
+		t.Errorf("Expected 2 data elements, got %d", len(program.Data))
+	}
+	if hints := program.Hints[1]; len(hints) != 1 || hints[0].Code != hint.Code {
+		t.Errorf("Expected hint %+v at pc 1, got %+v", hint, program.Hints)
+	}
+	if program.MainScope != "__main__" || program.CompilerVersion != "0.11.0" {
+		t.Errorf("Wrong program metadata: %s %s", program.MainScope, program.CompilerVersion)
+	}
+}
+
+func TestDeserializeProgramJsonInvalidHintPc(t *testing.T) {
+	compiled := parser.CompiledJson{Hints: map[string][]parser.HintParams{"zero": {}}}
+	if _, err := DeserializeProgramJson(compiled); err == nil {
+		t.Errorf("DeserializeProgramJson should fail with an invalid hint pc")
+	}
+}
+
+func TestDeserializeProgramJsonReferencesApTracking(t *testing.T) {
+	compiled := parser.CompiledJson{
+		ReferenceManager: parser.ReferenceManager{References: []parser.Reference{
+			{ApTrackingData: parser.ApTrackingData{Group: 1, Offset: 0}, Pc: 0, Value: "[cast(fp + (-3), felt*)]"},
+			{ApTrackingData: parser.ApTrackingData{Group: 2, Offset: 4}, Pc: 5, Value: "cast(ap + (-1), felt)"},
+		}},
+	}
+	program, err := DeserializeProgramJson(compiled)
+	if err != nil {
+		t.Fatalf("DeserializeProgramJson failed with error: %s", err)
+	}
+	if len(program.References) != 2 {
+		t.Fatalf("Expected 2 references, got %d", len(program.References))
+	}
+	if program.References[1].ApTrackingData != (parser.ApTrackingData{Group: 2, Offset: 4}) {
+		t.Errorf("Wrong ap tracking data: %+v", program.References[1].ApTrackingData)
+	}
+	if offset := program.References[1].Offset1; offset.Register != parser.AP || offset.Offset != -1 {
+		t.Errorf("Wrong reference offset: %+v", offset)
+	}
+}
+
+func TestDeserializeProgramJsonInvalidReference(t *testing.T) {
+	compiled := parser.CompiledJson{
+		ReferenceManager: parser.ReferenceManager{References: []parser.Reference{{Value: "fp + (-3)"}}},
+	}
+	if _, err := DeserializeProgramJson(compiled); err == nil {
+		t.Errorf("DeserializeProgramJson should fail with a malformed reference")
+	}
+}
+
+func TestProgramGetLocation(t *testing.T) {
+	location := parser.Location{InputFile: parser.InputFile{Filename: "main.cairo"}, StartLine: 3, StartCol: 5}
+	compiled := parser.CompiledJson{
+		DebugInfo: &parser.DebugInfo{
+			InstructionLocation: map[string]parser.InstructionLocation{"2": {Inst: location}},
+		},
+	}
+	program, err := DeserializeProgramJson(compiled)
+	if err != nil {
+		t.Fatalf("DeserializeProgramJson failed with error: %s", err)
+	}
+	got, ok := program.GetLocation(2)
+	if !ok || got != location {
+		t.Errorf("Wrong location for pc 2: %+v", got)
+	}
+	if _, ok := program.GetLocation(3); ok {
+		t.Errorf("There should be no location for pc 3")
+	}
+}
+
+func TestDeserializeCasmContractClass(t *testing.T) {
+	casm := parser.CasmContractClass{
+		Bytecode: []string{"0xa0680017fff8000", "0x7"},
+		Hints: []parser.CasmHintsAtOffset{
+			{Offset: 1, Hints: []parser.CasmHint{{Name: "AllocSegment", Args: []byte(`{}`)}}},
+		},
+		EntryPointsByType: parser.CasmEntryPointsByType{
+			External: []parser.CasmEntryPoint{{Selector: "0x1", Offset: 0}},
+		},
+	}
+	program, err := DeserializeCasmContractClass(casm)
+	if err != nil {
+		t.Fatalf("DeserializeCasmContractClass failed with error: %s", err)
+	}
+	if len(program.Data) != 2 {
+		t.Errorf("Expected 2 data elements, got %d", len(program.Data))
+	}
+	if hints := program.Cairo1Hints[1]; len(hints) != 1 || hints[0].Name != "AllocSegment" {
+		t.Errorf("Wrong hints: %+v", program.Cairo1Hints)
+	}
+	if program.EntryPointsByType == nil || len(program.EntryPointsByType.External) != 1 {
+		t.Errorf("Wrong entrypoints: %+v", program.EntryPointsByType)
+	}
+}
+
+func TestDeserializeCasmContractClassHintOutOfBounds(t *testing.T) {
+	casm := parser.CasmContractClass{
+		Bytecode: []string{"0x7"},
+		Hints:    []parser.CasmHintsAtOffset{{Offset: 1}},
+	}
+	if _, err := DeserializeCasmContractClass(casm); err == nil {
+		t.Errorf("DeserializeCasmContractClass should fail with a hint outside the bytecode")
+	}
+}
+
+func identifiersProgram() Program {
+	return Program{Identifiers: map[string]parser.Identifier{
+		"__main__.SHIFT": {Type: parser.IdentifierConst, Value: new(big.Int).Lsh(big.NewInt(1), 128)},
+		"__main__.NEG":   {Type: parser.IdentifierConst, Value: big.NewInt(-1)},
+		"__main__.ALIAS": {Type: parser.IdentifierAlias, Destination: "__main__.SHIFT"},
+		"__main__.LOOP":  {Type: parser.IdentifierAlias, Destination: "__main__.LOOP"},
+		"__main__.main":  {Type: parser.IdentifierFunction, PC: 3},
+		"__main__.Point": {
+			Type:     parser.IdentifierStruct,
+			FullName: "__main__.Point",
+			Size:     2,
+			Members: map[string]parser.Member{
+				"x": {CairoType: "felt", Offset: 0},
+				"y": {CairoType: "felt", Offset: 1},
+			},
+		},
+	}}
+}
+
+func TestProgramGetConstant(t *testing.T) {
+	program := identifiersProgram()
+	got, err := program.GetConstant("__main__.SHIFT")
+	if err != nil || got != lambdaworks.FeltTwoPow128() {
+		t.Errorf("Wrong constant value: %v, err: %v", got, err)
+	}
+	got, err = program.GetConstant("__main__.NEG")
+	if err != nil || got != lambdaworks.FeltMax() {
+		t.Errorf("Wrong negative constant value: %v, err: %v", got, err)
+	}
+}
+
+func TestProgramGetConstantThroughAlias(t *testing.T) {
+	program := identifiersProgram()
+	got, err := program.GetConstant("__main__.ALIAS")
+	if err != nil || got != lambdaworks.FeltTwoPow128() {
+		t.Errorf("Wrong constant value: %v, err: %v", got, err)
+	}
+}
+
+func TestProgramGetConstantErrors(t *testing.T) {
+	program := identifiersProgram()
+	for _, name := range []string{"__main__.main", "__main__.MISSING", "__main__.LOOP"} {
+		if _, err := program.GetConstant(name); err == nil {
+			t.Errorf("GetConstant should fail for %s", name)
+		}
+	}
+}
+
+func TestProgramGetStructMembers(t *testing.T) {
+	program := identifiersProgram()
+	members, err := program.GetStructMembers("__main__.Point")
+	expected := map[string]parser.Member{"x": {CairoType: "felt", Offset: 0}, "y": {CairoType: "felt", Offset: 1}}
+	if err != nil || !reflect.DeepEqual(members, expected) {
+		t.Errorf("Wrong struct members: %v, err: %v", members, err)
+	}
+	if _, err := program.GetStructMembers("__main__.SHIFT"); err == nil {
+		t.Errorf("GetStructMembers should fail for a constant")
+	}
+}
+
+func entrypointsProgram() Program {
+	return Program{Identifiers: map[string]parser.Identifier{
+		"__main__.main": {Type: parser.IdentifierFunction, PC: 3, Decorators: []string{}},
+		"__main__.main.ImplicitArgs": {
+			Type: parser.IdentifierStruct,
+			Members: map[string]parser.Member{
+				"range_check_ptr":   {CairoType: "felt", Offset: 2},
+				"syscall_ptr":       {CairoType: "felt*", Offset: 1},
+				"output_ptr":        {CairoType: "felt*", Offset: 0},
+				"range_check96_ptr": {CairoType: "felt", Offset: 3},
+			},
+		},
+		"__main__.helper":  {Type: parser.IdentifierFunction, PC: 10, Decorators: []string{"known_ap_change"}},
+		"__main__.CONST":   {Type: parser.IdentifierConst, Value: big.NewInt(1)},
+		"lib.math.abs_fn":  {Type: parser.IdentifierFunction, PC: 20},
+		"__main__.abs_fn":  {Type: parser.IdentifierAlias, Destination: "lib.math.abs_fn"},
+		"__main__.Missing": {Type: parser.IdentifierLabel, PC: 1},
+	}}
+}
+
+func TestProgramGetEntrypointMain(t *testing.T) {
+	program := entrypointsProgram()
+	got, err := program.GetEntrypoint("main")
+	expected := Entrypoint{Name: "__main__.main", PC: 3, Decorators: []string{}, Builtins: []string{"output", "range_check", "range_check96"}}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("Wrong entrypoint. Expected %+v, got %+v, err: %v", expected, got, err)
+	}
+}
+
+func TestProgramGetEntrypointFullNameAndAlias(t *testing.T) {
+	program := entrypointsProgram()
+	got, err := program.GetEntrypoint("__main__.helper")
+	if err != nil || got.PC != 10 || !reflect.DeepEqual(got.Decorators, []string{"known_ap_change"}) || got.Builtins != nil {
+		t.Errorf("Wrong entrypoint for helper: %+v, err: %v", got, err)
+	}
+	got, err = program.GetEntrypoint("abs_fn")
+	if err != nil || got.PC != 20 {
+		t.Errorf("Wrong entrypoint for abs_fn: %+v, err: %v", got, err)
+	}
+}
+
+func TestProgramGetEntrypointNotAFunction(t *testing.T) {
+	program := entrypointsProgram()
+	for _, name := range []string{"CONST", "Missing", "nope"} {
+		if _, err := program.GetEntrypoint(name); err == nil {
+			t.Errorf("GetEntrypoint should fail for %s", name)
+		}
+	}
+}
+
+func TestDeserializeProgramJsonResolvesMainEntrypoint(t *testing.T) {
+	program, err := DeserializeProgramJson(parser.CompiledJson{Identifiers: entrypointsProgram().Identifiers})
+	if err != nil {
+		t.Fatalf("DeserializeProgramJson failed with error: %s", err)
+	}
+	if program.MainEntrypoint == nil || program.MainEntrypoint.PC != 3 {
+		t.Errorf("Wrong main entrypoint: %+v", program.MainEntrypoint)
+	}
+}
+
+const roundTripProgramJson = `{
+	"attributes": [],
+	"builtins": ["output"],
+	"compiler_version": "0.11.0",
+	"data": ["0x40780017fff7fff", "0x1", "0x208b7fff7fff7ffe"],
+	"debug_info": null,
+	"hints": {
+		"0": [{
+			"accessible_scopes": ["__main__", "__main__.main"],
+			"code": "memory[ap] = segments.add()",
+			"flow_tracking_data": {"ap_tracking": {"group": 0, "offset": 0}, "reference_ids": {}}
+		}]
+	},
+	"identifiers": {
+		"__main__.main": {"decorators": [], "pc": 0, "type": "function"},
+		"__main__.SHIFT": {"type": "const", "value": 340282366920938463463374607431768211456},
+		"__main__.Args": {"full_name": "__main__.Args", "members": {"x": {"cairo_type": "felt", "offset": 0}}, "size": 1, "type": "struct"},
+		"__main__.x": {"cairo_type": "felt", "full_name": "__main__.x", "references": [{"ap_tracking_data": {"group": 0, "offset": 0}, "pc": 0, "value": "[cast(fp + (-3), felt*)]"}], "type": "reference"},
+		"__main__.alias": {"destination": "__main__.main", "type": "alias"}
+	},
+	"main_scope": "__main__",
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"reference_manager": {"references": [{"ap_tracking_data": {"group": 0, "offset": 0}, "pc": 0, "value": "[cast(fp + (-3), felt*)]"}]}
+}`
+
+func TestSerializeProgramJsonRoundTrip(t *testing.T) {
+	compiled, err := parser.ParseBytes([]byte(roundTripProgramJson))
+	if err != nil {
+		t.Fatalf("ParseBytes failed with error: %s", err)
+	}
+	program, err := DeserializeProgramJson(compiled)
+	if err != nil {
+		t.Fatalf("DeserializeProgramJson failed with error: %s", err)
+	}
+	var buffer bytes.Buffer
+	if err := program.WriteJSON(&buffer); err != nil {
+		t.Fatalf("WriteJSON failed with error: %s", err)
+	}
+	reparsed, err := parser.ParseBytes(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("ParseBytes failed on the serialized program with error: %s", err)
+	}
+	if !reflect.DeepEqual(reparsed, compiled) {
+		t.Errorf("Round trip changed the program.\nExpected %+v\nGot %+v", compiled, reparsed)
+	}
+}
+
+func TestSerializeProgramJsonOnlyWritesFieldsOfTheIdentifierType(t *testing.T) {
+	program := Program{Identifiers: map[string]parser.Identifier{
+		"__main__.main": {Type: parser.IdentifierFunction, PC: 2},
+	}}
+	var buffer bytes.Buffer
+	if err := program.WriteJSON(&buffer); err != nil {
+		t.Fatalf("WriteJSON failed with error: %s", err)
+	}
+	var raw map[string]json.RawMessage
+	json.Unmarshal(buffer.Bytes(), &raw)
+	expected := `{"__main__.main":{"decorators":[],"pc":2,"type":"function"}}`
+	if string(raw["identifiers"]) != expected {
+		t.Errorf("Wrong identifiers. Expected %s, got %s", expected, raw["identifiers"])
+	}
+}
+
+func TestSerializeProgramJsonRelocatableData(t *testing.T) {
+	program := Program{Data: []memory.MaybeRelocatable{*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 0))}}
+	if _, err := SerializeProgramJson(program); err == nil {
+		t.Errorf("SerializeProgramJson should fail with relocatable data")
+	}
+}
+
+func TestParseProgramStream(t *testing.T) {
+	program, err := ParseProgramStream(strings.NewReader(roundTripProgramJson))
+	if err != nil {
+		t.Fatalf("ParseProgramStream failed with error: %s", err)
+	}
+	compiled, _ := parser.ParseBytes([]byte(roundTripProgramJson))
+	expected, _ := DeserializeProgramJson(compiled)
+	if !reflect.DeepEqual(program, expected) {
+		t.Errorf("ParseProgramStream should match DeserializeProgramJson.\nExpected %+v\nGot %+v", expected, program)
+	}
+}
+
+func TestDeserializeProgramJsonInvalidHexData(t *testing.T) {
+	_, err := DeserializeProgramJson(parser.CompiledJson{Data: []string{"0x1", "0xzz"}})
+	if err == nil || err.Error() != `data[1]: invalid hex "0xzz"` {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func TestDeserializeProgramJsonMalformedReferencePath(t *testing.T) {
+	compiled := parser.CompiledJson{
+		ReferenceManager: parser.ReferenceManager{References: []parser.Reference{{Value: "cast(fp, felt)"}, {Value: "fp + 1"}}},
+	}
+	_, err := DeserializeProgramJson(compiled)
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Path != "reference_manager.references[1].value" {
+		t.Errorf("Expected a ParseError for the second reference, got: %v", err)
+	}
+}
+
+// Any input either fails to parse or parses into a program serializing back to an equivalent one
+func FuzzParseProgramStream(f *testing.F) {
+	f.Add([]byte(`{"data": ["0x480680017fff8000", "0x5", "0x208b7fff7fff7ffe"], "builtins": [], "identifiers": {"__main__.main": {"type": "function", "pc": 0}}, "hints": {"0": [{"code": "", "accessible_scopes": [], "flow_tracking_data": {"ap_tracking": {"group": 0, "offset": 0}, "reference_ids": {}}}]}, "reference_manager": {"references": [{"ap_tracking_data": {"group": 0, "offset": 0}, "pc": 0, "value": "[cast(fp + (-3), felt*)]"}]}}`))
+	programs, _ := filepath.Glob("../../cairo_programs/*.json")
+	for _, path := range programs {
+		if data, err := os.ReadFile(path); err == nil {
+			f.Add(data)
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		program, err := ParseProgramStream(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		var serialized bytes.Buffer
+		if err := program.WriteJSON(&serialized); err != nil {
+			t.Fatalf("The parsed program fails to serialize: %s", err)
+		}
+		reparsed, err := ParseProgramStream(&serialized)
+		if err != nil {
+			t.Fatalf("The serialized program fails to parse: %s", err)
+		}
+		if !reflect.DeepEqual(program.Data, reparsed.Data) || len(program.Hints) != len(reparsed.Hints) || len(program.References) != len(reparsed.References) {
+			t.Fatalf("The program changed through serialization")
+		}
+	})
+}
+
+func TestHintIndexes(t *testing.T) {
+	program := Program{Hints: map[uint][]parser.HintParams{
+		7: {{Code: "d"}},
+		0: {{Code: "a"}, {Code: "b"}},
+		3: {{Code: "c"}},
+	}}
+	expected := map[uint]uint{0: 0, 3: 2, 7: 3}
+	for i := 0; i < 10; i++ {
+		if indexes := program.HintIndexes(); !reflect.DeepEqual(indexes, expected) {
+			t.Fatalf("Wrong hint indexes, expected %v, got %v", expected, indexes)
+		}
+	}
+}
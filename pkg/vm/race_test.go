@@ -0,0 +1,6 @@
+//go:build race
+
+package vm_test
+
+// The race detector instruments memory accesses, which allocates
+const raceEnabled = true
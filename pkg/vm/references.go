@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Computes the value of a reference at the current state of the vm.
+// apTracking is the ap tracking data of the location the reference is accessed from (a hint
+// or an instruction), which is needed to resolve references based on ap.
+func (v *VirtualMachine) GetReferenceValue(reference parser.HintReference, apTracking parser.ApTrackingData) (memory.MaybeRelocatable, error) {
+	value, err := v.referenceExpression(reference, apTracking)
+	if err != nil || !reference.Dereference {
+		return value, err
+	}
+	addr, ok := value.GetRelocatable()
+	if !ok {
+		return memory.MaybeRelocatable{}, errors.New("Dereferenced reference doesn't point to a memory address")
+	}
+	cell, err := v.Segments.Memory.Get(addr)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return *cell, nil
+}
+
+// Computes the address of a dereferenced reference, that is, the memory cell holding its value
+func (v *VirtualMachine) GetReferenceAddress(reference parser.HintReference, apTracking parser.ApTrackingData) (memory.Relocatable, error) {
+	if !reference.Dereference {
+		return memory.Relocatable{}, errors.New("Reference is not stored in memory")
+	}
+	value, err := v.referenceExpression(reference, apTracking)
+	if err != nil {
+		return memory.Relocatable{}, err
+	}
+	addr, ok := value.GetRelocatable()
+	if !ok {
+		return memory.Relocatable{}, errors.New("Dereferenced reference doesn't point to a memory address")
+	}
+	return addr, nil
+}
+
+// Evaluates the expression of a reference, without its outer dereference
+func (v *VirtualMachine) referenceExpression(reference parser.HintReference, apTracking parser.ApTrackingData) (memory.MaybeRelocatable, error) {
+	offset1, err := v.offsetValue(reference.Offset1, reference.ApTrackingData, apTracking)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	offset2, err := v.offsetValue(reference.Offset2, reference.ApTrackingData, apTracking)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return offset1.Add(offset2)
+}
+
+func (v *VirtualMachine) offsetValue(offset parser.OffsetValue, referenceApTracking parser.ApTrackingData, apTracking parser.ApTrackingData) (memory.MaybeRelocatable, error) {
+	switch offset.Kind {
+	case parser.OffsetValueImmediate:
+		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromBigInt(offset.Immediate)), nil
+	case parser.OffsetValueValue:
+		return *memory.NewMaybeRelocatableFelt(feltFromInt(offset.Value)), nil
+	}
+	base, err := v.referenceRegister(offset.Register, referenceApTracking, apTracking)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	addr, err := base.AddFelt(feltFromInt(offset.Offset))
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	if !offset.Dereference {
+		return *memory.NewMaybeRelocatableRelocatable(addr), nil
+	}
+	cell, err := v.Segments.Memory.Get(addr)
+	if err != nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return *cell, nil
+}
+
+// Returns the value the register had when the reference was created. ap is corrected by the
+// ap increments tracked by the compiler since then, which is only possible within the same group
+func (v *VirtualMachine) referenceRegister(register parser.Register, referenceApTracking parser.ApTrackingData, apTracking parser.ApTrackingData) (memory.Relocatable, error) {
+	if register == parser.FP {
+		return v.RunContext.Fp, nil
+	}
+	if referenceApTracking.Group != apTracking.Group {
+		return memory.Relocatable{}, fmt.Errorf("Can't resolve an ap based reference from ap tracking group %d, it was created in group %d",
+			apTracking.Group, referenceApTracking.Group)
+	}
+	diff := apTracking.Offset - referenceApTracking.Offset
+	if diff < 0 {
+		return v.RunContext.Ap.AddUint(uint(-diff))
+	}
+	return v.RunContext.Ap.SubUint(uint(diff))
+}
+
+func feltFromInt(value int) lambdaworks.Felt {
+	if value < 0 {
+		return lambdaworks.FeltFromUint64(uint64(-value)).Neg()
+	}
+	return lambdaworks.FeltFromUint64(uint64(value))
+}
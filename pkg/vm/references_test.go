@@ -0,0 +1,90 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// A vm with ap = 1:4, fp = 1:2 and [1:0] = 10, [1:1] = 1:3, [1:3] = 20
+func referencesVM(t *testing.T) *vm.VirtualMachine {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	base := virtualMachine.Segments.AddSegment()
+	data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 3)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(20)),
+	}
+	if _, err := virtualMachine.Segments.LoadData(base, data); err != nil {
+		t.Fatalf("LoadData error in test: %s", err)
+	}
+	virtualMachine.RunContext.Ap = memory.NewRelocatable(1, 4)
+	virtualMachine.RunContext.Fp = memory.NewRelocatable(1, 2)
+	return virtualMachine
+}
+
+func reference(t *testing.T, value string, apTracking parser.ApTrackingData) parser.HintReference {
+	ref, err := parser.NewHintReference(parser.Reference{Value: value, ApTrackingData: apTracking})
+	if err != nil {
+		t.Fatalf("NewHintReference error in test: %s", err)
+	}
+	return ref
+}
+
+func TestGetReferenceValueFp(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	value, err := virtualMachine.GetReferenceValue(reference(t, "[cast(fp + (-2), felt*)]", parser.ApTrackingData{}), parser.ApTrackingData{})
+	expected := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10))
+	if err != nil || !value.IsEqual(expected) {
+		t.Errorf("Wrong reference value. Expected %+v, got %+v, err: %v", expected, value, err)
+	}
+}
+
+func TestGetReferenceValueApTracking(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	// The reference was created at ap offset 1, and ap was increased by 2 since then
+	ref := reference(t, "[cast(ap + (-2), felt*)]", parser.ApTrackingData{Group: 1, Offset: 1})
+	value, err := virtualMachine.GetReferenceValue(ref, parser.ApTrackingData{Group: 1, Offset: 3})
+	expected := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10))
+	if err != nil || !value.IsEqual(expected) {
+		t.Errorf("Wrong reference value. Expected %+v, got %+v, err: %v", expected, value, err)
+	}
+	if _, err := virtualMachine.GetReferenceValue(ref, parser.ApTrackingData{Group: 2, Offset: 0}); err == nil {
+		t.Errorf("Resolving an ap based reference from another group should fail")
+	}
+}
+
+func TestGetReferenceValueInnerDereference(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	// [1:1] = 1:3, so the reference points to 1:3
+	value, err := virtualMachine.GetReferenceValue(reference(t, "[cast([fp + (-1)], felt*)]", parser.ApTrackingData{}), parser.ApTrackingData{})
+	expected := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(20))
+	if err != nil || !value.IsEqual(expected) {
+		t.Errorf("Wrong reference value. Expected %+v, got %+v, err: %v", expected, value, err)
+	}
+}
+
+func TestGetReferenceValueNotDereferenced(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	value, err := virtualMachine.GetReferenceValue(reference(t, "cast(fp + 1, felt*)", parser.ApTrackingData{}), parser.ApTrackingData{})
+	expected := memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 3))
+	if err != nil || !value.IsEqual(expected) {
+		t.Errorf("Wrong reference value. Expected %+v, got %+v, err: %v", expected, value, err)
+	}
+	if _, err := virtualMachine.GetReferenceAddress(reference(t, "cast(fp + 1, felt*)", parser.ApTrackingData{}), parser.ApTrackingData{}); err == nil {
+		t.Errorf("GetReferenceAddress should fail for references not stored in memory")
+	}
+}
+
+func TestGetReferenceAddress(t *testing.T) {
+	virtualMachine := referencesVM(t)
+	addr, err := virtualMachine.GetReferenceAddress(reference(t, "[cast(fp + (-2), felt*)]", parser.ApTrackingData{}), parser.ApTrackingData{})
+	if err != nil || addr != memory.NewRelocatable(1, 0) {
+		t.Errorf("Wrong reference address, got %+v, err: %v", addr, err)
+	}
+}
@@ -0,0 +1,185 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+)
+
+// Relocated address of the program segment, which is always the first one
+const RelocatedProgramBase = 1
+
+// Returned when a relocated trace doesn't match the execution of its program
+type TraceVerificationError struct {
+	Step uint
+	Err  error
+}
+
+func (e *TraceVerificationError) Error() string {
+	return fmt.Sprintf("invalid transition at step %d: %s", e.Step, e.Err)
+}
+
+func (e *TraceVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Checks that a relocated trace and memory are a valid execution of the program: the program is
+// loaded at RelocatedProgramBase, and every transition of the trace follows the semantics of the
+// instruction at its pc, with the asserted memory values holding. The instruction of the last
+// entry is checked too, but not the state following it, as it isn't part of the trace.
+func VerifyTrace(program Program, trace []RelocatedTraceEntry, relocatedMemory []*lambdaworks.Felt) error {
+	replay := traceReplay{memory: relocatedMemory}
+	for i, word := range program.Data {
+		expected, ok := word.GetFelt()
+		if !ok {
+			return fmt.Errorf("program data at offset %d is not a felt", i)
+		}
+		value, err := replay.get(lambdaworks.FeltFromUint64(uint64(RelocatedProgramBase + i)))
+		if err != nil || value != expected {
+			return fmt.Errorf("program data at offset %d doesn't match the memory", i)
+		}
+	}
+	for i := range trace {
+		next, err := replay.transition(trace[i])
+		if err == nil && i+1 < len(trace) && next != trace[i+1] {
+			err = fmt.Errorf("expected pc=%s ap=%s fp=%s, trace has pc=%s ap=%s fp=%s",
+				next.Pc.String(), next.Ap.String(), next.Fp.String(), trace[i+1].Pc.String(), trace[i+1].Ap.String(), trace[i+1].Fp.String())
+		}
+		if err != nil {
+			return &TraceVerificationError{Step: uint(i), Err: err}
+		}
+	}
+	return nil
+}
+
+// Returns the number of times each relocated address was read by the instructions of the trace,
+// counting the instruction fetch and the dst, op0 and op1 operands of every step
+func AccessCounts(trace []RelocatedTraceEntry, relocatedMemory []*lambdaworks.Felt) (map[uint64]uint, error) {
+	replay := traceReplay{memory: relocatedMemory, accesses: make(map[uint64]uint)}
+	for i, entry := range trace {
+		if _, err := replay.transition(entry); err != nil {
+			return nil, &TraceVerificationError{Step: uint(i), Err: err}
+		}
+	}
+	return replay.accesses, nil
+}
+
+// Runs instructions over a relocated memory
+type traceReplay struct {
+	memory []*lambdaworks.Felt
+	// Number of reads of each address, only counted if not nil
+	accesses map[uint64]uint
+}
+
+func (r *traceReplay) get(addr lambdaworks.Felt) (lambdaworks.Felt, error) {
+	index, err := addr.ToU64()
+	if err != nil || index >= uint64(len(r.memory)) || r.memory[index] == nil {
+		return lambdaworks.Felt{}, fmt.Errorf("memory cell %s is empty", addr.String())
+	}
+	if r.accesses != nil {
+		r.accesses[index]++
+	}
+	return *r.memory[index], nil
+}
+
+// Returns the state following entry, checking the assertions of the instruction
+func (r *traceReplay) transition(entry RelocatedTraceEntry) (RelocatedTraceEntry, error) {
+	encoded, err := r.get(entry.Pc)
+	if err != nil {
+		return RelocatedTraceEntry{}, err
+	}
+	instruction, err := DecodeInstructionFelt(encoded)
+	if err != nil {
+		return RelocatedTraceEntry{}, err
+	}
+	if err := checkOpcodeExtension(&instruction); err != nil {
+		return RelocatedTraceEntry{}, err
+	}
+	register := func(reg Register) lambdaworks.Felt {
+		if reg == AP {
+			return entry.Ap
+		}
+		return entry.Fp
+	}
+
+	dst, err := r.get(register(instruction.DstReg).Add(feltFromInt(instruction.Off0)))
+	if err != nil {
+		return RelocatedTraceEntry{}, fmt.Errorf("dst: %w", err)
+	}
+	op0, err := r.get(register(instruction.Op0Reg).Add(feltFromInt(instruction.Off1)))
+	if err != nil {
+		return RelocatedTraceEntry{}, fmt.Errorf("op0: %w", err)
+	}
+	var op1Base lambdaworks.Felt
+	switch instruction.Op1Addr {
+	case Op1SrcImm:
+		op1Base = entry.Pc
+	case Op1SrcAP:
+		op1Base = entry.Ap
+	case Op1SrcFP:
+		op1Base = entry.Fp
+	case Op1SrcOp0:
+		op1Base = op0
+	}
+	op1, err := r.get(op1Base.Add(feltFromInt(instruction.Off2)))
+	if err != nil {
+		return RelocatedTraceEntry{}, fmt.Errorf("op1: %w", err)
+	}
+
+	var res lambdaworks.Felt
+	switch instruction.ResLogic {
+	case ResOp1:
+		res = op1
+	case ResAdd:
+		res = op0.Add(op1)
+	case ResMul:
+		res = op0.Mul(op1)
+	}
+
+	size := lambdaworks.FeltFromUint64(uint64(instruction.Size()))
+	switch instruction.Opcode {
+	case AssertEq:
+		if dst != res {
+			return RelocatedTraceEntry{}, fmt.Errorf("assert_eq failed: dst=%s, res=%s", dst.String(), res.String())
+		}
+	case Call:
+		if dst != entry.Fp {
+			return RelocatedTraceEntry{}, errors.New("call didn't save fp at [ap]")
+		}
+		if op0 != entry.Pc.Add(size) {
+			return RelocatedTraceEntry{}, errors.New("call didn't save the return pc at [ap + 1]")
+		}
+	}
+
+	next := entry
+	switch instruction.PcUpdate {
+	case PcUpdateRegular:
+		next.Pc = entry.Pc.Add(size)
+	case PcUpdateJump:
+		next.Pc = res
+	case PcUpdateJumpRel:
+		next.Pc = entry.Pc.Add(res)
+	case PcUpdateJnz:
+		if dst.IsZero() {
+			next.Pc = entry.Pc.Add(size)
+		} else {
+			next.Pc = entry.Pc.Add(op1)
+		}
+	}
+	switch instruction.ApUpdate {
+	case ApUpdateAdd:
+		next.Ap = entry.Ap.Add(res)
+	case ApUpdateAdd1:
+		next.Ap = entry.Ap.Add(lambdaworks.FeltOne())
+	case ApUpdateAdd2:
+		next.Ap = entry.Ap.Add(lambdaworks.FeltTwo())
+	}
+	switch instruction.FpUpdate {
+	case FpUpdateAPPlus2:
+		next.Fp = entry.Ap.Add(lambdaworks.FeltTwo())
+	case FpUpdateDst:
+		next.Fp = dst
+	}
+	return next, nil
+}
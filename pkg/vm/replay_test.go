@@ -0,0 +1,116 @@
+package vm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func relocatedEntry(pc uint64, ap uint64, fp uint64) vm.RelocatedTraceEntry {
+	return vm.RelocatedTraceEntry{Pc: lambdaworks.FeltFromUint64(pc), Ap: lambdaworks.FeltFromUint64(ap), Fp: lambdaworks.FeltFromUint64(fp)}
+}
+
+func relocatedMemory(values ...int64) []*lambdaworks.Felt {
+	cells := []*lambdaworks.Felt{nil}
+	for _, value := range values {
+		if value < 0 {
+			cells = append(cells, nil)
+			continue
+		}
+		felt := lambdaworks.FeltFromUint64(uint64(value))
+		cells = append(cells, &felt)
+	}
+	return cells
+}
+
+// Program running call rel 3; ret; [ap] = 5; ap++; ret
+func replayProgram() vm.Program {
+	words := []uint64{0x1104800180018000, 3, 0x208b7fff7fff7ffe, 0x480680017fff8000, 5, 0x208b7fff7fff7ffe}
+	var program vm.Program
+	for _, word := range words {
+		program.Data = append(program.Data, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(word)))
+	}
+	return program
+}
+
+// Memory of replayProgram run with fp = ap = 9: [7] and [8] hold the dummy return frame
+func replayMemory(result int64) []*lambdaworks.Felt {
+	return relocatedMemory(0x1104800180018000, 3, 0x208b7fff7fff7ffe, 0x480680017fff8000, 5, 0x208b7fff7fff7ffe, 0, 0, 9, 3, result)
+}
+
+func replayTrace() []vm.RelocatedTraceEntry {
+	return []vm.RelocatedTraceEntry{relocatedEntry(1, 9, 9), relocatedEntry(4, 11, 11), relocatedEntry(6, 12, 11), relocatedEntry(3, 12, 9)}
+}
+
+func TestVerifyTrace(t *testing.T) {
+	if err := vm.VerifyTrace(replayProgram(), replayTrace(), replayMemory(5)); err != nil {
+		t.Errorf("VerifyTrace failed with error: %s", err)
+	}
+}
+
+func TestVerifyTraceFailedAssertion(t *testing.T) {
+	err := vm.VerifyTrace(replayProgram(), replayTrace(), replayMemory(6))
+	var verificationError *vm.TraceVerificationError
+	if !errors.As(err, &verificationError) || verificationError.Step != 1 {
+		t.Errorf("VerifyTrace should fail at step 1, got: %v", err)
+	}
+}
+
+func TestVerifyTraceWrongRegisters(t *testing.T) {
+	trace := replayTrace()
+	trace[2] = relocatedEntry(6, 12, 12)
+	err := vm.VerifyTrace(replayProgram(), trace, replayMemory(5))
+	var verificationError *vm.TraceVerificationError
+	if !errors.As(err, &verificationError) || verificationError.Step != 1 {
+		t.Errorf("VerifyTrace should fail at step 1, got: %v", err)
+	}
+}
+
+func TestVerifyTraceEmptyOperand(t *testing.T) {
+	memory := replayMemory(5)
+	memory[10] = nil
+	if err := vm.VerifyTrace(replayProgram(), replayTrace(), memory); err == nil {
+		t.Errorf("VerifyTrace should fail with the return pc missing from memory")
+	}
+}
+
+func TestVerifyTraceLastStepOperand(t *testing.T) {
+	// The final ret reads its return pc from [8]
+	memory := replayMemory(5)
+	memory[8] = nil
+	err := vm.VerifyTrace(replayProgram(), replayTrace(), memory)
+	var verificationError *vm.TraceVerificationError
+	if !errors.As(err, &verificationError) || verificationError.Step != 3 {
+		t.Errorf("VerifyTrace should fail at step 3, got: %v", err)
+	}
+}
+
+func TestVerifyTraceProgramMismatch(t *testing.T) {
+	memory := replayMemory(5)
+	memory[5] = memory[2]
+	err := vm.VerifyTrace(replayProgram(), replayTrace(), memory)
+	var verificationError *vm.TraceVerificationError
+	if err == nil || errors.As(err, &verificationError) {
+		t.Errorf("VerifyTrace should fail before replaying the trace, got: %v", err)
+	}
+}
+
+func TestAccessCounts(t *testing.T) {
+	counts, err := vm.AccessCounts(replayTrace()[:3], replayMemory(5))
+	if err != nil {
+		t.Fatalf("AccessCounts failed with error: %s", err)
+	}
+	// call reads [1], [9], [10], [2]; the assertion reads [4], [11], [10], [5]; ret reads [6], [9], and [10] twice
+	expected := map[uint64]uint{1: 1, 2: 1, 4: 1, 5: 1, 6: 1, 9: 2, 10: 4, 11: 1}
+	if len(counts) != len(expected) {
+		t.Errorf("Wrong access counts. Expected %v, got %v", expected, counts)
+	}
+	for address, count := range expected {
+		if counts[address] != count {
+			t.Errorf("Wrong access count for address %d. Expected %d, got %d", address, count, counts[address])
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 )
 
@@ -13,6 +14,27 @@ type RunContext struct {
 	Pc memory.Relocatable
 	Ap memory.Relocatable
 	Fp memory.Relocatable
+	// Registers the run started with and the pc it should end at, set by the runner
+	InitialAp memory.Relocatable
+	InitialFp memory.Relocatable
+	FinalPc   memory.Relocatable
+}
+
+// Number of cells of the dummy frame proof mode runs start with
+const ProofModeFrameSize = 2
+
+// Prefixes the stack of a proof mode run, to be written at executionBase, with a dummy frame: its
+// fp points right after the frame and its return pc is 0. The initial ap and fp are set right
+// after the frame, as the program starts from __start__ with that frame as its own
+func (run_context *RunContext) InitializeProofModeFrame(executionBase memory.Relocatable, stack []memory.MaybeRelocatable) []memory.MaybeRelocatable {
+	frameEnd := executionBase
+	frameEnd.Offset += ProofModeFrameSize
+	prefixed := make([]memory.MaybeRelocatable, 0, ProofModeFrameSize+len(stack))
+	prefixed = append(prefixed, *memory.NewMaybeRelocatableRelocatable(frameEnd), *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()))
+	prefixed = append(prefixed, stack...)
+	run_context.InitialFp = frameEnd
+	run_context.InitialAp = frameEnd
+	return prefixed
 }
 
 func (run_context RunContext) ComputeDstAddr(instruction Instruction) (memory.Relocatable, error) {
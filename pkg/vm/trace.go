@@ -1,6 +1,12 @@
 package vm
 
 import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 )
@@ -16,3 +22,80 @@ type RelocatedTraceEntry struct {
 	Ap lambdaworks.Felt
 	Fp lambdaworks.Felt
 }
+
+// Writes the trace in the cairo-lang binary format: each entry is encoded as three
+// little-endian u64 values, ap, fp and pc, in this order.
+func EncodeTrace(relocatedTrace []RelocatedTraceEntry, dest io.Writer) error {
+	var buffer [24]byte
+	for i, entry := range relocatedTrace {
+		for j, register := range []lambdaworks.Felt{entry.Ap, entry.Fp, entry.Pc} {
+			value, err := register.ToU64()
+			if err != nil {
+				return fmt.Errorf("failed to encode trace at position %d: %w", i, err)
+			}
+			binary.LittleEndian.PutUint64(buffer[8*j:], value)
+		}
+		if _, err := dest.Write(buffer[:]); err != nil {
+			return fmt.Errorf("failed to encode trace at position %d, serialize error: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// Writes the relocated trace in the cairo-lang binary format. The VM must have been relocated.
+// A spilled trace is relocated as it is read back from its file
+func (v *VirtualMachine) WriteEncodedTrace(dest io.Writer) error {
+	if v.TraceSpill != nil {
+		return v.writeSpilledTrace(dest)
+	}
+	relocatedTrace, err := v.GetRelocatedTrace()
+	if err != nil {
+		return err
+	}
+	return EncodeTrace(relocatedTrace, dest)
+}
+
+func (v *VirtualMachine) writeSpilledTrace(dest io.Writer) error {
+	if v.traceRelocationTable == nil {
+		return errors.New("trace not relocated")
+	}
+	writer := bufio.NewWriter(dest)
+	var buffer [24]byte
+	var writeErr error
+	err := v.TraceSpill.Range(func(i uint, entry TraceEntry) bool {
+		for j, register := range [3]memory.Relocatable{entry.Ap, entry.Fp, entry.Pc} {
+			binary.LittleEndian.PutUint64(buffer[8*j:], uint64(register.RelocateAddress(&v.traceRelocationTable)))
+		}
+		if _, writeErr = writer.Write(buffer[:]); writeErr != nil {
+			writeErr = fmt.Errorf("failed to encode trace at position %d, serialize error: %s", i, writeErr)
+		}
+		return writeErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return writer.Flush()
+}
+
+// Reads a trace written in the cairo-lang binary format, the inverse of EncodeTrace
+func DecodeTrace(src io.Reader) ([]RelocatedTraceEntry, error) {
+	relocatedTrace := make([]RelocatedTraceEntry, 0)
+	var buffer [24]byte
+	for {
+		n, err := io.ReadFull(src, buffer[:])
+		if err == io.EOF {
+			return relocatedTrace, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode trace at position %d: truncated entry of %d bytes", len(relocatedTrace), n)
+		}
+		relocatedTrace = append(relocatedTrace, RelocatedTraceEntry{
+			Ap: lambdaworks.FeltFromUint64(binary.LittleEndian.Uint64(buffer[0:8])),
+			Fp: lambdaworks.FeltFromUint64(binary.LittleEndian.Uint64(buffer[8:16])),
+			Pc: lambdaworks.FeltFromUint64(binary.LittleEndian.Uint64(buffer[16:24])),
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// Size of a spilled entry: the segment index and offset of pc, ap and fp, as u64 values
+const spilledEntrySize = 48
+
+// A trace kept in a file instead of in memory, for runs whose trace doesn't fit in RAM.
+// Entries are appended as they are executed and relocated when the trace is written out,
+// the file is removed by Close
+type TraceSpill struct {
+	file   *os.File
+	writer *bufio.Writer
+	len    uint
+	// The ends of the trace are kept in memory for the AIR public input
+	first  TraceEntry
+	last   TraceEntry
+	buffer [spilledEntrySize]byte
+}
+
+// Creates a trace spill in a new file of dir, the default directory for temporary files if empty
+func NewTraceSpill(dir string) (*TraceSpill, error) {
+	file, err := os.CreateTemp(dir, "cairo-trace-*")
+	if err != nil {
+		return nil, err
+	}
+	return &TraceSpill{file: file, writer: bufio.NewWriterSize(file, 1<<20)}, nil
+}
+
+// Returns the number of entries in the trace
+func (s *TraceSpill) Len() uint {
+	return s.len
+}
+
+// Returns the path of the file holding the trace
+func (s *TraceSpill) Path() string {
+	return s.file.Name()
+}
+
+func (s *TraceSpill) Append(entry TraceEntry) error {
+	for i, register := range [3]memory.Relocatable{entry.Pc, entry.Ap, entry.Fp} {
+		binary.LittleEndian.PutUint64(s.buffer[16*i:], uint64(register.SegmentIndex))
+		binary.LittleEndian.PutUint64(s.buffer[16*i+8:], uint64(register.Offset))
+	}
+	if _, err := s.writer.Write(s.buffer[:]); err != nil {
+		return fmt.Errorf("failed to spill trace entry %d: %w", s.len, err)
+	}
+	if s.len == 0 {
+		s.first = entry
+	}
+	s.last = entry
+	s.len++
+	return nil
+}
+
+// Calls fn for every entry of the trace, in execution order.
+// Iteration stops early if fn returns false
+func (s *TraceSpill) Range(fn func(i uint, entry TraceEntry) bool) error {
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	// Read through a section so that appending afterwards still writes at the end of the file
+	reader := bufio.NewReaderSize(io.NewSectionReader(s.file, 0, int64(s.len)*spilledEntrySize), 1<<20)
+	var buffer [spilledEntrySize]byte
+	for i := uint(0); i < s.len; i++ {
+		if _, err := io.ReadFull(reader, buffer[:]); err != nil {
+			return fmt.Errorf("failed to read spilled trace entry %d: %w", i, err)
+		}
+		var registers [3]memory.Relocatable
+		for j := range registers {
+			registers[j] = memory.NewRelocatable(int(int64(binary.LittleEndian.Uint64(buffer[16*j:]))), uint(binary.LittleEndian.Uint64(buffer[16*j+8:])))
+		}
+		if !fn(i, TraceEntry{Pc: registers[0], Ap: registers[1], Fp: registers[2]}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Closes and removes the file holding the trace
+func (s *TraceSpill) Close() error {
+	err := s.file.Close()
+	if removeErr := os.Remove(s.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package vm_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestTraceSpillRoundTrip(t *testing.T) {
+	spill, err := vm.NewTraceSpill(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewTraceSpill failed with error: %s", err)
+	}
+	defer spill.Close()
+	entries := []vm.TraceEntry{
+		{Pc: memory.NewRelocatable(0, 0), Ap: memory.NewRelocatable(1, 2), Fp: memory.NewRelocatable(1, 2)},
+		{Pc: memory.NewRelocatable(4, 7), Ap: memory.NewRelocatable(1, 1<<40), Fp: memory.NewRelocatable(-1, 3)},
+	}
+	for _, entry := range entries {
+		if err := spill.Append(entry); err != nil {
+			t.Fatalf("Append failed with error: %s", err)
+		}
+	}
+	read := []vm.TraceEntry{}
+	if err := spill.Range(func(i uint, entry vm.TraceEntry) bool { read = append(read, entry); return true }); err != nil {
+		t.Fatalf("Range failed with error: %s", err)
+	}
+	if spill.Len() != 2 || !reflect.DeepEqual(read, entries) {
+		t.Errorf("Expected %v, got %v", entries, read)
+	}
+
+	// Entries can be appended after reading the trace back
+	spill.Append(entries[0])
+	read = read[:0]
+	spill.Range(func(i uint, entry vm.TraceEntry) bool { read = append(read, entry); return i == 0 })
+	if spill.Len() != 3 || !reflect.DeepEqual(read, entries) {
+		t.Errorf("Expected the iteration to stop after %v, got %v", entries, read)
+	}
+}
+
+func TestTraceSpillCloseRemovesFile(t *testing.T) {
+	spill, err := vm.NewTraceSpill(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewTraceSpill failed with error: %s", err)
+	}
+	if err := spill.Close(); err != nil {
+		t.Fatalf("Close failed with error: %s", err)
+	}
+	if _, err := os.Stat(spill.Path()); !os.IsNotExist(err) {
+		t.Errorf("The spill file should be removed, got %v", err)
+	}
+}
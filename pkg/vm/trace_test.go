@@ -0,0 +1,65 @@
+package vm_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+)
+
+func TestEncodeTrace(t *testing.T) {
+	trace := []vm.RelocatedTraceEntry{
+		{Pc: lambdaworks.FeltFromUint64(1), Ap: lambdaworks.FeltFromUint64(2), Fp: lambdaworks.FeltFromUint64(3)},
+		{Pc: lambdaworks.FeltFromUint64(0x0102), Ap: lambdaworks.FeltFromUint64(5), Fp: lambdaworks.FeltFromUint64(6)},
+	}
+	expected := []byte{
+		2, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0,
+		5, 0, 0, 0, 0, 0, 0, 0, 6, 0, 0, 0, 0, 0, 0, 0, 2, 1, 0, 0, 0, 0, 0, 0,
+	}
+	var buffer bytes.Buffer
+	if err := vm.EncodeTrace(trace, &buffer); err != nil {
+		t.Fatalf("EncodeTrace failed with error: %s", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), expected) {
+		t.Errorf("Wrong encoded trace. Expected %v, got %v", expected, buffer.Bytes())
+	}
+}
+
+func TestEncodeTraceValueTooBig(t *testing.T) {
+	trace := []vm.RelocatedTraceEntry{{Pc: lambdaworks.FeltMax(), Ap: lambdaworks.FeltOne(), Fp: lambdaworks.FeltOne()}}
+	var buffer bytes.Buffer
+	if err := vm.EncodeTrace(trace, &buffer); err == nil {
+		t.Errorf("EncodeTrace should fail with a register that doesn't fit in a u64")
+	}
+}
+
+func TestWriteEncodedTraceNotRelocated(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	var buffer bytes.Buffer
+	if err := virtualMachine.WriteEncodedTrace(&buffer); err == nil {
+		t.Errorf("WriteEncodedTrace should fail if the trace wasn't relocated")
+	}
+}
+
+func TestDecodeTraceRoundTrip(t *testing.T) {
+	trace := []vm.RelocatedTraceEntry{
+		{Pc: lambdaworks.FeltFromUint64(1), Ap: lambdaworks.FeltFromUint64(2), Fp: lambdaworks.FeltFromUint64(3)},
+		{Pc: lambdaworks.FeltFromUint64(0x0102), Ap: lambdaworks.FeltFromUint64(5), Fp: lambdaworks.FeltFromUint64(6)},
+	}
+	var buffer bytes.Buffer
+	if err := vm.EncodeTrace(trace, &buffer); err != nil {
+		t.Fatalf("EncodeTrace failed with error: %s", err)
+	}
+	decoded, err := vm.DecodeTrace(&buffer)
+	if err != nil || !reflect.DeepEqual(decoded, trace) {
+		t.Errorf("Wrong decoded trace. Expected %v, got %v, err: %v", trace, decoded, err)
+	}
+}
+
+func TestDecodeTraceTruncated(t *testing.T) {
+	if _, err := vm.DecodeTrace(bytes.NewReader(make([]byte, 30))); err == nil {
+		t.Errorf("DecodeTrace should fail on a truncated entry")
+	}
+}
@@ -1,11 +1,15 @@
 package vm
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parallel"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
 )
 
@@ -26,7 +30,51 @@ type VirtualMachine struct {
 	BuiltinRunners  []builtins.BuiltinRunner
 	Trace           []TraceEntry
 	RelocatedTrace  []RelocatedTraceEntry
-	RelocatedMemory map[uint]lambdaworks.Felt
+	RelocatedMemory []*lambdaworks.Felt
+	Hooks           Hooks
+	// When set, trace entries are spilled to this file instead of being kept in Trace
+	TraceSpill *TraceSpill
+	// Relocation table of a spilled trace, which is relocated as it is written out
+	traceRelocationTable []uint
+	// Instructions of the program loaded at programBase, see LoadDecodedProgram
+	decodedProgram DecodedProgram
+	programBase    memory.Relocatable
+	// Computed by the first call to GetRelocationTable
+	relocationTable []uint
+	// Smallest and largest biased offsets of the instructions run so far, see RangeCheckLimits
+	rcMin, rcMax int
+	rcLimitsSet  bool
+}
+
+// Functions called around the execution of each instruction, nil hooks are skipped.
+// An error returned by a hook aborts the step
+type Hooks struct {
+	PreStep  func(v *VirtualMachine) error
+	PostStep func(v *VirtualMachine) error
+}
+
+// Runs a hint of the program. index is the position of the hint among all the hints of the
+// program, see Program.HintIndexes
+type HintFunc func(v *VirtualMachine, index uint, hint *parser.HintParams) error
+
+// Returns a PreStep hook running the hints of the instruction about to run through run, in the
+// order they are declared at their pc. Only hints of the program segment run, the program must
+// not be modified afterwards
+func HintsPreStep(program *Program, run HintFunc) func(v *VirtualMachine) error {
+	indexes := program.HintIndexes()
+	return func(v *VirtualMachine) error {
+		pc := v.RunContext.Pc
+		if pc.SegmentIndex != 0 {
+			return nil
+		}
+		hints := program.Hints[pc.Offset]
+		for i := range hints {
+			if err := run(v, indexes[pc.Offset]+uint(i), &hints[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 }
 
 func NewVirtualMachine() *VirtualMachine {
@@ -38,22 +86,62 @@ func NewVirtualMachine() *VirtualMachine {
 }
 
 func (v *VirtualMachine) Step() error {
-	encoded_instruction, err := v.Segments.Memory.Get(v.RunContext.Pc)
-	if err != nil {
-		return fmt.Errorf("Failed to fetch instruction at %+v", v.RunContext.Pc)
+	if v.Hooks.PreStep != nil {
+		if err := v.Hooks.PreStep(v); err != nil {
+			return err
+		}
+	}
+	if err := v.step(); err != nil {
+		return err
+	}
+	if v.Hooks.PostStep != nil {
+		return v.Hooks.PostStep(v)
+	}
+	return nil
+}
+
+// Makes the steps running the program loaded at base use its instructions decoded ahead of time,
+// instead of decoding them from memory. Fails if they don't match the instructions in memory.
+// The program segment becomes executable, but memory is write-once, so code written to it can only
+// fill its empty cells, past the decoded instructions, and is decoded from memory when it runs
+func (v *VirtualMachine) LoadDecodedProgram(base memory.Relocatable, program DecodedProgram) error {
+	for offset := uint(0); offset < program.Len(); offset++ {
+		instruction, ok := program.Get(offset)
+		if !ok {
+			continue
+		}
+		addr := memory.NewRelocatable(base.SegmentIndex, base.Offset+offset)
+		felt, err := v.Segments.Memory.GetFelt(addr)
+		if err != nil {
+			return fmt.Errorf("Decoded program doesn't match the memory at %+v: %w", addr, err)
+		}
+		decoded, err := DecodeInstructionFelt(felt)
+		if err != nil || decoded != *instruction {
+			return fmt.Errorf("Decoded program doesn't match the memory at %+v", addr)
+		}
 	}
+	v.programBase = base
+	v.decodedProgram = program
+	v.Segments.Memory.MarkExecutable(base.SegmentIndex)
+	return nil
+}
 
-	encoded_instruction_felt, ok := encoded_instruction.GetFelt()
-	if !ok {
-		return errors.New("Wrong instruction encoding")
+func (v *VirtualMachine) step() error {
+	pc := v.RunContext.Pc
+	if pc.SegmentIndex == v.programBase.SegmentIndex && pc.Offset >= v.programBase.Offset {
+		if instruction, ok := v.decodedProgram.Get(pc.Offset - v.programBase.Offset); ok {
+			return v.runInstruction(instruction)
+		}
 	}
 
-	encoded_instruction_uint, err := encoded_instruction_felt.ToU64()
+	encoded_instruction_felt, err := v.Segments.Memory.GetFelt(v.RunContext.Pc)
 	if err != nil {
-		return err
+		return fmt.Errorf("Failed to fetch instruction at %+v: %w", v.RunContext.Pc, err)
 	}
+	// Code loaded at runtime, e.g. by a bootloader, can't be modified once it runs either
+	v.Segments.Memory.MarkExecutable(pc.SegmentIndex)
 
-	instruction, err := DecodeInstruction(encoded_instruction_uint)
+	instruction, err := DecodeInstructionFelt(encoded_instruction_felt)
 	if err != nil {
 		return err
 	}
@@ -62,6 +150,46 @@ func (v *VirtualMachine) Step() error {
 }
 
 func (v *VirtualMachine) RunInstruction(instruction *Instruction) error {
+	if err := instruction.Validate(); err != nil {
+		return err
+	}
+	return v.runInstruction(instruction)
+}
+
+// The prover range checks the offsets of every instruction run, biased into [0, 2^16)
+func (v *VirtualMachine) updateRangeCheckLimits(instruction *Instruction) {
+	for _, offset := range [3]int{instruction.Off0, instruction.Off1, instruction.Off2} {
+		offset += OffsetBias
+		if !v.rcLimitsSet || offset < v.rcMin {
+			v.rcMin = offset
+		}
+		if !v.rcLimitsSet || offset > v.rcMax {
+			v.rcMax = offset
+		}
+		v.rcLimitsSet = true
+	}
+}
+
+// Returns the smallest and largest biased offsets of the instructions run so far, ok is false if
+// no instruction ran yet
+func (v *VirtualMachine) RangeCheckLimits() (rcMin int, rcMax int, ok bool) {
+	return v.rcMin, v.rcMax, v.rcLimitsSet
+}
+
+// Only the original instruction set can run, blake and QM31 opcodes aren't implemented yet
+func checkOpcodeExtension(instruction *Instruction) error {
+	if instruction.OpcodeExtension != OpcodeExtensionStone {
+		return fmt.Errorf("Opcode extension %s is not supported yet", instruction.OpcodeExtension)
+	}
+	return nil
+}
+
+// Runs an already validated instruction
+func (v *VirtualMachine) runInstruction(instruction *Instruction) error {
+	if err := checkOpcodeExtension(instruction); err != nil {
+		return err
+	}
+	v.updateRangeCheckLimits(instruction)
 	operands, err := v.ComputeOperands(*instruction)
 	if err != nil {
 		return err
@@ -72,7 +200,14 @@ func (v *VirtualMachine) RunInstruction(instruction *Instruction) error {
 		return err
 	}
 
-	v.Trace = append(v.Trace, TraceEntry{Pc: v.RunContext.Pc, Ap: v.RunContext.Ap, Fp: v.RunContext.Fp})
+	entry := TraceEntry{Pc: v.RunContext.Pc, Ap: v.RunContext.Ap, Fp: v.RunContext.Fp}
+	if v.TraceSpill != nil {
+		if err := v.TraceSpill.Append(entry); err != nil {
+			return err
+		}
+	} else {
+		v.Trace = append(v.Trace, entry)
+	}
 
 	err = v.UpdateRegisters(instruction, &operands)
 	if err != nil {
@@ -83,24 +218,45 @@ func (v *VirtualMachine) RunInstruction(instruction *Instruction) error {
 	return nil
 }
 
-// Relocates the VM's trace, turning relocatable registers to numbered ones
+// Relocates the VM's trace, turning relocatable registers to numbered ones, in parallel.
+// A spilled trace is only relocated as it is written out
 func (v *VirtualMachine) RelocateTrace(relocationTable *[]uint) error {
 	if len(*relocationTable) < 2 {
 		return errors.New("no relocation found for execution segment")
 	}
-
-	for _, entry := range v.Trace {
-		v.RelocatedTrace = append(v.RelocatedTrace, RelocatedTraceEntry{
-			Pc: lambdaworks.FeltFromUint64(uint64(entry.Pc.RelocateAddress(relocationTable))),
-			Ap: lambdaworks.FeltFromUint64(uint64(entry.Ap.RelocateAddress(relocationTable))),
-			Fp: lambdaworks.FeltFromUint64(uint64(entry.Fp.RelocateAddress(relocationTable))),
-		})
+	if v.TraceSpill != nil {
+		v.traceRelocationTable = append([]uint(nil), *relocationTable...)
+		return nil
 	}
 
+	// Entries are relocated in parallel, each chunk writing to its own part of the relocated trace
+	v.RelocatedTrace = make([]RelocatedTraceEntry, len(v.Trace))
+	parallel.Chunks(len(v.Trace), relocationChunkSize, func(start int, end int) {
+		for i := start; i < end; i++ {
+			v.RelocatedTrace[i] = relocateTraceEntry(v.Trace[i], relocationTable)
+		}
+	})
+
 	return nil
 }
 
+// Number of trace entries relocated by each worker at a time
+const relocationChunkSize = 1 << 16
+
+func relocateTraceEntry(entry TraceEntry, relocationTable *[]uint) RelocatedTraceEntry {
+	return RelocatedTraceEntry{
+		Pc: lambdaworks.FeltFromUint64(uint64(entry.Pc.RelocateAddress(relocationTable))),
+		Ap: lambdaworks.FeltFromUint64(uint64(entry.Ap.RelocateAddress(relocationTable))),
+		Fp: lambdaworks.FeltFromUint64(uint64(entry.Fp.RelocateAddress(relocationTable))),
+	}
+}
+
+// Returns the relocated trace. Spilled traces are never relocated in memory, they can only be
+// written out with WriteEncodedTrace
 func (v *VirtualMachine) GetRelocatedTrace() ([]RelocatedTraceEntry, error) {
+	if v.TraceSpill != nil {
+		return nil, errors.New("trace spilled to disk, it can only be written out")
+	}
 	if len(v.RelocatedTrace) > 0 {
 		return v.RelocatedTrace, nil
 	} else {
@@ -108,16 +264,52 @@ func (v *VirtualMachine) GetRelocatedTrace() ([]RelocatedTraceEntry, error) {
 	}
 }
 
+// Returns the first and last entries of the relocated trace, spilled or not
+func (v *VirtualMachine) GetRelocatedTraceEnds() (RelocatedTraceEntry, RelocatedTraceEntry, error) {
+	if v.TraceSpill == nil {
+		trace, err := v.GetRelocatedTrace()
+		if err != nil {
+			return RelocatedTraceEntry{}, RelocatedTraceEntry{}, err
+		}
+		return trace[0], trace[len(trace)-1], nil
+	}
+	if v.traceRelocationTable == nil || v.TraceSpill.Len() == 0 {
+		return RelocatedTraceEntry{}, RelocatedTraceEntry{}, errors.New("trace not relocated")
+	}
+	return relocateTraceEntry(v.TraceSpill.first, &v.traceRelocationTable), relocateTraceEntry(v.TraceSpill.last, &v.traceRelocationTable), nil
+}
+
+// Returns the number of entries in the trace, spilled or not
+func (v *VirtualMachine) TraceLen() uint {
+	if v.TraceSpill != nil {
+		return v.TraceSpill.Len()
+	}
+	return uint(len(v.Trace))
+}
+
+// Calls fn for every entry of the trace, spilled or not, in execution order.
+// Iteration stops early if fn returns false
+func (v *VirtualMachine) RangeTrace(fn func(i uint, entry TraceEntry) bool) error {
+	if v.TraceSpill != nil {
+		return v.TraceSpill.Range(fn)
+	}
+	for i, entry := range v.Trace {
+		if !fn(uint(i), entry) {
+			break
+		}
+	}
+	return nil
+}
+
 func (v *VirtualMachine) Relocate() error {
 	v.Segments.ComputeEffectiveSizes()
-	if len(v.Trace) == 0 {
+	if v.TraceLen() == 0 {
 		return nil
 	}
 
-	relocationTable, ok := v.Segments.RelocateSegments()
-	// This should be unreachable
-	if !ok {
-		return errors.New("ComputeEffectiveSizes called but RelocateSegments still returned error")
+	relocationTable, err := v.GetRelocationTable()
+	if err != nil {
+		return err
 	}
 
 	relocatedMemory, err := v.Segments.RelocateMemory(&relocationTable)
@@ -130,9 +322,26 @@ func (v *VirtualMachine) Relocate() error {
 	return nil
 }
 
+// Returns the first relocated address of each segment. The table is computed from the segment
+// sizes on the first call and kept for the next ones, so that every relocation agrees on it. It
+// must only be called once the run is finished, as later writes don't change it
+func (v *VirtualMachine) GetRelocationTable() ([]uint, error) {
+	if v.relocationTable == nil {
+		v.Segments.ComputeEffectiveSizes()
+		relocationTable, ok := v.Segments.RelocateSegments()
+		// This should be unreachable
+		if !ok {
+			return nil, errors.New("ComputeEffectiveSizes called but RelocateSegments still returned error")
+		}
+		v.relocationTable = relocationTable
+	}
+	return v.relocationTable, nil
+}
+
+// The operands of an instruction. Res holds no value when it is unconstrained
 type Operands struct {
 	Dst memory.MaybeRelocatable
-	Res *memory.MaybeRelocatable
+	Res memory.MaybeRelocatable
 	Op0 memory.MaybeRelocatable
 	Op1 memory.MaybeRelocatable
 }
@@ -140,20 +349,20 @@ type Operands struct {
 func (vm *VirtualMachine) OpcodeAssertions(instruction Instruction, operands Operands) error {
 	switch instruction.Opcode {
 	case AssertEq:
-		if operands.Res == nil {
+		if !operands.Res.HasValue() {
 			return &VirtualMachineError{"UnconstrainedResAssertEq"}
 		}
 		if !operands.Res.IsEqual(&operands.Dst) {
 			return &VirtualMachineError{"DiffAssertValues"}
 		}
 	case Call:
-		new_rel, err := vm.RunContext.Pc.AddUint(instruction.Size())
+		returnPC, err := vm.RunContext.Pc.AddUint(instruction.Size())
 		if err != nil {
 			return err
 		}
-		returnPC := memory.NewMaybeRelocatableRelocatable(new_rel)
-
-		if !operands.Op0.IsEqual(returnPC) {
+		// Compare the registers directly, wrapping them would allocate on every call
+		op0, ok := operands.Op0.GetRelocatable()
+		if !ok || !op0.IsEqual(&returnPC) {
 			return &VirtualMachineError{"CantWriteReturnPc"}
 		}
 
@@ -167,144 +376,148 @@ func (vm *VirtualMachine) OpcodeAssertions(instruction Instruction, operands Ope
 	return nil
 }
 
-func (vm *VirtualMachine) DeduceDst(instruction Instruction, res *memory.MaybeRelocatable) *memory.MaybeRelocatable {
+// Deduces the value of dst if possible (based on res). Otherwise, returns a MaybeRelocatable without value.
+func (vm *VirtualMachine) DeduceDst(instruction Instruction, res memory.MaybeRelocatable) memory.MaybeRelocatable {
 	switch instruction.Opcode {
 	case AssertEq:
 		return res
 	case Call:
-		return memory.NewMaybeRelocatableRelocatable(vm.RunContext.Fp)
+		return *memory.NewMaybeRelocatableRelocatable(vm.RunContext.Fp)
 
 	}
-	return nil
+	return memory.MaybeRelocatable{}
 }
 
-// Deduces the value of op0 if possible (based on dst and op1). Otherwise, returns nil.
+// Deduces the value of op0 if possible (based on dst and op1). Otherwise, returns a MaybeRelocatable without value.
 // If res is deduced in the process returns its deduced value as well.
-func (vm *VirtualMachine) DeduceOp0(instruction *Instruction, dst *memory.MaybeRelocatable, op1 *memory.MaybeRelocatable) (deduced_op0 *memory.MaybeRelocatable, deduced_res *memory.MaybeRelocatable, error error) {
+func (vm *VirtualMachine) DeduceOp0(instruction *Instruction, dst memory.MaybeRelocatable, op1 memory.MaybeRelocatable) (deduced_op0 memory.MaybeRelocatable, deduced_res memory.MaybeRelocatable, error error) {
 	switch instruction.Opcode {
 	case Call:
 		deduced_op0 := vm.RunContext.Pc
 		deduced_op0.Offset += instruction.Size()
-		return memory.NewMaybeRelocatableRelocatable(deduced_op0), nil, nil
+		return *memory.NewMaybeRelocatableRelocatable(deduced_op0), memory.MaybeRelocatable{}, nil
 	case AssertEq:
 		switch instruction.ResLogic {
 		case ResAdd:
-			if dst != nil && op1 != nil {
-				deduced_op0, err := dst.Sub(*op1)
+			if dst.HasValue() && op1.HasValue() {
+				deduced_op0, err := dst.Sub(op1)
 				if err != nil {
-					return nil, nil, err
+					return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 				}
-				return &deduced_op0, dst, nil
+				return deduced_op0, dst, nil
 			}
 		case ResMul:
-			if dst != nil && op1 != nil {
-				dst_felt, dst_is_felt := dst.GetFelt()
-				op1_felt, op1_is_felt := op1.GetFelt()
-				if dst_is_felt && op1_is_felt && !op1_felt.IsZero() {
-					return memory.NewMaybeRelocatableFelt(dst_felt.Div(op1_felt)), dst, nil
+			dst_felt, dst_is_felt := dst.GetFelt()
+			op1_felt, op1_is_felt := op1.GetFelt()
+			if dst_is_felt && op1_is_felt && !op1_felt.IsZero() {
+				return *memory.NewMaybeRelocatableFelt(dst_felt.Div(op1_felt)), dst, nil
 
-				}
 			}
 		}
 	}
-	return nil, nil, nil
+	return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, nil
 }
 
-func (vm *VirtualMachine) DeduceOp1(instruction *Instruction, dst *memory.MaybeRelocatable, op0 *memory.MaybeRelocatable) (*memory.MaybeRelocatable, *memory.MaybeRelocatable, error) {
+// Deduces the value of op1 if possible (based on dst and op0). Otherwise, returns a MaybeRelocatable without value.
+// If res is deduced in the process returns its deduced value as well.
+func (vm *VirtualMachine) DeduceOp1(instruction *Instruction, dst memory.MaybeRelocatable, op0 memory.MaybeRelocatable) (memory.MaybeRelocatable, memory.MaybeRelocatable, error) {
 	if instruction.Opcode == AssertEq {
 		switch instruction.ResLogic {
 		case ResOp1:
 			return dst, dst, nil
 		case ResAdd:
-			if op0 != nil && dst != nil {
-				dst_rel, err := dst.Sub(*op0)
+			if op0.HasValue() && dst.HasValue() {
+				dst_rel, err := dst.Sub(op0)
 				if err != nil {
-					return nil, nil, err
+					return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 				}
-				return &dst_rel, dst, nil
+				return dst_rel, dst, nil
 			}
 		case ResMul:
 			dst_felt, dst_is_felt := dst.GetFelt()
 			op0_felt, op0_is_felt := op0.GetFelt()
 			if dst_is_felt && op0_is_felt && !op0_felt.IsZero() {
-				res := memory.NewMaybeRelocatableFelt(dst_felt.Div(op0_felt))
-				return res, dst, nil
+				return *memory.NewMaybeRelocatableFelt(dst_felt.Div(op0_felt)), dst, nil
 			}
 		}
 	}
-	return nil, nil, nil
+	return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, nil
 }
 
-func (vm *VirtualMachine) ComputeRes(instruction Instruction, op0 memory.MaybeRelocatable, op1 memory.MaybeRelocatable) (*memory.MaybeRelocatable, error) {
+// Computes res from op0 and op1, returns a MaybeRelocatable without value if res is unconstrained
+func (vm *VirtualMachine) ComputeRes(instruction Instruction, op0 memory.MaybeRelocatable, op1 memory.MaybeRelocatable) (memory.MaybeRelocatable, error) {
 	switch instruction.ResLogic {
 	case ResOp1:
-		return &op1, nil
+		return op1, nil
 
 	case ResAdd:
-		maybe_rel, err := op0.Add(op1)
-		if err != nil {
-			return nil, err
-		}
-		return &maybe_rel, nil
+		return op0.Add(op1)
 
 	case ResMul:
-		num_op0, m_type := op0.GetFelt()
-		num_op1, other_type := op1.GetFelt()
-		if m_type && other_type {
-			result := memory.NewMaybeRelocatableFelt(num_op0.Mul(num_op1))
-			return result, nil
-		} else {
-			return nil, errors.New("ComputeResRelocatableMul")
+		if !op0.IsFelt() || !op1.IsFelt() {
+			return memory.MaybeRelocatable{}, errors.New("ComputeResRelocatableMul")
 		}
-
-	case ResUnconstrained:
-		return nil, nil
+		return op0.Mul(op1)
 	}
-	return nil, nil
+	return memory.MaybeRelocatable{}, nil
 }
 
+// Computes the operands of the instruction, deducing and inserting the ones missing from memory.
+// Operands are handled as values to avoid allocating on every step
 func (vm *VirtualMachine) ComputeOperands(instruction Instruction) (Operands, error) {
-	var res *memory.MaybeRelocatable
+	var res memory.MaybeRelocatable
 
 	dst_addr, err := vm.RunContext.ComputeDstAddr(instruction)
 	if err != nil {
 		return Operands{}, errors.New("FailedToComputeDstAddr")
 	}
-	dst, _ := vm.Segments.Memory.Get(dst_addr)
+	dst, err := vm.Segments.Memory.TryGetValue(dst_addr)
+	if err != nil {
+		return Operands{}, err
+	}
 
 	op0_addr, err := vm.RunContext.ComputeOp0Addr(instruction)
 	if err != nil {
 		return Operands{}, fmt.Errorf("FailedToComputeOp0Addr: %s", err)
 	}
-	op0_op, _ := vm.Segments.Memory.Get(op0_addr)
+	op0, err := vm.Segments.Memory.TryGetValue(op0_addr)
+	if err != nil {
+		return Operands{}, err
+	}
 
-	op1_addr, err := vm.RunContext.ComputeOp1Addr(instruction, op0_op)
+	var op1_addr memory.Relocatable
+	if op0.HasValue() {
+		op1_addr, err = vm.RunContext.ComputeOp1Addr(instruction, &op0)
+	} else {
+		op1_addr, err = vm.RunContext.ComputeOp1Addr(instruction, nil)
+	}
 	if err != nil {
 		return Operands{}, fmt.Errorf("FailedToComputeOp1Addr: %s", err)
 	}
-	op1_op, _ := vm.Segments.Memory.Get(op1_addr)
+	op1, err := vm.Segments.Memory.TryGetValue(op1_addr)
+	if err != nil {
+		return Operands{}, err
+	}
 
-	var op0 memory.MaybeRelocatable
-	if op0_op != nil {
-		op0 = *op0_op
-	} else {
-		op0, res, err = vm.ComputeOp0Deductions(op0_addr, &instruction, dst, op1_op)
+	if !op0.HasValue() {
+		op0, res, err = vm.ComputeOp0Deductions(op0_addr, &instruction, dst, op1)
 		if err != nil {
 			return Operands{}, err
 		}
 	}
 
-	var op1 memory.MaybeRelocatable
-	if op1_op != nil {
-		op1 = *op1_op
-	} else {
-		op1, err = vm.ComputeOp1Deductions(op1_addr, &instruction, dst, op0_op, res)
+	if !op1.HasValue() {
+		var deducedRes memory.MaybeRelocatable
+		op1, deducedRes, err = vm.ComputeOp1Deductions(op1_addr, &instruction, dst, op0)
 		if err != nil {
 			return Operands{}, err
 		}
+		if !res.HasValue() {
+			res = deducedRes
+		}
 	}
 
-	if res == nil {
+	if !res.HasValue() {
 		res, err = vm.ComputeRes(instruction, op0, op1)
 
 		if err != nil {
@@ -312,16 +525,15 @@ func (vm *VirtualMachine) ComputeOperands(instruction Instruction) (Operands, er
 		}
 	}
 
-	if dst == nil {
-		deducedDst := vm.DeduceDst(instruction, res)
-		dst = deducedDst
-		if dst != nil {
-			vm.Segments.Memory.Insert(dst_addr, dst)
+	if !dst.HasValue() {
+		dst = vm.DeduceDst(instruction, res)
+		if dst.HasValue() {
+			vm.Segments.Memory.Insert(dst_addr, &dst)
 		}
 	}
 
 	operands := Operands{
-		Dst: *dst,
+		Dst: dst,
 		Op0: op0,
 		Op1: op1,
 		Res: res,
@@ -333,50 +545,44 @@ func (vm *VirtualMachine) ComputeOperands(instruction Instruction) (Operands, er
 // Also returns res if it was also deduced in the process
 // Inserts the deduced operand
 // Fails if Op0 was not deduced or if an error arised in the process
-func (vm *VirtualMachine) ComputeOp0Deductions(op0_addr memory.Relocatable, instruction *Instruction, dst *memory.MaybeRelocatable, op1 *memory.MaybeRelocatable) (deduced_op0 memory.MaybeRelocatable, deduced_res *memory.MaybeRelocatable, err error) {
-	op0, err := vm.DeduceMemoryCell(op0_addr)
+func (vm *VirtualMachine) ComputeOp0Deductions(op0_addr memory.Relocatable, instruction *Instruction, dst memory.MaybeRelocatable, op1 memory.MaybeRelocatable) (deduced_op0 memory.MaybeRelocatable, deduced_res memory.MaybeRelocatable, err error) {
+	op0, err := vm.deduceMemoryCellValue(op0_addr)
 	if err != nil {
-		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), nil, err
+		return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 	}
-	if op0 == nil {
+	if !op0.HasValue() {
 		op0, deduced_res, err = vm.DeduceOp0(instruction, dst, op1)
 		if err != nil {
-			return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), nil, err
+			return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 		}
 	}
-	if op0 != nil {
-		vm.Segments.Memory.Insert(op0_addr, op0)
-	} else {
-		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), nil, errors.New("Failed to compute or deduce op0")
+	if !op0.HasValue() {
+		return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, errors.New("Failed to compute or deduce op0")
 	}
-	return *op0, deduced_res, nil
+	vm.Segments.Memory.Insert(op0_addr, &op0)
+	return op0, deduced_res, nil
 }
 
 // Runs deductions for Op1, first runs builtin deductions, if this fails, attempts to deduce it based on dst and op0
-// Also updates res if it was also deduced in the process
+// Also returns res if it was also deduced in the process
 // Inserts the deduced operand
 // Fails if Op1 was not deduced or if an error arised in the process
-func (vm *VirtualMachine) ComputeOp1Deductions(op1_addr memory.Relocatable, instruction *Instruction, dst *memory.MaybeRelocatable, op0 *memory.MaybeRelocatable, res *memory.MaybeRelocatable) (memory.MaybeRelocatable, error) {
-	op1, err := vm.DeduceMemoryCell(op1_addr)
+func (vm *VirtualMachine) ComputeOp1Deductions(op1_addr memory.Relocatable, instruction *Instruction, dst memory.MaybeRelocatable, op0 memory.MaybeRelocatable) (deduced_op1 memory.MaybeRelocatable, deduced_res memory.MaybeRelocatable, err error) {
+	op1, err := vm.deduceMemoryCellValue(op1_addr)
 	if err != nil {
-		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
+		return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 	}
-	if op1 == nil {
-		var deducedRes *memory.MaybeRelocatable
-		op1, deducedRes, err = vm.DeduceOp1(instruction, dst, op0)
+	if !op1.HasValue() {
+		op1, deduced_res, err = vm.DeduceOp1(instruction, dst, op0)
 		if err != nil {
-			return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), err
-		}
-		if res == nil {
-			res = deducedRes
+			return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, err
 		}
 	}
-	if op1 != nil {
-		vm.Segments.Memory.Insert(op1_addr, op1)
-	} else {
-		return *memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()), errors.New("Failed to compute or deduce op1")
+	if !op1.HasValue() {
+		return memory.MaybeRelocatable{}, memory.MaybeRelocatable{}, errors.New("Failed to compute or deduce op1")
 	}
-	return *op1, nil
+	vm.Segments.Memory.Insert(op1_addr, &op1)
+	return op1, deduced_res, nil
 }
 
 // Updates the values of the RunContext's registers according to the executed instruction
@@ -396,7 +602,7 @@ func (vm *VirtualMachine) UpdatePc(instruction *Instruction, operands *Operands)
 	case PcUpdateRegular:
 		vm.RunContext.Pc.Offset += instruction.Size()
 	case PcUpdateJump:
-		if operands.Res == nil {
+		if !operands.Res.HasValue() {
 			return errors.New("Res.UNCONSTRAINED cannot be used with PcUpdate.JUMP")
 		}
 		res, ok := operands.Res.GetRelocatable()
@@ -405,7 +611,7 @@ func (vm *VirtualMachine) UpdatePc(instruction *Instruction, operands *Operands)
 		}
 		vm.RunContext.Pc = res
 	case PcUpdateJumpRel:
-		if operands.Res == nil {
+		if !operands.Res.HasValue() {
 			return errors.New("Res.UNCONSTRAINED cannot be used with PcUpdate.JUMP_REL")
 		}
 		res, ok := operands.Res.GetFelt()
@@ -418,10 +624,15 @@ func (vm *VirtualMachine) UpdatePc(instruction *Instruction, operands *Operands)
 		}
 		vm.RunContext.Pc = new_pc
 	case PcUpdateJnz:
+		// A relocatable dst is never zero, so in that case the jump is always taken
 		if operands.Dst.IsZero() {
 			vm.RunContext.Pc.Offset += instruction.Size()
 		} else {
-			new_pc, err := vm.RunContext.Pc.AddMaybeRelocatable(operands.Op1)
+			op1, ok := operands.Op1.GetFelt()
+			if !ok {
+				return errors.New("a relocatable value as Op1 cannot be used with PcUpdate.JNZ")
+			}
+			new_pc, err := vm.RunContext.Pc.AddFelt(op1)
 			if err != nil {
 				return err
 			}
@@ -436,10 +647,10 @@ func (vm *VirtualMachine) UpdatePc(instruction *Instruction, operands *Operands)
 func (vm *VirtualMachine) UpdateAp(instruction *Instruction, operands *Operands) error {
 	switch instruction.ApUpdate {
 	case ApUpdateAdd:
-		if operands.Res == nil {
+		if !operands.Res.HasValue() {
 			return errors.New("Res.UNCONSTRAINED cannot be used with ApUpdate.ADD")
 		}
-		new_ap, err := vm.RunContext.Ap.AddMaybeRelocatable(*operands.Res)
+		new_ap, err := vm.RunContext.Ap.AddMaybeRelocatable(operands.Res)
 		if err != nil {
 			return err
 		}
@@ -473,6 +684,16 @@ func (vm *VirtualMachine) UpdateFp(instruction *Instruction, operands *Operands)
 	return nil
 }
 
+// Returns the runner of the builtin with the given name, failing if the program doesn't use it
+func (vm *VirtualMachine) GetBuiltinRunner(name string) (builtins.BuiltinRunner, error) {
+	for i := range vm.BuiltinRunners {
+		if vm.BuiltinRunners[i].Name() == name {
+			return vm.BuiltinRunners[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Builtin %s is not used by the program", name)
+}
+
 // Applies the corresponding builtin's deduction rules if addr's segment index corresponds to a builtin segment
 // Returns nil if there is no deduction for the address
 func (vm *VirtualMachine) DeduceMemoryCell(addr memory.Relocatable) (*memory.MaybeRelocatable, error) {
@@ -486,3 +707,70 @@ func (vm *VirtualMachine) DeduceMemoryCell(addr memory.Relocatable) (*memory.May
 	}
 	return nil, nil
 }
+
+// Same as DeduceMemoryCell, returning a MaybeRelocatable without value if there is no deduction
+func (vm *VirtualMachine) deduceMemoryCellValue(addr memory.Relocatable) (memory.MaybeRelocatable, error) {
+	value, err := vm.DeduceMemoryCell(addr)
+	if err != nil || value == nil {
+		return memory.MaybeRelocatable{}, err
+	}
+	return *value, nil
+}
+
+// Writes the relocated memory in the cairo-lang binary format: each (address, value) pair is
+// encoded as an 8-byte little-endian address followed by the 32-byte little-endian value, in
+// address order and skipping memory holes.
+func EncodeMemory(relocatedMemory []*lambdaworks.Felt, dest io.Writer) error {
+	var buffer [40]byte
+	for i, value := range relocatedMemory {
+		if value == nil {
+			continue
+		}
+		binary.LittleEndian.PutUint64(buffer[:8], uint64(i))
+		copy(buffer[8:], value.ToLeBytes()[:])
+		if _, err := dest.Write(buffer[:]); err != nil {
+			return fmt.Errorf("failed to encode memory at position %d, serialize error: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// Writes the relocated memory in the cairo-lang binary format. The VM must have been relocated.
+func (v *VirtualMachine) WriteEncodedMemory(dest io.Writer) error {
+	if v.RelocatedMemory == nil {
+		return errors.New("memory not relocated")
+	}
+	return EncodeMemory(v.RelocatedMemory, dest)
+}
+
+// The relocated memory is decoded into a dense slice, so an address allocates every cell before
+// it. Addresses are capped so that a corrupted or hostile input can't allocate more than this many
+// cells, which is far more than any run fitting in memory uses
+const maxDecodedMemorySize = 1 << 28
+
+// Reads a relocated memory written in the cairo-lang binary format, the inverse of EncodeMemory.
+// Addresses not present in the encoding are left as nil holes
+func DecodeMemory(src io.Reader) ([]*lambdaworks.Felt, error) {
+	relocatedMemory := make([]*lambdaworks.Felt, 0)
+	var buffer [40]byte
+	for cell := 0; ; cell++ {
+		n, err := io.ReadFull(src, buffer[:])
+		if err == io.EOF {
+			return relocatedMemory, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode memory at cell %d: truncated cell of %d bytes", cell, n)
+		}
+		address := binary.LittleEndian.Uint64(buffer[:8])
+		if address >= maxDecodedMemorySize {
+			return nil, fmt.Errorf("failed to decode memory at cell %d: address %d exceeds the maximum memory size %d", cell, address, maxDecodedMemorySize)
+		}
+		var valueBytes [32]byte
+		copy(valueBytes[:], buffer[8:])
+		value := lambdaworks.FeltFromLeBytes(&valueBytes)
+		if address >= uint64(len(relocatedMemory)) {
+			relocatedMemory = append(relocatedMemory, make([]*lambdaworks.Felt, address+1-uint64(len(relocatedMemory)))...)
+		}
+		relocatedMemory[address] = &value
+	}
+}
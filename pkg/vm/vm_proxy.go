@@ -0,0 +1,91 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+// VMProxy is the view of the VirtualMachine handed to hints and syscall handlers.
+// It exposes a stable set of accessors so that hint code doesn't depend on the
+// internal layout of the VirtualMachine.
+type VMProxy struct {
+	vm *VirtualMachine
+}
+
+func NewVMProxy(vm *VirtualMachine) *VMProxy {
+	return &VMProxy{vm: vm}
+}
+
+// Returns the current value of the ap register
+func (p *VMProxy) GetAp() memory.Relocatable {
+	return p.vm.RunContext.Ap
+}
+
+// Returns the current value of the fp register
+func (p *VMProxy) GetFp() memory.Relocatable {
+	return p.vm.RunContext.Fp
+}
+
+// Returns the current value of the pc register
+func (p *VMProxy) GetPc() memory.Relocatable {
+	return p.vm.RunContext.Pc
+}
+
+// Reads the Felt value stored at the address of a variable
+// Fails if the cell is empty or if it holds a relocatable value
+func (p *VMProxy) GetFeltFromVar(varAddr memory.Relocatable) (lambdaworks.Felt, error) {
+	return p.vm.Segments.Memory.GetFelt(varAddr)
+}
+
+// Inserts a value at the address pointed by ap, without modifying the register
+func (p *VMProxy) InsertAtAp(val *memory.MaybeRelocatable) error {
+	return p.vm.Segments.Memory.Insert(p.vm.RunContext.Ap, val)
+}
+
+// Adds a new memory segment and returns its base
+func (p *VMProxy) AllocSegment() memory.Relocatable {
+	return p.vm.Segments.AddSegment()
+}
+
+// Reads the Relocatable value stored at the address of a variable
+// Fails if the cell is empty or if it holds a felt
+func (p *VMProxy) GetRelocatableFromVar(varAddr memory.Relocatable) (memory.Relocatable, error) {
+	return p.vm.Segments.Memory.GetRelocatable(varAddr)
+}
+
+// Returns the number of cells used by a segment. See MemorySegmentManager.SegmentUsedSize
+func (p *VMProxy) GetSegmentUsedSize(segmentIndex uint) uint {
+	return p.vm.Segments.SegmentUsedSize(segmentIndex)
+}
+
+// Inserts a value at the given address
+func (p *VMProxy) Insert(addr memory.Relocatable, val *memory.MaybeRelocatable) error {
+	return p.vm.Segments.Memory.Insert(addr, val)
+}
+
+// Converts a Cairo argument into a value, writing slices into new segments. See MemorySegmentManager.GenArg
+func (p *VMProxy) GenArg(arg any) (memory.MaybeRelocatable, error) {
+	return p.vm.Segments.GenArg(arg)
+}
+
+// Returns the runner of the builtin with the given name, failing if the program doesn't use it.
+// Hints use it to reach the state of a builtin, e.g. its base
+func (p *VMProxy) GetBuiltinRunner(name string) (builtins.BuiltinRunner, error) {
+	return p.vm.GetBuiltinRunner(name)
+}
+
+// Returns the output builtin runner, through which hints add pages and attributes to the output
+func (p *VMProxy) GetOutputBuiltin() (*builtins.OutputBuiltinRunner, error) {
+	runner, err := p.vm.GetBuiltinRunner("output")
+	if err != nil {
+		return nil, err
+	}
+	output, ok := runner.(*builtins.OutputBuiltinRunner)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected runner %T for the output builtin", runner)
+	}
+	return output, nil
+}
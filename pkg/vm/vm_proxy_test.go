@@ -0,0 +1,129 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/lambdaclass/cairo-vm.go/pkg/builtins"
+	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
+	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
+)
+
+func TestVMProxyRegisters(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.RunContext.Pc = memory.NewRelocatable(0, 3)
+	virtualMachine.RunContext.Ap = memory.NewRelocatable(1, 5)
+	virtualMachine.RunContext.Fp = memory.NewRelocatable(1, 4)
+	proxy := vm.NewVMProxy(virtualMachine)
+	if proxy.GetPc() != memory.NewRelocatable(0, 3) {
+		t.Errorf("Wrong pc value, got %+v", proxy.GetPc())
+	}
+	if proxy.GetAp() != memory.NewRelocatable(1, 5) {
+		t.Errorf("Wrong ap value, got %+v", proxy.GetAp())
+	}
+	if proxy.GetFp() != memory.NewRelocatable(1, 4) {
+		t.Errorf("Wrong fp value, got %+v", proxy.GetFp())
+	}
+}
+
+func TestVMProxyInsertAtApAndGetFeltFromVar(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	virtualMachine.RunContext.Ap = memory.NewRelocatable(0, 2)
+	proxy := vm.NewVMProxy(virtualMachine)
+
+	err := proxy.InsertAtAp(memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)))
+	if err != nil {
+		t.Errorf("InsertAtAp failed with error: %s", err)
+	}
+	felt, err := proxy.GetFeltFromVar(memory.NewRelocatable(0, 2))
+	if err != nil {
+		t.Errorf("GetFeltFromVar failed with error: %s", err)
+	}
+	if felt != lambdaworks.FeltFromUint64(7) {
+		t.Errorf("Wrong value read from var")
+	}
+}
+
+func TestVMProxyGetFeltFromVarRelocatable(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(memory.NewRelocatable(0, 0), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 1)))
+	proxy := vm.NewVMProxy(virtualMachine)
+
+	_, err := proxy.GetFeltFromVar(memory.NewRelocatable(0, 0))
+	if err == nil {
+		t.Errorf("GetFeltFromVar should fail when reading a relocatable value")
+	}
+}
+
+func TestVMProxyAllocSegment(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	proxy := vm.NewVMProxy(virtualMachine)
+	base := proxy.AllocSegment()
+	if base != memory.NewRelocatable(1, 0) {
+		t.Errorf("Wrong segment base, got %+v", base)
+	}
+	if virtualMachine.Segments.Memory.NumSegments() != 2 {
+		t.Errorf("AllocSegment should add a segment to the vm's memory")
+	}
+}
+
+func TestVMProxyInsertAndGetRelocatableFromVar(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	proxy := vm.NewVMProxy(virtualMachine)
+
+	err := proxy.Insert(memory.NewRelocatable(0, 1), memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 4)))
+	if err != nil {
+		t.Errorf("Insert failed with error: %s", err)
+	}
+	value, err := proxy.GetRelocatableFromVar(memory.NewRelocatable(0, 1))
+	if err != nil || value != memory.NewRelocatable(0, 4) {
+		t.Errorf("Wrong value read from var: %+v, err: %v", value, err)
+	}
+}
+
+func TestVMProxyGenArg(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	proxy := vm.NewVMProxy(virtualMachine)
+	arg, err := proxy.GenArg([]lambdaworks.Felt{lambdaworks.FeltFromUint64(3)})
+	if err != nil {
+		t.Fatalf("GenArg failed with error: %s", err)
+	}
+	base, ok := arg.GetRelocatable()
+	if !ok {
+		t.Fatalf("GenArg should return the base of a new segment")
+	}
+	if felt, err := proxy.GetFeltFromVar(base); err != nil || felt != lambdaworks.FeltFromUint64(3) {
+		t.Errorf("The slice should have been written to the new segment")
+	}
+}
+
+func TestVMProxyGetBuiltinRunner(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	output := builtins.NewOutputBuiltinRunner()
+	output.InitializeSegments(&virtualMachine.Segments)
+	virtualMachine.BuiltinRunners = append(virtualMachine.BuiltinRunners, output)
+	proxy := vm.NewVMProxy(virtualMachine)
+
+	runner, err := proxy.GetBuiltinRunner("output")
+	if err != nil || runner.Base() != output.Base() {
+		t.Errorf("Expected the output runner, got %v, err: %v", runner, err)
+	}
+	typed, err := proxy.GetOutputBuiltin()
+	if err != nil || typed != output {
+		t.Errorf("Expected the output runner, got %v, err: %v", typed, err)
+	}
+	if _, err := proxy.GetBuiltinRunner("ecdsa"); err == nil || err.Error() != "Builtin ecdsa is not used by the program" {
+		t.Errorf("Looking up a builtin the program doesn't use should fail, got %v", err)
+	}
+}
+
+func TestVMProxyGetOutputBuiltinWithoutOutput(t *testing.T) {
+	proxy := vm.NewVMProxy(vm.NewVirtualMachine())
+	if _, err := proxy.GetOutputBuiltin(); err == nil {
+		t.Errorf("GetOutputBuiltin should fail without the output builtin")
+	}
+}
@@ -2,11 +2,15 @@ package vm_test
 
 import (
 	"bytes"
-
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/lambdaclass/cairo-vm.go/pkg/lambdaworks"
+	"github.com/lambdaclass/cairo-vm.go/pkg/parser"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/cairo_run"
 	"github.com/lambdaclass/cairo-vm.go/pkg/vm/memory"
@@ -15,11 +19,11 @@ import (
 func TestDeduceOp0OpcodeRet(t *testing.T) {
 	instruction := vm.Instruction{Opcode: vm.Ret}
 	vm := vm.NewVirtualMachine()
-	op0, res, err := vm.DeduceOp0(&instruction, nil, nil)
+	op0, res, err := vm.DeduceOp0(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
 	if err != nil {
 		t.Errorf("DeduceOp0 failed with error: %s", err)
 	}
-	if op0 != nil || res != nil {
+	if op0.HasValue() || res.HasValue() {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -28,11 +32,11 @@ func TestDeduceOp0OpcodeAssertEqResMulOk(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6))
 	op1 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3))
-	op0, res, err := vm.DeduceOp0(&instruction, dst, op1)
+	op0, res, err := vm.DeduceOp0(&instruction, *dst, *op1)
 	if err != nil {
 		t.Errorf("DeduceOp0 failed with error: %s", err)
 	}
-	if !reflect.DeepEqual(op0, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))) || !reflect.DeepEqual(res, dst) {
+	if !reflect.DeepEqual(op0, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))) || !reflect.DeepEqual(res, *dst) {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -42,8 +46,8 @@ func TestDeduceOp0OpcodeAssertEqResMulZeroDiv(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(6))
 	op1 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0))
-	op0, res, err := vm.DeduceOp0(&instruction, dst, op1)
-	if op0 != nil || res != nil || err != nil {
+	op0, res, err := vm.DeduceOp0(&instruction, *dst, *op1)
+	if op0.HasValue() || res.HasValue() || err != nil {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -53,8 +57,8 @@ func TestDeduceOp0OpcodeAssertEqResMulRelValues(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	dst := memory.NewMaybeRelocatableRelocatable(memory.Relocatable{})
 	op1 := memory.NewMaybeRelocatableRelocatable(memory.Relocatable{})
-	op0, res, err := vm.DeduceOp0(&instruction, dst, op1)
-	if op0 != nil || res != nil || err != nil {
+	op0, res, err := vm.DeduceOp0(&instruction, *dst, *op1)
+	if op0.HasValue() || res.HasValue() || err != nil {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -62,8 +66,8 @@ func TestDeduceOp0OpcodeAssertEqResMulRelValues(t *testing.T) {
 func TestDeduceOp0OpcodeAssertEqResMulNilValues(t *testing.T) {
 	instruction := vm.Instruction{Opcode: vm.AssertEq, ResLogic: vm.ResAdd}
 	vm := vm.NewVirtualMachine()
-	op0, res, err := vm.DeduceOp0(&instruction, nil, nil)
-	if op0 != nil || res != nil || err != nil {
+	op0, res, err := vm.DeduceOp0(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
+	if op0.HasValue() || res.HasValue() || err != nil {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -73,11 +77,11 @@ func TestDeduceOp0OpcodeAssertEqResAddOk(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
 	op1 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))
-	op0, res, err := vm.DeduceOp0(&instruction, dst, op1)
+	op0, res, err := vm.DeduceOp0(&instruction, *dst, *op1)
 	if err != nil {
 		t.Errorf("DeduceOp0 failed with error: %s", err)
 	}
-	if !reflect.DeepEqual(op0, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))) || !reflect.DeepEqual(res, dst) {
+	if !reflect.DeepEqual(op0, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))) || !reflect.DeepEqual(res, *dst) {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -87,11 +91,11 @@ func TestDeduceOp0OpcodeAssertEqResAddRelValues(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	dst := memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 6})
 	op1 := memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 2})
-	op0, res, err := vm.DeduceOp0(&instruction, dst, op1)
+	op0, res, err := vm.DeduceOp0(&instruction, *dst, *op1)
 	if err != nil {
 		t.Errorf("DeduceOp0 failed with error: %s", err)
 	}
-	if !reflect.DeepEqual(op0, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(4))) || !reflect.DeepEqual(res, dst) {
+	if !reflect.DeepEqual(op0, *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(4))) || !reflect.DeepEqual(res, *dst) {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -99,8 +103,8 @@ func TestDeduceOp0OpcodeAssertEqResAddRelValues(t *testing.T) {
 func TestDeduceOp0OpcodeAssertEqResAddNilValues(t *testing.T) {
 	instruction := vm.Instruction{Opcode: vm.AssertEq, ResLogic: vm.ResAdd}
 	vm := vm.NewVirtualMachine()
-	op0, res, err := vm.DeduceOp0(&instruction, nil, nil)
-	if op0 != nil || res != nil || err != nil {
+	op0, res, err := vm.DeduceOp0(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
+	if op0.HasValue() || res.HasValue() || err != nil {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -109,11 +113,11 @@ func TestDeduceOp0OpcodeCall(t *testing.T) {
 	instruction := vm.Instruction{Opcode: vm.Call, Op1Addr: vm.Op1SrcAP}
 	vm := vm.NewVirtualMachine()
 	vm.RunContext.Pc = memory.Relocatable{SegmentIndex: 1, Offset: 7}
-	op0, res, err := vm.DeduceOp0(&instruction, nil, nil)
+	op0, res, err := vm.DeduceOp0(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
 	if err != nil {
 		t.Errorf("DeduceOp0 failed with error: %s", err)
 	}
-	if !reflect.DeepEqual(op0, memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 8})) || res != nil {
+	if !reflect.DeepEqual(op0, *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 8})) || res.HasValue() {
 		t.Errorf("Wrong values returned by DeduceOp0")
 	}
 }
@@ -139,7 +143,7 @@ func TestUpdateRegistersAllRegularNoImm(t *testing.T) {
 
 func TestUpdateRegistersMixedTypes(t *testing.T) {
 	instruction := vm.Instruction{FpUpdate: vm.FpUpdateDst, ApUpdate: vm.ApUpdateAdd2, PcUpdate: vm.PcUpdateJumpRel, Op1Addr: vm.Op1SrcAP}
-	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 11)), Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8))}
+	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 11)), Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8))}
 	v := vm.NewVirtualMachine()
 	v.RunContext = vm.RunContext{Pc: memory.NewRelocatable(0, 4), Ap: memory.NewRelocatable(1, 5), Fp: memory.NewRelocatable(1, 6)}
 	err := v.UpdateRegisters(&instruction, &operands)
@@ -249,7 +253,7 @@ func TestUpdateApAdd1(t *testing.T) {
 }
 func TestUpdateApAddWithResInt(t *testing.T) {
 	instruction := vm.Instruction{ApUpdate: vm.ApUpdateAdd}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdateAp(&instruction, &operands)
 	if err != nil {
@@ -262,7 +266,7 @@ func TestUpdateApAddWithResInt(t *testing.T) {
 
 func TestUpdateApAddWithResRel(t *testing.T) {
 	instruction := vm.Instruction{ApUpdate: vm.ApUpdateAdd}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableRelocatable(memory.Relocatable{})}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{})}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdateAp(&instruction, &operands)
 	if err == nil {
@@ -309,7 +313,7 @@ func TestUpdatePcRegularWithImm(t *testing.T) {
 func TestUpdatePcJumpWithRelRes(t *testing.T) {
 	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJump}
 	res := memory.Relocatable{SegmentIndex: 0, Offset: 5}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableRelocatable(res)}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableRelocatable(res)}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdatePc(&instruction, &operands)
 	if err != nil {
@@ -322,7 +326,7 @@ func TestUpdatePcJumpWithRelRes(t *testing.T) {
 
 func TestUpdatePcJumpWithIntRes(t *testing.T) {
 	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJump}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0))}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0))}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdatePc(&instruction, &operands)
 	if err == nil {
@@ -343,7 +347,7 @@ func TestUpdatePcJumpWithoutRes(t *testing.T) {
 
 func TestUpdatePcJumpRelWithIntRes(t *testing.T) {
 	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJumpRel}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdatePc(&instruction, &operands)
 	if err != nil {
@@ -357,7 +361,7 @@ func TestUpdatePcJumpRelWithIntRes(t *testing.T) {
 func TestUpdatePcJumpRelWithRelRes(t *testing.T) {
 	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJumpRel}
 	res := memory.Relocatable{SegmentIndex: 0, Offset: 5}
-	operands := vm.Operands{Res: memory.NewMaybeRelocatableRelocatable(res)}
+	operands := vm.Operands{Res: *memory.NewMaybeRelocatableRelocatable(res)}
 	vm := vm.NewVirtualMachine()
 
 	err := vm.UpdatePc(&instruction, &operands)
@@ -421,11 +425,49 @@ func TestUpdatePcJnzDstNotZeroOp1Rel(t *testing.T) {
 	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)), Op1: *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{})}
 	vm := vm.NewVirtualMachine()
 	err := vm.UpdatePc(&instruction, &operands)
+	if err == nil || err.Error() != "a relocatable value as Op1 cannot be used with PcUpdate.JNZ" {
+		t.Errorf("UpdatePc should have failed with a relocatable op1, got: %v", err)
+	}
+}
+
+func TestUpdatePcJnzDstRelOp1Int(t *testing.T) {
+	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJnz}
+	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 0}), Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5))}
+	vm := vm.NewVirtualMachine()
+	vm.RunContext.Pc = memory.Relocatable{SegmentIndex: 0, Offset: 2}
+	err := vm.UpdatePc(&instruction, &operands)
+	if err != nil {
+		t.Errorf("UpdatePc failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(vm.RunContext.Pc, memory.Relocatable{SegmentIndex: 0, Offset: 7}) {
+		t.Errorf("Wrong value after pc update %v", vm.RunContext.Pc)
+	}
+}
+
+func TestUpdatePcJnzDstRelOp1Rel(t *testing.T) {
+	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJnz}
+	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 1, Offset: 0}), Op1: *memory.NewMaybeRelocatableRelocatable(memory.Relocatable{SegmentIndex: 0, Offset: 3})}
+	vm := vm.NewVirtualMachine()
+	err := vm.UpdatePc(&instruction, &operands)
 	if err == nil {
 		t.Errorf("UpdatePc should have failed")
 	}
 }
 
+func TestUpdatePcJnzDstNotZeroNegativeOp1(t *testing.T) {
+	instruction := vm.Instruction{PcUpdate: vm.PcUpdateJnz}
+	operands := vm.Operands{Dst: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(1)), Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromDecString("-2"))}
+	vm := vm.NewVirtualMachine()
+	vm.RunContext.Pc = memory.Relocatable{SegmentIndex: 0, Offset: 5}
+	err := vm.UpdatePc(&instruction, &operands)
+	if err != nil {
+		t.Errorf("UpdatePc failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(vm.RunContext.Pc, memory.Relocatable{SegmentIndex: 0, Offset: 3}) {
+		t.Errorf("Wrong value after pc update %v", vm.RunContext.Pc)
+	}
+}
+
 // Things we are skipping for now:
 // - Initializing hint_executor and passing it to `cairo_run`
 // - cairo_run_config stuff
@@ -489,7 +531,7 @@ func TestComputeOperandsAddAp(t *testing.T) {
 
 	expected_operands := vm.Operands{
 		Dst: *dst_addr_value,
-		Res: dst_addr_value,
+		Res: *dst_addr_value,
 		Op0: *op0_addr_value,
 		Op1: *op1_addr_value,
 	}
@@ -505,11 +547,48 @@ func TestComputeOperandsAddAp(t *testing.T) {
 	if operands.Op1 != expected_operands.Op1 {
 		t.Errorf("Different op1 register")
 	}
-	if *operands.Res != *expected_operands.Res {
+	if operands.Res != expected_operands.Res {
 		t.Errorf("Different res register")
 	}
 }
 
+func TestRunInstructionDoesNotAllocate(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations can't be measured with the race detector on")
+	}
+	// [ap] = [fp - 1]; ap++
+	instruction := vm.Instruction{
+		Off0:     0,
+		Off1:     -1,
+		Off2:     -1,
+		DstReg:   vm.AP,
+		Op0Reg:   vm.FP,
+		Op1Addr:  vm.Op1SrcFP,
+		ResLogic: vm.ResOp1,
+		PcUpdate: vm.PcUpdateRegular,
+		ApUpdate: vm.ApUpdateAdd1,
+		FpUpdate: vm.FpUpdateRegular,
+		Opcode:   vm.AssertEq,
+	}
+	virtualMachine := vm.NewVirtualMachine()
+	virtualMachine.Segments.AddSegment()
+	execution := virtualMachine.Segments.AddSegment()
+	virtualMachine.Segments.Memory.Insert(execution, memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)))
+	virtualMachine.RunContext.Ap = memory.NewRelocatable(execution.SegmentIndex, 1)
+	virtualMachine.RunContext.Fp = memory.NewRelocatable(execution.SegmentIndex, 1)
+	// Growing the trace and the memory is amortized over the runs
+	virtualMachine.Trace = make([]vm.TraceEntry, 0, 1000)
+
+	allocs := testing.AllocsPerRun(500, func() {
+		if err := virtualMachine.RunInstruction(&instruction); err != nil {
+			t.Fatalf("RunInstruction failed with error: %s", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected RunInstruction not to allocate, got %v allocations per run", allocs)
+	}
+}
+
 func TestDeduceMemoryCellNoBuiltins(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	addr := memory.Relocatable{}
@@ -541,13 +620,24 @@ func TestRelocateTraceOneEntry(t *testing.T) {
 }
 
 func TestWriteBinaryMemoryFile(t *testing.T) {
-	var relocatedMemory = make(map[uint]lambdaworks.Felt)
-	relocatedMemory[1] = lambdaworks.FeltFromUint64(66)
-	relocatedMemory[2] = lambdaworks.FeltFromUint64(42)
-	relocatedMemory[3] = lambdaworks.FeltFromUint64(30)
+	a := lambdaworks.FeltFromUint64(66)
+	b := lambdaworks.FeltFromUint64(42)
+	relocatedMemory := []*lambdaworks.Felt{nil, &a, nil, &b}
 
 	var actualMemoryBuffer bytes.Buffer
-	cairo_run.WriteEncodedMemory(relocatedMemory, &actualMemoryBuffer)
+	err := cairo_run.WriteEncodedMemory(relocatedMemory, &actualMemoryBuffer)
+	if err != nil {
+		t.Errorf("WriteEncodedMemory failed with error: %s", err)
+	}
+
+	// Holes are skipped, so only two (address, value) pairs are written
+	encoded := actualMemoryBuffer.Bytes()
+	if len(encoded) != 2*40 {
+		t.Fatalf("Wrong encoded memory length: %d", len(encoded))
+	}
+	if encoded[0] != 1 || encoded[8] != 66 || encoded[40] != 3 || encoded[48] != 42 {
+		t.Errorf("Wrong encoded memory: %v", encoded)
+	}
 }
 
 func buildTestProgramMemory(virtualMachine *vm.VirtualMachine) {
@@ -577,7 +667,6 @@ func TestOpcodeAssertionsResUnconstrained(t *testing.T) {
 
 	operands := vm.Operands{
 		Dst: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
-		Res: nil,
 		Op0: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)),
 		Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
 	}
@@ -607,7 +696,7 @@ func TestOpcodeAssertionsInstructionFailed(t *testing.T) {
 
 	operands := vm.Operands{
 		Dst: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)),
-		Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
+		Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
 		Op0: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)),
 		Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
 	}
@@ -637,7 +726,7 @@ func TestOpcodeAssertionsInstructionFailedRelocatables(t *testing.T) {
 
 	operands := vm.Operands{
 		Dst: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 1)),
-		Res: memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2)),
+		Res: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2)),
 		Op0: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)),
 		Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
 	}
@@ -666,7 +755,7 @@ func TestOpcodeAssertionsInconsistentOp0(t *testing.T) {
 
 	operands := vm.Operands{
 		Dst: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 8)),
-		Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
+		Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
 		Op0: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(9)),
 		Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
 	}
@@ -696,7 +785,7 @@ func TestOpcodeAssertionsInconsistentDst(t *testing.T) {
 
 	operands := vm.Operands{
 		Dst: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
-		Res: memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
+		Res: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(8)),
 		Op0: *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(0, 1)),
 		Op1: *memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(10)),
 	}
@@ -726,17 +815,17 @@ func TestDeduceOp1OpcodeCall(t *testing.T) {
 
 	vm := vm.NewVirtualMachine()
 
-	m1, m2, err := vm.DeduceOp1(&instruction, nil, nil)
+	m1, m2, err := vm.DeduceOp1(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if m1 != nil {
+	if m1.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 
-	if m2 != nil {
+	if m2.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 }
@@ -764,16 +853,16 @@ func TestDeduceOp1OpcodeAssertEqResAddWithOptionals(t *testing.T) {
 	expected_dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltOne())
 	expected_op0 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(3))
 
-	m1, m2, err := vm.DeduceOp1(&instruction, dst, op0)
+	m1, m2, err := vm.DeduceOp1(&instruction, *dst, *op0)
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if *m1 != *expected_dst {
+	if m1 != *expected_dst {
 		t.Error("Different dst value")
 	}
-	if *m2 != *expected_op0 {
+	if m2 != *expected_op0 {
 		t.Error("Different op0 value")
 	}
 }
@@ -795,17 +884,17 @@ func TestDeduceOp1OpcodeAssertEqResAddWithoutOptionals(t *testing.T) {
 
 	vm := vm.NewVirtualMachine()
 
-	m1, m2, err := vm.DeduceOp1(&instruction, nil, nil)
+	m1, m2, err := vm.DeduceOp1(&instruction, memory.MaybeRelocatable{}, memory.MaybeRelocatable{})
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if m1 != nil {
+	if m1.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 
-	if m2 != nil {
+	if m2.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 }
@@ -832,16 +921,16 @@ func TestDeduceOp1OpcodeAssertEqResMulNonZeroOp0(t *testing.T) {
 	expected_dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(2))
 	expected_op0 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(4))
 
-	m1, m2, err := vm.DeduceOp1(&instruction, dst, op0)
+	m1, m2, err := vm.DeduceOp1(&instruction, *dst, *op0)
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if *m1 != *expected_dst {
+	if m1 != *expected_dst {
 		t.Error("Different dst value")
 	}
-	if *m2 != *expected_op0 {
+	if m2 != *expected_op0 {
 		t.Error("Different op0 value")
 	}
 }
@@ -866,17 +955,17 @@ func TestDeduceOp1OpcodeAssertEqResMulZeroOp0(t *testing.T) {
 	dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(4))
 	op0 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0))
 
-	m1, m2, err := vm.DeduceOp1(&instruction, dst, op0)
+	m1, m2, err := vm.DeduceOp1(&instruction, *dst, *op0)
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if m1 != nil {
+	if m1.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 
-	if m2 != nil {
+	if m2.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 }
@@ -900,17 +989,17 @@ func TestDeduceOp1OpcodeAssertEqResOp1WithoutDst(t *testing.T) {
 
 	op0 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(0))
 
-	m1, m2, err := vm.DeduceOp1(&instruction, nil, op0)
+	m1, m2, err := vm.DeduceOp1(&instruction, memory.MaybeRelocatable{}, *op0)
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if m1 != nil {
+	if m1.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 
-	if m2 != nil {
+	if m2.HasValue() {
 		t.Error("maybe relocatable of deduced operand is not nil")
 	}
 }
@@ -936,16 +1025,16 @@ func TestDeduceOp1OpcodeAssertEqResOp1WithDst(t *testing.T) {
 	expected_dst := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
 	expected_op0 := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
 
-	m1, m2, err := vm.DeduceOp1(&instruction, dst, nil)
+	m1, m2, err := vm.DeduceOp1(&instruction, *dst, memory.MaybeRelocatable{})
 
 	if err != nil {
 		t.Error(err)
 	}
 
-	if *m1 != *expected_dst {
+	if m1 != *expected_dst {
 		t.Error("Different dst value")
 	}
-	if *m2 != *expected_op0 {
+	if m2 != *expected_op0 {
 		t.Error("Different op0 value")
 	}
 }
@@ -970,9 +1059,9 @@ func TestDeduceDstOpcodeAssertEqWithRes(t *testing.T) {
 	res := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
 	expected_res := memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7))
 
-	result_res := vm.DeduceDst(instruction, res)
+	result_res := vm.DeduceDst(instruction, *res)
 
-	if *expected_res != *result_res {
+	if *expected_res != result_res {
 		t.Error("Different Res value")
 	}
 }
@@ -995,11 +1084,11 @@ func TestDeduceDstOpcodeCall(t *testing.T) {
 	vm := vm.NewVirtualMachine()
 	vm.RunContext.Fp = memory.NewRelocatable(1, 0)
 
-	result_dst := vm.DeduceDst(instruction, nil)
+	result_dst := vm.DeduceDst(instruction, memory.MaybeRelocatable{})
 	mr := memory.NewRelocatable(1, 0)
 	expected_dst := memory.NewMaybeRelocatableRelocatable(mr)
 
-	if *result_dst != *expected_dst {
+	if result_dst != *expected_dst {
 		t.Error("Different Dst value")
 	}
 }
@@ -1020,9 +1109,9 @@ func TestDeduceDstOpcodeAssertEqWithoutRes(t *testing.T) {
 	}
 
 	vm := vm.NewVirtualMachine()
-	result_res := vm.DeduceDst(instruction, nil)
+	result_res := vm.DeduceDst(instruction, memory.MaybeRelocatable{})
 
-	if result_res != nil {
+	if result_res.HasValue() {
 		t.Error("Different Res value")
 	}
 }
@@ -1044,9 +1133,314 @@ func TestDeduceDstOpcodeRet(t *testing.T) {
 
 	vm := vm.NewVirtualMachine()
 
-	result_dst := vm.DeduceDst(instruction, nil)
+	result_dst := vm.DeduceDst(instruction, memory.MaybeRelocatable{})
 
-	if result_dst != nil {
+	if result_dst.HasValue() {
 		t.Error("Different Dst value than nil")
 	}
 }
+
+func TestRunInstructionInvalidOffset(t *testing.T) {
+	instruction := vm.Instruction{Off0: vm.MaxOffset + 1, Opcode: vm.NOp}
+	vm := vm.NewVirtualMachine()
+	err := vm.RunInstruction(&instruction)
+	if err == nil {
+		t.Errorf("RunInstruction should fail with an out of range offset")
+	}
+	if vm.CurrentStep != 0 || len(vm.Trace) != 0 {
+		t.Errorf("RunInstruction should not execute an invalid instruction")
+	}
+}
+
+func TestComputeOperandsNegativeSegmentAccess(t *testing.T) {
+	instruction := vm.Instruction{DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP, Opcode: vm.NOp}
+	vmachine := vm.NewVirtualMachine()
+	vmachine.Segments.AddSegment()
+	vmachine.RunContext.Ap = memory.NewRelocatable(-1, 0)
+	_, err := vmachine.ComputeOperands(instruction)
+	if err == nil {
+		t.Errorf("ComputeOperands should propagate memory access errors")
+	}
+}
+
+func TestEncodeMemorySkipsHoles(t *testing.T) {
+	one := lambdaworks.FeltOne()
+	value := lambdaworks.FeltFromHex("0x0102")
+	relocatedMemory := []*lambdaworks.Felt{nil, &one, nil, &value}
+	expected := make([]byte, 80)
+	expected[0] = 1
+	expected[8] = 1
+	expected[40] = 3
+	expected[48] = 2
+	expected[49] = 1
+	var buffer bytes.Buffer
+	if err := vm.EncodeMemory(relocatedMemory, &buffer); err != nil {
+		t.Fatalf("EncodeMemory failed with error: %s", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), expected) {
+		t.Errorf("Wrong encoded memory. Expected %v, got %v", expected, buffer.Bytes())
+	}
+}
+
+func TestWriteEncodedMemoryNotRelocated(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	var buffer bytes.Buffer
+	if err := virtualMachine.WriteEncodedMemory(&buffer); err == nil {
+		t.Errorf("WriteEncodedMemory should fail if the memory wasn't relocated")
+	}
+}
+
+func TestDecodeMemoryRoundTrip(t *testing.T) {
+	one := lambdaworks.FeltOne()
+	value := lambdaworks.FeltFromHex("0x0102")
+	relocatedMemory := []*lambdaworks.Felt{nil, &one, nil, &value}
+	var buffer bytes.Buffer
+	if err := vm.EncodeMemory(relocatedMemory, &buffer); err != nil {
+		t.Fatalf("EncodeMemory failed with error: %s", err)
+	}
+	decoded, err := vm.DecodeMemory(&buffer)
+	if err != nil || !reflect.DeepEqual(decoded, relocatedMemory) {
+		t.Errorf("Wrong decoded memory. Expected %v, got %v, err: %v", relocatedMemory, decoded, err)
+	}
+}
+
+func TestDecodeMemoryTruncated(t *testing.T) {
+	if _, err := vm.DecodeMemory(bytes.NewReader(make([]byte, 41))); err == nil {
+		t.Errorf("DecodeMemory should fail on a truncated cell")
+	}
+}
+
+func TestDecodeMemorySparse(t *testing.T) {
+	value := lambdaworks.FeltFromUint64(7)
+	relocatedMemory := make([]*lambdaworks.Felt, 101)
+	relocatedMemory[100] = &value
+	var buffer bytes.Buffer
+	if err := vm.EncodeMemory(relocatedMemory, &buffer); err != nil {
+		t.Fatalf("EncodeMemory failed with error: %s", err)
+	}
+	decoded, err := vm.DecodeMemory(&buffer)
+	if err != nil || !reflect.DeepEqual(decoded, relocatedMemory) {
+		t.Errorf("Wrong decoded memory. Expected %v, got %v, err: %v", relocatedMemory, decoded, err)
+	}
+}
+
+func TestDecodeMemoryHugeAddress(t *testing.T) {
+	var buffer [40]byte
+	binary.LittleEndian.PutUint64(buffer[:8], 1<<40)
+	if _, err := vm.DecodeMemory(bytes.NewReader(buffer[:])); err == nil {
+		t.Errorf("DecodeMemory should fail on an address out of bounds")
+	}
+}
+
+// A vm about to run `[ap] = 5; ap++` from 0:0, with ap = fp = 1:1 and [fp - 1] = 0
+func hooksVM(t *testing.T) *vm.VirtualMachine {
+	virtualMachine := vm.NewVirtualMachine()
+	programBase := virtualMachine.Segments.AddSegment()
+	executionBase := virtualMachine.Segments.AddSegment()
+	data := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x480680017fff8000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+	}
+	if _, err := virtualMachine.Segments.LoadData(programBase, data); err != nil {
+		t.Fatalf("LoadData error in test: %s", err)
+	}
+	if _, err := virtualMachine.Segments.LoadData(executionBase, []memory.MaybeRelocatable{*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero())}); err != nil {
+		t.Fatalf("LoadData error in test: %s", err)
+	}
+	executionBase.Offset = 1
+	virtualMachine.RunContext = vm.RunContext{Pc: programBase, Ap: executionBase, Fp: executionBase}
+	return virtualMachine
+}
+
+func TestStepHooks(t *testing.T) {
+	virtualMachine := hooksVM(t)
+	var calls []string
+	virtualMachine.Hooks.PreStep = func(v *vm.VirtualMachine) error {
+		calls = append(calls, fmt.Sprintf("pre %d", v.RunContext.Pc.Offset))
+		return nil
+	}
+	virtualMachine.Hooks.PostStep = func(v *vm.VirtualMachine) error {
+		calls = append(calls, fmt.Sprintf("post %d", v.RunContext.Pc.Offset))
+		return nil
+	}
+	if err := virtualMachine.Step(); err != nil {
+		t.Fatalf("Step error in test: %s", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"pre 0", "post 2"}) {
+		t.Errorf("Wrong hook calls, got %v", calls)
+	}
+}
+
+func TestPreStepHookErrorAbortsStep(t *testing.T) {
+	virtualMachine := hooksVM(t)
+	virtualMachine.Hooks.PreStep = func(v *vm.VirtualMachine) error { return errors.New("stop") }
+	if err := virtualMachine.Step(); err == nil || virtualMachine.CurrentStep != 0 {
+		t.Errorf("The step should have been aborted, err: %v", err)
+	}
+}
+
+func TestStepDecodedProgram(t *testing.T) {
+	virtualMachine := hooksVM(t)
+	program := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x480680017fff8000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+	}
+	if err := virtualMachine.LoadDecodedProgram(virtualMachine.RunContext.Pc, vm.DecodeProgram(program)); err != nil {
+		t.Fatalf("LoadDecodedProgram error in test: %s", err)
+	}
+	if err := virtualMachine.Step(); err != nil {
+		t.Fatalf("Step error in test: %s", err)
+	}
+	value, err := virtualMachine.Segments.Memory.GetFelt(memory.NewRelocatable(1, 1))
+	if err != nil || value != lambdaworks.FeltFromUint64(5) {
+		t.Errorf("Expected the step to write 5, got %v, err: %v", value, err)
+	}
+	if virtualMachine.RunContext.Pc != memory.NewRelocatable(0, 2) || virtualMachine.RunContext.Ap != memory.NewRelocatable(1, 2) {
+		t.Errorf("Wrong registers after the step: %+v", virtualMachine.RunContext)
+	}
+	// Past the end of the decoded program, the instruction is fetched from memory
+	if err := virtualMachine.Step(); err == nil || !strings.Contains(err.Error(), "Failed to fetch instruction") {
+		t.Errorf("Expected the step past the program to fail fetching its instruction, got %v", err)
+	}
+}
+
+func TestLoadDecodedProgramMismatch(t *testing.T) {
+	virtualMachine := hooksVM(t)
+	// [ap] = 5 without incrementing ap, while memory holds [ap] = 5; ap++
+	program := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x400680017fff8000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+	}
+	if err := virtualMachine.LoadDecodedProgram(virtualMachine.RunContext.Pc, vm.DecodeProgram(program)); err == nil {
+		t.Errorf("LoadDecodedProgram should fail on a program that doesn't match memory")
+	}
+}
+
+// hooksVM with its program decoded ahead of time
+func codeWritesVM(t *testing.T) *vm.VirtualMachine {
+	virtualMachine := hooksVM(t)
+	program := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromHex("0x480680017fff8000")),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(5)),
+	}
+	if err := virtualMachine.LoadDecodedProgram(virtualMachine.RunContext.Pc, vm.DecodeProgram(program)); err != nil {
+		t.Fatalf("LoadDecodedProgram error in test: %s", err)
+	}
+	return virtualMachine
+}
+
+// A hook writing [ap] = 7, without incrementing ap, right after the program once the pc reaches it
+func writeCode(v *vm.VirtualMachine) error {
+	if v.RunContext.Pc != memory.NewRelocatable(0, 2) {
+		return nil
+	}
+	instruction := vm.Instruction{Off0: 0, Off1: -1, Off2: 1, DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcImm,
+		ResLogic: vm.ResOp1, PcUpdate: vm.PcUpdateRegular, ApUpdate: vm.ApUpdateRegular, FpUpdate: vm.FpUpdateRegular, Opcode: vm.AssertEq}
+	encoded, err := instruction.Encode()
+	if err != nil {
+		return err
+	}
+	_, err = v.Segments.LoadData(v.RunContext.Pc, []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(encoded)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltFromUint64(7)),
+	})
+	return err
+}
+
+func TestStepRunsCodeWrittenByHooks(t *testing.T) {
+	virtualMachine := codeWritesVM(t)
+	virtualMachine.Hooks.PreStep = writeCode
+	for i := 0; i < 2; i++ {
+		if err := virtualMachine.Step(); err != nil {
+			t.Fatalf("Step error in test: %s", err)
+		}
+	}
+	// The code written past the decoded program is decoded from memory
+	value, err := virtualMachine.Segments.Memory.GetFelt(memory.NewRelocatable(1, 2))
+	if err != nil || value != lambdaworks.FeltFromUint64(7) || virtualMachine.RunContext.Ap != memory.NewRelocatable(1, 2) {
+		t.Errorf("Expected [ap] = 7 to run, got value %v and ap %+v, err: %v", value, virtualMachine.RunContext.Ap, err)
+	}
+}
+
+func TestStepForbiddenCodeWrites(t *testing.T) {
+	virtualMachine := codeWritesVM(t)
+	virtualMachine.Segments.Memory.SetForbidCodeWrites(true)
+	virtualMachine.Hooks.PreStep = writeCode
+	if err := virtualMachine.Step(); err != nil {
+		t.Fatalf("Step error in test: %s", err)
+	}
+	if err := virtualMachine.Step(); err == nil || !strings.Contains(err.Error(), "holds code") {
+		t.Errorf("Expected the hook's write to the program segment to fail, got %v", err)
+	}
+}
+
+func TestStepUnsupportedOpcodeExtension(t *testing.T) {
+	virtualMachine := vm.NewVirtualMachine()
+	programBase := virtualMachine.Segments.AddSegment()
+	executionBase := virtualMachine.Segments.AddSegment()
+	// [ap] = [fp] + [fp] as a QM31 addition
+	instruction := vm.Instruction{DstReg: vm.AP, Op0Reg: vm.FP, Op1Addr: vm.Op1SrcFP, ResLogic: vm.ResAdd,
+		Opcode: vm.AssertEq, OpcodeExtension: vm.OpcodeExtensionQM31Operation}
+	encoded, err := instruction.EncodeFelt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := virtualMachine.Segments.Memory.Insert(programBase, memory.NewMaybeRelocatableFelt(encoded)); err != nil {
+		t.Fatal(err)
+	}
+	virtualMachine.RunContext = vm.RunContext{Pc: programBase, Ap: executionBase, Fp: executionBase}
+	if err := virtualMachine.Step(); err == nil || err.Error() != "Opcode extension qm31_operation is not supported yet" {
+		t.Errorf("Expected the QM31 instruction to be rejected, got %v", err)
+	}
+}
+
+func TestInitializeProofModeFrame(t *testing.T) {
+	var runContext vm.RunContext
+	builtinBase := *memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(2, 0))
+	stack := runContext.InitializeProofModeFrame(memory.NewRelocatable(1, 0), []memory.MaybeRelocatable{builtinBase})
+	expected := []memory.MaybeRelocatable{
+		*memory.NewMaybeRelocatableRelocatable(memory.NewRelocatable(1, 2)),
+		*memory.NewMaybeRelocatableFelt(lambdaworks.FeltZero()),
+		builtinBase,
+	}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Wrong stack, expected %v, got %v", expected, stack)
+	}
+	if runContext.InitialAp != memory.NewRelocatable(1, 2) || runContext.InitialFp != memory.NewRelocatable(1, 2) {
+		t.Errorf("Expected the initial ap and fp right after the frame, got %+v and %+v", runContext.InitialAp, runContext.InitialFp)
+	}
+}
+
+func TestHintsPreStep(t *testing.T) {
+	program := vm.Program{Hints: map[uint][]parser.HintParams{
+		0: {{Code: "a"}, {Code: "b"}},
+		2: {{Code: "c"}},
+	}}
+	var ran []string
+	preStep := vm.HintsPreStep(&program, func(v *vm.VirtualMachine, index uint, hint *parser.HintParams) error {
+		ran = append(ran, fmt.Sprintf("%d:%s", index, hint.Code))
+		return nil
+	})
+	virtualMachine := vm.NewVirtualMachine()
+	for _, pc := range []memory.Relocatable{memory.NewRelocatable(0, 2), memory.NewRelocatable(0, 1), memory.NewRelocatable(0, 0), memory.NewRelocatable(1, 0)} {
+		virtualMachine.RunContext.Pc = pc
+		if err := preStep(virtualMachine); err != nil {
+			t.Fatalf("PreStep error in test: %s", err)
+		}
+	}
+	// The hints at 0 run in order, none run outside of the program segment
+	expected := []string{"2:c", "0:a", "1:b"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("Expected the hints %v to run, got %v", expected, ran)
+	}
+
+	virtualMachine.RunContext.Pc = memory.NewRelocatable(0, 0)
+	ran = nil
+	failing := vm.HintsPreStep(&program, func(v *vm.VirtualMachine, index uint, hint *parser.HintParams) error {
+		ran = append(ran, hint.Code)
+		return errors.New("hint failed")
+	})
+	if err := failing(virtualMachine); err == nil || len(ran) != 1 {
+		t.Errorf("Expected the first failing hint to stop the others, ran %v, err: %v", ran, err)
+	}
+}